@@ -0,0 +1,253 @@
+package blueprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// GenerateBlueprint writes the API in API Blueprint format (https://apiblueprint.org/) to outputSpec, ./API.apib by default
+func GenerateBlueprint(parser *parser.Parser, host string, outputSpec *string) error {
+	var filename string
+	if *outputSpec == "" {
+		filename = path.Join("./", "API.apib")
+	} else {
+		filename = path.Join(*outputSpec)
+	}
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create API Blueprint file: %v\n", err)
+	}
+	defer fd.Close()
+
+	fd.Write(Render(parser, host))
+	return nil
+}
+
+// Render translates top level APIs to groups, sub APIs to resources, operations to actions
+// and models to data structures
+func Render(p *parser.Parser, host string) []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString("FORMAT: 1A\n")
+	if host != "" {
+		buf.WriteString("HOST: " + host + "\n")
+	}
+	title := p.Listing.Infos.Title
+	if title == "" {
+		title = "API"
+	}
+	buf.WriteString(fmt.Sprintf("\n# %s\n\n", title))
+	if description := p.Listing.Infos.FullDescription(); description != "" {
+		buf.WriteString(description + "\n\n")
+	}
+
+	apiKeys := make([]string, 0, len(p.TopLevelApis))
+	for apiKey := range p.TopLevelApis {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Strings(apiKeys)
+
+	// data structures are named after the last part of the model Ids, prefixed with the package when two models share it
+	models := p.GetModels()
+	modelIds := make([]string, 0, len(models))
+	for id := range models {
+		modelIds = append(modelIds, id)
+	}
+	sort.Strings(modelIds)
+	names := parser.ShortModelNames(modelIds)
+
+	for _, apiKey := range apiKeys {
+		api := p.TopLevelApis[apiKey]
+		buf.WriteString(fmt.Sprintf("# Group %s\n\n", apiKey))
+		for _, ref := range p.Listing.Apis {
+			if ref.Path == api.ResourcePath && ref.Description != "" {
+				buf.WriteString(ref.Description + "\n\n")
+			}
+		}
+
+		for _, subApi := range api.Apis {
+			buf.WriteString(fmt.Sprintf("## %s [%s]\n\n", subApi.Path, subApi.Path))
+			for _, op := range subApi.Operations {
+				writeAction(&buf, api, subApi.Path, op, names)
+			}
+		}
+	}
+
+	if len(models) > 0 {
+		buf.WriteString("# Data Structures\n\n")
+		for _, id := range modelIds {
+			writeDataStructure(&buf, models[id], names)
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeAction(buf *bytes.Buffer, api *parser.ApiDeclaration, apiPath string, op *parser.Operation, names map[string]string) {
+	name := op.Nickname
+	if name == "" {
+		name = op.HttpMethod + " " + apiPath
+	}
+
+	var queryParams []string
+	for _, param := range op.Parameters {
+		if param.ParamType == "query" {
+			queryParams = append(queryParams, param.Name)
+		}
+	}
+	if len(queryParams) > 0 {
+		buf.WriteString(fmt.Sprintf("### %s [%s %s{?%s}]\n\n", name, op.HttpMethod, apiPath, strings.Join(queryParams, ",")))
+	} else {
+		buf.WriteString(fmt.Sprintf("### %s [%s]\n\n", name, op.HttpMethod))
+	}
+	if op.Summary != "" {
+		buf.WriteString(op.Summary + "\n\n")
+	}
+	if op.Notes != "" {
+		buf.WriteString(op.Notes + "\n\n")
+	}
+
+	var uriParams, headers []parser.Parameter
+	var body *parser.Parameter
+	for i, param := range op.Parameters {
+		switch param.ParamType {
+		case "path", "query":
+			uriParams = append(uriParams, param)
+		case "header":
+			headers = append(headers, param)
+		case "body":
+			body = &op.Parameters[i]
+		}
+	}
+
+	if len(uriParams) > 0 {
+		buf.WriteString("+ Parameters\n")
+		for _, param := range uriParams {
+			required := "optional"
+			if param.Required {
+				required = "required"
+			}
+			buf.WriteString(fmt.Sprintf("    + %s: `%s` (%s, %s)", param.Name, api.ExampleString(param.DataType), msonType(param.DataType, names), required))
+			if description := strings.Trim(param.Description, "\""); description != "" {
+				buf.WriteString(" - " + description)
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+
+	if body != nil || len(headers) > 0 {
+		contentType := parser.ContentTypeJson
+		if len(op.Consumes) > 0 {
+			contentType = op.Consumes[0]
+		}
+		buf.WriteString(fmt.Sprintf("+ Request (%s)\n\n", contentType))
+		if len(headers) > 0 {
+			buf.WriteString("    + Headers\n\n")
+			for _, header := range headers {
+				buf.WriteString(fmt.Sprintf("            %s: %s\n", header.Name, api.ExampleString(header.DataType)))
+			}
+			buf.WriteString("\n")
+		}
+		if body != nil {
+			buf.WriteString(fmt.Sprintf("    + Attributes (%s)\n\n", msonType(body.DataType, names)))
+			writeBody(buf, api.ExampleValue(body.DataType))
+		}
+	}
+
+	for _, response := range op.ResponseMessages {
+		contentType := parser.ContentTypeJson
+		if len(op.Produces) > 0 {
+			contentType = op.Produces[0]
+		}
+		buf.WriteString(fmt.Sprintf("+ Response %d (%s)\n\n", response.Code, contentType))
+		if response.Message != "" {
+			buf.WriteString("    " + response.Message + "\n\n")
+		}
+		if response.ResponseModel != "" {
+			buf.WriteString(fmt.Sprintf("    + Attributes (%s)\n\n", msonType(response.ResponseModel, names)))
+			writeBody(buf, api.ExampleValue(response.ResponseModel))
+		}
+	}
+}
+
+func writeBody(buf *bytes.Buffer, example interface{}) {
+	json, err := json.MarshalIndent(example, "", "    ")
+	if err != nil {
+		return
+	}
+	buf.WriteString("    + Body\n\n")
+	for _, line := range strings.Split(string(json), "\n") {
+		buf.WriteString("            " + line + "\n")
+	}
+	buf.WriteString("\n")
+}
+
+func writeDataStructure(buf *bytes.Buffer, model *parser.Model, names map[string]string) {
+	buf.WriteString(fmt.Sprintf("## %s (object)\n\n", modelTypeName(model.Id, names)))
+
+	propertyNames := make([]string, 0, len(model.Properties))
+	for name := range model.Properties {
+		propertyNames = append(propertyNames, name)
+	}
+	sort.Strings(propertyNames)
+
+	for _, name := range propertyNames {
+		property := model.Properties[name]
+		typeName := property.Type
+		if typeName == "array" {
+			itemType := property.Items.Type
+			if itemType == "" {
+				itemType = property.Items.Ref
+			}
+			typeName = "array[" + itemType + "]"
+		}
+
+		attributes := msonType(typeName, names)
+		for _, required := range model.Required {
+			if required == name {
+				attributes += ", required"
+			}
+		}
+		buf.WriteString(fmt.Sprintf("+ %s (%s)", name, attributes))
+		if property.Description != "" {
+			buf.WriteString(" - " + property.Description)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+}
+
+// msonType maps swagger type names to MSON types, models are referenced by their name in names
+func msonType(typeName string, names map[string]string) string {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		return "array[" + msonType(typeName[len("array["):len(typeName)-1], names) + "]"
+	}
+	switch typeName {
+	case "bool":
+		return "boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune", "uintptr",
+		"float32", "float64", "complex64", "complex128":
+		return "number"
+	case "string", "error", "Time", "":
+		return "string"
+	}
+	if strings.Contains(typeName, "interface") {
+		return "object"
+	}
+	return modelTypeName(typeName, names)
+}
+
+// modelTypeName returns the data structure name of a model, its name in names, the last part of its Id otherwise
+func modelTypeName(modelId string, names map[string]string) string {
+	if name, ok := names[modelId]; ok {
+		return name
+	}
+	return parser.ShortModelName(modelId)
+}
@@ -0,0 +1,262 @@
+// Package changes compares two versions of a spec and classifies the differences
+// as breaking or additive, to be used as a release gate.
+package changes
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// Change is a difference between the old and the new spec. Location is "METHOD /path" or "model Id"
+type Change struct {
+	Breaking bool
+	Location string
+	Message  string
+}
+
+func (c Change) String() string {
+	kind := "additive"
+	if c.Breaking {
+		kind = "BREAKING"
+	}
+	return fmt.Sprintf("%-8s %s: %s", kind, c.Location, c.Message)
+}
+
+// HasBreaking reports if any of the changes is breaking
+func HasBreaking(changes []Change) bool {
+	for _, change := range changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns the changes from oldApis to newApis, sorted by location
+func Compare(oldApis, newApis map[string]*parser.ApiDeclaration) []Change {
+	var changes []Change
+	add := func(breaking bool, location, format string, args ...interface{}) {
+		changes = append(changes, Change{breaking, location, fmt.Sprintf(format, args...)})
+	}
+
+	oldOperations, newOperations := operations(oldApis), operations(newApis)
+	for _, location := range sortedKeys(oldOperations) {
+		oldOp := oldOperations[location]
+		newOp, ok := newOperations[location]
+		if !ok {
+			add(true, location, "operation removed")
+			continue
+		}
+		compareOperations(location, oldOp, newOp, add)
+	}
+	for _, location := range sortedKeys(newOperations) {
+		if _, ok := oldOperations[location]; !ok {
+			add(false, location, "operation added")
+		}
+	}
+
+	oldModels, newModels := models(oldApis), models(newApis)
+	for _, id := range sortedKeys(oldModels) {
+		location := "model " + id
+		newModel, ok := newModels[id]
+		if !ok {
+			add(true, location, "model removed")
+			continue
+		}
+		compareModels(location, oldModels[id], newModel, add)
+	}
+	for _, id := range sortedKeys(newModels) {
+		if _, ok := oldModels[id]; !ok {
+			add(false, "model "+id, "model added")
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Location < changes[j].Location
+	})
+	return changes
+}
+
+type addFunc func(breaking bool, location, format string, args ...interface{})
+
+func compareOperations(location string, oldOp, newOp *parser.Operation, add addFunc) {
+	if wireType(oldOp.Type) != wireType(newOp.Type) {
+		add(true, location, "response type changed from %s to %s", oldOp.Type, newOp.Type)
+	}
+
+	oldParams, newParams := parameters(oldOp), parameters(newOp)
+	for _, key := range sortedKeys(oldParams) {
+		oldParam := oldParams[key]
+		newParam, ok := newParams[key]
+		if !ok {
+			add(true, location, "%s removed", key)
+			continue
+		}
+		if wireType(oldParam.DataType) != wireType(newParam.DataType) {
+			add(true, location, "%s type changed from %s to %s", key, oldParam.DataType, newParam.DataType)
+		}
+		if !oldParam.Required && newParam.Required {
+			add(true, location, "%s became required", key)
+		} else if oldParam.Required && !newParam.Required {
+			add(false, location, "%s became optional", key)
+		}
+	}
+	for _, key := range sortedKeys(newParams) {
+		if _, ok := oldParams[key]; !ok {
+			if newParams[key].Required {
+				add(true, location, "required %s added", key)
+			} else {
+				add(false, location, "optional %s added", key)
+			}
+		}
+	}
+
+	oldResponses, newResponses := responses(oldOp), responses(newOp)
+	for _, code := range sortedKeys(oldResponses) {
+		newResponse, ok := newResponses[code]
+		if !ok {
+			add(true, location, "response %s removed", code)
+		} else if oldResponses[code].ResponseModel != newResponse.ResponseModel {
+			add(true, location, "response %s model changed from %s to %s", code, oldResponses[code].ResponseModel, newResponse.ResponseModel)
+		}
+	}
+	for _, code := range sortedKeys(newResponses) {
+		if _, ok := oldResponses[code]; !ok {
+			add(false, location, "response %s added", code)
+		}
+	}
+}
+
+func compareModels(location string, oldModel, newModel *parser.Model, add addFunc) {
+	for _, name := range sortedKeys(oldModel.Properties) {
+		oldProperty := oldModel.Properties[name]
+		newProperty, ok := newModel.Properties[name]
+		if !ok {
+			add(true, location, "property %s removed", name)
+			continue
+		}
+		if oldType, newType := propertyType(oldProperty), propertyType(newProperty); oldType != newType {
+			add(true, location, "property %s type changed from %s to %s", name, oldType, newType)
+		}
+
+		if len(oldProperty.Enum) > 0 || len(newProperty.Enum) > 0 {
+			var removed, added []string
+			for _, value := range oldProperty.Enum {
+				if !contains(newProperty.Enum, value) {
+					removed = append(removed, value)
+				}
+			}
+			for _, value := range newProperty.Enum {
+				if !contains(oldProperty.Enum, value) {
+					added = append(added, value)
+				}
+			}
+			// a property without enum accepts every value
+			if len(oldProperty.Enum) == 0 {
+				add(true, location, "property %s enum narrowed to %s", name, strings.Join(newProperty.Enum, ", "))
+			} else if len(newProperty.Enum) > 0 && len(removed) > 0 {
+				add(true, location, "property %s enum narrowed, removed %s", name, strings.Join(removed, ", "))
+			}
+			if len(newProperty.Enum) == 0 {
+				add(false, location, "property %s enum removed", name)
+			} else if len(oldProperty.Enum) > 0 && len(added) > 0 {
+				add(false, location, "property %s enum widened, added %s", name, strings.Join(added, ", "))
+			}
+		}
+
+		if !contains(oldModel.Required, name) && contains(newModel.Required, name) {
+			add(true, location, "property %s became required", name)
+		}
+	}
+	for _, name := range sortedKeys(newModel.Properties) {
+		if _, ok := oldModel.Properties[name]; !ok {
+			if contains(newModel.Required, name) {
+				add(true, location, "required property %s added", name)
+			} else {
+				add(false, location, "property %s added", name)
+			}
+		}
+	}
+}
+
+// operations indexes the operations of all APIs by "METHOD /path"
+func operations(apis map[string]*parser.ApiDeclaration) map[string]*parser.Operation {
+	result := make(map[string]*parser.Operation)
+	for _, api := range apis {
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				result[strings.ToUpper(op.HttpMethod)+" "+subApi.Path] = op
+			}
+		}
+	}
+	return result
+}
+
+func models(apis map[string]*parser.ApiDeclaration) map[string]*parser.Model {
+	result := make(map[string]*parser.Model)
+	for _, api := range apis {
+		for id, model := range api.Models {
+			result[id] = model
+		}
+	}
+	return result
+}
+
+// parameters indexes the parameters of op by "<paramType> param <name>"
+func parameters(op *parser.Operation) map[string]parser.Parameter {
+	result := make(map[string]parser.Parameter, len(op.Parameters))
+	for _, param := range op.Parameters {
+		result[fmt.Sprintf("%s param %s", param.ParamType, param.Name)] = param
+	}
+	return result
+}
+
+func responses(op *parser.Operation) map[string]parser.ResponseMessage {
+	result := make(map[string]parser.ResponseMessage, len(op.ResponseMessages))
+	for _, response := range op.ResponseMessages {
+		result[fmt.Sprint(response.Code)] = response
+	}
+	return result
+}
+
+func propertyType(property *parser.ModelProperty) string {
+	if property.Type == "array" {
+		if property.Items.Type != "" {
+			return "array[" + wireType(property.Items.Type) + "]"
+		}
+		return "array[" + property.Items.Ref + "]"
+	}
+	return wireType(property.Type)
+}
+
+// wireType is the Go type a type is read back as from a spec, int and int64 are the same int64 on the wire
+func wireType(typeName string) string {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		return "array[" + wireType(typeName[len("array["):len(typeName)-1]) + "]"
+	}
+	goType, _ := parser.GoDataType(parser.SwaggerDataType(typeName, ""))
+	return goType
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map with string keys, in order
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,267 @@
+package changes_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yvasiyarov/swagger/changes"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// newApis returns a spec with a GET and a DELETE on /users/{id} and the model they return
+func newApis() map[string]*parser.ApiDeclaration {
+	get := &parser.Operation{
+		HttpMethod: "GET",
+		Nickname:   "GetUser",
+		Type:       "fixture.User",
+		Parameters: []parser.Parameter{
+			{ParamType: "path", Name: "id", DataType: "int", Required: true},
+			{ParamType: "query", Name: "fields", DataType: "string"},
+		},
+		ResponseMessages: []parser.ResponseMessage{
+			{Code: 200, ResponseModel: "fixture.User"},
+			{Code: 404, ResponseModel: "fixture.Error"},
+		},
+	}
+	remove := &parser.Operation{
+		HttpMethod: "DELETE",
+		Nickname:   "DeleteUser",
+		Type:       "void",
+		Parameters: []parser.Parameter{{ParamType: "path", Name: "id", DataType: "int", Required: true}},
+	}
+	user := &parser.Model{
+		Id:       "fixture.User",
+		Required: []string{"id"},
+		Properties: map[string]*parser.ModelProperty{
+			"id":     &parser.ModelProperty{Type: "int"},
+			"name":   &parser.ModelProperty{Type: "string"},
+			"status": &parser.ModelProperty{Type: "string", Enum: []string{"active", "disabled"}},
+		},
+	}
+	return map[string]*parser.ApiDeclaration{
+		"/users": &parser.ApiDeclaration{
+			ResourcePath: "/users",
+			Apis:         []*parser.Api{{Path: "/users/{id}", Operations: []*parser.Operation{get, remove}}},
+			Models:       map[string]*parser.Model{"fixture.User": user},
+		},
+	}
+}
+
+func getUser(apis map[string]*parser.ApiDeclaration) *parser.Operation {
+	return apis["/users"].Apis[0].Operations[0]
+}
+
+func user(apis map[string]*parser.ApiDeclaration) *parser.Model {
+	return apis["/users"].Models["fixture.User"]
+}
+
+func TestCompare(t *testing.T) {
+	rules := []struct {
+		name     string
+		change   func(apis map[string]*parser.ApiDeclaration)
+		expected []changes.Change
+	}{
+		{
+			name:   "unchanged",
+			change: func(apis map[string]*parser.ApiDeclaration) {},
+		},
+		{
+			name: "operation removed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				apis["/users"].Apis[0].Operations = apis["/users"].Apis[0].Operations[:1]
+			},
+			expected: []changes.Change{{true, "DELETE /users/{id}", "operation removed"}},
+		},
+		{
+			name: "operation added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				apis["/users"].Apis = append(apis["/users"].Apis, &parser.Api{
+					Path:       "/users",
+					Operations: []*parser.Operation{{HttpMethod: "GET", Nickname: "ListUsers", Type: "array[fixture.User]"}},
+				})
+			},
+			expected: []changes.Change{{false, "GET /users", "operation added"}},
+		},
+		{
+			name: "param removed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters = getUser(apis).Parameters[:1]
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "query param fields removed"}},
+		},
+		{
+			name: "param became required",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters[1].Required = true
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "query param fields became required"}},
+		},
+		{
+			name: "param became optional",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters[0].Required = false
+			},
+			expected: []changes.Change{{false, "GET /users/{id}", "path param id became optional"}},
+		},
+		{
+			name: "required param added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters = append(getUser(apis).Parameters, parser.Parameter{ParamType: "header", Name: "X-Tenant", DataType: "string", Required: true})
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "required header param X-Tenant added"}},
+		},
+		{
+			name: "optional param added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters = append(getUser(apis).Parameters, parser.Parameter{ParamType: "query", Name: "lang", DataType: "string"})
+			},
+			expected: []changes.Change{{false, "GET /users/{id}", "optional query param lang added"}},
+		},
+		{
+			name: "param type changed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters[0].DataType = "string"
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "path param id type changed from int to string"}},
+		},
+		{
+			name: "param type with the same wire type",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Parameters[0].DataType = "int64"
+			},
+		},
+		{
+			name: "response type changed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).Type = "array[fixture.User]"
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "response type changed from fixture.User to array[fixture.User]"}},
+		},
+		{
+			name: "response removed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).ResponseMessages = getUser(apis).ResponseMessages[:1]
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "response 404 removed"}},
+		},
+		{
+			name: "response model changed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).ResponseMessages[1].ResponseModel = "fixture.NotFound"
+			},
+			expected: []changes.Change{{true, "GET /users/{id}", "response 404 model changed from fixture.Error to fixture.NotFound"}},
+		},
+		{
+			name: "response added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				getUser(apis).ResponseMessages = append(getUser(apis).ResponseMessages, parser.ResponseMessage{Code: 304})
+			},
+			expected: []changes.Change{{false, "GET /users/{id}", "response 304 added"}},
+		},
+		{
+			name: "model removed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				delete(apis["/users"].Models, "fixture.User")
+			},
+			expected: []changes.Change{{true, "model fixture.User", "model removed"}},
+		},
+		{
+			name: "model added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				apis["/users"].Models["fixture.Group"] = &parser.Model{Id: "fixture.Group", Properties: map[string]*parser.ModelProperty{}}
+			},
+			expected: []changes.Change{{false, "model fixture.Group", "model added"}},
+		},
+		{
+			name: "property removed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				delete(user(apis).Properties, "name")
+			},
+			expected: []changes.Change{{true, "model fixture.User", "property name removed"}},
+		},
+		{
+			name: "property type changed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["id"] = &parser.ModelProperty{Type: "string"}
+			},
+			expected: []changes.Change{{true, "model fixture.User", "property id type changed from int64 to string"}},
+		},
+		{
+			name: "property became required",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Required = append(user(apis).Required, "name")
+			},
+			expected: []changes.Change{{true, "model fixture.User", "property name became required"}},
+		},
+		{
+			name: "required property added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["email"] = &parser.ModelProperty{Type: "string"}
+				user(apis).Required = append(user(apis).Required, "email")
+			},
+			expected: []changes.Change{{true, "model fixture.User", "required property email added"}},
+		},
+		{
+			name: "optional property added",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["email"] = &parser.ModelProperty{Type: "string"}
+			},
+			expected: []changes.Change{{false, "model fixture.User", "property email added"}},
+		},
+		{
+			name: "enum narrowed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["status"].Enum = []string{"active"}
+			},
+			expected: []changes.Change{{true, "model fixture.User", "property status enum narrowed, removed disabled"}},
+		},
+		{
+			name: "enum added to a property",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["name"].Enum = []string{"admin"}
+			},
+			expected: []changes.Change{{true, "model fixture.User", "property name enum narrowed to admin"}},
+		},
+		{
+			name: "enum widened",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["status"].Enum = []string{"active", "disabled", "locked"}
+			},
+			expected: []changes.Change{{false, "model fixture.User", "property status enum widened, added locked"}},
+		},
+		{
+			name: "enum removed",
+			change: func(apis map[string]*parser.ApiDeclaration) {
+				user(apis).Properties["status"].Enum = nil
+			},
+			expected: []changes.Change{{false, "model fixture.User", "property status enum removed"}},
+		},
+	}
+
+	for _, rule := range rules {
+		oldApis, newApis := newApis(), newApis()
+		rule.change(newApis)
+
+		actual := changes.Compare(oldApis, newApis)
+		assert.Equal(t, rule.expected, actual, "Wrong changes for %s", rule.name)
+		assert.Equal(t, len(rule.expected) > 0 && rule.expected[0].Breaking, changes.HasBreaking(actual), "Wrong breaking status for %s", rule.name)
+	}
+}
+
+func TestCompareSortsByLocation(t *testing.T) {
+	oldApis, newApis := newApis(), newApis()
+	getUser(newApis).Parameters = getUser(newApis).Parameters[:1]
+	newApis["/users"].Apis[0].Operations = newApis["/users"].Apis[0].Operations[:1]
+	user(newApis).Properties["email"] = &parser.ModelProperty{Type: "string"}
+
+	assert.Equal(t, []changes.Change{
+		{true, "DELETE /users/{id}", "operation removed"},
+		{true, "GET /users/{id}", "query param fields removed"},
+		{false, "model fixture.User", "property email added"},
+	}, changes.Compare(oldApis, newApis), "Wrong changes")
+}
+
+func TestChangeString(t *testing.T) {
+	assert.Equal(t, "BREAKING GET /users/{id}: operation removed", changes.Change{true, "GET /users/{id}", "operation removed"}.String(), "Wrong breaking change")
+	assert.Equal(t, "additive model fixture.User: property email added", changes.Change{false, "model fixture.User", "property email added"}.String(), "Wrong additive change")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/generator"
+	"github.com/yvasiyarov/swagger/html"
+	"github.com/yvasiyarov/swagger/markup"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// AVAILABLE_SHELLS are the shells of the completion command
+const AVAILABLE_SHELLS = "bash|zsh|fish"
+
+// completionValues are the values completed for the flags with a fixed set of values
+var completionValues = map[string]string{
+	"format":           generator.AVAILABLE_FORMATS,
+	"goFramework":      generator.AVAILABLE_FRAMEWORKS,
+	"to":               generator.AVAILABLE_MIGRATIONS,
+	"errors":           generator.AVAILABLE_ERROR_FORMATS,
+	"logFormat":        "text|json",
+	"sort":             parser.AVAILABLE_SORTS,
+	"dialect":          parser.AVAILABLE_DIALECTS,
+	"modelNaming":      parser.ModelNamingFull + "|" + parser.ModelNamingPackage + "|" + parser.ModelNamingShort,
+	"htmlViewer":       html.AVAILABLE_VIEWERS,
+	"locale":           markup.AVAILABLE_LOCALES,
+	"samples":          markup.AVAILABLE_SAMPLES,
+	"confluenceMarkup": "wiki|storage",
+}
+
+// completionLists are the flags whose value is a comma separated list of completionValues
+var completionLists = []string{"format", "samples"}
+
+// completionFlag is a flag of the command line, as the completion scripts need it
+type completionFlag struct {
+	name   string
+	usage  string
+	isBool bool
+	values []string // nil for free values, like paths
+}
+
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	flag.VisitAll(func(f *flag.Flag) {
+		completion := completionFlag{name: f.Name, usage: strings.SplitN(f.Usage, "\n", 2)[0]}
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+			completion.isBool = boolFlag.IsBoolFlag()
+		}
+		if values, ok := completionValues[f.Name]; ok {
+			completion.values = strings.Split(values, "|")
+		}
+		flags = append(flags, completion)
+	})
+	sort.Slice(flags, func(i, j int) bool { return flags[i].name < flags[j].name })
+	return flags
+}
+
+// writeCompletion writes the completion script of shell: the commands, the flags and the values of
+// the flags with a fixed set of values are completed, files for the other ones
+func writeCompletion(w io.Writer, shell string) error {
+	flags := completionFlags()
+	commands := strings.Replace(generator.AVAILABLE_COMMANDS, "|", " ", -1)
+	switch shell {
+	case "bash":
+		writeBashCompletion(w, commands, flags)
+	case "zsh":
+		// zsh runs the bash completion through its bash compatibility
+		fmt.Fprintln(w, "autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(w, commands, flags)
+	case "fish":
+		writeFishCompletion(w, commands, flags)
+	default:
+		return fmt.Errorf("Invalid shell %q specified. Must be one of %v.", shell, AVAILABLE_SHELLS)
+	}
+	return nil
+}
+
+func writeBashCompletion(w io.Writer, commands string, flags []completionFlag) {
+	var names, valueFlags []string
+	for _, f := range flags {
+		if f.isBool {
+			names = append(names, "-"+f.name)
+		} else {
+			names = append(names, "-"+f.name+"=")
+			valueFlags = append(valueFlags, "-"+f.name)
+		}
+	}
+
+	fmt.Fprintf(w, `_swagger() {
+    local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} flag= values=
+    # = is a word of its own: -format=sw is -format = sw
+    if [[ $cur == = ]]; then
+        flag=$prev cur=
+    elif [[ $prev == = ]]; then
+        flag=${COMP_WORDS[COMP_CWORD-2]}
+    elif [[ " %s " == *" $prev "* ]]; then
+        flag=$prev
+    fi
+    if [[ -n $flag ]]; then
+        case $flag in
+`, strings.Join(valueFlags, " "))
+	for _, f := range flags {
+		if f.values == nil {
+			continue
+		}
+		fmt.Fprintf(w, "            -%s) values=\"%s\" ;;\n", f.name, strings.Join(f.values, " "))
+	}
+	fmt.Fprintf(w, `            *) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+        esac
+        local prefix=
+        if [[ " %s " == *" $flag "* && $cur == *,* ]]; then
+            prefix=${cur%%,*}, cur=${cur##*,}
+        fi
+        COMPREPLY=($(compgen -P "$prefix" -W "$values" -- "$cur"))
+        return
+    fi
+    if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    elif [[ $cur == -* ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+        [[ $COMPREPLY == *= ]] && compopt -o nospace 2>/dev/null
+    elif [[ ${COMP_WORDS[1]} == completion ]]; then
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    fi
+}
+complete -F _swagger swagger
+`, "-"+strings.Join(completionLists, " -"), commands, strings.Join(names, " "), strings.Replace(AVAILABLE_SHELLS, "|", " ", -1))
+}
+
+func writeFishCompletion(w io.Writer, commands string, flags []completionFlag) {
+	fmt.Fprintf(w, "complete -c swagger -f -n __fish_use_subcommand -a '%s'\n", commands)
+	fmt.Fprintf(w, "complete -c swagger -f -n '__fish_seen_subcommand_from completion' -a '%s'\n", strings.Replace(AVAILABLE_SHELLS, "|", " ", -1))
+	for _, f := range flags {
+		option := fmt.Sprintf("complete -c swagger -o %s -d %s", f.name, fishQuote(f.usage))
+		switch {
+		case f.isBool:
+		case f.values != nil:
+			option += fmt.Sprintf(" -x -a '%s'", strings.Join(f.values, " "))
+		default:
+			option += " -r"
+		}
+		fmt.Fprintln(w, option)
+	}
+}
+
+// fishQuote quotes s for fish, in single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
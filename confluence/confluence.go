@@ -0,0 +1,194 @@
+// Package confluence publishes pages to a Confluence space with the Confluence REST API
+package confluence
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/yvasiyarov/swagger/logger"
+)
+
+// Environment variables of the credentials: with a user, the token is sent with basic authentication
+// (Confluence Cloud API tokens), without it is a bearer token (Confluence Server personal access tokens)
+const (
+	USER_ENV  = "CONFLUENCE_USER"
+	TOKEN_ENV = "CONFLUENCE_TOKEN"
+)
+
+// PUBLISH_TIMEOUT is the time a request to Confluence can take, so an unresponsive server does not hang the generation
+const PUBLISH_TIMEOUT = 60 * time.Second
+
+// Space is where pages are published
+type Space struct {
+	Url      string // base URL of Confluence, e.g. https://acme.atlassian.net/wiki
+	Key      string
+	ParentId string // Id of the parent page of the published pages, optional
+	User     string
+	Token    string
+	Client   *http.Client
+}
+
+// NewSpace returns the space of the Confluence at baseUrl, with the credentials of the environment
+func NewSpace(baseUrl, key, parentId string) (*Space, error) {
+	if baseUrl == "" || key == "" {
+		return nil, errors.New("Publishing to Confluence needs -confluenceUrl and -confluenceSpace\n")
+	}
+	space := &Space{
+		Url:      strings.TrimSuffix(baseUrl, "/"),
+		Key:      key,
+		ParentId: parentId,
+		User:     os.Getenv(USER_ENV),
+		Token:    os.Getenv(TOKEN_ENV),
+		Client:   &http.Client{Timeout: PUBLISH_TIMEOUT},
+	}
+	if space.Token == "" {
+		return nil, fmt.Errorf("Publishing to Confluence needs the $%s environment variable\n", TOKEN_ENV)
+	}
+	return space, nil
+}
+
+type content struct {
+	Id        string     `json:"id,omitempty"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Space     spaceKey   `json:"space"`
+	Ancestors []ancestor `json:"ancestors,omitempty"`
+	Body      body       `json:"body"`
+	Version   *version   `json:"version,omitempty"`
+}
+
+type spaceKey struct {
+	Key string `json:"key"`
+}
+
+type ancestor struct {
+	Id string `json:"id"`
+}
+
+type body struct {
+	Storage contentBody `json:"storage"`
+}
+
+type contentBody struct {
+	Value          string `json:"value"`
+	Representation string `json:"representation"`
+}
+
+type version struct {
+	Number int `json:"number"`
+}
+
+// PublishWiki creates or updates the page title with wiki markup, as written by the confluence format.
+// It returns the URL of the page.
+func (space *Space) PublishWiki(title string, wiki string) (string, error) {
+	var storage contentBody
+	err := space.call("POST", "/rest/api/contentbody/convert/storage", contentBody{Value: wiki, Representation: "wiki"}, &storage)
+	if err != nil {
+		return "", fmt.Errorf("Can not convert the page to the Confluence storage format: %v\n", err)
+	}
+	return space.Publish(title, storage.Value)
+}
+
+// Publish creates the page title with the storage format (XHTML) value, or updates it if it exists.
+// It returns the URL of the page.
+func (space *Space) Publish(title string, value string) (string, error) {
+	page := content{
+		Type:  "page",
+		Title: title,
+		Space: spaceKey{space.Key},
+		Body:  body{Storage: contentBody{Value: value, Representation: "storage"}},
+	}
+	if space.ParentId != "" {
+		page.Ancestors = []ancestor{{space.ParentId}}
+	}
+
+	var found struct {
+		Results []struct {
+			Id      string  `json:"id"`
+			Version version `json:"version"`
+		} `json:"results"`
+	}
+	query := url.Values{"spaceKey": {space.Key}, "title": {title}, "expand": {"version"}}
+	if err := space.call("GET", "/rest/api/content?"+query.Encode(), nil, &found); err != nil {
+		return "", fmt.Errorf("Can not look up Confluence page %s: %v\n", title, err)
+	}
+
+	var published struct {
+		Id    string `json:"id"`
+		Links struct {
+			Base  string `json:"base"`
+			WebUi string `json:"webui"`
+		} `json:"_links"`
+	}
+	if len(found.Results) == 0 {
+		logger.Debugf("Creating Confluence page %s in space %s", title, space.Key)
+		if err := space.call("POST", "/rest/api/content", page, &published); err != nil {
+			return "", fmt.Errorf("Can not create Confluence page %s: %v\n", title, err)
+		}
+	} else {
+		page.Id = found.Results[0].Id
+		page.Version = &version{Number: found.Results[0].Version.Number + 1}
+		logger.Debugf("Updating Confluence page %s (%s) to version %d", title, page.Id, page.Version.Number)
+		if err := space.call("PUT", "/rest/api/content/"+page.Id, page, &published); err != nil {
+			return "", fmt.Errorf("Can not update Confluence page %s: %v\n", title, err)
+		}
+	}
+
+	if published.Links.WebUi == "" {
+		return space.Url + "/pages/viewpage.action?pageId=" + published.Id, nil
+	}
+	base := published.Links.Base
+	if base == "" {
+		base = space.Url
+	}
+	return base + published.Links.WebUi, nil
+}
+
+// call sends request as JSON to the Confluence REST API and decodes the JSON response into response
+func (space *Space) call(method, path string, request interface{}, response interface{}) error {
+	var requestBody io.Reader
+	if request != nil {
+		body, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		requestBody = bytes.NewReader(body)
+	}
+
+	httpRequest, err := http.NewRequest(method, space.Url+path, requestBody)
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Accept", "application/json")
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if space.User != "" {
+		httpRequest.SetBasicAuth(space.User, space.Token)
+	} else {
+		httpRequest.Header.Set("Authorization", "Bearer "+space.Token)
+	}
+
+	httpResponse, err := space.Client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	responseBody, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: %s %s", method, path, httpResponse.Status, strings.TrimSpace(string(responseBody)))
+	}
+	return json.Unmarshal(responseBody, response)
+}
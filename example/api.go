@@ -132,6 +132,36 @@ func (c *Context) GetStruct3(rw web.ResponseWriter, req *web.Request) {
 	c.WriteResponse(StructureWithSlice{})
 }
 
+// @Title UploadAttachment
+// @Description upload an attachment with its metadata
+// @Param   file     form    file     true        "Attachment" contentType(image/png,application/pdf)
+// @Param   name     form    string   true        "Name of the attachment"
+// @Param   tags     form    string   false       "Comma separated tags"
+// @Success 200 {object} string
+// @Failure 400 {object} APIError "Invalid attachment"
+// @Router /testapi/upload-attachment [post]
+func (c *Context) UploadAttachment(rw web.ResponseWriter, req *web.Request) {
+	c.WriteResponse("Some string")
+}
+
+// @Title ChatWebsocket
+// @Description chat with the other clients
+// @Websocket client {object} SimpleStructure "Message sent to the other clients"
+// @Websocket server {array} SimpleStructure "Messages of the other clients"
+// @Success 101 {object} string "Switching to the WebSocket protocol"
+// @Router /testapi/chat [get]
+func (c *Context) ChatWebsocket(rw web.ResponseWriter, req *web.Request) {
+}
+
+// @Title StreamEvents
+// @Description stream the events of the shop
+// @Event order-created {object} OrderID "An order was created"
+// @Event ratings {array} Rating "Ratings of the last minute"
+// @Success 200 {object} string
+// @Router /testapi/events [get]
+func (c *Context) StreamEvents(rw web.ResponseWriter, req *web.Request) {
+}
+
 func InitRouter() *web.Router {
 	router := web.New(Context{}).
 		Middleware(web.LoggerMiddleware).
@@ -1,6 +1,11 @@
 package example
 
 import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
 //	"github.com/yvasiyarov/swagger/example/subpackage"
 )
 
@@ -34,3 +39,153 @@ type APIError struct {
 	ErrorCode    int
 	ErrorMessage string
 }
+
+// Pet is the base model of a type hierarchy
+// @Discriminator kind
+// @SubTypes Cat,Dog
+type Pet struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type Cat struct {
+	Pet
+	Lives int `json:"lives"`
+}
+
+type Dog struct {
+	Pet
+	Breed string `json:"breed"`
+}
+
+// Money is marshaled as a string, not as a model
+type Money struct {
+	Cents int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(`"0.00"`), nil
+}
+
+// OrderID is marshaled as its number
+type OrderID struct {
+	value int64
+}
+
+func (id OrderID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.value)
+}
+
+// Rating is marshaled as a float
+type Rating int
+
+func (r *Rating) MarshalJSON() ([]byte, error) {
+	if r == nil {
+		return nil, errors.New("no rating")
+	}
+	return []byte(strconv.FormatFloat(float64(*r)/10, 'f', 1, 64)), nil
+}
+
+// Coordinates marshals its fields through an alias without the MarshalJSON method, it is a model
+type Coordinates struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+func (c Coordinates) MarshalJSON() ([]byte, error) {
+	type alias Coordinates
+	return json.Marshal(alias(c))
+}
+
+// UserID identifies a user
+type UserID int64
+
+type Emails []string
+
+type SimpleStructures []SimpleStructure
+
+// StructureWithNamedTypes has fields of named types, documented as their underlying types
+type StructureWithNamedTypes struct {
+	Id        UserID
+	Emails    Emails
+	Friends   []UserID
+	Structure SimpleStructures
+}
+
+// Node refers to itself
+type Node struct {
+	Name     string
+	Children []Node
+}
+
+// Team and Member refer to each other
+type Team struct {
+	Members []Member
+}
+
+type Member struct {
+	Team *Team
+}
+
+// StructureWithFormats has string fields with a format
+type StructureWithFormats struct {
+	Id       string `format:"uuid"`
+	Address  net.IP
+	Homepage *url.URL
+	Links    []url.URL
+}
+
+// StructureWithConstraints has fields with bounds
+type StructureWithConstraints struct {
+	Age  int    `minimum:"18" maximum:"130"`
+	Name string `minLength:"1" maxLength:"64" pattern:"^[A-Z](a|b)*$"`
+}
+
+// StructureWithComments has fields described by their comments
+type StructureWithComments struct {
+	// Street and number
+	Street string
+	City   string // Name of the city
+	Zip    string `description:"Postal code"` // Tag descriptions win
+}
+
+type Status string
+
+const (
+	StatusActive   Status = "active"
+	StatusDisabled        = Status("disabled")
+)
+
+type Level int
+
+const (
+	LevelLow Level = iota + 1
+	LevelMedium
+	_
+	LevelHigh
+)
+
+// StructureWithEnums has fields of types with constants
+type StructureWithEnums struct {
+	Status Status
+	Level  Level
+}
+
+// StructureWithIgnoredField has a field left out of the docs
+type StructureWithIgnoredField struct {
+	Name     string `json:"name"`
+	Password string `json:"password" swaggerignore:"true"`
+}
+
+// StructureWithAccessTags has a field which is only read and one which is only written
+type StructureWithAccessTags struct {
+	Id       int    `json:"id" readonly:"true"`
+	Password string `json:"password" writeonly:"true"`
+}
+
+// StructureWithOptionalFields has fields which are optional with -pointerOptional
+type StructureWithOptionalFields struct {
+	Id       int     `json:"id"`
+	Nickname string  `json:"nickname,omitempty"`
+	Email    *string `json:"email"`
+}
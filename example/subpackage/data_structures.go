@@ -4,3 +4,9 @@ type SimpleStructure struct {
 	Id   int
 	Name string
 }
+
+// Money has the name of example.Money, but does not marshal itself
+type Money struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
@@ -1,293 +1,289 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
 	"flag"
-	"fmt"
-	"go/ast"
-	"log"
 	"os"
-	"path"
-	"regexp"
+	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/yvasiyarov/swagger/generator"
+	"github.com/yvasiyarov/swagger/html"
+	"github.com/yvasiyarov/swagger/logger"
 	"github.com/yvasiyarov/swagger/markup"
+	"github.com/yvasiyarov/swagger/output"
 	"github.com/yvasiyarov/swagger/parser"
 )
 
-const (
-	AVAILABLE_FORMATS = "go|swagger|asciidoc|markdown|confluence"
-)
-
-var apiPackage = flag.String("apiPackage", "", "The package that implements the API controllers, relative to $GOPATH/src")
-var mainApiFile = flag.String("mainApiFile", "", "The file that contains the general API annotations, relative to $GOPATH/src")
-var outputFormat = flag.String("format", "go", "Output format type for the generated files: "+AVAILABLE_FORMATS)
+var apiPackage = flag.String("apiPackage", "", "The package that implements the API controllers: an import path, or a directory relative to the current one (./handlers)")
+var mainApiFile = flag.String("mainApiFile", "", "The file that contains the general API annotations: a file of a package (github.com/acme/api/main.go), or a file path")
+var outputFormat = &formatsFlag{value: "go"}
 var outputSpec = flag.String("output", "", "Output (path) for the generated file(s)")
 var controllerClass = flag.String("controllerClass", "", "Speed up parsing by specifying which receiver objects have the controller methods")
-
-var generatedFileTemplate = `
-package docs
-
-import (
-	"encoding/json"
-	"github.com/astaxie/beego"
-	"github.com/astaxie/beego/context"
-	"github.com/astaxie/beego/swagger"
-	"strings"
-)
-
-const (
-    Rootinfo string = {{resourceListing}}
-    Subapi string = {{apiDescriptions}}
-)
-var BasePath string
-
-var rootapi swagger.ResourceListing
-var apilist map[string]*swagger.ApiDeclaration
+var goTemplate = flag.String("goTemplate", "", "Path to a Go text/template used to generate docs.go instead of the built-in -goFramework templates")
+var markupTemplate = flag.String("markupTemplate", "", "Path to a Go text/template used to render asciidoc|markdown|confluence instead of the built-in layout")
+var goPackage = flag.String("goPackage", "docs", "Package name of the generated Go file (-format=go)")
+var goDir = flag.String("goDir", "docs", "Directory of the generated Go file (-format=go), relative to -output")
+var goFile = flag.String("goFile", "docs.go", "Name of the generated Go file (-format=go)")
+var docsRoute = flag.String("docsRoute", "/rawdoc", "Route SetupRouter serves the docs under (-goFramework=beego|nethttp)")
+var uiRoute = flag.String("uiRoute", "/swagger-ui", "Route SetupRouter serves the Swagger UI under (-goFramework=beego|nethttp), none for no Swagger UI route")
+var embedUI = flag.Bool("embedUI", false, "Embed the Swagger UI files in docs.go (-goFramework=nethttp), served by SetupRouter under -uiRoute. Needs Go 1.16")
+var uiAssets = flag.String("uiAssets", "", "Directory of the Swagger UI files of -embedUI, the swagger-ui directory of this tool by default")
+var validation = flag.Bool("validation", false, "Also write validation.go next to docs.go (-format=go): a middleware checking requests and responses against the docs at runtime")
+var validationTag = flag.String("validationTag", "", "Build tag of validation.go, so the middleware is only compiled in e.g. integration tests")
+var samples = flag.String("samples", "curl", "Comma separated languages of the request samples of the operations in the asciidoc|markdown|confluence formats: "+markup.AVAILABLE_SAMPLES+", none for no samples")
+var locale = flag.String("locale", "en", "Language of the texts of the asciidoc|markdown|confluence layout: "+markup.AVAILABLE_LOCALES)
+var markupStrings = flag.String("markupStrings", "", "Path to a JSON object replacing English texts of the asciidoc|markdown|confluence layout with custom ones, e.g. {\"Models\": \"Types\"}")
+var splitMarkup = flag.Bool("splitMarkup", false, "Write the resources and the models of the asciidoc|markdown formats to their own files, next to the -output index file")
+var clientPackage = flag.String("clientPackage", "client", "Package name of the client written by -format=goclient")
+var serverPackage = flag.String("serverPackage", "server", "Package name of the server interface and router written by -format=goserver")
+var graphqlQueries = flag.Bool("graphqlQueries", false, "Also write a Query type with a field per GET operation in -format=graphql, the models are written without it")
+var htmlViewer = flag.String("htmlViewer", "rapidoc", "Viewer rendering the page of -format=html: "+html.AVAILABLE_VIEWERS)
+var publish = flag.Bool("publish", false, "Publish the -format=confluence page to the -confluenceSpace space instead of writing a file. Credentials are read from $CONFLUENCE_USER and $CONFLUENCE_TOKEN")
+var confluenceMarkup = flag.String("confluenceMarkup", "wiki", "Markup of the confluence format: wiki, or storage for the XHTML storage format of the Confluence REST API and editors")
+var confluenceUrl = flag.String("confluenceUrl", "", "Base URL of Confluence for -publish, e.g. https://acme.atlassian.net/wiki")
+var confluenceSpace = flag.String("confluenceSpace", "", "Key of the Confluence space -publish creates or updates the page in")
+var confluenceParent = flag.String("confluenceParent", "", "Id of the parent page of the page published by -publish")
+var confluenceTitle = flag.String("confluenceTitle", "", "Title of the page published by -publish, the @Title of the API by default")
+var uploadTarget = flag.String("upload", "", "After writing the output, upload the Swagger 2.0 spec to swaggerhub:owner/api (key in $SWAGGERHUB_API_KEY) or PUT it to a URL")
+var uploadHeader = flag.String("uploadHeader", "", "Header of the -upload request, e.g. \"Authorization: Bearer ${TOKEN}\". Environment variables are expanded")
+var goFramework = flag.String("goFramework", "plain", "Framework the generated docs.go (-format=go) is built on: "+generator.AVAILABLE_FRAMEWORKS)
+var lint = flag.Bool("lint", false, "Only check the annotations and report problems with their file:line, nothing is written")
+var coverage = flag.Bool("coverage", false, "Only report the handlers without annotations and the models without description, nothing is written")
+var coverageMin = flag.Float64("coverage-min", 0, "With -coverage, fail if less than this percentage of the handlers and models is documented")
+var incremental = flag.String("incremental", "", "Cache directory of incremental generation: the spec of each package is cached there, and only the packages whose files changed are parsed again")
+var headers = flag.String("headers", "", "YAML or JSON list of standard response headers (e.g. X-RateLimit-Limit, Link) added to the responses of all operations, or of the ones of their tags")
+var patch = flag.String("patch", "", "RFC 6902 JSON Patch file (JSON or YAML) applied to the spec before it is written, for corner cases like renaming a model")
+var overlay = flag.String("overlay", "", "YAML or JSON file of hand-written info, operations and models deep merged into the generated spec")
+var dialect = flag.String("dialect", parser.DialectNative, "Dialect of the annotations: "+parser.AVAILABLE_DIALECTS+" (github.com/swaggo/swag annotations: @Summary, @Param name in type required \"desc\", @Tags...; github.com/go-swagger/go-swagger comments: swagger:route, swagger:parameters, swagger:response)")
+var strict = flag.Bool("strict", false, "Fail on unknown annotations (typos like @Sucess) and malformed annotations instead of skipping them")
+var timingsFlag = flag.Bool("timings", false, "Log the time spent in each phase: parsing the general info and each package, and serializing and writing each format. Also with -v")
+var cpuProfile = flag.String("cpuprofile", "", "Write a CPU profile of the run to this file, for go tool pprof")
+var memProfile = flag.String("memprofile", "", "Write a memory profile of the run to this file, for go tool pprof")
+var verbose = flag.Bool("v", false, "Verbose: log the progress of every parsed file and operation")
+var quiet = flag.Bool("q", false, "Quiet: log only warnings and errors")
+var logFormat = flag.String("logFormat", "text", "Log format: text|json")
+var fileMode = flag.String("filemode", "0644", "Permissions of the written files, in octal, also set on existing files")
+var dirMode = flag.String("dirmode", "0755", "Permissions of the created directories, in octal, also set on existing output directories")
+var summary = flag.String("summary", "", "Write a JSON summary of the run to this file: status, exit code, error and written files")
+var errorFormat = flag.String("errors", "text", "Format of the annotation errors, and of the problems of -lint and -coverage: "+generator.AVAILABLE_ERROR_FORMATS+" (a JSON array of {file, line, column, message, severity} on stdout)")
+var dryRunFlag = flag.Bool("dry-run", false, "Parse and generate everything, but only print a summary instead of writing files")
+var oldSpec = flag.String("oldSpec", "", "breaking and migrate commands: directory of the old spec, written by -format=swagger")
+var migrateTo = flag.String("to", "3.0", "migrate command: version of the written document: "+generator.AVAILABLE_MIGRATIONS)
+var listen = flag.String("listen", generator.DEFAULT_MOCK_ADDRESS, "mock command: address the mock server listens on")
+var newSpec = flag.String("newSpec", "", "breaking command: directory of the new spec, the spec of -apiPackage if empty")
+var specs = flag.String("specs", "", "merge command: comma separated [name=]directory list of specs written by -format=swagger")
+var basePath = flag.String("basePath", "", "API URL or path on -host, overrides @BasePath")
+var host = flag.String("host", "", "API host[:port], overrides @Host")
+var schemes = flag.String("schemes", "", "Comma separated API schemes (http,https), overrides @Schemes. The first one is used in the basePath")
+var versions = flag.String("versions", "", "Comma separated API versions (e.g. v1,v2) to generate separately, selected by @Version or path prefix")
+var sortOrder = flag.String("sort", parser.SortByPath, "Order of the paths and operations of each resource: "+parser.AVAILABLE_SORTS+" (declaration order in the code)")
+var includeTags = flag.String("include-tags", "", "Comma separated resources (the tags of Swagger 2.0 and OpenAPI 3.0) to generate, e.g. admin,users, all of them if empty")
+var excludePaths = flag.String("exclude-paths", "", "Comma separated path prefixes of operations left out of the output, e.g. /internal,/debug")
+var includeInternal = flag.Bool("include-internal", false, "Also generate the operations and controllers annotated with @Internal, left out by default")
+var audiences = flag.String("audiences", "", "Comma separated audiences (e.g. public,partner,internal) to generate separately, selected by @Audience and audience field tags")
+var buildTags = flag.String("tags", "", "Comma separated build tags of the parsed files, as go build -tags: files whose build constraints do not match are skipped")
+var includeTests = flag.Bool("include-tests", false, "Also parse the _test.go files of the API packages, skipped by default")
+var modelNaming = flag.String("modelNaming", parser.ModelNamingFull, "How model names are formed: full (github.com.acme.api.models.User), package (models.User, like swaggo), short (User) or a template like {{.Package}}_{{.Name}}")
+var pointerOptional = flag.Bool("pointerOptional", false, "Document pointer fields as optional and nullable, and other fields without omitempty as required")
+var marshalTypes = flag.String("marshalTypes", "", "Comma separated type=swaggerType list of types marshaling themselves (e.g. decimal.Decimal=float64), overriding the detected MarshalJSON/MarshalText types")
+var indent = flag.Int("indent", 4, "Number of spaces the JSON files of -format=swagger are indented with, 0 for minified JSON")
+var compact = flag.Bool("compact", false, "Minify the JSON embedded in docs.go (-format=go), to reduce the binary size")
+var embed = flag.Bool("embed", false, "Write the spec as JSON files embedded with //go:embed by docs.go (-format=go), instead of Go string constants. Needs Go 1.16")
+var skipValidation = flag.Bool("skipValidation", false, "Write the go|swagger output even if it breaks the official Swagger 1.2 JSON schemas or the references between models, params and paths. Not checked: the uri, email, mime-type and uri-template formats of strings")
+var preHook = flag.String("preHook", "", "Shell command run before parsing, e.g. go generate ./...")
+var postHook = flag.String("postHook", "", "Shell command run after the output is written, e.g. gofmt -w docs. SWAGGER_FORMAT and SWAGGER_OUTPUT are set")
 
 func init() {
-	version := beego.AppConfig.DefaultString("version", "2.0")
-	BasePath = "/" + version
-	if beego.EnableDocs {
-		err := json.Unmarshal([]byte(Rootinfo), &rootapi)
-		if err != nil {
-			beego.Error(err)
-		}
-		err = json.Unmarshal([]byte(Subapi), &apilist)
-		if err != nil {
-			beego.Error(err)
-		}
-		beego.GlobalDocApi["Root"] = rootapi
-		beego.Trace("Load Docs: version", rootapi.ApiVersion)
-		for k, v := range apilist {
-			for i, a := range v.Apis {
-				a.Path = urlReplace(a.Path)
-				v.Apis[i] = a
-			}
-			v.BasePath = BasePath
-			beego.GlobalDocApi[strings.Trim(k, "/")] = v
-		}
-	}
+	flag.Var(outputFormat, "format", "Output format type for the generated files: "+generator.AVAILABLE_FORMATS+". Comma separated or repeated for several formats from one parse")
 }
 
-// SetupRouter ...
-func SetupRouter(ns *beego.Namespace) {
-	docns := beego.NewNamespace("/rawdoc")
-	docns.Get("/", func(ctx *context.Context) {
-		ctx.Output.Json(rootapi, false, false)
-	})
-	for k, v := range apilist {
-		vv := v
-		docns.Get("/"+strings.Trim(k, "/"), func(ctx *context.Context) {
-			ctx.Output.Json(vv, false, false)
-		})
-	}
-	ns.Namespace(docns)
+// formatsFlag is -format: a comma separated list of formats, which can be repeated too
+type formatsFlag struct {
+	value string
+	isSet bool
 }
 
+func (f *formatsFlag) String() string {
+	return f.value
+}
 
-func urlReplace(src string) string {
-	pt := strings.Split(src, "/")
-	for i, p := range pt {
-		if len(p) > 0 {
-			if p[0] == ':' {
-				pt[i] = "{" + p[1:] + "}"
-			} else if p[0] == '?' && p[1] == ':' {
-				pt[i] = "{" + p[2:] + "}"
-			}
-		}
+func (f *formatsFlag) Set(value string) error {
+	if f.isSet {
+		f.value += "," + value
+	} else {
+		f.value, f.isSet = value, true
 	}
-	return strings.Join(pt, "/")
+	return nil
 }
 
-`
-
-// It must return true if funcDeclaration is controller. We will try to parse only comments before controllers
-func IsController(funcDeclaration *ast.FuncDecl) bool {
-	if len(*controllerClass) == 0 {
-		// Search every method
-		return true
+func main() {
+	// Commands are given before the flags: swagger diff -apiPackage=...
+	command := ""
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command = os.Args[1]
+		flag.CommandLine.Parse(os.Args[2:])
+	} else {
+		flag.Parse()
 	}
-	if funcDeclaration.Recv != nil && len(funcDeclaration.Recv.List) > 0 {
-		if starExpression, ok := funcDeclaration.Recv.List[0].Type.(*ast.StarExpr); ok {
-			receiverName := fmt.Sprint(starExpression.X)
-			matched, err := regexp.MatchString(string(*controllerClass), receiverName)
-			if err != nil {
-				log.Fatalf("The -controllerClass argument is not a valid regular expression: %v\n", err)
-			}
-			return matched
-		}
+	if command != "" && !strings.Contains("|"+generator.AVAILABLE_COMMANDS+"|", "|"+command+"|") {
+		logger.Fatalf("Unknown command %s. Must be one of %v.", command, generator.AVAILABLE_COMMANDS)
 	}
-	return false
-}
 
-func generateSwaggerDocs(parser *parser.Parser) error {
-	fd, err := os.Create(path.Join(*outputSpec, "docs/docs.go"))
-	if err != nil {
-		return fmt.Errorf("Can not create document file: %v\n", err)
+	if *verbose {
+		logger.SetLevel(logger.LevelDebug)
+	} else if *quiet {
+		logger.SetLevel(logger.LevelWarn)
 	}
-	defer fd.Close()
-
-	var apiDescriptions bytes.Buffer
-
-	apiDescriptions.WriteString("`{")
-	isFirst := true
-	for apiKey, apiDescription := range parser.TopLevelApis {
-		if isFirst {
-			isFirst = false
-		} else {
-			apiDescriptions.WriteString(",\n")
-		}
-		apiDescriptions.WriteString("\"" + apiKey + "\":")
-
-		json, err := json.MarshalIndent(apiDescription, "", "    ")
-		if err != nil {
-			return fmt.Errorf("Can not serialise []ApiDescription to JSON: %v\n", err)
-		}
-		apiDescriptions.Write(json)
+	switch strings.ToLower(*logFormat) {
+	case "text":
+	case "json":
+		logger.SetJSON(true)
+	default:
+		logger.Fatalf("Invalid -logFormat specified. Must be one of text|json.")
 	}
-	apiDescriptions.WriteString("}`")
 
-	doc := strings.Replace(generatedFileTemplate, "{{resourceListing}}", "`"+string(parser.GetResourceListingJson())+"`", -1)
-	doc = strings.Replace(doc, "{{apiDescriptions}}", apiDescriptions.String(), -1)
-
-	fd.WriteString(doc)
-
-	return nil
-}
-
-func generateSwaggerUiFiles(parser *parser.Parser) error {
-	fd, err := os.Create(path.Join(*outputSpec, "index.json"))
-	if err != nil {
-		return fmt.Errorf("Can not create the master index.json file: %v\n", err)
+	if *indent < 0 {
+		logger.Fatalf("Invalid -indent specified. Must be 0 or a positive number of spaces.")
 	}
-	defer fd.Close()
-	fd.WriteString(string(parser.GetResourceListingJson()))
-
-	for apiKey, apiDescription := range parser.TopLevelApis {
-		err = os.MkdirAll(path.Join(*outputSpec, apiKey), 0777)
-		if err != nil {
-			return err
+	if mode, err := strconv.ParseUint(*fileMode, 8, 32); err != nil || mode > 0777 {
+		logger.Fatalf("Invalid -filemode specified. Must be an octal mode like 0644.")
+	} else {
+		output.FileMode = os.FileMode(mode)
+	}
+	if mode, err := strconv.ParseUint(*dirMode, 8, 32); err != nil || mode > 0777 {
+		logger.Fatalf("Invalid -dirmode specified. Must be an octal mode like 0755.")
+	} else {
+		output.DirMode = os.FileMode(mode)
+	}
+	if err := parser.CheckModelNaming(*modelNaming); err != nil {
+		logger.Fatalf("Invalid -modelNaming specified: %v", err)
+	}
+	if !strings.Contains("|"+parser.AVAILABLE_SORTS+"|", "|"+*sortOrder+"|") {
+		logger.Fatalf("Invalid -sort specified. Must be one of %v.", parser.AVAILABLE_SORTS)
+	}
+	if !strings.Contains("|"+parser.AVAILABLE_DIALECTS+"|", "|"+*dialect+"|") {
+		logger.Fatalf("Invalid -dialect specified. Must be one of %v.", parser.AVAILABLE_DIALECTS)
+	}
+	if !strings.Contains("|"+generator.AVAILABLE_ERROR_FORMATS+"|", "|"+*errorFormat+"|") {
+		logger.Fatalf("Invalid -errors specified. Must be one of %v.", generator.AVAILABLE_ERROR_FORMATS)
+	}
+	if command == "completion" {
+		if err := writeCompletion(os.Stdout, flag.Arg(0)); err != nil {
+			logger.Fatalf("%v", err)
 		}
+		return
+	}
 
-		fd, err = os.Create(path.Join(*outputSpec, apiKey, "index.json"))
-		if err != nil {
-			return fmt.Errorf("Can not create the %s/index.json file: %v\n", apiKey, err)
+	// comparing two spec directories needs no source code
+	if *apiPackage != "" || !(command == "breaking" && *newSpec != "") && command != "merge" {
+		// without -apiPackage, the package of the current directory is documented, e.g. by //go:generate swagger
+		resolvedPackage, err := resolveApiPackage(*apiPackage)
+		if *apiPackage == "" && (err != nil || !hasGoFiles(".")) {
+			flag.PrintDefaults()
+			return
 		}
-		defer fd.Close()
-
-		json, err := json.MarshalIndent(apiDescription, "", "    ")
 		if err != nil {
-			return fmt.Errorf("Can not serialise []ApiDescription to JSON: %v\n", err)
+			logger.Fatalf("Invalid -apiPackage specified: %v", err)
 		}
-
-		fd.Write(json)
-		log.Printf("Wrote %v/index.json", apiKey)
-	}
-
-	return nil
-}
-
-func InitParser() *parser.Parser {
-	parser := parser.NewParser()
-
-	parser.BasePath = "{{.}}"
-	parser.IsController = IsController
-
-	parser.TypesImplementingMarshalInterface["NullString"] = "string"
-	parser.TypesImplementingMarshalInterface["NullInt64"] = "int"
-	parser.TypesImplementingMarshalInterface["NullFloat64"] = "float"
-	parser.TypesImplementingMarshalInterface["NullBool"] = "bool"
-
-	return parser
-}
-
-type GeneratorParams struct {
-	ApiPackage, MainApiFile, OutputFormat, OutputSpec, ControllerClass string
-}
-
-func Generate(params GeneratorParams) error {
-	parser := InitParser()
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		return errors.New("Please, set $GOPATH environment variable\n")
-	}
-
-	log.Println("Start parsing")
-
-	//Support gopaths with multiple directories
-	dirs := strings.Split(gopath, ":")
-	found := false
-	for _, d := range dirs {
-		apifile := path.Join(d, "src", params.MainApiFile)
-		if _, err := os.Stat(apifile); err == nil {
-			parser.ParseGeneralApiInfo(apifile)
-			found = true
+		*apiPackage = resolvedPackage
+		if *mainApiFile, err = resolveMainApiFile(*mainApiFile, *apiPackage); err != nil {
+			logger.Fatalf("Invalid -mainApiFile specified: %v", err)
 		}
 	}
-	if found == false {
-		apifile := path.Join(gopath, "src", params.MainApiFile)
-		return fmt.Errorf("Could not find apifile %s to parse\n", apifile)
-	}
-
-	parser.ParseApi(params.ApiPackage)
-	log.Println("Finish parsing")
 
-	var err error
-	confirmMsg := ""
-	format := strings.ToLower(params.OutputFormat)
-	switch format {
-	case "go":
-		err = generateSwaggerDocs(parser)
-		confirmMsg = "Doc file generated"
-	case "asciidoc":
-		err = markup.GenerateMarkup(parser, new(markup.MarkupAsciiDoc), &params.OutputSpec, ".adoc")
-		confirmMsg = "AsciiDoc file generated"
-	case "markdown":
-		err = markup.GenerateMarkup(parser, new(markup.MarkupMarkDown), &params.OutputSpec, ".md")
-		confirmMsg = "MarkDown file generated"
-	case "confluence":
-		err = markup.GenerateMarkup(parser, new(markup.MarkupConfluence), &params.OutputSpec, ".confluence")
-		confirmMsg = "Confluence file generated"
-	case "swagger":
-		err = generateSwaggerUiFiles(parser)
-		confirmMsg = "Swagger UI files generated"
-	default:
-		err = fmt.Errorf("Invalid -format specified. Must be one of %v.", AVAILABLE_FORMATS)
+	params := generator.GeneratorParams{
+		ApiPackage:       *apiPackage,
+		MainApiFile:      *mainApiFile,
+		OutputFormat:     outputFormat.value,
+		OutputSpec:       filepath.ToSlash(*outputSpec),
+		ControllerClass:  *controllerClass,
+		GoFramework:      *goFramework,
+		GoTemplate:       *goTemplate,
+		GoPackage:        *goPackage,
+		GoDir:            filepath.ToSlash(*goDir),
+		GoFile:           *goFile,
+		DocsRoute:        *docsRoute,
+		UIRoute:          *uiRoute,
+		EmbedUI:          *embedUI,
+		UIAssets:         *uiAssets,
+		HtmlViewer:       *htmlViewer,
+		ClientPackage:    *clientPackage,
+		ServerPackage:    *serverPackage,
+		GraphqlQueries:   *graphqlQueries,
+		Publish:          *publish,
+		ConfluenceMarkup: *confluenceMarkup,
+		Validation:       *validation,
+		ValidationTag:    *validationTag,
+		Samples:          *samples,
+		Locale:           *locale,
+		MarkupStrings:    *markupStrings,
+		SplitMarkup:      *splitMarkup,
+		ConfluenceUrl:    *confluenceUrl,
+		ConfluenceSpace:  *confluenceSpace,
+		ConfluenceParent: *confluenceParent,
+		ConfluenceTitle:  *confluenceTitle,
+		Upload:           *uploadTarget,
+		UploadHeader:     *uploadHeader,
+		Listen:           *listen,
+		MarkupTemplate:   *markupTemplate,
+		SkipValidation:   *skipValidation,
+		Lint:             *lint,
+		Coverage:         *coverage,
+		CoverageMin:      *coverageMin,
+		Strict:           *strict,
+		DryRun:           *dryRunFlag,
+		Command:          command,
+		OldSpec:          *oldSpec,
+		MigrateTo:        *migrateTo,
+		Overlay:          *overlay,
+		Timings:          *timingsFlag || *verbose,
+		Headers:          *headers,
+		Incremental:      *incremental,
+		Patch:            *patch,
+		ErrorFormat:      *errorFormat,
+		Dialect:          *dialect,
+		NewSpec:          *newSpec,
+		Specs:            *specs,
+		Versions:         *versions,
+		Audiences:        *audiences,
+		BasePath:         *basePath,
+		Host:             *host,
+		Schemes:          *schemes,
+		IncludeTags:      *includeTags,
+		ExcludePaths:     *excludePaths,
+		IncludeInternal:  *includeInternal,
+		IncludeTests:     *includeTests,
+		BuildTags:        *buildTags,
+		ModelNaming:      *modelNaming,
+		PointerOptional:  *pointerOptional,
+		Sort:             *sortOrder,
+		MarshalTypes:     *marshalTypes,
+		Indent:           *indent,
+		Compact:          *compact,
+		Embed:            *embed,
+		PreHook:          *preHook,
+		PostHook:         *postHook,
 	}
 
+	stopProfiling, err := startProfiling(*cpuProfile, *memProfile)
 	if err != nil {
-		return err
-	}
-	log.Println(confirmMsg)
-
-	return nil
-}
-
-func main() {
-	flag.Parse()
-
-	if *mainApiFile == "" {
-		*mainApiFile = *apiPackage + "/main.go"
-	}
-
-	if *apiPackage == "" {
-		flag.PrintDefaults()
-		return
+		logger.Fatalf("%v", err)
 	}
-
-	params := GeneratorParams{
-		ApiPackage:      *apiPackage,
-		MainApiFile:     *mainApiFile,
-		OutputFormat:    *outputFormat,
-		OutputSpec:      *outputSpec,
-		ControllerClass: *controllerClass,
+	recorder := &generator.IORecorder{FileSystem: output.Current}
+	output.Current = recorder
+	err = generator.Generate(params)
+	stopProfiling()
+	if *summary != "" {
+		if summaryErr := generator.WriteSummary(*summary, params, recorder, err); summaryErr != nil {
+			logger.Errorf("Can not write -summary %s: %v", *summary, summaryErr)
+		}
 	}
-
-	err := Generate(params)
 	if err != nil {
-		log.Fatal(err.Error())
+		if params.ErrorFormat == "json" {
+			generator.WriteErrorDiagnostics(os.Stdout, err)
+		}
+		logger.Errorf("%v", err)
+		os.Exit(generator.ExitCode(err, recorder))
 	}
 }
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// AVAILABLE_ERROR_FORMATS are the values of -errors
+const AVAILABLE_ERROR_FORMATS = "text|json"
+
+// Diagnostic is a problem reported with -errors=json, for CI systems and editors. The problems which
+// are not about an annotation, like a package which can not be found, have no file.
+type Diagnostic struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"` // error or warning
+}
+
+// reportedError is an error whose diagnostics are already written
+type reportedError struct {
+	error
+}
+
+func (err reportedError) Unwrap() error {
+	return err.error
+}
+
+// issueDiagnostics returns the diagnostics of annotation problems
+func issueDiagnostics(issues []parser.LintIssue, severity string) []Diagnostic {
+	diagnostics := make([]Diagnostic, 0, len(issues))
+	for _, issue := range issues {
+		diagnostics = append(diagnostics, Diagnostic{
+			File:     issue.Pos.Filename,
+			Line:     issue.Pos.Line,
+			Column:   issue.Pos.Column,
+			Message:  issue.Message,
+			Severity: severity,
+		})
+	}
+	return diagnostics
+}
+
+// errorDiagnostics returns the diagnostics of an error of the generation: one per annotation error of
+// an ErrorList, the error itself otherwise
+func errorDiagnostics(err error) []Diagnostic {
+	var errorList parser.ErrorList
+	if errors.As(err, &errorList) {
+		return issueDiagnostics(errorList, "error")
+	}
+	return []Diagnostic{{Message: strings.TrimSpace(err.Error()), Severity: "error"}}
+}
+
+// writeDiagnostics writes the diagnostics as a JSON array, one diagnostic per line
+func writeDiagnostics(w io.Writer, diagnostics []Diagnostic) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, diagnostic := range diagnostics {
+		data, err := json.Marshal(diagnostic)
+		if err != nil {
+			return err
+		}
+		separator := ",\n "
+		if i == 0 {
+			separator = "\n "
+		}
+		if _, err := io.WriteString(w, separator+string(data)); err != nil {
+			return err
+		}
+	}
+	if len(diagnostics) > 0 {
+		_, err := io.WriteString(w, "\n]\n")
+		return err
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+// WriteErrorDiagnostics writes the diagnostics of an error of Generate with -errors=json, unless they
+// are already written, like the problems found by -lint
+func WriteErrorDiagnostics(w io.Writer, err error) error {
+	var reported reportedError
+	if errors.As(err, &reported) {
+		return nil
+	}
+	return writeDiagnostics(w, errorDiagnostics(err))
+}
@@ -0,0 +1,186 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+const (
+	// Lines of context around the changes of a diff hunk
+	diffContext = 3
+	// Above this many line pairs the changed block is reported as a whole, instead of line by line
+	maxDiffCells = 4000000
+)
+
+// diffOutput generates the output in memory and compares it with the files on disk.
+// The unified diff of every out of date file is printed, an error is returned if there is any.
+func diffOutput(parser *parser.Parser, params GeneratorParams) error {
+	memory := output.NewMemory()
+	defer func(previous output.FileSystem) {
+		output.Current = previous
+	}(output.Current)
+	output.Current = memory
+
+	if _, err := writeOutput(parser, params); err != nil {
+		return err
+	}
+
+	outdated := 0
+	for _, name := range memory.Names() {
+		generated, _ := memory.Content(name)
+		existing, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			existing = nil
+		} else if err != nil {
+			return classify(fmt.Errorf("Can not read %s: %v\n", name, err), EXIT_IO_ERROR)
+		}
+		if bytes.Equal(existing, generated) {
+			continue
+		}
+		outdated++
+		fmt.Print(unifiedDiff(name, string(existing), string(generated)))
+	}
+
+	if outdated > 0 {
+		return classify(fmt.Errorf("%d generated file(s) are out of date, regenerate them\n", outdated), EXIT_OUTPUT_DIFFERS)
+	}
+	return nil
+}
+
+// unifiedDiff returns the diff of the committed and the regenerated content of a file
+func unifiedDiff(name, existing, generated string) string {
+	a, b := splitLines(existing), splitLines(generated)
+	ops := diffLines(a, b)
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("--- %s (committed)\n+++ %s (generated)\n", name, name))
+
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		// extend the hunk while changes are closer than 2*diffContext lines
+		end := start
+		for i := start; i < len(ops); i++ {
+			if ops[i].kind != ' ' {
+				end = i + 1
+			} else if i-end >= 2*diffContext {
+				break
+			}
+		}
+		from, to := start-diffContext, end+diffContext
+		if from < 0 {
+			from = 0
+		}
+		if to > len(ops) {
+			to = len(ops)
+		}
+
+		aStart, aCount, bStart, bCount := 0, 0, 0, 0
+		if from < len(ops) {
+			aStart, bStart = ops[from].aLine, ops[from].bLine
+		}
+		var hunk bytes.Buffer
+		for _, op := range ops[from:to] {
+			hunk.WriteString(string(op.kind) + op.text + "\n")
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+		}
+		buf.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aStart+1, aCount, bStart+1, bCount))
+		buf.Write(hunk.Bytes())
+		start = to
+	}
+	return buf.String()
+}
+
+type diffOp struct {
+	kind         byte // ' ', '-' or '+'
+	text         string
+	aLine, bLine int
+}
+
+// diffLines computes a line diff with the longest common subsequence of the changed middle block
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{' ', a[i], i, i})
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(middleA)*len(middleB) > maxDiffCells {
+		for i, line := range middleA {
+			ops = append(ops, diffOp{'-', line, prefix + i, prefix})
+		}
+		for j, line := range middleB {
+			ops = append(ops, diffOp{'+', line, prefix + len(middleA), prefix + j})
+		}
+	} else {
+		// lcs[i][j] is the LCS length of middleA[i:] and middleB[j:]
+		lcs := make([][]int, len(middleA)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(middleB)+1)
+		}
+		for i := len(middleA) - 1; i >= 0; i-- {
+			for j := len(middleB) - 1; j >= 0; j-- {
+				if middleA[i] == middleB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(middleA) || j < len(middleB) {
+			switch {
+			case i < len(middleA) && j < len(middleB) && middleA[i] == middleB[j]:
+				ops = append(ops, diffOp{' ', middleA[i], prefix + i, prefix + j})
+				i++
+				j++
+			case j < len(middleB) && (i == len(middleA) || lcs[i][j+1] > lcs[i+1][j]):
+				ops = append(ops, diffOp{'+', middleB[j], prefix + i, prefix + j})
+				j++
+			default:
+				ops = append(ops, diffOp{'-', middleA[i], prefix + i, prefix + j})
+				i++
+			}
+		}
+	}
+
+	for k := 0; k < suffix; k++ {
+		i, j := len(a)-suffix+k, len(b)-suffix+k
+		ops = append(ops, diffOp{' ', a[i], i, j})
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
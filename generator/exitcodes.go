@@ -0,0 +1,146 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+)
+
+// Exit codes of the generator, so that pipelines can branch on the class of a failure
+const (
+	EXIT_OK               = 0
+	EXIT_FAILURE          = 1 // any other failure, like an invalid flag or a failed hook
+	EXIT_PARSE_ERROR      = 2 // annotations which can not be parsed, problems found by -lint
+	EXIT_VALIDATION_ERROR = 3 // invalid generated spec, breaking changes, coverage below -coverage-min
+	EXIT_OUTPUT_DIFFERS   = 4 // generated files out of date, with the diff command
+	EXIT_IO_ERROR         = 5 // files or directories which can not be read or written
+)
+
+// exitStatuses are the statuses of the -summary file, by exit code
+var exitStatuses = map[int]string{
+	EXIT_OK:               "ok",
+	EXIT_FAILURE:          "failure",
+	EXIT_PARSE_ERROR:      "parse-error",
+	EXIT_VALIDATION_ERROR: "validation-error",
+	EXIT_OUTPUT_DIFFERS:   "output-differs",
+	EXIT_IO_ERROR:         "io-error",
+}
+
+// classifiedError is an error with the exit code of its class
+type classifiedError struct {
+	error
+	code int
+}
+
+func (err classifiedError) Unwrap() error {
+	return err.error
+}
+
+// classify returns err with the exit code of its class, nil if err is nil
+func classify(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	return classifiedError{err, code}
+}
+
+// ExitCode returns the exit code of an error of Generate. Unclassified errors are IO errors if the
+// output could not be written.
+func ExitCode(err error, recorder *IORecorder) int {
+	var classified classifiedError
+	var pathError *os.PathError
+	switch {
+	case err == nil:
+		return EXIT_OK
+	case errors.As(err, &classified):
+		return classified.code
+	case errors.As(err, &pathError), recorder != nil && recorder.failed:
+		return EXIT_IO_ERROR
+	}
+	return EXIT_FAILURE
+}
+
+// IORecorder is the file system the output is written to: it records the written files, and whether
+// writing failed, whatever the format writing them. Its FileSystem is the one it writes to, e.g. output.Current
+type IORecorder struct {
+	output.FileSystem
+	files  []string
+	failed bool
+}
+
+func (recorder *IORecorder) Create(name string) (output.File, error) {
+	file, err := recorder.FileSystem.Create(name)
+	if err != nil {
+		recorder.failed = true
+		return nil, err
+	}
+	if name != output.Stdout {
+		recorder.files = append(recorder.files, name)
+	}
+	return recordedFile{file, recorder}, nil
+}
+
+func (recorder *IORecorder) MkdirAll(dir string, perm os.FileMode) error {
+	err := recorder.FileSystem.MkdirAll(dir, perm)
+	recorder.failed = recorder.failed || err != nil
+	return err
+}
+
+// recordedFile is a file created by an IORecorder, whose write failures are recorded
+type recordedFile struct {
+	output.File
+	recorder *IORecorder
+}
+
+func (file recordedFile) Write(p []byte) (int, error) {
+	n, err := file.File.Write(p)
+	file.recorder.failed = file.recorder.failed || err != nil
+	return n, err
+}
+
+func (file recordedFile) WriteString(s string) (int, error) {
+	n, err := file.File.WriteString(s)
+	file.recorder.failed = file.recorder.failed || err != nil
+	return n, err
+}
+
+func (file recordedFile) Close() error {
+	err := file.File.Close()
+	file.recorder.failed = file.recorder.failed || err != nil
+	return err
+}
+
+// Summary is the JSON summary of a run written to -summary, for CI pipelines
+type Summary struct {
+	Command  string   `json:"command"` // generate without command
+	Status   string   `json:"status"`  // ok, failure, parse-error, validation-error, output-differs or io-error
+	ExitCode int      `json:"exitCode"`
+	Error    string   `json:"error,omitempty"`
+	Files    []string `json:"files,omitempty"` // written files, none with -dry-run or the diff command
+}
+
+// WriteSummary writes the summary of a run ending with err to fileName
+func WriteSummary(fileName string, params GeneratorParams, recorder *IORecorder, err error) error {
+	code := ExitCode(err, recorder)
+	summary := Summary{
+		Command:  params.Command,
+		Status:   exitStatuses[code],
+		ExitCode: code,
+		Files:    recorder.files,
+	}
+	if summary.Command == "" {
+		summary.Command = "generate"
+	}
+	if err != nil {
+		summary.Error = strings.TrimSpace(err.Error())
+	}
+	data, marshalErr := json.MarshalIndent(summary, "", "    ")
+	if marshalErr != nil {
+		return marshalErr
+	}
+	return ioutil.WriteFile(fileName, append(data, '\n'), output.FileMode)
+}
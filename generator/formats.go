@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/blueprint"
+	"github.com/yvasiyarov/swagger/graphql"
+	"github.com/yvasiyarov/swagger/html"
+	"github.com/yvasiyarov/swagger/jsonschema"
+	"github.com/yvasiyarov/swagger/markup"
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+	"github.com/yvasiyarov/swagger/postman"
+	"github.com/yvasiyarov/swagger/raml"
+	"github.com/yvasiyarov/swagger/typescript"
+)
+
+// Prefix of the executables implementing a format: -format=openapi3 runs swagger-format-openapi3
+const FORMAT_PLUGIN_PREFIX = "swagger-format-"
+
+// Generator writes an output format of the parsed API
+type Generator interface {
+	// Name is the -format value of the format
+	Name() string
+	// Generate writes the files of the format into output.Current and returns a confirmation message
+	Generate(parser *parser.Parser, params GeneratorParams) (string, error)
+}
+
+var generators = make(map[string]Generator)
+
+// RegisterGenerator makes a format available to -format. Formats are added to the build with a
+// file registering them from its init function, the formats of other tools with a plugin executable.
+func RegisterGenerator(generator Generator) {
+	generators[strings.ToLower(generator.Name())] = generator
+}
+
+// LookupGenerator returns the registered generator of format, or the plugin executable
+// FORMAT_PLUGIN_PREFIX+format found in $PATH
+func LookupGenerator(format string) (Generator, bool) {
+	format = strings.ToLower(format)
+	if generator, ok := generators[format]; ok {
+		return generator, true
+	}
+	if command, err := exec.LookPath(FORMAT_PLUGIN_PREFIX + format); err == nil {
+		return &pluginGenerator{name: format, command: command}, true
+	}
+	return nil, false
+}
+
+// generatorFunc is a Generator implemented by a function
+type generatorFunc struct {
+	name       string
+	confirmMsg string
+	generate   func(parser *parser.Parser, params GeneratorParams) error
+}
+
+func (g *generatorFunc) Name() string {
+	return g.name
+}
+
+func (g *generatorFunc) Generate(parser *parser.Parser, params GeneratorParams) (string, error) {
+	return g.confirmMsg, g.generate(parser, params)
+}
+
+func init() {
+	for _, generator := range []*generatorFunc{
+		{"go", "Doc file generated", generateSwaggerDocs},
+		{"swagger", "Swagger UI files generated", generateSwaggerUiFiles},
+		{"asciidoc", "AsciiDoc file generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return generateMarkup(parser, new(markup.MarkupAsciiDoc), params, ".adoc")
+		}},
+		{"markdown", "MarkDown file generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return generateMarkup(parser, new(markup.MarkupMarkDown), params, ".md")
+		}},
+		{"confluence", "Confluence page generated", generateConfluence},
+		{"postman", "Postman collection generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return postman.GenerateCollection(parser, baseUrl(parser), &params.OutputSpec)
+		}},
+		{"apib", "API Blueprint file generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return blueprint.GenerateBlueprint(parser, baseUrl(parser), &params.OutputSpec)
+		}},
+		{"raml", "RAML file generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return raml.GenerateRaml(parser, baseUrl(parser), &params.OutputSpec)
+		}},
+		{"typescript", "TypeScript definitions generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return typescript.GenerateDefinitions(parser, &params.OutputSpec)
+		}},
+		{"goclient", "Go client generated", generateGoClient},
+		{"goserver", "Go server stubs generated", generateGoServer},
+		{"graphql", "GraphQL schema generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return graphql.GenerateSchema(parser, params.GraphqlQueries, &params.OutputSpec)
+		}},
+		{"html", "HTML documentation generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return html.GenerateHtml(parser, baseUrl(parser), params.HtmlViewer, &params.OutputSpec)
+		}},
+		{"jsonschema", "JSON Schema files generated", func(parser *parser.Parser, params GeneratorParams) error {
+			return jsonschema.GenerateSchemas(parser, &params.OutputSpec)
+		}},
+	} {
+		RegisterGenerator(generator)
+	}
+}
+
+// PluginInput is what plugin executables read on their standard input, what they write on
+// their standard output is written to the -output file (API.<format> by default)
+type PluginInput struct {
+	Format          string                            `json:"format"`
+	BaseUrl         string                            `json:"baseUrl"`
+	ResourceListing *parser.ResourceListing           `json:"resourceListing"`
+	ApiDeclarations map[string]*parser.ApiDeclaration `json:"apiDeclarations"`
+}
+
+// pluginGenerator runs a plugin executable
+type pluginGenerator struct {
+	name    string
+	command string
+}
+
+func (g *pluginGenerator) Name() string {
+	return g.name
+}
+
+func (g *pluginGenerator) Generate(parser *parser.Parser, params GeneratorParams) (string, error) {
+	input, err := json.Marshal(PluginInput{
+		Format:          g.name,
+		BaseUrl:         baseUrl(parser),
+		ResourceListing: parser.Listing,
+		ApiDeclarations: parser.TopLevelApis,
+	})
+	if err != nil {
+		return "", fmt.Errorf("Can not serialise the API for the %s plugin: %v\n", g.name, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(g.command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("The %s plugin failed: %v\n%s", g.command, err, stderr.String())
+	}
+
+	outputSpec := params.OutputSpec
+	if outputSpec == "" {
+		outputSpec = defaultOutputFile(g.name)
+	}
+	fd, err := output.Create(outputSpec)
+	if err != nil {
+		return "", fmt.Errorf("Can not create %s: %v\n", outputSpec, err)
+	}
+	defer fd.Close()
+	if _, err := fd.Write(stdout.Bytes()); err != nil {
+		return "", fmt.Errorf("Can not write %s: %v\n", outputSpec, err)
+	}
+	return fmt.Sprintf("%s file generated by %s", g.name, g.command), nil
+}
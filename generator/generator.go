@@ -0,0 +1,1196 @@
+// Package generator parses an annotated API package and writes its docs in the output formats. The
+// swagger command is a front end to Generate, programs can call it too, with Go hooks and formats.
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/yvasiyarov/swagger/changes"
+	"github.com/yvasiyarov/swagger/goclient"
+	"github.com/yvasiyarov/swagger/jsonpatch"
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/markup"
+	"github.com/yvasiyarov/swagger/merge"
+	"github.com/yvasiyarov/swagger/mock"
+	"github.com/yvasiyarov/swagger/openapi"
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	AVAILABLE_FORMATS    = "go|swagger|asciidoc|markdown|confluence|postman|apib|raml|typescript|goclient|goserver|graphql|jsonschema|html"
+	AVAILABLE_FRAMEWORKS = "plain|beego|nethttp"
+	AVAILABLE_COMMANDS   = "diff|breaking|merge|mock|migrate|completion"
+	AVAILABLE_MIGRATIONS = "2.0|3.0"
+
+	// The URL test requests are sent to, when the output format needs one and no -host is given
+	DEFAULT_BASE_URL = "http://localhost:8080"
+
+	// The address the mock command listens on, when no -listen is given
+	DEFAULT_MOCK_ADDRESS = "localhost:8080"
+
+	// The basePath of docs.go, replaced with the URL of the server at runtime
+	BASE_PATH_PLACEHOLDER = "{{.}}"
+)
+
+// IsController returns the controller filter of -controllerClass, a regular expression the receiver
+// names of the controllers must match: every method is a controller when it is empty. It must return
+// true if funcDeclaration is controller. We will try to parse only comments before controllers
+func IsController(controllerClass string) (func(funcDeclaration *ast.FuncDecl) bool, error) {
+	controllerRegexp, err := regexp.Compile(controllerClass)
+	if err != nil {
+		return nil, fmt.Errorf("The -controllerClass argument is not a valid regular expression: %v\n", err)
+	}
+	return func(funcDeclaration *ast.FuncDecl) bool {
+		if len(controllerClass) == 0 {
+			// Search every method
+			return true
+		}
+		if funcDeclaration.Recv != nil && len(funcDeclaration.Recv.List) > 0 {
+			if starExpression, ok := funcDeclaration.Recv.List[0].Type.(*ast.StarExpr); ok {
+				receiverName := fmt.Sprint(starExpression.X)
+				return controllerRegexp.MatchString(receiverName)
+			}
+		}
+		return false
+	}, nil
+}
+
+// GoTemplateData is the data the docs.go templates are executed with
+type GoTemplateData struct {
+	// Package is the package name of docs.go, set by -goPackage
+	Package string
+	// DocsRoute and UIRoute are the default routes of the docs and the Swagger UI in SetupRouter
+	DocsRoute, UIRoute string
+	// EmbedUI is set by -embedUI: the Swagger UI files are copied to the UIAssetsDir directory,
+	// next to docs.go, to be embedded with //go:embed
+	EmbedUI     bool
+	UIAssetsDir string
+	// ResourceListing and ApiDescriptions are JSON documents, quoted as Go raw string literals. They
+	// are empty with Embed: the JSON is only written to the files.
+	ResourceListing string
+	ApiDescriptions string
+	Listing         *parser.ResourceListing
+	Apis            map[string]*parser.ApiDeclaration
+	// Embed is set by -embed: the JSON documents are also written to ResourceListingFile and
+	// ApiDescriptionsFile, next to docs.go, to be embedded with //go:embed
+	Embed                                    bool
+	ResourceListingFile, ApiDescriptionsFile string
+	// ValidationTag is the build tag of validation.go, set by -validationTag
+	ValidationTag string
+}
+
+var goTemplateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		json, err := json.MarshalIndent(v, "", "    ")
+		return string(json), err
+	},
+	"quote": strconv.Quote,
+	"lower": strings.ToLower,
+	"trim":  strings.Trim,
+}
+
+// validateSpec checks the serialised resource listing and API declarations, all violations are reported at once
+func validateSpec(p *parser.Parser) error {
+	var messages []string
+	for _, err := range parser.ValidateResourceListing(p.GetResourceListingJson()) {
+		messages = append(messages, fmt.Sprintf("index.json#%v", err))
+	}
+	for apiKey, apiDescription := range p.TopLevelApis {
+		if apiDescription.BasePath == BASE_PATH_PLACEHOLDER {
+			// the placeholder is replaced by the URL the docs are served from, the schema wants a URL
+			placeholder := *apiDescription
+			placeholder.BasePath = baseUrl(p)
+			apiDescription = &placeholder
+		}
+		json, err := json.Marshal(apiDescription)
+		if err != nil {
+			return fmt.Errorf("Can not serialise []ApiDescription to JSON: %v\n", err)
+		}
+		for _, err := range parser.ValidateApiDeclaration(json) {
+			messages = append(messages, fmt.Sprintf("%s/index.json#%v", apiKey, err))
+		}
+	}
+	if len(messages) > 0 {
+		sort.Strings(messages)
+		return classify(fmt.Errorf("Generated spec breaks the rules of Swagger %s (use -skipValidation to write it anyway):\n%s\n", parser.SwaggerVersion, strings.Join(messages, "\n")), EXIT_VALIDATION_ERROR)
+	}
+	return nil
+}
+
+func generateSwaggerDocs(parser *parser.Parser, params GeneratorParams) error {
+	if !params.SkipValidation {
+		if err := validateSpec(parser); err != nil {
+			return err
+		}
+	}
+
+	var fileTemplate string
+	if params.GoTemplate != "" {
+		templateText, err := ioutil.ReadFile(params.GoTemplate)
+		if err != nil {
+			return fmt.Errorf("Can not read -goTemplate file: %v\n", err)
+		}
+		fileTemplate = string(templateText)
+	} else {
+		framework := strings.ToLower(params.GoFramework)
+		if framework == "" {
+			framework = "plain"
+		}
+		var ok bool
+		if fileTemplate, ok = generatedFileTemplates[framework]; !ok {
+			return fmt.Errorf("Invalid -goFramework specified. Must be one of %v.", AVAILABLE_FRAMEWORKS)
+		}
+	}
+
+	if params.GoPackage == "" {
+		params.GoPackage = "docs"
+	}
+	if params.GoDir == "" {
+		params.GoDir = "docs"
+	}
+	if params.GoFile == "" {
+		params.GoFile = "docs.go"
+	}
+	if !token.IsIdentifier(params.GoPackage) {
+		return fmt.Errorf("Invalid -goPackage specified: %s is not a Go identifier.", params.GoPackage)
+	}
+
+	// templates declare Rootinfo and Subapi with {{template "spec" .}}
+	tmpl := template.New("docs.go").Funcs(goTemplateFuncs)
+	if _, err := tmpl.New("spec").Parse(specTemplate); err != nil {
+		return fmt.Errorf("Can not parse docs.go template: %v\n", err)
+	}
+	if _, err := tmpl.Parse(fileTemplate); err != nil {
+		return fmt.Errorf("Can not parse docs.go template: %v\n", err)
+	}
+
+	docsIndent := 4
+	if params.Compact {
+		docsIndent = 0
+	}
+	data := GoTemplateData{
+		Package:             params.GoPackage,
+		DocsRoute:           routePath(params.DocsRoute, "/rawdoc"),
+		UIRoute:             routePath(params.UIRoute, "/swagger-ui"),
+		EmbedUI:             params.EmbedUI,
+		UIAssetsDir:         UI_ASSETS_DIR,
+		Listing:             parser.Listing,
+		Apis:                parser.TopLevelApis,
+		Embed:               params.Embed,
+		ResourceListingFile: "rootinfo.json",
+		ApiDescriptionsFile: "subapi.json",
+		ValidationTag:       params.ValidationTag,
+	}
+
+	goDir := path.Join(params.OutputSpec, params.GoDir)
+	if err := output.MkdirAll(goDir, output.DirMode); err != nil {
+		return fmt.Errorf("Can not create %s directory: %v\n", goDir, err)
+	}
+	if params.EmbedUI {
+		if framework := strings.ToLower(params.GoFramework); params.GoTemplate == "" && framework != "nethttp" {
+			return errors.New("-embedUI needs -goFramework=nethttp\n")
+		}
+		assetsDir, err := swaggerUiAssets(params.UIAssets)
+		if err != nil {
+			return err
+		}
+		if err := copySwaggerUi(assetsDir, path.Join(goDir, data.UIAssetsDir)); err != nil {
+			return fmt.Errorf("Can not copy the Swagger UI files: %v\n", err)
+		}
+	}
+	if params.Embed {
+		// the JSON is streamed to the embedded files, it is never held in memory as a whole
+		if err := writeJsonFile(path.Join(goDir, data.ResourceListingFile), parser.Listing, docsIndent); err != nil {
+			return err
+		}
+		if err := writeApiDescriptionsFile(path.Join(goDir, data.ApiDescriptionsFile), parser, docsIndent); err != nil {
+			return err
+		}
+	} else {
+		var resourceListing, apiDescriptions bytes.Buffer
+		if err := writeJson(&resourceListing, parser.Listing, docsIndent); err != nil {
+			return err
+		}
+		if err := writeApiDescriptions(&apiDescriptions, parser, docsIndent); err != nil {
+			return err
+		}
+		data.ResourceListing = rawStringLiteral(resourceListing.String())
+		data.ApiDescriptions = rawStringLiteral(apiDescriptions.String())
+	}
+
+	var source bytes.Buffer
+	if err := tmpl.Execute(&source, data); err != nil {
+		return fmt.Errorf("Can not execute docs.go template: %v\n", err)
+	}
+	formatted, err := formatGoSource(goDir, params.GoFile, source.Bytes(), nil)
+	if err != nil {
+		return err
+	}
+
+	fd, err := output.Create(path.Join(goDir, params.GoFile))
+	if err != nil {
+		return fmt.Errorf("Can not create document file: %v\n", err)
+	}
+	defer fd.Close()
+	if _, err := fd.Write(formatted); err != nil {
+		return fmt.Errorf("Can not write document file: %v\n", err)
+	}
+
+	if params.Validation {
+		return generateValidation(goDir, data, map[string][]byte{params.GoFile: formatted})
+	}
+	return nil
+}
+
+// generateValidation writes validation.go, the middleware validating requests and responses against
+// the Subapi JSON of docs.go, which is in docsSource
+func generateValidation(goDir string, data GoTemplateData, docsSource map[string][]byte) error {
+	if data.ValidationTag != "" && !token.IsIdentifier(data.ValidationTag) {
+		return fmt.Errorf("Invalid -validationTag specified: %s is not a build tag.", data.ValidationTag)
+	}
+	tmpl, err := template.New("validation.go").Parse(validationFileTemplate)
+	if err != nil {
+		return fmt.Errorf("Can not parse validation.go template: %v\n", err)
+	}
+	var source bytes.Buffer
+	if err := tmpl.Execute(&source, data); err != nil {
+		return fmt.Errorf("Can not execute validation.go template: %v\n", err)
+	}
+	formatted, err := formatGoSource(goDir, "validation.go", source.Bytes(), docsSource)
+	if err != nil {
+		return err
+	}
+	return writeFile(path.Join(goDir, "validation.go"), formatted)
+}
+
+// generateGoClient writes the -format=goclient client of the API, client.go by default
+func generateGoClient(parser *parser.Parser, params GeneratorParams) error {
+	if !token.IsIdentifier(params.ClientPackage) {
+		return fmt.Errorf("Invalid -clientPackage specified: %s is not a Go identifier.", params.ClientPackage)
+	}
+	fileName := params.OutputSpec
+	if fileName == "" {
+		fileName = defaultOutputFile("goclient")
+	}
+	formatted, err := formatGoSource(goSourceDir(fileName), path.Base(fileName), goclient.Render(parser, baseUrl(parser), params.ClientPackage), nil)
+	if err != nil {
+		return err
+	}
+	return writeFile(fileName, formatted)
+}
+
+// generateGoServer writes the -format=goserver interface of the operations and its router, server.go by default
+func generateGoServer(parser *parser.Parser, params GeneratorParams) error {
+	if !token.IsIdentifier(params.ServerPackage) {
+		return fmt.Errorf("Invalid -serverPackage specified: %s is not a Go identifier.", params.ServerPackage)
+	}
+	fileName := params.OutputSpec
+	if fileName == "" {
+		fileName = defaultOutputFile("goserver")
+	}
+	formatted, err := formatGoSource(goSourceDir(fileName), path.Base(fileName), goclient.RenderServer(parser, params.ServerPackage), nil)
+	if err != nil {
+		return err
+	}
+	return writeFile(fileName, formatted)
+}
+
+// routePath returns a route of SetupRouter with a leading and no trailing slash, defaultRoute if it is
+// empty, or "" if it is none
+func routePath(route string, defaultRoute string) string {
+	switch route = strings.TrimSpace(route); route {
+	case "":
+		return defaultRoute
+	case "none":
+		return ""
+	case "/":
+		return route
+	}
+	return "/" + strings.Trim(route, "/")
+}
+
+// writeFile creates the file name with content
+func writeFile(name string, content []byte) error {
+	fd, err := output.Create(name)
+	if err != nil {
+		return fmt.Errorf("Can not create %s: %v\n", name, err)
+	}
+	defer fd.Close()
+	if _, err := fd.Write(content); err != nil {
+		return fmt.Errorf("Can not write %s: %v\n", name, err)
+	}
+	return nil
+}
+
+// writeJsonFile streams v to the file name, indented with indent spaces or minified if indent is 0
+func writeJsonFile(name string, v interface{}, indent int) error {
+	return writeFileWith(name, func(w io.Writer) error {
+		return writeJson(w, v, indent)
+	})
+}
+
+// writeApiDescriptionsFile streams the API declarations of parser to the file name, as one JSON object
+func writeApiDescriptionsFile(name string, parser *parser.Parser, indent int) error {
+	return writeFileWith(name, func(w io.Writer) error {
+		return writeApiDescriptions(w, parser, indent)
+	})
+}
+
+// writeFileWith creates the file name and writes it with write, through a buffer
+func writeFileWith(name string, write func(w io.Writer) error) error {
+	fd, err := output.Create(name)
+	if err != nil {
+		return fmt.Errorf("Can not create %s: %v\n", name, err)
+	}
+	defer fd.Close()
+	buffered := bufio.NewWriter(fd)
+	if err := write(buffered); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("Can not write %s: %v\n", name, err)
+	}
+	return fd.Close()
+}
+
+// rawStringLiteral quotes the JSON document json as a Go raw string literal. A backtick can only be
+// in a JSON string, where it is written as the \u0060 escape.
+func rawStringLiteral(json string) string {
+	return "`" + strings.Replace(json, "`", `\u0060`, -1) + "`"
+}
+
+// writeJson writes v to w, indented with indent spaces or minified if indent is 0
+func writeJson(w io.Writer, v interface{}, indent int) error {
+	json, err := marshalJson(v, indent)
+	if err != nil {
+		return fmt.Errorf("Can not serialise %T to JSON: %v\n", v, err)
+	}
+	_, err = w.Write(json)
+	return err
+}
+
+// writeApiDescriptions writes the API declarations of parser to w as one JSON object, keyed by the
+// sorted API keys, so the diff command sees no change when the spec did not change. The declarations
+// are serialised one at a time: the whole object is never held in memory by writeApiDescriptions.
+func writeApiDescriptions(w io.Writer, parser *parser.Parser, indent int) error {
+	apiKeys := make([]string, 0, len(parser.TopLevelApis))
+	for apiKey := range parser.TopLevelApis {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Strings(apiKeys)
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+	for i, apiKey := range apiKeys {
+		separator := ""
+		if i > 0 {
+			separator = ",\n"
+		}
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+		if err := writeJson(w, apiKey, 0); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ":"); err != nil {
+			return err
+		}
+		if err := writeJson(w, parser.TopLevelApis[apiKey], indent); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}")
+	return err
+}
+
+func generateSwaggerUiFiles(parser *parser.Parser, params GeneratorParams) error {
+	if !params.SkipValidation {
+		if err := validateSpec(parser); err != nil {
+			return err
+		}
+	}
+
+	// the files are streamed one at a time, so that the spec is never serialised as a whole
+	if err := writeJsonFile(path.Join(params.OutputSpec, "index.json"), parser.Listing, params.Indent); err != nil {
+		return err
+	}
+
+	for apiKey, apiDescription := range parser.TopLevelApis {
+		if err := output.MkdirAll(path.Join(params.OutputSpec, apiKey), output.DirMode); err != nil {
+			return err
+		}
+		if err := writeJsonFile(path.Join(params.OutputSpec, apiKey, "index.json"), apiDescription, params.Indent); err != nil {
+			return err
+		}
+		logger.Debugf("Wrote %v/index.json", apiKey)
+	}
+
+	return nil
+}
+
+func generateMarkup(parser *parser.Parser, m markup.Markup, params GeneratorParams, defaultFileExtension string) error {
+	options, err := markupOptions(parser, params)
+	if err != nil {
+		return err
+	}
+	if params.MarkupTemplate != "" {
+		return markup.GenerateMarkupFromTemplate(parser, m, options, params.MarkupTemplate, nil, &params.OutputSpec, defaultFileExtension)
+	}
+	return markup.GenerateMarkup(parser, m, options, &params.OutputSpec, defaultFileExtension)
+}
+
+// markupOptions returns the options of the markup formats set by -samples, -locale and -markupStrings
+func markupOptions(parser *parser.Parser, params GeneratorParams) (markup.Options, error) {
+	sampleLanguages, err := markup.ParseSamples(params.Samples)
+	if err != nil {
+		return markup.Options{}, err
+	}
+	translations, err := markup.LoadTranslations(params.Locale, params.MarkupStrings)
+	if err != nil {
+		return markup.Options{}, err
+	}
+	options := markup.Options{BaseUrl: baseUrl(parser), Samples: sampleLanguages, Translations: translations}
+	if params.SplitMarkup {
+		if params.OutputSpec == output.Stdout {
+			return markup.Options{}, errors.New("-splitMarkup writes several files, it can not be used with -output -\n")
+		}
+		options.Split = true
+	}
+	return options, nil
+}
+
+// lintAnnotations prints every annotation problem of the API package as file:line: message
+func lintAnnotations(parser *parser.Parser, params GeneratorParams) error {
+	issues := parser.Lint(params.ApiPackage)
+	if params.ErrorFormat == "json" {
+		if err := writeDiagnostics(os.Stdout, issueDiagnostics(issues, "error")); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Println(issue)
+		}
+	}
+	if len(issues) > 0 {
+		return classify(reportedError{fmt.Errorf("Found %d annotation problem(s)\n", len(issues))}, EXIT_PARSE_ERROR)
+	}
+	logger.Infof("No annotation problems found")
+	return nil
+}
+
+// coverageReport prints the undocumented handlers and models, and the documentation coverage. It fails
+// if the coverage is below -coverage-min.
+func coverageReport(p *parser.Parser, params GeneratorParams) error {
+	if params.CoverageMin < 0 || params.CoverageMin > 100 {
+		return fmt.Errorf("Invalid -coverage-min specified. Must be between 0 and 100.")
+	}
+	coverage, err := p.Coverage(params.ApiPackage)
+	if err != nil {
+		return classify(err, EXIT_PARSE_ERROR)
+	}
+	var undocumented []parser.LintIssue
+	for _, item := range coverage {
+		if item.Documented {
+			continue
+		}
+		if item.Kind == "model" {
+			undocumented = append(undocumented, parser.LintIssue{Pos: item.Pos, Message: fmt.Sprintf("model %s has no description", item.Name)})
+		} else {
+			undocumented = append(undocumented, parser.LintIssue{Pos: item.Pos, Message: fmt.Sprintf("%s has no annotations", item.Name)})
+		}
+	}
+	if percent := coverage.Percent(""); percent < params.CoverageMin {
+		err = classify(fmt.Errorf("Documentation coverage %.1f%% is below -coverage-min %.1f%%\n", percent, params.CoverageMin), EXIT_VALIDATION_ERROR)
+	}
+
+	// with -errors=json, the undocumented items are warnings and the percentages are logged
+	if params.ErrorFormat == "json" {
+		diagnostics := issueDiagnostics(undocumented, "warning")
+		if err != nil {
+			diagnostics = append(diagnostics, errorDiagnostics(err)...)
+			err = classify(reportedError{err}, EXIT_VALIDATION_ERROR)
+		}
+		if writeErr := writeDiagnostics(os.Stdout, diagnostics); writeErr != nil {
+			return writeErr
+		}
+	} else {
+		for _, issue := range undocumented {
+			fmt.Println(issue)
+		}
+	}
+	for _, kind := range []string{"operation", "model", ""} {
+		documented, total := coverage.Count(kind)
+		label := kind + "s"
+		if kind == "" {
+			label = "total"
+		}
+		if params.ErrorFormat == "json" {
+			logger.Infof("%s %d/%d documented (%.1f%%)", label+":", documented, total, coverage.Percent(kind))
+		} else {
+			fmt.Printf("%-12s %d/%d documented (%.1f%%)\n", label+":", documented, total, coverage.Percent(kind))
+		}
+	}
+	return err
+}
+
+// applyServerFlags overrides the @BasePath, @Host and @Schemes annotations with the -basePath, -host and -schemes flags.
+// With a host, the basePath becomes <first scheme>://<host><path of basePath>.
+func applyServerFlags(p *parser.Parser, params GeneratorParams) {
+	if params.BasePath != "" {
+		p.BasePath = params.BasePath
+	}
+	if params.Host != "" {
+		p.Host = params.Host
+	}
+	if params.Schemes != "" {
+		p.Schemes = strings.Split(strings.Replace(params.Schemes, " ", "", -1), ",")
+	}
+	if p.Host == "" {
+		return
+	}
+
+	scheme := "http"
+	if len(p.Schemes) > 0 && p.Schemes[0] != "" {
+		scheme = p.Schemes[0]
+	}
+	basePath := ""
+	if p.BasePath != BASE_PATH_PLACEHOLDER {
+		basePath = p.BasePath
+		if parsedUrl, err := url.Parse(basePath); err == nil && parsedUrl.Host != "" {
+			basePath = parsedUrl.Path
+		}
+		if basePath = strings.Trim(basePath, "/"); basePath != "" {
+			basePath = "/" + basePath
+		}
+	}
+	p.BasePath = scheme + "://" + p.Host + basePath
+}
+
+// applyOverlay merges the -overlay file into the parsed API
+func applyOverlay(p *parser.Parser, params GeneratorParams) error {
+	if params.Overlay == "" {
+		return nil
+	}
+	defer timings.track("apply -overlay", time.Now())
+	content, err := ioutil.ReadFile(params.Overlay)
+	if err != nil {
+		return fmt.Errorf("Can not read -overlay file: %v\n", err)
+	}
+	var overlay map[string]interface{}
+	// JSON is YAML too
+	if err := yaml.Unmarshal(content, &overlay); err != nil {
+		return fmt.Errorf("Can not parse -overlay file %s: %v\n", params.Overlay, err)
+	}
+	if err := p.ApplyOverlay(overlay); err != nil {
+		return err
+	}
+	logger.Debugf("Applied the overlay %s", params.Overlay)
+	return nil
+}
+
+// applyHeaders adds the standard response headers of the -headers file to the parsed API
+func applyHeaders(p *parser.Parser, params GeneratorParams) error {
+	if params.Headers == "" {
+		return nil
+	}
+	defer timings.track("apply -headers", time.Now())
+	content, err := ioutil.ReadFile(params.Headers)
+	if err != nil {
+		return fmt.Errorf("Can not read -headers file: %v\n", err)
+	}
+	var headers []parser.StandardHeader
+	// JSON is YAML too
+	if err := yaml.Unmarshal(content, &headers); err != nil {
+		return fmt.Errorf("Can not parse -headers file %s: %v\n", params.Headers, err)
+	}
+	if err := p.AddStandardHeaders(headers); err != nil {
+		return fmt.Errorf("Can not apply -headers file %s: %v\n", params.Headers, err)
+	}
+	logger.Debugf("Added the %d standard headers of %s", len(headers), params.Headers)
+	return nil
+}
+
+// applyPatch applies the -patch file to the parsed API
+func applyPatch(p *parser.Parser, params GeneratorParams) error {
+	if params.Patch == "" {
+		return nil
+	}
+	defer timings.track("apply -patch", time.Now())
+	content, err := ioutil.ReadFile(params.Patch)
+	if err != nil {
+		return fmt.Errorf("Can not read -patch file: %v\n", err)
+	}
+	var patch []jsonpatch.Operation
+	// JSON is YAML too
+	if err := yaml.Unmarshal(content, &patch); err != nil {
+		return fmt.Errorf("Can not parse -patch file %s: %v\n", params.Patch, err)
+	}
+	if err := p.ApplyPatch(patch); err != nil {
+		return fmt.Errorf("Can not apply -patch file %s: %v\n", params.Patch, err)
+	}
+	logger.Debugf("Applied the patch %s", params.Patch)
+	return nil
+}
+
+// filterApis keeps the resources of -include-tags and drops the operations under -exclude-paths,
+// the parser is returned unchanged without these flags
+func filterApis(p *parser.Parser, params GeneratorParams) *parser.Parser {
+	if params.IncludeTags == "" && params.ExcludePaths == "" {
+		return p
+	}
+	var includeTags, excludePaths []string
+	if params.IncludeTags != "" {
+		includeTags = strings.Split(strings.Replace(params.IncludeTags, " ", "", -1), ",")
+	}
+	if params.ExcludePaths != "" {
+		excludePaths = strings.Split(strings.Replace(params.ExcludePaths, " ", "", -1), ",")
+	}
+
+	filtered := p.Filter(includeTags, excludePaths)
+	if len(filtered.TopLevelApis) == 0 {
+		logger.Warnf("No operation left after -include-tags %s and -exclude-paths %s", params.IncludeTags, params.ExcludePaths)
+	}
+	return filtered
+}
+
+// baseUrl is the URL test requests of the postman, apib and raml formats are sent to
+func baseUrl(p *parser.Parser) string {
+	if strings.Contains(p.BasePath, "://") {
+		return p.BasePath
+	}
+	if strings.HasPrefix(p.BasePath, "/") {
+		return DEFAULT_BASE_URL + p.BasePath
+	}
+	return DEFAULT_BASE_URL
+}
+
+// InitParser returns the parser of Generate, parsing the controllers of controllerClass
+func InitParser(controllerClass string) (*parser.Parser, error) {
+	isController, err := IsController(controllerClass)
+	if err != nil {
+		return nil, err
+	}
+	parser := parser.NewParser()
+
+	parser.BasePath = BASE_PATH_PLACEHOLDER
+	parser.IsController = isController
+
+	parser.TypesImplementingMarshalInterface["NullString"] = "string"
+	parser.TypesImplementingMarshalInterface["NullInt64"] = "int"
+	parser.TypesImplementingMarshalInterface["NullFloat64"] = "float64"
+	parser.TypesImplementingMarshalInterface["NullBool"] = "bool"
+
+	return parser, nil
+}
+
+// GeneratorParams are the parameters of Generate, the flags of the swagger command
+type GeneratorParams struct {
+	ApiPackage, MainApiFile, OutputFormat, OutputSpec, ControllerClass, GoFramework, GoTemplate, MarkupTemplate                                                                                            string
+	Command, OldSpec, NewSpec, Specs, Versions, Audiences, BasePath, Host, Schemes, ModelNaming, MarshalTypes, Sort, IncludeTags, ExcludePaths, BuildTags, MigrateTo, Overlay, Patch, Dialect, ErrorFormat string
+	GoPackage, GoDir, GoFile, DocsRoute, UIRoute, UIAssets, HtmlViewer, ClientPackage, ServerPackage                                                                                                       string
+	ConfluenceMarkup, ConfluenceUrl, ConfluenceSpace, ConfluenceParent, ConfluenceTitle, Upload, UploadHeader, Listen, ValidationTag, Samples, Locale, MarkupStrings, Headers, Incremental                 string
+	PreHook, PostHook                                                                                                                                                                                      string // shell commands
+	SkipValidation, Lint, Strict, DryRun, PointerOptional, Compact, Embed, EmbedUI, Publish, Validation, SplitMarkup, IncludeInternal, IncludeTests, Timings, GraphqlQueries                               bool
+	Indent                                                                                                                                                                                                 int // spaces of the JSON files, 0 for minified JSON
+	Coverage                                                                                                                                                                                               bool
+	CoverageMin                                                                                                                                                                                            float64 // percentage of documented handlers and models -coverage requires
+	// Go callbacks run before parsing and after writing the output, before the shell commands
+	PreHooks, PostHooks []Hook
+}
+
+// marshalJson serialises v indented with indent spaces, or minified if indent is 0
+func marshalJson(v interface{}, indent int) ([]byte, error) {
+	if indent <= 0 {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", strings.Repeat(" ", indent))
+}
+
+// Generate parses the API package of params and writes the output formats, or runs params.Command.
+// Errors of annotations are parser.ErrorList, ExitCode tells the class of the other errors.
+func Generate(params GeneratorParams) error {
+	if params.Timings {
+		previous := output.Current
+		timings = &phaseTimings{}
+		output.Current = timedFileSystem{previous, timings}
+		defer func(start time.Time) {
+			output.Current = previous
+			timings.report(time.Since(start))
+			timings = nil
+		}(time.Now())
+	}
+
+	if err := runHooks("pre", params.PreHooks, params.PreHook, params); err != nil {
+		return err
+	}
+
+	// commands working on written specs only
+	switch {
+	case params.Command == "breaking" && params.NewSpec != "":
+		_, newApis, err := parser.ReadSpec(params.NewSpec)
+		if err != nil {
+			return err
+		}
+		return breakingChanges(newApis, params)
+	case params.Command == "merge":
+		return mergeSpecs(params)
+	case params.Command == "migrate":
+		return migrateSpec(params)
+	}
+
+	marshalTypes, err := parser.ParseMarshalTypes(params.MarshalTypes)
+	if err != nil {
+		return err
+	}
+
+	parser, err := InitParser(params.ControllerClass)
+	if err != nil {
+		return err
+	}
+	parser.Strict = params.Strict
+	parser.ModelNaming = params.ModelNaming
+	parser.PointerOptional = params.PointerOptional
+	parser.Sort = params.Sort
+	parser.IncludeInternal = params.IncludeInternal
+	parser.IncludeTests = params.IncludeTests
+	parser.Dialect = params.Dialect
+	if params.BuildTags != "" {
+		parser.BuildTags = strings.Split(strings.Replace(params.BuildTags, " ", "", -1), ",")
+	}
+	for typeName, swaggerType := range marshalTypes {
+		parser.TypesImplementingMarshalInterface[typeName] = swaggerType
+	}
+	if params.Lint {
+		return lintAnnotations(parser, params)
+	}
+
+	logger.Infof("Start parsing")
+
+	apifile := FindMainApiFile(parser, params.MainApiFile)
+	if apifile == "" {
+		return fmt.Errorf("Could not find apifile %s to parse, it is neither a file of a package listed by the go command nor a file path\n", params.MainApiFile)
+	}
+	// the annotation errors of the main API file are returned by ParseApi, with the ones of the API package
+	start := time.Now()
+	if err := parser.ParseGeneralApiInfo(apifile); err != nil && !isAnnotationErrors(err) {
+		return classify(err, EXIT_PARSE_ERROR)
+	}
+	timings.track("parse general info", start)
+
+	applyServerFlags(parser, params)
+	start = time.Now()
+	err = parseApi(parser, apifile, params)
+	timings.trackPackages(parser.PackageTimings, start)
+	if err != nil {
+		return classify(err, EXIT_PARSE_ERROR)
+	}
+	logger.Infof("Finish parsing")
+	if err := applyOverlay(parser, params); err != nil {
+		return err
+	}
+	if err := applyHeaders(parser, params); err != nil {
+		return err
+	}
+	if params.Coverage {
+		return coverageReport(parser, params)
+	}
+	parser = filterApis(parser, params)
+	if err := applyPatch(parser, params); err != nil {
+		return err
+	}
+
+	if params.DryRun {
+		return dryRun(parser, params)
+	}
+	switch params.Command {
+	case "diff":
+		return diffOutput(parser, params)
+	case "breaking":
+		return breakingChanges(parser.TopLevelApis, params)
+	case "mock":
+		return serveMock(parser, params)
+	}
+
+	confirmMsg, err := writeBucketOutput(parser, params)
+	if err != nil {
+		return err
+	}
+	logger.Infof("%s", confirmMsg)
+
+	if err := uploadSpec(parser, params); err != nil {
+		return err
+	}
+	return runHooks("post", params.PostHooks, params.PostHook, params)
+}
+
+// outputVariant is a part of the parsed API written into its own sub directory of -output
+type outputVariant struct {
+	name   string // sub directory, e.g. v1 or v1/public
+	filter func(*parser.Parser) *parser.Parser
+}
+
+// outputVariants returns one variant per -versions version, one per -audiences audience, or one per
+// version and audience if both are set. There is none without these flags.
+func outputVariants(params GeneratorParams) []outputVariant {
+	var variants []outputVariant
+	for _, version := range strings.Split(params.Versions, ",") {
+		if version = strings.TrimSpace(version); version != "" {
+			version := version
+			variants = append(variants, outputVariant{version, func(p *parser.Parser) *parser.Parser {
+				return p.FilterVersion(version)
+			}})
+		}
+	}
+
+	var audiences []outputVariant
+	for _, audience := range strings.Split(params.Audiences, ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			audience := audience
+			audiences = append(audiences, outputVariant{audience, func(p *parser.Parser) *parser.Parser {
+				return p.FilterAudience(audience)
+			}})
+		}
+	}
+	if len(variants) == 0 {
+		return audiences
+	}
+	if len(audiences) == 0 {
+		return variants
+	}
+
+	var combined []outputVariant
+	for _, version := range variants {
+		for _, audience := range audiences {
+			version, audience := version, audience
+			combined = append(combined, outputVariant{path.Join(version.name, audience.name), func(p *parser.Parser) *parser.Parser {
+				return audience.filter(version.filter(p))
+			}})
+		}
+	}
+	return combined
+}
+
+// writeOutput generates the -format files of the parsed API into output.Current, once per
+// -versions version and -audiences audience into their sub directory if these flags are set
+func writeOutput(p *parser.Parser, params GeneratorParams) (string, error) {
+	variants := outputVariants(params)
+	if params.OutputSpec == output.Stdout {
+		formats := splitFormats(params.OutputFormat)
+		if len(formats) != 1 || directoryFormats[formats[0]] || len(variants) > 0 {
+			return "", errors.New("-output - writes one file format to the standard output, it can not be used with the go, swagger and jsonschema formats, several formats, -versions or -audiences\n")
+		}
+	}
+	if formats := splitFormats(params.OutputFormat); len(formats) > 1 {
+		return writeFormats(p, params, formats)
+	}
+	if len(variants) == 0 {
+		return writeFormat(p, params)
+	}
+
+	var confirmMsgs []string
+	for _, variant := range variants {
+		variantParams := params
+		variantParams.OutputSpec = versionOutputSpec(params.OutputSpec, strings.ToLower(params.OutputFormat), variant.name)
+		if err := output.MkdirAll(versionOutputDir(variantParams.OutputSpec, strings.ToLower(params.OutputFormat)), output.DirMode); err != nil {
+			return "", fmt.Errorf("Can not create %s output directory: %v\n", variant.name, err)
+		}
+
+		confirmMsg, err := writeFormat(variant.filter(p), variantParams)
+		if err != nil {
+			return "", err
+		}
+		confirmMsgs = append(confirmMsgs, fmt.Sprintf("%s (%s)", confirmMsg, variant.name))
+	}
+	return strings.Join(confirmMsgs, ", "), nil
+}
+
+// writeBucketOutput is writeOutput, which uploads the files to the bucket of an s3://bucket/prefix
+// or gs://bucket/prefix -output
+func writeBucketOutput(p *parser.Parser, params GeneratorParams) (string, error) {
+	if !output.IsBucket(params.OutputSpec) {
+		return writeOutput(p, params)
+	}
+	bucket, err := output.NewBucket(params.OutputSpec)
+	if err != nil {
+		return "", err
+	}
+
+	previous := output.Current
+	output.Current = bucket
+	bucketParams := params
+	bucketParams.OutputSpec = ""
+	confirmMsg, err := writeOutput(p, bucketParams)
+	output.Current = previous
+	if err != nil {
+		return "", err
+	}
+
+	defer timings.track("upload to "+bucket.Location, time.Now())
+	if err := bucket.Upload(); err != nil {
+		return "", classify(err, EXIT_IO_ERROR)
+	}
+	return fmt.Sprintf("%s, %d files uploaded to %s", confirmMsg, len(bucket.Names()), bucket.Location), nil
+}
+
+// splitFormats returns the formats of a comma separated -format
+func splitFormats(formatList string) []string {
+	var formats []string
+	for _, format := range strings.Split(formatList, ",") {
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// writeFormats generates several formats from one parse: -output is a directory, directory formats
+// are written into it (jsonschema into its schemas sub directory) and file formats are written to
+// their default file name in it
+func writeFormats(p *parser.Parser, params GeneratorParams, formats []string) (string, error) {
+	for _, format := range formats {
+		if _, ok := LookupGenerator(format); !ok {
+			return "", fmt.Errorf("Invalid -format %s specified. Must be one of %v, or a format with a %s<format> executable in $PATH.", format, AVAILABLE_FORMATS, FORMAT_PLUGIN_PREFIX)
+		}
+	}
+
+	var confirmMsgs []string
+	for _, format := range formats {
+		formatParams := params
+		formatParams.OutputFormat = format
+		formatParams.OutputSpec = formatOutputSpec(params.OutputSpec, format)
+		if dir := versionOutputDir(formatParams.OutputSpec, format); len(outputVariants(params)) == 0 && dir != "" {
+			if err := output.MkdirAll(dir, output.DirMode); err != nil {
+				return "", fmt.Errorf("Can not create %s output directory: %v\n", format, err)
+			}
+		}
+
+		confirmMsg, err := writeOutput(p, formatParams)
+		if err != nil {
+			return "", err
+		}
+		confirmMsgs = append(confirmMsgs, confirmMsg)
+	}
+	return strings.Join(confirmMsgs, ", "), nil
+}
+
+// formatOutputSpec is the -output of format, when several formats are written into the outputDir directory
+func formatOutputSpec(outputDir, format string) string {
+	switch {
+	case format == "jsonschema":
+		return path.Join(outputDir, "schemas")
+	case directoryFormats[format]:
+		return outputDir
+	}
+	return path.Join(outputDir, defaultOutputFile(format))
+}
+
+// defaultOutputFile is the default -output of a file format, API.<format> for plugins
+func defaultOutputFile(format string) string {
+	if fileName, ok := defaultOutputFiles[format]; ok {
+		return fileName
+	}
+	return "API." + format
+}
+
+// Formats written into the -output directory, other formats write the -output file
+var directoryFormats = map[string]bool{"go": true, "swagger": true, "jsonschema": true}
+
+// Default -output file of the file formats
+var defaultOutputFiles = map[string]string{
+	"asciidoc":   "API.adoc",
+	"markdown":   "API.md",
+	"confluence": "API.confluence",
+	"postman":    "API.postman_collection.json",
+	"apib":       "API.apib",
+	"raml":       "API.raml",
+	"typescript": "API.d.ts",
+	"goclient":   "client.go",
+	"goserver":   "server.go",
+	"graphql":    "API.graphql",
+	"html":       "API.html",
+}
+
+// versionOutputSpec inserts the version directory: out -> out/v1 for directory formats, out/API.md -> out/v1/API.md otherwise
+func versionOutputSpec(outputSpec, format, version string) string {
+	if directoryFormats[format] {
+		if format == "jsonschema" && outputSpec == "" {
+			outputSpec = "schemas"
+		}
+		return path.Join(outputSpec, version)
+	}
+	if outputSpec == "" {
+		outputSpec = defaultOutputFile(format)
+	}
+	return path.Join(path.Dir(outputSpec), version, path.Base(outputSpec))
+}
+
+func versionOutputDir(outputSpec, format string) string {
+	if directoryFormats[format] {
+		return outputSpec
+	}
+	return path.Dir(outputSpec)
+}
+
+// writeFormat generates the -format files of the parsed API into output.Current
+func writeFormat(parser *parser.Parser, params GeneratorParams) (string, error) {
+	generator, ok := LookupGenerator(params.OutputFormat)
+	if !ok {
+		return "", fmt.Errorf("Invalid -format specified. Must be one of %v, or a format with a %s<format> executable in $PATH.", AVAILABLE_FORMATS, FORMAT_PLUGIN_PREFIX)
+	}
+	defer timings.trackOutput(strings.ToLower(params.OutputFormat))()
+	return generator.Generate(parser, params)
+}
+
+// dryRun generates the output in memory and prints what would have been written
+func dryRun(parser *parser.Parser, params GeneratorParams) error {
+	memory := output.NewMemory()
+	defer func(previous output.FileSystem) {
+		output.Current = previous
+	}(output.Current)
+	output.Current = memory
+
+	filesLocation := ""
+	if output.IsBucket(params.OutputSpec) {
+		filesLocation, params.OutputSpec = " to "+params.OutputSpec, ""
+	}
+	if _, err := writeOutput(parser, params); err != nil {
+		return err
+	}
+
+	operations := 0
+	for _, api := range parser.TopLevelApis {
+		for _, subApi := range api.Apis {
+			operations += len(subApi.Operations)
+		}
+	}
+	fmt.Printf("%d operations, %d models\n", operations, len(parser.GetModels()))
+	fmt.Printf("Files that would be written%s:\n", filesLocation)
+	for _, name := range memory.Names() {
+		content, _ := memory.Content(name)
+		fmt.Printf("    %s (%d bytes)\n", name, len(content))
+	}
+	return nil
+}
+
+// breakingChanges prints the changes from the -oldSpec to newApis, it fails if any of them is breaking
+func breakingChanges(newApis map[string]*parser.ApiDeclaration, params GeneratorParams) error {
+	if params.OldSpec == "" {
+		return errors.New("The breaking command needs -oldSpec\n")
+	}
+	_, oldApis, err := parser.ReadSpec(params.OldSpec)
+	if err != nil {
+		return err
+	}
+
+	apiChanges := changes.Compare(oldApis, newApis)
+	for _, change := range apiChanges {
+		fmt.Println(change)
+	}
+	if changes.HasBreaking(apiChanges) {
+		return classify(errors.New("The API has breaking changes\n"), EXIT_VALIDATION_ERROR)
+	}
+	logger.Infof("No breaking changes, %d additive change(s)", len(apiChanges))
+	return nil
+}
+
+// migrateSpec writes the Swagger 1.2 spec of -oldSpec as a Swagger 2.0 swagger.json or an OpenAPI 3.0
+// openapi.json document, in the -output directory
+func migrateSpec(params GeneratorParams) error {
+	if params.OldSpec == "" {
+		return errors.New("The migrate command needs -oldSpec\n")
+	}
+	listing, apis, err := parser.ReadSpec(params.OldSpec)
+	if err != nil {
+		return err
+	}
+	migrated := parser.NewParser()
+	migrated.Listing, migrated.TopLevelApis = listing, apis
+	for _, ref := range listing.Apis {
+		if api := apis[strings.Trim(ref.Path, "/")]; api != nil && api.BasePath != "" {
+			migrated.BasePath = api.BasePath
+			break
+		}
+	}
+
+	var document interface{}
+	fileName := "openapi.json"
+	swagger2 := openapi.Convert(migrated, baseUrl(migrated))
+	switch params.MigrateTo {
+	case "2.0":
+		document, fileName = swagger2, "swagger.json"
+	case "3.0":
+		document = openapi.Upgrade(swagger2)
+	default:
+		return fmt.Errorf("Invalid -to specified. Must be one of %v.", AVAILABLE_MIGRATIONS)
+	}
+
+	name := params.OutputSpec
+	if name != output.Stdout {
+		if name != "" {
+			if err := output.MkdirAll(name, output.DirMode); err != nil {
+				return fmt.Errorf("Can not create %s directory: %v\n", name, err)
+			}
+		}
+		name = path.Join(name, fileName)
+	}
+	if err := writeJsonFile(name, document, params.Indent); err != nil {
+		return err
+	}
+	logger.Infof("Migrated %d resource(s) of %s to %s", len(apis), params.OldSpec, name)
+	return runHooks("post", params.PostHooks, params.PostHook, params)
+}
+
+// serveMock answers the documented operations with example payloads on -listen, until it is killed
+func serveMock(parser *parser.Parser, params GeneratorParams) error {
+	listen := params.Listen
+	if listen == "" {
+		listen = DEFAULT_MOCK_ADDRESS
+	}
+	logger.Infof("Mock server listening on %s", listen)
+	return http.ListenAndServe(listen, mock.NewServer(parser))
+}
+
+// mergeSpecs writes the combined spec of the -specs services in -format
+func mergeSpecs(params GeneratorParams) error {
+	services, err := merge.ReadServices(params.Specs)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		return errors.New("The merge command needs -specs\n")
+	}
+
+	merged := parser.NewParser()
+	merged.Listing, merged.TopLevelApis = merge.Merge(services)
+
+	confirmMsg, err := writeBucketOutput(merged, params)
+	if err != nil {
+		return err
+	}
+	logger.Infof("%s, merged %d services", confirmMsg, len(services))
+	if err := uploadSpec(merged, params); err != nil {
+		return err
+	}
+	return runHooks("post", params.PostHooks, params.PostHook, params)
+}
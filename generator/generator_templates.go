@@ -0,0 +1,674 @@
+package generator
+
+// specTemplate declares the Rootinfo and Subapi JSON of the docs.go templates, as string constants
+// or, with -embed, as variables embedding the JSON files written next to docs.go
+var specTemplate = `{{if .Embed}}//go:embed {{.ResourceListingFile}}
+var Rootinfo string
+
+//go:embed {{.ApiDescriptionsFile}}
+var Subapi string{{else}}const (
+    Rootinfo string = {{.ResourceListing}}
+    Subapi string = {{.ApiDescriptions}}
+){{end}}`
+
+// plainFileTemplate only embeds the JSON and provides accessors for it, so it can be used with any framework
+var plainFileTemplate = `
+package {{.Package}}
+
+import (
+	"encoding/json"{{if .Embed}}
+	_ "embed"{{end}}
+)
+
+{{template "spec" .}}
+
+var apilist map[string]json.RawMessage
+
+func init() {
+	err := json.Unmarshal([]byte(Subapi), &apilist)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ResourceListing returns the resource listing JSON
+func ResourceListing() []byte {
+	return []byte(Rootinfo)
+}
+
+// ApiDescription returns the API declaration JSON of the resource
+func ApiDescription(resource string) ([]byte, bool) {
+	apiDescription, ok := apilist[resource]
+	return apiDescription, ok
+}
+
+// Resources returns keys of all documented resources
+func Resources() []string {
+	resources := make([]string, 0, len(apilist))
+	for resource := range apilist {
+		resources = append(resources, resource)
+	}
+	return resources
+}
+`
+
+// beegoFileTemplate registers the docs in beego.GlobalDocApi and serves them under DocsRoute (/rawdoc by default)
+var beegoFileTemplate = `
+package {{.Package}}
+
+import (
+	"encoding/json"{{if .Embed}}
+	_ "embed"{{end}}
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/astaxie/beego/swagger"
+	"strings"
+)
+
+{{template "spec" .}}
+var BasePath string
+
+var rootapi swagger.ResourceListing
+var apilist map[string]*swagger.ApiDeclaration
+
+func init() {
+	version := beego.AppConfig.DefaultString("version", "2.0")
+	BasePath = "/" + version
+	if beego.EnableDocs {
+		err := json.Unmarshal([]byte(Rootinfo), &rootapi)
+		if err != nil {
+			beego.Error(err)
+		}
+		err = json.Unmarshal([]byte(Subapi), &apilist)
+		if err != nil {
+			beego.Error(err)
+		}
+		beego.GlobalDocApi["Root"] = rootapi
+		beego.Trace("Load Docs: version", rootapi.ApiVersion)
+		for k, v := range apilist {
+			for i, a := range v.Apis {
+				a.Path = urlReplace(a.Path)
+				v.Apis[i] = a
+			}
+			v.BasePath = BasePath
+			beego.GlobalDocApi[strings.Trim(k, "/")] = v
+		}
+	}
+}
+
+// DocsRoute is where SetupRouter serves the docs. If UIRoute and UIDir are set,
+// the Swagger UI files of UIDir are served under UIRoute too.
+var (
+	DocsRoute = {{quote .DocsRoute}}
+	UIRoute   = {{quote .UIRoute}}
+	UIDir     string
+)
+
+// SetupRouter ...
+func SetupRouter(ns *beego.Namespace) {
+	if UIRoute != "" && UIDir != "" {
+		beego.SetStaticPath(UIRoute, UIDir)
+	}
+	docns := beego.NewNamespace(DocsRoute)
+	docns.Get("/", func(ctx *context.Context) {
+		ctx.Output.Json(rootapi, false, false)
+	})
+	for k, v := range apilist {
+		vv := v
+		docns.Get("/"+strings.Trim(k, "/"), func(ctx *context.Context) {
+			ctx.Output.Json(vv, false, false)
+		})
+	}
+	ns.Namespace(docns)
+}
+
+
+func urlReplace(src string) string {
+	pt := strings.Split(src, "/")
+	for i, p := range pt {
+		if len(p) > 0 {
+			if p[0] == ':' {
+				pt[i] = "{" + p[1:] + "}"
+			} else if p[0] == '?' && p[1] == ':' {
+				pt[i] = "{" + p[2:] + "}"
+			} else if p == "*" {
+				pt[i] = "{splat}"
+			} else if p == "*.*" {
+				pt[i] = "{path}.{ext}"
+			}
+		}
+	}
+	return strings.Join(pt, "/")
+}
+
+`
+
+// netHttpFileTemplate depends on the standard library only and serves the docs with http.Handlers
+var netHttpFileTemplate = `
+package {{.Package}}
+
+import (
+	"encoding/json"{{if .EmbedUI}}
+	"embed"
+	"io/fs"
+	"strconv"{{else if .Embed}}
+	_ "embed"{{end}}
+	"net/http"
+	"strings"
+)
+
+{{template "spec" .}}
+
+// BasePath is the URL test requests are sent to. It must be set before the handlers serve any request.
+var BasePath = "http://127.0.0.1:8080"
+
+var apilist map[string]json.RawMessage
+
+func init() {
+	err := json.Unmarshal([]byte(Subapi), &apilist)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Handler serves the resource listing at "/" and the API declarations at "/<resource>"
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		apiKey := strings.Trim(r.URL.Path, "/")
+		if apiKey == "" {
+			w.Write([]byte(Rootinfo))
+			return
+		}
+		if apiDescription, ok := apilist[apiKey]; ok {
+			w.Write([]byte(strings.Replace(string(apiDescription), "{{"{{.}}"}}", BasePath, -1)))
+			return
+		}
+		http.NotFound(w, r)
+	})
+}
+
+{{- if .EmbedUI}}
+//go:embed {{.UIAssetsDir}}
+var uiFiles embed.FS
+
+// UIHandler serves the Swagger UI files from uiDir or, if uiDir is empty, the embedded Swagger UI,
+// which loads the docs from DocsRoute
+func UIHandler(uiDir string) http.Handler {
+	if uiDir != "" {
+		return http.FileServer(http.Dir(uiDir))
+	}
+	files, err := fs.Sub(uiFiles, {{quote .UIAssetsDir}})
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.FileServer(http.FS(files))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if page := strings.Trim(r.URL.Path, "/"); page != "" && page != "index.html" {
+			fileServer.ServeHTTP(w, r)
+			return
+		}
+		index, err := fs.ReadFile(files, "index.html")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		docsUrl := "window.location.host + " + strconv.Quote(strings.TrimSuffix(DocsRoute, "/"))
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(strings.Replace(string(index), "window.location.host", docsUrl, 1)))
+	})
+}
+{{- else}}
+// UIHandler serves the Swagger UI files from uiDir
+func UIHandler(uiDir string) http.Handler {
+	return http.FileServer(http.Dir(uiDir))
+}
+{{- end}}
+
+// DocsRoute is where SetupRouter registers the docs, UIRoute where it registers the Swagger UI, if not empty
+var (
+	DocsRoute = {{quote .DocsRoute}}
+	UIRoute   = {{quote .UIRoute}}
+)
+
+// SetupRouter registers the docs under DocsRoute and, if {{if not .EmbedUI}}uiDir and {{end}}UIRoute {{if .EmbedUI}}is{{else}}are{{end}} not empty, the Swagger UI under UIRoute{{if .EmbedUI}}:
+// the files of uiDir, or the embedded Swagger UI if uiDir is empty{{end}}
+func SetupRouter(mux *http.ServeMux, uiDir string) {
+	docsRoute := strings.TrimSuffix(DocsRoute, "/")
+	mux.Handle(docsRoute+"/", http.StripPrefix(docsRoute, Handler()))
+	if {{if not .EmbedUI}}uiDir != "" && {{end}}UIRoute != "" {
+		uiRoute := strings.TrimSuffix(UIRoute, "/") + "/"
+		mux.Handle(uiRoute, http.StripPrefix(uiRoute, UIHandler(uiDir)))
+	}
+}
+`
+
+// generatedFileTemplates contains the docs.go templates per -goFramework value
+var generatedFileTemplates = map[string]string{
+	"plain":   plainFileTemplate,
+	"beego":   beegoFileTemplate,
+	"nethttp": netHttpFileTemplate,
+}
+
+// validationFileTemplate is the -validation middleware, written next to docs.go. It depends on the
+// standard library and on the Subapi JSON of docs.go only, so it works with every -goFramework.
+var validationFileTemplate = `{{if .ValidationTag}}//go:build {{.ValidationTag}}
+
+{{end}}package {{.Package}}
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Violation is a difference between a request, or a response, and the docs
+type Violation struct {
+	Method  string
+	Path    string
+	Status  int // status of the response, 0 for the violations of the request
+	Message string
+}
+
+func (v Violation) Error() string {
+	if v.Status == 0 {
+		return v.Method + " " + v.Path + ": request: " + v.Message
+	}
+	return v.Method + " " + v.Path + ": response " + strconv.Itoa(v.Status) + ": " + v.Message
+}
+
+// OnViolation is called by Validate with every violation. It logs them by default,
+// integration tests can fail instead.
+var OnViolation = func(r *http.Request, violation Violation) {
+	log.Printf("API docs violation: %v", violation)
+}
+
+// ValidationPrefix is removed from the request paths before they are matched with the documented paths, e.g. /api
+var ValidationPrefix = ""
+
+type validationItems map[string]string
+
+func (items validationItems) typeName() string {
+	if ref := items["$ref"]; ref != "" {
+		return ref
+	}
+	return items["type"]
+}
+
+type validationModel struct {
+	Required   []string
+	Properties map[string]struct {
+		Type  string
+		Items validationItems
+		Enum  []string
+	}
+}
+
+type validationOperation struct {
+	HttpMethod string
+	Type       string
+	Items      validationItems
+	Parameters []struct {
+		ParamType string
+		Name      string
+		Type      string
+		Required  bool
+	}
+	ResponseMessages []struct {
+		Code          int
+		ResponseModel string
+	}
+}
+
+type validationRoute struct {
+	segments  []string
+	operation validationOperation
+	models    map[string]validationModel
+}
+
+var (
+	validationRoutes     []*validationRoute
+	validationRoutesOnce sync.Once
+)
+
+// loadValidationRoutes reads the documented operations from Subapi
+func loadValidationRoutes() {
+	var apis map[string]struct {
+		Apis []struct {
+			Path       string
+			Operations []validationOperation
+		}
+		Models map[string]validationModel
+	}
+	if err := json.Unmarshal([]byte(Subapi), &apis); err != nil {
+		panic(err)
+	}
+	for _, api := range apis {
+		for _, subApi := range api.Apis {
+			for _, operation := range subApi.Operations {
+				validationRoutes = append(validationRoutes, &validationRoute{
+					segments:  splitValidationPath(subApi.Path),
+					operation: operation,
+					models:    api.Models,
+				})
+			}
+		}
+	}
+}
+
+func splitValidationPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func isPathVariable(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// matchRoute returns the route of the operation of r and the values of its path variables.
+// Static segments win over variables: /users/me before /users/{id}.
+func matchRoute(r *http.Request) (*validationRoute, map[string]string) {
+	segments := splitValidationPath(strings.TrimPrefix(r.URL.Path, ValidationPrefix))
+	var matched *validationRoute
+	var matchedVariables map[string]string
+	for _, route := range validationRoutes {
+		if len(route.segments) != len(segments) || !strings.EqualFold(route.operation.HttpMethod, r.Method) {
+			continue
+		}
+		variables := make(map[string]string)
+		for i, segment := range route.segments {
+			if isPathVariable(segment) {
+				variables[segment[1:len(segment)-1]] = segments[i]
+			} else if segment != segments[i] {
+				variables = nil
+				break
+			}
+		}
+		if variables != nil && (matched == nil || len(variables) < len(matchedVariables)) {
+			matched, matchedVariables = route, variables
+		}
+	}
+	return matched, matchedVariables
+}
+
+// Validate checks the requests served by next and their responses against the docs, and reports the
+// differences to OnViolation: undocumented operations and status codes, missing or malformed parameters,
+// and JSON bodies which do not match their model. Requests and responses are passed through unchanged.
+func Validate(next http.Handler) http.Handler {
+	validationRoutesOnce.Do(loadValidationRoutes)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := func(status int, message string) {
+			OnViolation(r, Violation{Method: r.Method, Path: r.URL.Path, Status: status, Message: message})
+		}
+		route, variables := matchRoute(r)
+		if route == nil {
+			report(0, "undocumented operation")
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, problem := range route.validateRequest(r, variables) {
+			report(0, problem)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		for _, problem := range route.validateResponse(recorder) {
+			report(recorder.status, problem)
+		}
+	})
+}
+
+// responseRecorder keeps a copy of the response written to ResponseWriter
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (recorder *responseRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *responseRecorder) Write(data []byte) (int, error) {
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (route *validationRoute) validateRequest(r *http.Request, variables map[string]string) []string {
+	var problems []string
+	var form url.Values
+	for _, parameter := range route.operation.Parameters {
+		var value string
+		var isSet bool
+		switch parameter.ParamType {
+		case "path":
+			value, isSet = variables[parameter.Name]
+		case "query":
+			values, ok := r.URL.Query()[parameter.Name]
+			if isSet = ok && len(values) > 0; isSet {
+				value = values[0]
+			}
+		case "header":
+			value = r.Header.Get(parameter.Name)
+			isSet = value != ""
+		case "form":
+			if form == nil {
+				form = readForm(r)
+			}
+			values, ok := form[parameter.Name]
+			if isSet = ok && len(values) > 0; isSet {
+				value = values[0]
+			}
+		case "body":
+			body := readBody(r)
+			if len(bytes.TrimSpace(body)) == 0 {
+				if parameter.Required {
+					problems = append(problems, "missing body")
+				}
+				continue
+			}
+			if !isJson(r.Header.Get("Content-Type")) {
+				continue
+			}
+			problems = append(problems, route.validateJson(parameter.Type, body, "body")...)
+			continue
+		}
+
+		if !isSet {
+			if parameter.Required {
+				problems = append(problems, fmt.Sprintf("missing %s parameter %s", parameter.ParamType, parameter.Name))
+			}
+			continue
+		}
+		if problem := validateParameter(parameter.Type, value); problem != "" {
+			problems = append(problems, fmt.Sprintf("%s parameter %s: %s", parameter.ParamType, parameter.Name, problem))
+		}
+	}
+	return problems
+}
+
+// readBody reads the body of r and replaces it with a copy, for the handler
+func readBody(r *http.Request) []byte {
+	if r.Body == nil {
+		return nil
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body
+}
+
+// readForm returns the URL encoded form of r, without consuming its body
+func readForm(r *http.Request) url.Values {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return url.Values{}
+	}
+	form, _ := url.ParseQuery(string(readBody(r)))
+	return form
+}
+
+func isJson(contentType string) bool {
+	return contentType == "" || strings.Contains(contentType, "json")
+}
+
+// validateParameter checks the value of a path, query, header or form parameter
+func validateParameter(typeName string, value string) string {
+	var err error
+	switch typeName {
+	case "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return strconv.Quote(value) + " is not a valid " + typeName
+	}
+	return ""
+}
+
+func (route *validationRoute) validateResponse(recorder *responseRecorder) []string {
+	typeName, ok := route.responseType(recorder.status)
+	if !ok {
+		return []string{"undocumented status code"}
+	}
+	body := bytes.TrimSpace(recorder.body.Bytes())
+	if typeName == "" || typeName == "void" || len(body) == 0 || !isJson(recorder.Header().Get("Content-Type")) {
+		return nil
+	}
+	return route.validateJson(typeName, body, "body")
+}
+
+// responseType returns the type of the response with status, false if it is not documented.
+// Without any documented success response, 200 is the response of the type of the operation.
+func (route *validationRoute) responseType(status int) (string, bool) {
+	hasSuccess := false
+	for _, response := range route.operation.ResponseMessages {
+		if response.Code == status {
+			return response.ResponseModel, true
+		}
+		hasSuccess = hasSuccess || response.Code >= 200 && response.Code < 300
+	}
+	if hasSuccess || status != http.StatusOK {
+		return "", false
+	}
+	if route.operation.Type == "array" {
+		return "array[" + route.operation.Items.typeName() + "]", true
+	}
+	return route.operation.Type, true
+}
+
+func (route *validationRoute) validateJson(typeName string, body []byte, at string) []string {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []string{at + ": invalid JSON: " + err.Error()}
+	}
+	return route.validateValue(typeName, value, at)
+}
+
+// validateValue checks a decoded JSON value against a type of the docs. Types without a model,
+// like time.Time or interface{}, and null values are not checked.
+func (route *validationRoute) validateValue(typeName string, value interface{}, at string) []string {
+	if value == nil || typeName == "" {
+		return nil
+	}
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{at + ": expected an array"}
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, route.validateValue(typeName[len("array["):len(typeName)-1], item, at+"["+strconv.Itoa(i)+"]")...)
+		}
+		return problems
+	}
+
+	switch typeName {
+	case "integer":
+		if number, ok := value.(json.Number); !ok || strings.ContainsAny(number.String(), ".eE") {
+			return []string{at + ": expected an integer"}
+		}
+		return nil
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return []string{at + ": expected a number"}
+		}
+		return nil
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []string{at + ": expected a string"}
+		}
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{at + ": expected a boolean"}
+		}
+		return nil
+	}
+
+	model, ok := route.models[typeName]
+	if !ok {
+		return nil
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return []string{at + ": expected an object " + typeName}
+	}
+	var problems []string
+	for _, name := range model.Required {
+		if _, ok := object[name]; !ok {
+			problems = append(problems, at+": missing property "+name)
+		}
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyValue := object[name]
+		property, ok := model.Properties[name]
+		if !ok {
+			problems = append(problems, at+": undocumented property "+name)
+			continue
+		}
+		propertyType := property.Type
+		if propertyType == "array" {
+			propertyType = "array[" + property.Items.typeName() + "]"
+		}
+		problems = append(problems, route.validateValue(propertyType, propertyValue, at+"."+name)...)
+		if text, ok := propertyValue.(string); ok && len(property.Enum) > 0 && !containsString(property.Enum, text) {
+			problems = append(problems, at+"."+name+": "+strconv.Quote(text)+" is not one of "+strings.Join(property.Enum, ", "))
+		}
+	}
+	return problems
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+`
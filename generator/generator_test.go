@@ -0,0 +1,136 @@
+package generator_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yvasiyarov/swagger/confluence"
+	"github.com/yvasiyarov/swagger/generator"
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+const (
+	examplePackage = "github.com/yvasiyarov/swagger/example"
+	exampleApiFile = examplePackage + "/web/main.go"
+)
+
+// titleGenerator writes the title of the API, a format added by a program calling Generate
+type titleGenerator struct{}
+
+func (titleGenerator) Name() string {
+	return "title"
+}
+
+func (titleGenerator) Generate(p *parser.Parser, params generator.GeneratorParams) (string, error) {
+	fd, err := output.Create(params.OutputSpec)
+	if err != nil {
+		return "", err
+	}
+	defer fd.Close()
+	_, err = fd.WriteString(p.Listing.Infos.Title)
+	return "Title written", err
+}
+
+func TestGenerateWithHooksAndRegisteredGenerator(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "swagger_generate")
+	if err != nil {
+		t.Fatalf("Can not create output directory: %v\n", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	generator.RegisterGenerator(titleGenerator{})
+	registered, ok := generator.LookupGenerator("Title")
+	assert.True(t, ok, "Registered generator is not found")
+	assert.Equal(t, "title", registered.Name(), "Wrong generator found")
+
+	var stages []string
+	params := generator.GeneratorParams{
+		ApiPackage:   examplePackage,
+		MainApiFile:  exampleApiFile,
+		OutputFormat: "title",
+		OutputSpec:   filepath.Join(outputDir, "title.txt"),
+		PreHooks: []generator.Hook{func(params generator.GeneratorParams) error {
+			_, err := os.Stat(params.OutputSpec)
+			assert.True(t, os.IsNotExist(err), "Output is written before the pre hook")
+			stages = append(stages, "pre")
+			return nil
+		}},
+		PostHooks: []generator.Hook{func(params generator.GeneratorParams) error {
+			title, err := ioutil.ReadFile(params.OutputSpec)
+			assert.Nil(t, err, "Output is not written before the post hook")
+			assert.NotEmpty(t, string(title), "Title of the example API is not written")
+			stages = append(stages, "post")
+			return nil
+		}},
+	}
+	assert.Nil(t, generator.Generate(params), "Generation failed")
+	assert.Equal(t, []string{"pre", "post"}, stages, "Hooks did not run in order")
+
+	stages = nil
+	failing := params
+	failing.OutputSpec = filepath.Join(outputDir, "failing.txt")
+	failing.PreHooks = []generator.Hook{func(params generator.GeneratorParams) error {
+		return errors.New("pre hook failed")
+	}}
+	assert.EqualError(t, generator.Generate(failing), "pre hook failed", "Error of the pre hook is not returned")
+	assert.Empty(t, stages, "Post hook runs after a failed pre hook")
+	_, err = os.Stat(failing.OutputSpec)
+	assert.True(t, os.IsNotExist(err), "Output is written after a failed pre hook")
+}
+
+func TestInvalidControllerClass(t *testing.T) {
+	_, err := generator.IsController("Controller(")
+	assert.NotNil(t, err, "Invalid -controllerClass regular expression accepted")
+
+	params := generator.GeneratorParams{
+		ApiPackage:      examplePackage,
+		MainApiFile:     exampleApiFile,
+		OutputFormat:    "swagger",
+		ControllerClass: "Controller(",
+	}
+	assert.NotNil(t, generator.Generate(params), "Generate must return the error of an invalid -controllerClass")
+}
+
+// TestGeneratedSpecIsValid generates the example, which has no @APIBasePath: the placeholder the
+// base path is replaced with must pass the validation of the swagger format
+func TestGeneratedSpecIsValid(t *testing.T) {
+	outputDir, err := ioutil.TempDir("", "swagger_generate")
+	if err != nil {
+		t.Fatalf("Can not create output directory: %v\n", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	params := generator.GeneratorParams{
+		ApiPackage:   examplePackage,
+		MainApiFile:  exampleApiFile,
+		OutputFormat: "swagger",
+		OutputSpec:   outputDir,
+	}
+	assert.Nil(t, generator.Generate(params), "Generated spec of the example is not valid")
+	_, err = os.Stat(filepath.Join(outputDir, "index.json"))
+	assert.Nil(t, err, "Resource listing is not written")
+}
+
+func TestDryRunPublishNeedsNoToken(t *testing.T) {
+	if token, ok := os.LookupEnv(confluence.TOKEN_ENV); ok {
+		os.Unsetenv(confluence.TOKEN_ENV)
+		defer os.Setenv(confluence.TOKEN_ENV, token)
+	}
+
+	params := generator.GeneratorParams{
+		ApiPackage:      examplePackage,
+		MainApiFile:     exampleApiFile,
+		OutputFormat:    "confluence",
+		Publish:         true,
+		DryRun:          true,
+		ConfluenceUrl:   "https://acme.atlassian.net/wiki",
+		ConfluenceSpace: "API",
+		ConfluenceTitle: "Example API",
+	}
+	assert.Nil(t, generator.Generate(params), "Dry run of -publish needs the Confluence token")
+}
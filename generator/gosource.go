@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+)
+
+// formatGoSource gofmts generated Go code, with unused imports removed and imports sorted, like goimports
+// does. An error is returned if the code does not parse, e.g. because of a broken -goTemplate, or if it
+// does not type-check with the other files of its package: the .go files already in dir, if dir is not
+// empty, and packageSources, the generated files of the package by file name.
+func formatGoSource(dir string, fileName string, source []byte, packageSources map[string][]byte) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	file, err := goparser.ParseFile(fileSet, fileName, source, goparser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("Generated %s is not valid Go code: %v\n", fileName, err)
+	}
+	removeUnusedImports(file, dir)
+	ast.SortImports(fileSet, file)
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fileSet, file); err != nil {
+		return nil, fmt.Errorf("Can not format generated %s: %v\n", fileName, err)
+	}
+	// removed imports leave empty lines, formatting again removes them
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("Can not format generated %s: %v\n", fileName, err)
+	}
+	if err := checkGoSource(dir, fileName, formatted, packageSources); err != nil {
+		return nil, err
+	}
+	return formatted, nil
+}
+
+// goSourceDir is the directory of the other files of the package of the generated fileName
+func goSourceDir(fileName string) string {
+	if fileName == output.Stdout {
+		return ""
+	}
+	return path.Dir(fileName)
+}
+
+// checkGoSource type-checks the generated fileName with the other files of its package. The errors
+// of the packages which can not be imported, like the framework of a -goTemplate which is not
+// installed here, are ignored: only the generated code itself is checked.
+func checkGoSource(dir string, fileName string, source []byte, packageSources map[string][]byte) error {
+	fileSet := token.NewFileSet()
+	file, err := goparser.ParseFile(fileSet, fileName, source, 0)
+	if err != nil {
+		return fmt.Errorf("Generated %s is not valid Go code: %v\n", fileName, err)
+	}
+	files := append([]*ast.File{file}, packageFiles(fileSet, dir, file.Name.Name, fileName, packageSources)...)
+
+	// the name of a package which is not imported is unknown, its uses like chi.NewRouter() are undefined
+	qualifiers := qualifierNames(file)
+	var importErrors bool
+	var typeErrors []types.Error
+	config := types.Config{
+		Importer: importer.Default(),
+		Error: func(err error) {
+			if typeError, ok := err.(types.Error); ok && strings.Contains(typeError.Msg, "could not import") {
+				importErrors = true
+			} else if ok {
+				typeErrors = append(typeErrors, typeError)
+			}
+		},
+	}
+	config.Check(file.Name.Name, fileSet, files, nil)
+	var messages []string
+	for _, typeError := range typeErrors {
+		if !importErrors || !qualifiers[strings.TrimPrefix(typeError.Msg, "undefined: ")] {
+			messages = append(messages, typeError.Error())
+		}
+	}
+	if len(messages) > 0 {
+		return fmt.Errorf("Generated %s does not compile:\n%s\n", fileName, strings.Join(messages, "\n"))
+	}
+	return nil
+}
+
+// packageFiles parses the files of packageName other than fileName: packageSources, then the .go files
+// of dir which are not tests and have no generated replacement. Files which do not parse are skipped.
+func packageFiles(fileSet *token.FileSet, dir string, packageName string, fileName string, packageSources map[string][]byte) []*ast.File {
+	sources := make(map[string][]byte)
+	if dir != "" {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+		for _, match := range matches {
+			if strings.HasSuffix(match, "_test.go") {
+				continue
+			}
+			if source, err := ioutil.ReadFile(match); err == nil {
+				sources[filepath.Base(match)] = source
+			}
+		}
+	}
+	for name, source := range packageSources {
+		sources[name] = source
+	}
+	delete(sources, path.Base(fileName))
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var files []*ast.File
+	for _, name := range names {
+		file, err := goparser.ParseFile(fileSet, name, sources[name], 0)
+		if err == nil && file.Name.Name == packageName {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// removeUnusedImports removes the imports whose package name is not used in the file. Blank and dot
+// imports are kept, and so are the imports whose package can not be found from dir to know its name.
+func removeUnusedImports(file *ast.File, dir string) {
+	usedNames := qualifierNames(file)
+
+	isUsed := func(importSpec *ast.ImportSpec) bool {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			return true
+		}
+		if importSpec.Name != nil {
+			name := importSpec.Name.Name
+			return name == "_" || name == "." || usedNames[name]
+		}
+		if usedNames[path.Base(importPath)] {
+			return true
+		}
+		// the name of github.com/go-chi/chi/v5 or gopkg.in/yaml.v3 is not the last element of its path
+		name, ok := importedPackageName(importPath, dir)
+		return !ok || usedNames[name]
+	}
+
+	var imports []*ast.ImportSpec
+	for _, importSpec := range file.Imports {
+		if isUsed(importSpec) {
+			imports = append(imports, importSpec)
+		}
+	}
+	file.Imports = imports
+
+	var decls []ast.Decl
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+		var specs []ast.Spec
+		for _, spec := range genDecl.Specs {
+			if isUsed(spec.(*ast.ImportSpec)) {
+				specs = append(specs, spec)
+			}
+		}
+		if len(specs) > 0 {
+			genDecl.Specs = specs
+			decls = append(decls, genDecl)
+		}
+	}
+	file.Decls = decls
+}
+
+// importedPackageName returns the name declared by the package of importPath, imported from dir
+func importedPackageName(importPath string, dir string) (string, bool) {
+	if dir == "" {
+		dir = "."
+	}
+	pkg, err := build.Import(importPath, dir, 0)
+	if err != nil || pkg.Name == "" {
+		return "", false
+	}
+	return pkg.Name, true
+}
+
+// qualifierNames returns the names qualifying selectors, like strings of strings.Split, which are not
+// declared in the file: the names of the imported packages which are used
+func qualifierNames(file *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+				names[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return names
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatGoSourceKeepsImportsNamedOtherThanTheirPath(t *testing.T) {
+	source := `package client
+
+import (
+	"strings"
+	"example.com/go-chi/chi/v5"
+	"fmt"
+)
+
+func Router() interface{} {
+	fmt.Println("router")
+	return chi.NewRouter()
+}
+`
+	formatted, err := formatGoSource("", "client.go", []byte(source), nil)
+	assert.Nil(t, err, "Can not format Go source")
+	assert.Contains(t, string(formatted), `"example.com/go-chi/chi/v5"`, "Import of a /v5 package is removed")
+	assert.Contains(t, string(formatted), `"fmt"`, "Used import is removed")
+	assert.False(t, strings.Contains(string(formatted), `"strings"`), "Unused import is kept")
+}
+
+func TestFormatGoSourceTypeChecks(t *testing.T) {
+	_, err := formatGoSource("", "client.go", []byte("package client\n\nvar count int = \"one\"\n"), nil)
+	assert.NotNil(t, err, "Code which does not compile is accepted")
+
+	validation := []byte("package docs\n\nvar size = len(Subapi)\n")
+	_, err = formatGoSource("", "validation.go", validation, nil)
+	assert.NotNil(t, err, "Code using an undefined name is accepted")
+	_, err = formatGoSource("", "validation.go", validation, map[string][]byte{"docs.go": []byte("package docs\n\nconst Subapi = `{}`\n")})
+	assert.Nil(t, err, "Files of the package are not type-checked together")
+}
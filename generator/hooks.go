@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/output"
+)
+
+// Hook runs before parsing or after the output is written, e.g. to format the generated files,
+// copy them into a docs site or notify a webhook. An error stops the generation.
+type Hook func(params GeneratorParams) error
+
+// commandHook is a Hook running a -preHook or -postHook shell command. The command gets the
+// parameters in the SWAGGER_API_PACKAGE, SWAGGER_FORMAT and SWAGGER_OUTPUT environment variables.
+func commandHook(command string) Hook {
+	return func(params GeneratorParams) error {
+		cmd := exec.Command("sh", "-c", command)
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		}
+		cmd.Env = append(os.Environ(),
+			"SWAGGER_API_PACKAGE="+params.ApiPackage,
+			"SWAGGER_FORMAT="+params.OutputFormat,
+			"SWAGGER_OUTPUT="+params.OutputSpec,
+		)
+		cmd.Stdout = os.Stdout
+		if params.OutputSpec == output.Stdout {
+			// the standard output is the generated file
+			cmd.Stdout = os.Stderr
+		}
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("Hook \"%s\" failed: %v\n", command, err)
+		}
+		return nil
+	}
+}
+
+// writesOutput tells if params write the output. -dry-run and the diff command preview it, -lint,
+// -coverage and the breaking command report on the standard output and the mock command serves it.
+func writesOutput(params GeneratorParams) bool {
+	if params.DryRun || params.Lint || params.Coverage {
+		return false
+	}
+	switch params.Command {
+	case "diff", "breaking", "mock":
+		return false
+	}
+	return true
+}
+
+// runHooks runs the Go callbacks, then the shell command if any. Both the pre and the post hooks
+// are skipped when the output is not written, so they always run in pairs.
+func runHooks(stage string, callbacks []Hook, command string, params GeneratorParams) error {
+	if !writesOutput(params) {
+		logger.Debugf("Skipping %s hooks, the output is not written", stage)
+		return nil
+	}
+	hooks := callbacks
+	if command != "" {
+		hooks = append(hooks[:len(hooks):len(hooks)], commandHook(command))
+	}
+	for _, hook := range hooks {
+		logger.Debugf("Running %s hook", stage)
+		if err := hook(params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
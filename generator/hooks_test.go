@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooksAreSkippedWhenNothingIsWritten(t *testing.T) {
+	runs := 0
+	hooks := []Hook{func(params GeneratorParams) error {
+		runs++
+		return nil
+	}}
+
+	for _, params := range []GeneratorParams{
+		{DryRun: true},
+		{Lint: true},
+		{Coverage: true},
+		{Command: "diff"},
+		{Command: "breaking"},
+		{Command: "mock"},
+	} {
+		assert.Nil(t, runHooks("pre", hooks, "false", params), "Skipped pre hook failed with %+v", params)
+		assert.Nil(t, runHooks("post", hooks, "false", params), "Skipped post hook failed with %+v", params)
+	}
+	assert.Equal(t, 0, runs, "Hook runs when nothing is written")
+
+	assert.Nil(t, runHooks("pre", hooks, "", GeneratorParams{}), "Hook failed")
+	assert.Equal(t, 1, runs, "Hook does not run")
+}
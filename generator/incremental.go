@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// parseApi parses the API package, incrementally with -incremental: only the packages which changed
+// since the previous run are parsed again
+func parseApi(p *parser.Parser, apiFile string, params GeneratorParams) error {
+	if params.Incremental == "" {
+		return p.ParseApi(params.ApiPackage)
+	}
+	key, err := incrementalKey(apiFile, params)
+	if err != nil {
+		return err
+	}
+	return p.ParseApiIncremental(params.ApiPackage, params.Incremental, key)
+}
+
+// incrementalKey returns the key of the -incremental cache: the hash of what the specs of all the
+// packages depend on, the main API file, the options of the parser and the generator itself
+func incrementalKey(apiFile string, params GeneratorParams) (string, error) {
+	content, err := ioutil.ReadFile(apiFile)
+	if err != nil {
+		return "", fmt.Errorf("Can not read main API file: %v\n", err)
+	}
+	options, _ := json.Marshal([]interface{}{
+		params.ApiPackage, params.ControllerClass, params.ModelNaming, params.MarshalTypes, params.Sort,
+		params.BuildTags, params.Dialect, params.BasePath, params.Host, params.Schemes,
+		params.PointerOptional, params.IncludeInternal, params.IncludeTests, params.Strict,
+	})
+	hash := sha256.New()
+	hash.Write(content)
+	hash.Write(options)
+	// a new build of the generator may parse differently
+	if executable, err := os.Executable(); err == nil {
+		if info, err := os.Stat(executable); err == nil {
+			fmt.Fprintf(hash, "%s %d %d", executable, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
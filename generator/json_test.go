@@ -0,0 +1,37 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+func TestApiDescriptionsQuoteKeysAndBackticks(t *testing.T) {
+	apiKey := `users"` + "`" + `\admin`
+	p := parser.NewParser()
+	p.TopLevelApis[apiKey] = &parser.ApiDeclaration{
+		ResourcePath: "/users",
+		Apis:         []*parser.Api{{Path: "/users", Description: "Set `name`"}},
+	}
+
+	var descriptions bytes.Buffer
+	if !assert.Nil(t, writeApiDescriptions(&descriptions, p, 0), "Can not write the API descriptions") {
+		return
+	}
+	var decoded map[string]*parser.ApiDeclaration
+	if assert.Nil(t, json.Unmarshal(descriptions.Bytes(), &decoded), "API descriptions are not JSON: %s", descriptions.String()) {
+		assert.Contains(t, decoded, apiKey, "API key is not kept")
+	}
+
+	literal := rawStringLiteral(descriptions.String())
+	unquoted, err := strconv.Unquote(literal)
+	if assert.Nil(t, err, "Not a Go string literal: %s", literal) {
+		decoded = nil
+		assert.Nil(t, json.Unmarshal([]byte(unquoted), &decoded), "Go string literal is not JSON: %s", unquoted)
+		assert.Equal(t, "Set `name`", decoded[apiKey].Apis[0].Description, "Description is not kept")
+	}
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// IsRelativePath tells if a -apiPackage or -mainApiFile is a path relative to the current directory
+// (., ./handlers, ../api/main.go, .\handlers on Windows) or an absolute path, rather than an import path.
+// Import paths have no backslash: .\handlers is a relative path on every OS.
+func IsRelativePath(name string) bool {
+	slashed := strings.Replace(name, `\`, "/", -1)
+	return slashed == "." || slashed == ".." || strings.HasPrefix(slashed, "./") || strings.HasPrefix(slashed, "../") || filepath.IsAbs(name)
+}
+
+// FindMainApiFile returns the path of the -mainApiFile name, "" if it does not exist. A file of a package
+// is looked for in the directory the go command lists its package in, which needs no $GOPATH with
+// modules, and in the GOPATH and GOROOT; other names are file paths.
+func FindMainApiFile(p *parser.Parser, name string) string {
+	if !IsRelativePath(name) {
+		if dir := p.CheckRealPackagePath(path.Dir(name)); dir != "" {
+			if fileName := filepath.Join(dir, path.Base(name)); isFile(fileName) {
+				return fileName
+			}
+		}
+	}
+	if isFile(name) {
+		if absName, err := filepath.Abs(name); err == nil {
+			return absName
+		}
+	}
+	return ""
+}
+
+// isFile tells if name is an existing file, not a directory
+func isFile(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
+
+// isAnnotationErrors tells if err is the errors of annotations, which do not stop the parsing
+func isAnnotationErrors(err error) bool {
+	_, ok := err.(parser.ErrorList)
+	return ok
+}
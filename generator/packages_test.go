@@ -0,0 +1,20 @@
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRelativePath(t *testing.T) {
+	for _, name := range []string{".", "..", "./handlers", "../api/main.go", `.\handlers`, `..\api\main.go`, filepath.Join(string(filepath.Separator)+"src", "api")} {
+		assert.True(t, IsRelativePath(name), "%s is not a path", name)
+	}
+	for _, name := range []string{"github.com/acme/api", "github.com/acme/api/main.go", "handlers", ".well-known"} {
+		assert.False(t, IsRelativePath(name), "%s is not an import path", name)
+	}
+	if filepath.Separator == '\\' {
+		assert.True(t, IsRelativePath(`C:\src\api`), "Windows absolute path is not a path")
+	}
+}
@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yvasiyarov/swagger/confluence"
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/markup"
+	"github.com/yvasiyarov/swagger/openapi"
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+	"github.com/yvasiyarov/swagger/upload"
+)
+
+// AVAILABLE_CONFLUENCE_MARKUPS are the values of -confluenceMarkup
+const AVAILABLE_CONFLUENCE_MARKUPS = "wiki|storage"
+
+// generateConfluence writes the confluence format, in wiki markup or in the storage format (-confluenceMarkup),
+// or, with -publish, creates or updates its page in the -confluenceSpace space
+func generateConfluence(parser *parser.Parser, params GeneratorParams) error {
+	// the wiki markup is one page
+	params.SplitMarkup = false
+	var m markup.Markup
+	var fileExtension string
+	switch strings.ToLower(params.ConfluenceMarkup) {
+	case "", "wiki":
+		m, fileExtension = new(markup.MarkupConfluence), ".confluence"
+	case "storage":
+		m, fileExtension = new(markup.MarkupConfluenceStorage), ".xhtml"
+	default:
+		return fmt.Errorf("Invalid -confluenceMarkup specified. Must be one of %v.", AVAILABLE_CONFLUENCE_MARKUPS)
+	}
+	if !params.Publish {
+		return generateMarkup(parser, m, params, fileExtension)
+	}
+	memory := output.NewMemory()
+	defer func(previous output.FileSystem) {
+		output.Current = previous
+	}(output.Current)
+	output.Current = memory
+
+	pageParams := params
+	pageParams.OutputSpec = "API" + fileExtension
+	if err := generateMarkup(parser, m, pageParams, fileExtension); err != nil {
+		return err
+	}
+	page, _ := memory.Content(pageParams.OutputSpec)
+
+	title := params.ConfluenceTitle
+	if title == "" {
+		title = parser.Listing.Infos.Title
+	}
+	if title == "" {
+		return fmt.Errorf("Publishing to Confluence needs a page title: -confluenceTitle or @Title\n")
+	}
+	if params.DryRun {
+		// without credentials, the dry run previews the page only
+		logger.Infof("Would publish %s to the %s Confluence space", title, params.ConfluenceSpace)
+		return nil
+	}
+	space, err := confluence.NewSpace(params.ConfluenceUrl, params.ConfluenceSpace, params.ConfluenceParent)
+	if err != nil {
+		return err
+	}
+	var pageUrl string
+	if _, isStorage := m.(*markup.MarkupConfluenceStorage); isStorage {
+		pageUrl, err = space.Publish(title, string(page))
+	} else {
+		pageUrl, err = space.PublishWiki(title, string(page))
+	}
+	if err != nil {
+		return err
+	}
+	logger.Infof("Published %s", pageUrl)
+	return nil
+}
+
+// uploadSpec sends the Swagger 2.0 spec of the API to -upload, if set
+func uploadSpec(p *parser.Parser, params GeneratorParams) error {
+	if params.Upload == "" {
+		return nil
+	}
+	defer timings.track("upload to "+params.Upload, time.Now())
+	target, err := upload.NewTarget(params.Upload, params.UploadHeader, p.Listing.ApiVersion)
+	if err != nil {
+		return err
+	}
+	spec, err := json.Marshal(openapi.Convert(p, baseUrl(p)))
+	if err != nil {
+		return fmt.Errorf("Can not serialise the spec to JSON: %v\n", err)
+	}
+	if err := target.Upload(spec); err != nil {
+		return err
+	}
+	logger.Infof("Spec uploaded to %s", params.Upload)
+	return nil
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// timings are the durations of the phases of the run reported with -timings, nil without it
+var timings *phaseTimings
+
+type phaseTiming struct {
+	name     string
+	duration time.Duration
+}
+
+type phaseTimings struct {
+	phases []phaseTiming
+	io     time.Duration // spent creating, writing and closing the output files
+}
+
+// track adds the phase name, started at start. It does nothing without -timings.
+func (t *phaseTimings) track(name string, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.phases = append(t.phases, phaseTiming{name, time.Since(start)})
+}
+
+// trackPackages adds the parsing of the packages by ParseApi, started at start, and the resolution of
+// the models it ends with
+func (t *phaseTimings) trackPackages(packageTimings []parser.PackageTiming, start time.Time) {
+	if t == nil {
+		return
+	}
+	for _, packageTiming := range packageTimings {
+		t.phases = append(t.phases, phaseTiming{"parse package " + packageTiming.Package, packageTiming.Duration})
+		start = start.Add(packageTiming.Duration)
+	}
+	t.track("resolve models", start)
+}
+
+// trackOutput returns the function adding the serialize and write phases of format when its output
+// is written: writing is the time spent in the file system since trackOutput was called, serializing
+// the rest of it
+func (t *phaseTimings) trackOutput(format string) func() {
+	if t == nil {
+		return func() {}
+	}
+	start, io := time.Now(), t.io
+	return func() {
+		written := t.io - io
+		t.phases = append(t.phases,
+			phaseTiming{"serialize " + format, time.Since(start) - written},
+			phaseTiming{"write " + format, written})
+	}
+}
+
+// report logs the phases with their share of the total duration of the run
+func (t *phaseTimings) report(total time.Duration) {
+	logger.Infof("Timings, %s in total:", milliseconds(total))
+	width := 0
+	for _, phase := range t.phases {
+		if len(phase.name) > width {
+			width = len(phase.name)
+		}
+	}
+	for _, phase := range t.phases {
+		share := 0
+		if total > 0 {
+			share = int(100 * phase.duration / total)
+		}
+		logger.Infof("    %-*s %12s %3d%%", width, phase.name, milliseconds(phase.duration), share)
+	}
+}
+
+// milliseconds formats d in milliseconds, which are readable for the fast phases and the slow ones
+func milliseconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
+
+// timedFileSystem is the file system the output is written to with -timings: the time spent in it is
+// the io of the timings
+type timedFileSystem struct {
+	output.FileSystem
+	timings *phaseTimings
+}
+
+func (fs timedFileSystem) Create(name string) (output.File, error) {
+	defer fs.timings.spend(time.Now())
+	file, err := fs.FileSystem.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return timedFile{file, fs.timings}, nil
+}
+
+func (fs timedFileSystem) MkdirAll(dir string, perm os.FileMode) error {
+	defer fs.timings.spend(time.Now())
+	return fs.FileSystem.MkdirAll(dir, perm)
+}
+
+// spend adds the time since start to the io of the timings
+func (t *phaseTimings) spend(start time.Time) {
+	t.io += time.Since(start)
+}
+
+// timedFile is a file created by a timedFileSystem
+type timedFile struct {
+	output.File
+	timings *phaseTimings
+}
+
+func (file timedFile) Write(p []byte) (int, error) {
+	defer file.timings.spend(time.Now())
+	return file.File.Write(p)
+}
+
+func (file timedFile) WriteString(s string) (int, error) {
+	defer file.timings.spend(time.Now())
+	return file.File.WriteString(s)
+}
+
+func (file timedFile) Close() error {
+	defer file.timings.spend(time.Now())
+	return file.File.Close()
+}
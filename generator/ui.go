@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// Import path of this tool, its swagger-ui directory is embedded by -embedUI
+const SWAGGER_IMPORT_PATH = "github.com/yvasiyarov/swagger"
+
+// UI_ASSETS_DIR is the directory of the Swagger UI files, next to docs.go, with -embedUI
+const UI_ASSETS_DIR = "swagger-ui"
+
+// swaggerUiAssets returns the directory of the Swagger UI files to embed: uiAssets, or
+// the swagger-ui directory of this tool in the GOPATH
+func swaggerUiAssets(uiAssets string) (string, error) {
+	if uiAssets == "" {
+		for _, gopath := range parser.Gopath() {
+			dir := filepath.Join(gopath, "src", filepath.FromSlash(SWAGGER_IMPORT_PATH), "swagger-ui")
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				return dir, nil
+			}
+		}
+		return "", errors.New("Can not find the swagger-ui directory of " + SWAGGER_IMPORT_PATH + " in the GOPATH, set -uiAssets\n")
+	}
+	if info, err := os.Stat(uiAssets); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("-uiAssets %s is not a directory\n", uiAssets)
+	}
+	return uiAssets, nil
+}
+
+// copySwaggerUi copies the Swagger UI files of assetsDir into targetDir
+func copySwaggerUi(assetsDir, targetDir string) error {
+	return filepath.Walk(assetsDir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(assetsDir, name)
+		if err != nil {
+			return err
+		}
+		target := path.Join(targetDir, filepath.ToSlash(relative))
+		if info.IsDir() {
+			return output.MkdirAll(target, output.DirMode)
+		}
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("Can not read Swagger UI file: %v\n", err)
+		}
+		return writeFile(target, content)
+	})
+}
@@ -0,0 +1,536 @@
+// Package goclient renders typed Go code of the parsed API: a struct per model and per params of an
+// operation, used by a client with a method per operation, and by the interface a server implements
+package goclient
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+var pathParamRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// client renders the Go code of an API
+type client struct {
+	buf        bytes.Buffer
+	models     map[string]*parser.Model
+	ids        []string          // sorted model Ids
+	modelNames map[string]string // model Id -> struct name
+}
+
+// operation is an operation of the API, with the name of its method and of the fields of its params
+type operation struct {
+	*parser.Operation
+	path   string
+	method string
+	fields []string
+}
+
+// Render returns the Go source of the client, in package packageName, of the API served at baseUrl.
+// It imports the packages any client may use: the unused ones must be removed, like goimports does.
+func Render(p *parser.Parser, baseUrl string, packageName string) []byte {
+	c := newClient(p)
+	c.writeHeader(packageName, "is a client of "+title(p), imports)
+	fmt.Fprintf(&c.buf, "\n// DefaultBaseURL is the URL of the API\nconst DefaultBaseURL = %s\n", strconv.Quote(baseUrl))
+	c.buf.WriteString(runtime)
+	c.writeModels()
+	for _, op := range c.operations(p) {
+		c.writeParams(op)
+		c.writeOperation(op)
+	}
+	return c.buf.Bytes()
+}
+
+func newClient(p *parser.Parser) *client {
+	c := &client{models: p.GetModels(), modelNames: make(map[string]string)}
+	for id := range c.models {
+		c.ids = append(c.ids, id)
+	}
+	sort.Strings(c.ids)
+	c.nameModels(c.ids)
+	return c
+}
+
+// writeHeader writes the package clause of the code, with its doc, and its imports
+func (c *client) writeHeader(packageName string, doc string, imports string) {
+	fmt.Fprintf(&c.buf, "// Code generated by swagger. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&c.buf, "// Package %s %s\n", packageName, doc)
+	fmt.Fprintf(&c.buf, "package %s\n\n", packageName)
+	c.buf.WriteString(imports)
+}
+
+// operations returns the operations of the API by resource, with the names of their methods. The
+// websockets are left out, they are not opened by a request.
+func (c *client) operations(p *parser.Parser) []*operation {
+	resources := make([]string, 0, len(p.TopLevelApis))
+	for resource := range p.TopLevelApis {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	var operations []*operation
+	methods := make(map[string]bool)
+	for _, resource := range resources {
+		for _, api := range p.TopLevelApis[resource].Apis {
+			for _, op := range api.Operations {
+				if op.Websocket != nil {
+					continue
+				}
+				method := identifier(op.Nickname)
+				if method == "" || methods[method] {
+					method = identifier(resource) + method
+				}
+				for i := 2; method == "" || methods[method]; i++ {
+					method = identifier(op.HttpMethod+" "+api.Path) + strconv.Itoa(i)
+				}
+				methods[method] = true
+
+				fields := make(map[string]bool)
+				paramFields := make([]string, len(op.Parameters))
+				for i, param := range op.Parameters {
+					paramFields[i] = field(param.Name, fields)
+				}
+				operations = append(operations, &operation{op, api.Path, method, paramFields})
+			}
+		}
+	}
+	return operations
+}
+
+func (c *client) writeModels() {
+	for _, id := range c.ids {
+		c.writeModel(c.models[id])
+	}
+}
+
+func title(p *parser.Parser) string {
+	if p.Listing.Infos.Title != "" {
+		return p.Listing.Infos.Title
+	}
+	return "the API"
+}
+
+// nameModels names the struct of each model after the last part of its Id, prefixed with the parts
+// before it while it collides with the name of another model
+func (c *client) nameModels(ids []string) {
+	for id, name := range parser.ShortModelNames(ids) {
+		c.modelNames[id] = identifier(name)
+	}
+}
+
+func (c *client) writeModel(model *parser.Model) {
+	name := c.modelNames[model.Id]
+	c.buf.WriteString("\n")
+	if model.Description != "" {
+		writeComment(&c.buf, "", name, model.Description)
+	} else {
+		fmt.Fprintf(&c.buf, "// %s is the %s model\n", name, model.Id)
+	}
+	fmt.Fprintf(&c.buf, "type %s struct {\n", name)
+
+	properties := make([]string, 0, len(model.Properties))
+	for property := range model.Properties {
+		properties = append(properties, property)
+	}
+	sort.Strings(properties)
+	fields := make(map[string]bool)
+	for _, property := range properties {
+		p := model.Properties[property]
+		typeName := c.propertyType(p)
+		required := false
+		for _, requiredProperty := range model.Required {
+			required = required || requiredProperty == property
+		}
+		tag := property
+		if !required {
+			tag += ",omitempty"
+		}
+		if p.Nullable && !strings.HasPrefix(typeName, "[]") && typeName != "interface{}" {
+			typeName = "*" + typeName
+		}
+		writeComment(&c.buf, "\t", "", p.Description)
+		fmt.Fprintf(&c.buf, "\t%s %s `json:%s`\n", field(property, fields), typeName, strconv.Quote(tag))
+	}
+	c.buf.WriteString("}\n")
+}
+
+func (c *client) propertyType(property *parser.ModelProperty) string {
+	if property.Type == "array" {
+		itemType := property.Items.Type
+		if itemType == "" {
+			itemType = property.Items.Ref
+		}
+		return "[]" + c.goType(itemType)
+	}
+	return c.goType(property.Type)
+}
+
+// goType is the Go type of a type name of the spec: a Go type, a model Id or array[T]
+func (c *client) goType(typeName string) string {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		return "[]" + c.goType(typeName[len("array["):len(typeName)-1])
+	}
+	switch typeName {
+	case "bool", "string", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"byte", "rune", "float32", "float64":
+		return typeName
+	case "error":
+		return "string"
+	case "Time", "time.Time":
+		return "time.Time"
+	}
+	if parser.IsFileType(typeName) {
+		return "io.Reader"
+	}
+	if name, ok := c.modelNames[typeName]; ok {
+		return name
+	}
+	return "interface{}"
+}
+
+// resultType is the Go type of the success response of an operation, "" if it has none
+func (c *client) resultType(op *parser.Operation) string {
+	typeName := op.Type
+	for _, response := range op.ResponseMessages {
+		if response.Code >= 200 && response.Code < 300 {
+			typeName = response.ResponseModel
+			break
+		}
+	}
+	if typeName == "array" {
+		typeName = "array[" + op.Items.Type + op.Items.Ref + "]"
+	}
+	if typeName == "" || typeName == "void" {
+		return ""
+	}
+	return c.goType(typeName)
+}
+
+// writeParams writes the struct of the params of an operation, if it has any
+func (c *client) writeParams(op *operation) {
+	if len(op.Parameters) == 0 {
+		return
+	}
+	fmt.Fprintf(&c.buf, "\n// %sParams are the params of %s\ntype %sParams struct {\n", op.method, op.method, op.method)
+	for i, param := range op.Parameters {
+		writeComment(&c.buf, "\t", "", strings.Trim(param.Description, "\""))
+		fmt.Fprintf(&c.buf, "\t%s %s\n", op.fields[i], c.paramType(param))
+	}
+	c.buf.WriteString("}\n")
+}
+
+// writeSignature writes the doc comment and the signature of the method of an operation: the one of the
+// client starts with func and its receiver, the one of an interface is indented
+func (c *client) writeSignature(op *operation, result string, indent string, prefix string) {
+	writeComment(&c.buf, indent, op.method, op.Summary)
+	if op.Summary != "" {
+		c.buf.WriteString(indent + "//\n")
+	}
+	fmt.Fprintf(&c.buf, "%s// %s %s\n", indent, op.HttpMethod, op.path)
+	fmt.Fprintf(&c.buf, "%s%s%s(ctx context.Context", indent, prefix, op.method)
+	if len(op.Parameters) > 0 {
+		fmt.Fprintf(&c.buf, ", params %sParams", op.method)
+	}
+	if result != "" {
+		fmt.Fprintf(&c.buf, ") (%s, error)", result)
+	} else {
+		c.buf.WriteString(") error")
+	}
+}
+
+// writeOperation writes the method of the client calling an operation
+func (c *client) writeOperation(op *operation) {
+	result := c.resultType(op.Operation)
+	c.buf.WriteString("\n")
+	c.writeSignature(op, result, "", "func (c *Client) ")
+	c.buf.WriteString(" {\n")
+	if result != "" {
+		fmt.Fprintf(&c.buf, "\tvar result %s\n", result)
+	}
+	returnErr := "return err"
+	if result != "" {
+		returnErr = "return result, err"
+	}
+
+	var hasForm, hasFiles bool
+	for _, param := range op.Parameters {
+		hasForm = hasForm || param.ParamType == "form"
+		hasFiles = hasFiles || param.ParamType == "form" && parser.IsFileType(param.DataType)
+	}
+	fmt.Fprintf(&c.buf, "\treq := &request{method: %s, path: %s, query: url.Values{}, header: http.Header{}}\n", strconv.Quote(op.HttpMethod), c.pathExpression(op))
+	if hasForm {
+		c.buf.WriteString("\tfields := url.Values{}\n")
+	}
+	if hasFiles {
+		c.buf.WriteString("\tfiles := map[string]io.Reader{}\n")
+	}
+	for i, param := range op.Parameters {
+		value := "params." + op.fields[i]
+		switch param.ParamType {
+		case "query":
+			c.writeParam(param, value, "req.query.Add")
+		case "header":
+			c.writeParam(param, value, "req.header.Add")
+		case "form":
+			if parser.IsFileType(param.DataType) {
+				fmt.Fprintf(&c.buf, "\tif %s != nil {\n\t\tfiles[%s] = %s\n\t}\n", value, strconv.Quote(param.Name), value)
+			} else {
+				c.writeParam(param, value, "fields.Add")
+			}
+		case "body":
+			fmt.Fprintf(&c.buf, "\tbody, err := jsonBody(%s)\n\tif err != nil {\n\t\t%s\n\t}\n", value, returnErr)
+			c.buf.WriteString("\treq.body, req.contentType = body, \"application/json\"\n")
+		}
+	}
+	if hasFiles {
+		fmt.Fprintf(&c.buf, "\tform, contentType, err := multipartBody(fields, files)\n\tif err != nil {\n\t\t%s\n\t}\n", returnErr)
+		c.buf.WriteString("\treq.body, req.contentType = form, contentType\n")
+	} else if hasForm {
+		c.buf.WriteString("\treq.body, req.contentType = strings.NewReader(fields.Encode()), \"application/x-www-form-urlencoded\"\n")
+	}
+	if result != "" {
+		c.buf.WriteString("\tif err := c.do(ctx, req, &result); err != nil {\n\t\treturn result, err\n\t}\n\treturn result, nil\n}\n")
+	} else {
+		c.buf.WriteString("\treturn c.do(ctx, req, nil)\n}\n")
+	}
+}
+
+// paramType is the type of the field of a param: optional params are pointers, omitted when nil
+func (c *client) paramType(param parser.Parameter) string {
+	typeName := c.goType(param.DataType)
+	if param.ParamType == "body" {
+		return typeName
+	}
+	if param.AllowMultiple {
+		return "[]" + typeName
+	}
+	if param.Required || param.ParamType == "path" || strings.HasPrefix(typeName, "[]") || typeName == "io.Reader" || typeName == "interface{}" {
+		return typeName
+	}
+	return "*" + typeName
+}
+
+// writeParam writes the code adding the value of a query, header or form param with add
+func (c *client) writeParam(param parser.Parameter, value string, add string) {
+	name := strconv.Quote(param.Name)
+	switch typeName := c.paramType(param); {
+	case strings.HasPrefix(typeName, "[]"):
+		fmt.Fprintf(&c.buf, "\tfor _, value := range %s {\n\t\t%s(%s, fmt.Sprint(value))\n\t}\n", value, add, name)
+	case strings.HasPrefix(typeName, "*"):
+		fmt.Fprintf(&c.buf, "\tif %s != nil {\n\t\t%s(%s, fmt.Sprint(*%s))\n\t}\n", value, add, name, value)
+	default:
+		fmt.Fprintf(&c.buf, "\t%s(%s, fmt.Sprint(%s))\n", add, name, value)
+	}
+}
+
+// pathExpression is the Go expression of the path of an operation, with its path params
+func (c *client) pathExpression(op *operation) string {
+	path := op.path
+	var parts []string
+	last := 0
+	for _, match := range pathParamRegexp.FindAllStringSubmatchIndex(path, -1) {
+		parts = append(parts, strconv.Quote(path[last:match[0]]))
+		name := path[match[2]:match[3]]
+		value := "\"\""
+		for i, param := range op.Parameters {
+			if param.ParamType == "path" && param.Name == name {
+				value = "url.PathEscape(fmt.Sprint(params." + op.fields[i] + "))"
+			}
+		}
+		parts = append(parts, value)
+		last = match[1]
+	}
+	if last < len(path) || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(path[last:]))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// identifier returns an exported Go identifier of name: user_id => UserId, GET /users => GetUsers
+func identifier(name string) string {
+	var buf bytes.Buffer
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if buf.Len() == 0 && unicode.IsDigit(r) {
+			buf.WriteString("X")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// field returns the name of the struct field of name, unique among fields
+func field(name string, fields map[string]bool) string {
+	fieldName := identifier(name)
+	if fieldName == "" {
+		fieldName = "Field"
+	}
+	for i := 2; fields[fieldName]; i++ {
+		fieldName = identifier(name) + strconv.Itoa(i)
+	}
+	fields[fieldName] = true
+	return fieldName
+}
+
+// writeComment writes the doc comment of a declaration, starting with its name
+func writeComment(buf *bytes.Buffer, indent string, name string, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	if name != "" {
+		text = name + " " + strings.ToLower(text[:1]) + text[1:]
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(buf, "%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// imports are the packages the client may use
+const imports = `import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+`
+
+// runtime is the code of the client which does not depend on the API
+const runtime = `
+// Client calls the API. Header is added to every request, e.g. for authentication.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Header     http.Header
+}
+
+// NewClient returns a client of the API served at baseURL, DefaultBaseURL if empty
+func NewClient(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{BaseURL: baseURL, HTTPClient: http.DefaultClient, Header: http.Header{}}
+}
+
+// Error is the error of a response whose status is not a success
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", err.StatusCode, http.StatusText(err.StatusCode), bytes.TrimSpace(err.Body))
+}
+
+// request is the request of an operation
+type request struct {
+	method, path string
+	query        url.Values
+	header       http.Header
+	body         io.Reader
+	contentType  string
+}
+
+// do sends a request, and decodes the JSON body of its success response into result, if not nil
+func (c *Client) do(ctx context.Context, req *request, result interface{}) error {
+	target := strings.TrimSuffix(c.BaseURL, "/") + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+	httpRequest, err := http.NewRequest(req.method, target, req.body)
+	if err != nil {
+		return err
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+	for name, values := range c.Header {
+		httpRequest.Header[name] = values
+	}
+	for name, values := range req.header {
+		httpRequest.Header[name] = values
+	}
+	if req.contentType != "" {
+		httpRequest.Header.Set("Content-Type", req.contentType)
+	}
+	httpRequest.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return &Error{StatusCode: response.StatusCode, Body: body}
+	}
+	if result == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if text, ok := result.(*string); ok && !json.Valid(body) {
+		*text = string(body)
+		return nil
+	}
+	return json.Unmarshal(body, result)
+}
+
+// jsonBody is the JSON body of a request
+func jsonBody(v interface{}) (io.Reader, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(body), nil
+}
+
+// multipartBody is the multipart/form-data body of a request with files, and its content type
+func multipartBody(fields url.Values, files map[string]io.Reader) (io.Reader, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, values := range fields {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	for name, file := range files {
+		part, err := writer.CreateFormFile(name, name)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := io.Copy(part, file); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, writer.FormDataContentType(), nil
+}
+`
@@ -0,0 +1,327 @@
+package goclient
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// RenderServer returns the Go source of the server stubs of the API, in package packageName: the Server
+// interface with a method per operation, Unimplemented to embed in its implementations, and NewHandler
+// routing the requests to them. Like Render, it imports the packages any server may use.
+func RenderServer(p *parser.Parser, packageName string) []byte {
+	c := newClient(p)
+	c.writeHeader(packageName, "is the server of "+title(p)+": its operations are the methods of Server, served by NewHandler", serverImports)
+	c.buf.WriteString(serverRuntime)
+	c.writeModels()
+	operations := c.operations(p)
+	for _, op := range operations {
+		c.writeParams(op)
+	}
+
+	c.buf.WriteString("\n// Server implements the operations of the API\ntype Server interface {\n")
+	for i, op := range operations {
+		if i > 0 {
+			c.buf.WriteString("\n")
+		}
+		c.writeSignature(op, c.resultType(op.Operation), "\t", "")
+		c.buf.WriteString("\n")
+	}
+	c.buf.WriteString("}\n")
+
+	c.buf.WriteString("\n// Unimplemented implements Server, its methods fail with 501 Not Implemented: embed it in the\n")
+	c.buf.WriteString("// implementations of Server, so they build while operations are added to the API\n")
+	c.buf.WriteString("type Unimplemented struct{}\n")
+	for _, op := range operations {
+		result := c.resultType(op.Operation)
+		fmt.Fprintf(&c.buf, "\nfunc (Unimplemented) %s(ctx context.Context", op.method)
+		if len(op.Parameters) > 0 {
+			fmt.Fprintf(&c.buf, ", params %sParams", op.method)
+		}
+		if result != "" {
+			fmt.Fprintf(&c.buf, ") (%s, error) {\n\tvar result %s\n\treturn result, errNotImplemented\n}\n", result, result)
+		} else {
+			c.buf.WriteString(") error {\n\treturn errNotImplemented\n}\n")
+		}
+	}
+
+	c.buf.WriteString("\n// NewHandler returns the handler routing the requests of the operations to server. Its routes are the\n")
+	c.buf.WriteString("// paths of the API without its base path: serve it with http.StripPrefix to add it.\n")
+	c.buf.WriteString("func NewHandler(server Server) http.Handler {\n\treturn &handler{routes: []*route{\n")
+	for _, op := range operations {
+		pattern, _ := pathPattern(op.path)
+		fmt.Fprintf(&c.buf, "\t\t{%s, regexp.MustCompile(%s), handle%s(server)},\n", strconv.Quote(op.HttpMethod), "`"+pattern+"`", op.method)
+	}
+	c.buf.WriteString("\t}}\n}\n")
+
+	for _, op := range operations {
+		c.writeHandler(op)
+	}
+	return c.buf.Bytes()
+}
+
+// pathPattern returns the regular expression of the escaped paths of an operation, with a group per path
+// param, and the names of its path params
+func pathPattern(path string) (string, []string) {
+	var pattern strings.Builder
+	var names []string
+	last := 0
+	for _, match := range pathParamRegexp.FindAllStringSubmatchIndex(path, -1) {
+		pattern.WriteString(regexp.QuoteMeta(path[last:match[0]]) + "([^/]+)")
+		names = append(names, path[match[2]:match[3]])
+		last = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(path[last:]))
+	return "^" + pattern.String() + "$", names
+}
+
+// writeHandler writes the function decoding the params of an operation, and writing its result
+func (c *client) writeHandler(op *operation) {
+	result := c.resultType(op.Operation)
+	fmt.Fprintf(&c.buf, "\n// handle%s decodes the params of %s, and writes its result\n", op.method, op.method)
+	fmt.Fprintf(&c.buf, "func handle%s(server Server) func(w http.ResponseWriter, r *http.Request, pathParams []string) error {\n", op.method)
+	c.buf.WriteString("\treturn func(w http.ResponseWriter, r *http.Request, pathParams []string) error {\n")
+
+	_, pathNames := pathPattern(op.path)
+	var hasQuery, hasForm bool
+	for _, param := range op.Parameters {
+		hasQuery = hasQuery || param.ParamType == "query"
+		hasForm = hasForm || param.ParamType == "form"
+	}
+	if len(op.Parameters) > 0 {
+		fmt.Fprintf(&c.buf, "\t\tvar params %sParams\n", op.method)
+	}
+	if hasQuery {
+		c.buf.WriteString("\t\tquery := r.URL.Query()\n")
+	}
+	if hasForm {
+		c.buf.WriteString("\t\tif err := parseForm(r); err != nil {\n\t\t\treturn err\n\t\t}\n")
+	}
+	for i, param := range op.Parameters {
+		target := "params." + op.fields[i]
+		name := strconv.Quote(param.Name)
+		switch param.ParamType {
+		case "path":
+			for j, pathName := range pathNames {
+				if pathName == param.Name {
+					fmt.Fprintf(&c.buf, "\t\tif err := parse(%s, pathParams[%d], &%s); err != nil {\n\t\t\treturn err\n\t\t}\n", name, j, target)
+				}
+			}
+		case "query":
+			c.writeDecode(param, target, "query["+name+"]")
+		case "header":
+			c.writeDecode(param, target, "r.Header[http.CanonicalHeaderKey("+name+")]")
+		case "form":
+			if parser.IsFileType(param.DataType) {
+				fmt.Fprintf(&c.buf, "\t\tif file, _, err := r.FormFile(%s); err == nil {\n\t\t\tdefer file.Close()\n\t\t\t%s = file\n\t\t}", name, target)
+				if param.Required {
+					fmt.Fprintf(&c.buf, " else {\n\t\t\treturn missing(%s)\n\t\t}", name)
+				}
+				c.buf.WriteString("\n")
+			} else {
+				c.writeDecode(param, target, "r.PostForm["+name+"]")
+			}
+		case "body":
+			fmt.Fprintf(&c.buf, "\t\tif err := decodeBody(r, &%s, %t); err != nil {\n\t\t\treturn err\n\t\t}\n", target, param.Required)
+		}
+	}
+
+	call := "server." + op.method + "(r.Context()"
+	if len(op.Parameters) > 0 {
+		call += ", params"
+	}
+	call += ")"
+	if result != "" {
+		fmt.Fprintf(&c.buf, "\t\tresult, err := %s\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n", call)
+		fmt.Fprintf(&c.buf, "\t\treturn writeResult(w, %d, result)\n", successCode(op.Operation, 200))
+	} else {
+		fmt.Fprintf(&c.buf, "\t\tif err := %s; err != nil {\n\t\t\treturn err\n\t\t}\n", call)
+		fmt.Fprintf(&c.buf, "\t\tw.WriteHeader(%d)\n\t\treturn nil\n", successCode(op.Operation, 204))
+	}
+	c.buf.WriteString("\t}\n}\n")
+}
+
+// writeDecode writes the code parsing the values of a query, header or form param into target
+func (c *client) writeDecode(param parser.Parameter, target string, values string) {
+	name := strconv.Quote(param.Name)
+	switch typeName := c.paramType(param); {
+	case strings.HasPrefix(typeName, "[]"):
+		fmt.Fprintf(&c.buf, "\t\tfor _, value := range %s {\n\t\t\tvar item %s\n", values, typeName[2:])
+		fmt.Fprintf(&c.buf, "\t\t\tif err := parse(%s, value, &item); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n", name)
+		fmt.Fprintf(&c.buf, "\t\t\t%s = append(%s, item)\n\t\t}\n", target, target)
+		if param.Required {
+			fmt.Fprintf(&c.buf, "\t\tif len(%s) == 0 {\n\t\t\treturn missing(%s)\n\t\t}\n", target, name)
+		}
+	case strings.HasPrefix(typeName, "*"):
+		fmt.Fprintf(&c.buf, "\t\tif values := %s; len(values) > 0 {\n\t\t\t%s = new(%s)\n", values, target, typeName[1:])
+		fmt.Fprintf(&c.buf, "\t\t\tif err := parse(%s, values[0], %s); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n\t\t}\n", name, target)
+	default:
+		fmt.Fprintf(&c.buf, "\t\tif values := %s; len(values) > 0 {\n", values)
+		fmt.Fprintf(&c.buf, "\t\t\tif err := parse(%s, values[0], &%s); err != nil {\n\t\t\t\treturn err\n\t\t\t}\n\t\t}", name, target)
+		if param.Required {
+			fmt.Fprintf(&c.buf, " else {\n\t\t\treturn missing(%s)\n\t\t}", name)
+		}
+		c.buf.WriteString("\n")
+	}
+}
+
+// successCode is the status of the success response of an operation, defaultCode if it documents none
+func successCode(op *parser.Operation, defaultCode int) int {
+	for _, response := range op.ResponseMessages {
+		if response.Code >= 200 && response.Code < 300 {
+			return response.Code
+		}
+	}
+	return defaultCode
+}
+
+// serverImports are the packages the server may use
+const serverImports = `import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+`
+
+// serverRuntime is the code of the server which does not depend on the API
+const serverRuntime = `
+// Error is an error of an operation with the status of its response. The status of other errors is 500
+// Internal Server Error.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
+
+var errNotImplemented = &Error{StatusCode: http.StatusNotImplemented, Message: "Not implemented"}
+
+// route is the route of an operation: the groups of its pattern are its path params
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	handle  func(w http.ResponseWriter, r *http.Request, pathParams []string) error
+}
+
+// handler serves the routes of the operations
+type handler struct {
+	routes []*route
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var allowed []string
+	for _, route := range h.routes {
+		matches := route.pattern.FindStringSubmatch(r.URL.EscapedPath())
+		if matches == nil {
+			continue
+		}
+		if route.method != r.Method {
+			allowed = append(allowed, route.method)
+			continue
+		}
+		pathParams := matches[1:]
+		for i, value := range pathParams {
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				writeError(w, &Error{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("Invalid path: %v", err)})
+				return
+			}
+			pathParams[i] = unescaped
+		}
+		if err := route.handle(w, r, pathParams); err != nil {
+			writeError(w, err)
+		}
+		return
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		writeError(w, &Error{StatusCode: http.StatusMethodNotAllowed, Message: "Method not allowed"})
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// writeError writes the response of an error of an operation
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var operationErr *Error
+	if errors.As(err, &operationErr) {
+		status = operationErr.StatusCode
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// writeResult writes the result of an operation as JSON
+func writeResult(w http.ResponseWriter, status int, result interface{}) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = w.Write(body)
+	return err
+}
+
+// missing is the error of a required param without value
+func missing(name string) error {
+	return &Error{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("Missing %s", name)}
+}
+
+// parse parses the value of the param name into target
+func parse(name string, value string, target interface{}) error {
+	var err error
+	switch target := target.(type) {
+	case *string:
+		*target = value
+	case *interface{}:
+		*target = value
+	case *time.Time:
+		*target, err = time.Parse(time.RFC3339, value)
+	default:
+		_, err = fmt.Sscan(value, target)
+	}
+	if err != nil {
+		return &Error{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("Invalid %s: %v", name, err)}
+	}
+	return nil
+}
+
+// parseForm parses the url encoded or multipart form of a request into its PostForm
+func parseForm(r *http.Request) error {
+	var err error
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		err = r.ParseMultipartForm(32 << 20)
+	} else {
+		err = r.ParseForm()
+	}
+	if err != nil {
+		return &Error{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("Invalid form: %v", err)}
+	}
+	return nil
+}
+
+// decodeBody decodes the JSON body of a request into target
+func decodeBody(r *http.Request, target interface{}, required bool) error {
+	err := json.NewDecoder(r.Body).Decode(target)
+	if err == io.EOF && !required {
+		return nil
+	}
+	if err != nil {
+		return &Error{StatusCode: http.StatusBadRequest, Message: fmt.Sprintf("Invalid body: %v", err)}
+	}
+	return nil
+}
+`
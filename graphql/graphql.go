@@ -0,0 +1,336 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+var nameRegexp = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// GenerateSchema writes the GraphQL schema of all parsed models to outputSpec, ./API.graphql by default.
+// With queries, a field of the Query type is added per GET operation.
+func GenerateSchema(parser *parser.Parser, queries bool, outputSpec *string) error {
+	var filename string
+	if *outputSpec == "" {
+		filename = path.Join("./", "API.graphql")
+	} else {
+		filename = path.Join(*outputSpec)
+	}
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create GraphQL schema file: %v\n", err)
+	}
+	defer fd.Close()
+
+	fd.Write(Render(parser.TopLevelApis, queries))
+	return nil
+}
+
+// schema renders the GraphQL schema of an API
+type schema struct {
+	buf        bytes.Buffer
+	typeNames  map[string]string // model Id -> type name
+	enums      bytes.Buffer
+	enumNames  map[string]bool
+	scalars    map[string]bool // custom scalars used by the types
+	inputTypes bool            // models are not input types, the arguments of queries use JSON instead
+}
+
+// Render emits one object type per model, with an enum per property with enum values. Field names
+// follow the json tags, fields which are required are non-null.
+func Render(apis map[string]*parser.ApiDeclaration, queries bool) []byte {
+	models := make(map[string]*parser.Model)
+	for _, api := range apis {
+		for id, model := range api.Models {
+			models[id] = model
+		}
+	}
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	s := &schema{typeNames: typeNames(ids), enumNames: make(map[string]bool), scalars: make(map[string]bool)}
+	for _, id := range ids {
+		s.buf.WriteString("\n")
+		s.writeType(models[id])
+	}
+	if queries {
+		s.writeQueries(apis)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Code generated by swagger. DO NOT EDIT.\n")
+	if len(s.scalars) > 0 {
+		buf.WriteString("\n")
+		for _, scalar := range []string{"DateTime", "JSON", "Upload"} {
+			if s.scalars[scalar] {
+				fmt.Fprintf(&buf, "scalar %s\n", scalar)
+			}
+		}
+	}
+	buf.Write(s.enums.Bytes())
+	buf.Write(s.buf.Bytes())
+	return buf.Bytes()
+}
+
+// typeNames names the types of the models after the last part of their Id, prefixed with the parts
+// before it when several models have the same last part
+func typeNames(ids []string) map[string]string {
+	names := parser.ShortModelNames(ids)
+	for id, shortName := range names {
+		names[id] = name(shortName)
+	}
+	return names
+}
+
+func (s *schema) writeType(model *parser.Model) {
+	typeName := s.typeNames[model.Id]
+	writeDescription(&s.buf, "", model.Description)
+	fmt.Fprintf(&s.buf, "type %s {\n", typeName)
+
+	names := make([]string, 0, len(model.Properties))
+	for name := range model.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		// an object type has fields, the ones of the model are unknown
+		s.scalars["JSON"] = true
+		s.buf.WriteString("  _: JSON\n")
+	}
+
+	for _, propertyName := range names {
+		property := model.Properties[propertyName]
+		writeDescription(&s.buf, "  ", property.Description)
+		fieldType := s.propertyType(typeName, propertyName, property)
+		required := false
+		for _, requiredName := range model.Required {
+			required = required || requiredName == propertyName
+		}
+		if required && !property.Nullable {
+			fieldType += "!"
+		}
+		fmt.Fprintf(&s.buf, "  %s: %s\n", name(propertyName), fieldType)
+	}
+	s.buf.WriteString("}\n")
+}
+
+func (s *schema) propertyType(typeName string, propertyName string, property *parser.ModelProperty) string {
+	if len(property.Enum) > 0 && s.graphqlType(property.Type) == "String" {
+		if enumName, ok := s.writeEnum(typeName+exported(propertyName), property.Enum); ok {
+			return enumName
+		}
+	}
+	if property.Type == "array" {
+		itemType := property.Items.Type
+		if itemType == "" {
+			itemType = property.Items.Ref
+		}
+		if itemType == "byte" || itemType == "uint8" {
+			// encoding/json writes []byte as a base64 string
+			return "String"
+		}
+		return "[" + s.graphqlType(itemType) + "!]"
+	}
+	return s.graphqlType(property.Type)
+}
+
+// writeEnum writes the enum of the values of a property, if they are valid enum values
+func (s *schema) writeEnum(enumName string, values []string) (string, bool) {
+	for _, value := range values {
+		if !nameRegexp.MatchString(value) || value == "true" || value == "false" || value == "null" {
+			return "", false
+		}
+	}
+	for base, i := enumName, 2; s.enumNames[enumName]; i++ {
+		enumName = fmt.Sprintf("%s%d", base, i)
+	}
+	s.enumNames[enumName] = true
+	fmt.Fprintf(&s.enums, "\nenum %s {\n", enumName)
+	for _, value := range values {
+		fmt.Fprintf(&s.enums, "  %s\n", value)
+	}
+	s.enums.WriteString("}\n")
+	return enumName, true
+}
+
+// graphqlType maps Go type names to GraphQL types, models are referenced by type name. The integers
+// are Int, as most GraphQL servers serialise them.
+func (s *schema) graphqlType(typeName string) string {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		return "[" + s.graphqlType(typeName[len("array["):len(typeName)-1]) + "!]"
+	}
+	switch typeName {
+	case "bool":
+		return "Boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune", "uintptr":
+		return "Int"
+	case "float32", "float64":
+		return "Float"
+	case "string", "error":
+		return "String"
+	case "Time", "time.Time":
+		s.scalars["DateTime"] = true
+		return "DateTime"
+	}
+	if parser.IsFileType(typeName) {
+		s.scalars["Upload"] = true
+		return "Upload"
+	}
+	if typeName, ok := s.typeNames[typeName]; ok && !s.inputTypes {
+		return typeName
+	}
+	s.scalars["JSON"] = true
+	return "JSON"
+}
+
+// writeQueries writes the Query type, with a field per GET operation returning a result. The path and
+// query params of the operation are the arguments of its field.
+func (s *schema) writeQueries(apis map[string]*parser.ApiDeclaration) {
+	resources := make([]string, 0, len(apis))
+	for resource := range apis {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	var fields bytes.Buffer
+	fieldNames := make(map[string]bool)
+	for _, resource := range resources {
+		for _, api := range apis[resource].Apis {
+			for _, op := range api.Operations {
+				resultType := operationType(op)
+				if op.HttpMethod != "GET" || op.Websocket != nil || resultType == "" || resultType == "void" {
+					continue
+				}
+				fieldName := unexported(name(op.Nickname))
+				if fieldName == "" || fieldNames[fieldName] {
+					fieldName = unexported(name(strings.Trim(api.Path, "/")))
+				}
+				for base, i := fieldName, 2; fieldNames[fieldName]; i++ {
+					fieldName = fmt.Sprintf("%s%d", base, i)
+				}
+				fieldNames[fieldName] = true
+
+				description := op.Summary
+				if description != "" {
+					description += "\n\n"
+				}
+				writeDescription(&fields, "  ", description+"GET "+api.Path)
+				fields.WriteString("  " + fieldName)
+				var arguments []string
+				s.inputTypes = true
+				for _, param := range op.Parameters {
+					if param.ParamType != "path" && param.ParamType != "query" {
+						continue
+					}
+					argumentType := s.graphqlType(param.DataType)
+					if param.AllowMultiple && !strings.HasPrefix(argumentType, "[") {
+						argumentType = "[" + argumentType + "!]"
+					}
+					if param.Required || param.ParamType == "path" {
+						argumentType += "!"
+					}
+					arguments = append(arguments, name(param.Name)+": "+argumentType)
+				}
+				s.inputTypes = false
+				if len(arguments) > 0 {
+					fields.WriteString("(" + strings.Join(arguments, ", ") + ")")
+				}
+				fmt.Fprintf(&fields, ": %s\n", s.graphqlType(resultType))
+			}
+		}
+	}
+	if fields.Len() > 0 {
+		s.buf.WriteString("\ntype Query {\n")
+		s.buf.Write(fields.Bytes())
+		s.buf.WriteString("}\n")
+	}
+}
+
+// operationType is the type of the success response of an operation
+func operationType(op *parser.Operation) string {
+	for _, response := range op.ResponseMessages {
+		if response.Code >= 200 && response.Code < 300 {
+			return response.ResponseModel
+		}
+	}
+	if op.Type == "array" {
+		return "array[" + op.Items.Type + op.Items.Ref + "]"
+	}
+	return op.Type
+}
+
+// name replaces the characters which are not valid in GraphQL names: some-id => some_id
+func name(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if r > unicode.MaxASCII || !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			runes[i] = '_'
+		}
+	}
+	if len(runes) > 0 && unicode.IsDigit(runes[0]) {
+		return "_" + string(runes)
+	}
+	return string(runes)
+}
+
+// exported returns name starting with an upper case letter, without its underscores: some_id => SomeId
+func exported(s string) string {
+	var buf bytes.Buffer
+	upper := true
+	for _, r := range name(s) {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+func unexported(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(exported(s))
+	if len(runes) == 0 {
+		return name(s)
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// writeDescription writes a description as a block string
+func writeDescription(buf *bytes.Buffer, indent string, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	description = strings.Replace(description, `"""`, `\"""`, -1)
+	if !strings.Contains(description, "\n") {
+		fmt.Fprintf(buf, "%s\"\"\"%s\"\"\"\n", indent, description)
+		return
+	}
+	fmt.Fprintf(buf, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(description, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			line = indent + line
+		}
+		buf.WriteString(line + "\n")
+	}
+	fmt.Fprintf(buf, "%s\"\"\"\n", indent)
+}
@@ -0,0 +1,98 @@
+// Package html writes the API as a single HTML file, rendered in the browser by a documentation viewer
+package html
+
+import (
+	"fmt"
+	"html/template"
+	"path"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/openapi"
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// AVAILABLE_VIEWERS are the values of -htmlViewer
+const AVAILABLE_VIEWERS = "rapidoc|elements"
+
+// viewerTemplates are the pages of the viewers, executed with a page. The viewers are loaded
+// from unpkg.com, and read the Swagger 2.0 spec embedded in the page.
+var viewerTemplates = map[string]string{
+	// https://rapidocweb.com
+	"rapidoc": `<!doctype html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <script type="module" src="https://unpkg.com/rapidoc/dist/rapidoc-min.js"></script>
+</head>
+<body>
+  <rapi-doc id="api" render-style="read" show-header="false" allow-spec-url-load="false" allow-spec-file-load="false"></rapi-doc>
+  <script>
+    customElements.whenDefined("rapi-doc").then(function () {
+      document.getElementById("api").loadSpec({{.Spec}});
+    });
+  </script>
+</body>
+</html>
+`,
+	// https://stoplight.io/open-source/elements
+	"elements": `<!doctype html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1, shrink-to-fit=no">
+  <title>{{.Title}}</title>
+  <script src="https://unpkg.com/@stoplight/elements/web-components.min.js"></script>
+  <link rel="stylesheet" href="https://unpkg.com/@stoplight/elements/styles.min.css">
+</head>
+<body>
+  <elements-api id="api" router="hash" layout="sidebar"></elements-api>
+  <script>
+    customElements.whenDefined("elements-api").then(function () {
+      document.getElementById("api").apiDescriptionDocument = {{.Spec}};
+    });
+  </script>
+</body>
+</html>
+`,
+}
+
+type page struct {
+	Title string
+	Spec  *openapi.Document
+}
+
+// GenerateHtml writes the API as an HTML page rendered by viewer, one of AVAILABLE_VIEWERS, to outputSpec,
+// ./API.html by default
+func GenerateHtml(parser *parser.Parser, baseUrl string, viewer string, outputSpec *string) error {
+	if viewer == "" {
+		viewer = "rapidoc"
+	}
+	viewerTemplate, ok := viewerTemplates[strings.ToLower(viewer)]
+	if !ok {
+		return fmt.Errorf("Invalid -htmlViewer specified. Must be one of %v.", AVAILABLE_VIEWERS)
+	}
+	tmpl, err := template.New(viewer).Parse(viewerTemplate)
+	if err != nil {
+		return fmt.Errorf("Can not parse %s page template: %v\n", viewer, err)
+	}
+
+	var filename string
+	if *outputSpec == "" {
+		filename = path.Join("./", "API.html")
+	} else {
+		filename = path.Join(*outputSpec)
+	}
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create HTML file: %v\n", err)
+	}
+	defer fd.Close()
+
+	spec := openapi.Convert(parser, baseUrl)
+	if err := tmpl.Execute(fd, page{Title: spec.Info.Title, Spec: spec}); err != nil {
+		return fmt.Errorf("Can not execute %s page template: %v\n", viewer, err)
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+// Package jsonpatch applies RFC 6902 JSON Patch documents to decoded JSON documents
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Operation is an operation of a patch: add, remove, replace, move, copy or test
+type Operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"` // move and copy only
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Apply applies the operations of patch in order to document, as decoded by encoding/json into an
+// interface{}, and returns the patched document. The patch is atomic: document is left unchanged
+// if an operation fails.
+func Apply(document interface{}, patch []Operation) (interface{}, error) {
+	patched := deepCopy(document)
+	for i, operation := range patch {
+		var err error
+		switch operation.Op {
+		case "add":
+			patched, err = add(patched, operation.Path, normalise(operation.Value))
+		case "remove":
+			patched, _, err = remove(patched, operation.Path)
+		case "replace":
+			if patched, _, err = remove(patched, operation.Path); err == nil {
+				patched, err = add(patched, operation.Path, normalise(operation.Value))
+			}
+		case "move":
+			var value interface{}
+			if strings.HasPrefix(operation.Path+"/", operation.From+"/") && operation.Path != operation.From {
+				err = fmt.Errorf("can not move %s into itself", operation.From)
+			} else if patched, value, err = remove(patched, operation.From); err == nil {
+				patched, err = add(patched, operation.Path, value)
+			}
+		case "copy":
+			var value interface{}
+			if value, err = get(patched, operation.From); err == nil {
+				patched, err = add(patched, operation.Path, deepCopy(value))
+			}
+		case "test":
+			var value interface{}
+			if value, err = get(patched, operation.Path); err == nil && !reflect.DeepEqual(value, normalise(operation.Value)) {
+				err = fmt.Errorf("value of %s is %v", operation.Path, value)
+			}
+		default:
+			err = fmt.Errorf("unknown op %q", operation.Op)
+		}
+		if err != nil {
+			return document, fmt.Errorf("Operation %d (%s %s) failed: %v", i, operation.Op, operation.Path, err)
+		}
+	}
+	return patched, nil
+}
+
+// tokens returns the reference tokens of a JSON pointer, /a~1b/c~0d is [a/b c~d]
+func tokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	parts := strings.Split(pointer[1:], "/")
+	for i, part := range parts {
+		parts[i] = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+	}
+	return parts, nil
+}
+
+// index returns the index of a reference token in an array of length n, "-" is n
+func index(token string, n int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > n || (i == n && !allowEnd) || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return i, nil
+}
+
+func get(document interface{}, pointer string) (interface{}, error) {
+	parts, err := tokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	value := document
+	for _, part := range parts {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = typed[part]; !ok {
+				return nil, fmt.Errorf("%s does not exist", pointer)
+			}
+		case []interface{}:
+			i, err := index(part, len(typed), false)
+			if err != nil {
+				return nil, err
+			}
+			value = typed[i]
+		default:
+			return nil, fmt.Errorf("%s does not exist", pointer)
+		}
+	}
+	return value, nil
+}
+
+// add adds value at pointer, and returns the document, which is replaced if pointer is the root
+func add(document interface{}, pointer string, value interface{}) (interface{}, error) {
+	parts, err := tokens(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return value, nil
+	}
+	return update(document, parts, func(parent interface{}, last string) (interface{}, error) {
+		switch typed := parent.(type) {
+		case map[string]interface{}:
+			typed[last] = value
+			return typed, nil
+		case []interface{}:
+			i, err := index(last, len(typed), true)
+			if err != nil {
+				return nil, err
+			}
+			typed = append(typed, nil)
+			copy(typed[i+1:], typed[i:])
+			typed[i] = value
+			return typed, nil
+		}
+		return nil, fmt.Errorf("parent of %s is not an object or an array", pointer)
+	})
+}
+
+// remove removes the value at pointer, and returns the document and the removed value
+func remove(document interface{}, pointer string) (interface{}, interface{}, error) {
+	parts, err := tokens(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(parts) == 0 {
+		return nil, document, nil
+	}
+	var removed interface{}
+	document, err = update(document, parts, func(parent interface{}, last string) (interface{}, error) {
+		switch typed := parent.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if removed, ok = typed[last]; !ok {
+				return nil, fmt.Errorf("%s does not exist", pointer)
+			}
+			delete(typed, last)
+			return typed, nil
+		case []interface{}:
+			i, err := index(last, len(typed), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = typed[i]
+			return append(typed[:i], typed[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%s does not exist", pointer)
+	})
+	return document, removed, err
+}
+
+// update replaces the parent of the last token of parts by the result of change, arrays being values
+func update(document interface{}, parts []string, change func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if len(parts) == 1 {
+		return change(document, parts[0])
+	}
+	switch typed := document.(type) {
+	case map[string]interface{}:
+		child, ok := typed[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("/%s does not exist", strings.Join(parts, "/"))
+		}
+		updated, err := update(child, parts[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		typed[parts[0]] = updated
+		return typed, nil
+	case []interface{}:
+		i, err := index(parts[0], len(typed), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(typed[i], parts[1:], change)
+		if err != nil {
+			return nil, err
+		}
+		typed[i] = updated
+		return typed, nil
+	}
+	return nil, fmt.Errorf("/%s does not exist", strings.Join(parts, "/"))
+}
+
+// deepCopy copies a decoded JSON value, so the patch does not change the values it is given
+func deepCopy(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed))
+		for key, child := range typed {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(typed))
+		for i, child := range typed {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	}
+	return value
+}
+
+// normalise returns a copy of value as encoding/json decodes it, numbers are float64
+func normalise(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalised interface{}
+	if err := json.Unmarshal(data, &normalised); err != nil {
+		return value
+	}
+	return normalised
+}
@@ -0,0 +1,165 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yvasiyarov/swagger/jsonpatch"
+)
+
+func decode(t *testing.T, data string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("Can not decode %s: %v", data, err)
+	}
+	return value
+}
+
+// TestRFC6902Examples applies the examples of RFC 6902 appendix A, an empty expected document
+// means the patch must fail
+func TestRFC6902Examples(t *testing.T) {
+	examples := []struct {
+		name     string
+		document string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "A.1 adding an object member",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			expected: `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:     "A.2 adding an array element",
+			document: `{"foo": ["bar", "baz"]}`,
+			patch:    `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			expected: `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:     "A.3 removing an object member",
+			document: `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "remove", "path": "/baz"}]`,
+			expected: `{"foo": "bar"}`,
+		},
+		{
+			name:     "A.4 removing an array element",
+			document: `{"foo": ["bar", "qux", "baz"]}`,
+			patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+			expected: `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:     "A.5 replacing a value",
+			document: `{"baz": "qux", "foo": "bar"}`,
+			patch:    `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			expected: `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:     "A.6 moving a value",
+			document: `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch:    `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			expected: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:     "A.7 moving an array element",
+			document: `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch:    `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			expected: `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name:     "A.8 testing a value: success",
+			document: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch:    `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			expected: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:     "A.9 testing a value: error",
+			document: `{"baz": "qux"}`,
+			patch:    `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+		},
+		{
+			name:     "A.10 adding a nested member object",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			expected: `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:     "A.11 ignoring unrecognized elements",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			expected: `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:     "A.12 adding to a nonexistent target",
+			document: `{"foo": "bar"}`,
+			patch:    `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		},
+		{
+			name:     "A.14 ~ escape ordering",
+			document: `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "test", "path": "/~01", "value": 10}]`,
+			expected: `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:     "A.15 comparing strings and numbers",
+			document: `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "test", "path": "/~01", "value": "10"}]`,
+		},
+		{
+			name:     "A.16 adding an array value",
+			document: `{"foo": ["bar"]}`,
+			patch:    `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			expected: `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		{
+			name:     "~1 escaping of /",
+			document: `{"/": 9, "~1": 10}`,
+			patch:    `[{"op": "replace", "path": "/~1", "value": 11}, {"op": "add", "path": "/a~1b~0c", "value": 12}]`,
+			expected: `{"/": 11, "~1": 10, "a/b~c": 12}`,
+		},
+		{
+			name:     "copying a value",
+			document: `{"foo": {"bar": [1]}}`,
+			patch:    `[{"op": "copy", "from": "/foo/bar", "path": "/baz"}, {"op": "add", "path": "/baz/-", "value": 2}]`,
+			expected: `{"foo": {"bar": [1]}, "baz": [1, 2]}`,
+		},
+		{
+			name:     "removing a nonexistent value",
+			document: `{"foo": ["bar"]}`,
+			patch:    `[{"op": "remove", "path": "/foo/1"}]`,
+		},
+	}
+
+	for _, example := range examples {
+		var patch []jsonpatch.Operation
+		if err := json.Unmarshal([]byte(example.patch), &patch); err != nil {
+			t.Fatalf("Can not decode patch of %s: %v", example.name, err)
+		}
+		document := decode(t, example.document)
+
+		actual, err := jsonpatch.Apply(document, patch)
+		if example.expected == "" {
+			assert.NotNil(t, err, "Patch of %s must fail", example.name)
+			assert.Equal(t, decode(t, example.document), actual, "Failed patch of %s must return the document unchanged", example.name)
+			continue
+		}
+		assert.Nil(t, err, "Patch of %s failed", example.name)
+		assert.Equal(t, decode(t, example.expected), actual, "Wrong document for %s", example.name)
+		assert.Equal(t, decode(t, example.document), document, "Patch of %s changed its input", example.name)
+	}
+}
+
+func TestApplyIsAtomic(t *testing.T) {
+	document := decode(t, `{"foo": ["bar"]}`)
+	patch := []jsonpatch.Operation{
+		{Op: "add", Path: "/foo/-", Value: "baz"},
+		{Op: "test", Path: "/foo/0", Value: "qux"},
+	}
+
+	actual, err := jsonpatch.Apply(document, patch)
+	if assert.NotNil(t, err, "Failing test op must fail the patch") {
+		assert.Equal(t, `Operation 1 (test /foo/0) failed: value of /foo/0 is bar`, err.Error(), "Wrong error")
+	}
+	assert.Equal(t, decode(t, `{"foo": ["bar"]}`), actual, "Earlier operations of a failed patch must be dropped")
+}
@@ -0,0 +1,208 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+const SchemaVersion = "http://json-schema.org/draft-04/schema#"
+
+// Schema is a JSON Schema (draft 4) document or subschema
+type Schema map[string]interface{}
+
+// RefFunc returns the $ref value used to reference the model with the given Id
+type RefFunc func(modelId string) string
+
+// FileRef references models stored by GenerateSchemas, one file per model
+func FileRef(modelId string) string {
+	return modelId + ".json"
+}
+
+// ModelSchema converts model to a JSON Schema, other models are referenced with ref
+func ModelSchema(model *parser.Model, ref RefFunc) Schema {
+	properties := make(map[string]interface{}, len(model.Properties))
+	for name, property := range model.Properties {
+		properties[name] = PropertySchema(property, ref)
+	}
+
+	schema := Schema{
+		"type":       "object",
+		"properties": properties,
+	}
+	if model.Description != "" {
+		schema["description"] = model.Description
+	}
+	if len(model.Required) > 0 {
+		schema["required"] = model.Required
+	}
+	// a value of a type hierarchy is one of the subtypes
+	if len(model.SubTypes) > 0 {
+		oneOf := make([]Schema, 0, len(model.SubTypes))
+		for _, subType := range model.SubTypes {
+			oneOf = append(oneOf, Schema{"$ref": ref(subType)})
+		}
+		schema["oneOf"] = oneOf
+	}
+	return schema
+}
+
+// PropertySchema converts a model property to a JSON Schema
+func PropertySchema(property *parser.ModelProperty, ref RefFunc) Schema {
+	var schema Schema
+	if property.Type == "array" {
+		itemType := property.Items.Type
+		if itemType == "" {
+			itemType = property.Items.Ref
+		}
+		schema = TypeSchema("array["+itemType+"]", ref)
+	} else {
+		schema = TypeSchema(property.Type, ref)
+	}
+
+	if property.Description != "" {
+		schema["description"] = property.Description
+	}
+	if property.Format != "" {
+		schema["format"] = property.Format
+	}
+	if len(property.Enum) > 0 {
+		schema["enum"] = enumValues(schema["type"], property.Enum)
+	}
+	if minimum, err := strconv.ParseFloat(property.Minimum, 64); err == nil {
+		schema["minimum"] = minimum
+	}
+	if maximum, err := strconv.ParseFloat(property.Maximum, 64); err == nil {
+		schema["maximum"] = maximum
+	}
+	if property.MinLength > 0 {
+		schema["minLength"] = property.MinLength
+	}
+	if property.MaxLength > 0 {
+		schema["maxLength"] = property.MaxLength
+	}
+	if property.Pattern != "" {
+		schema["pattern"] = property.Pattern
+	}
+	if property.Nullable {
+		if typeName, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typeName, "null"}
+		} else if ref, ok := schema["$ref"]; ok {
+			delete(schema, "$ref")
+			schema["oneOf"] = []Schema{{"$ref": ref}, {"type": "null"}}
+		}
+	}
+	if property.ReadOnly {
+		schema["readOnly"] = true
+	}
+	if property.WriteOnly {
+		schema["writeOnly"] = true
+	}
+	return schema
+}
+
+// enumValues returns the enum of a property, numbers for the integer and number types
+func enumValues(schemaType interface{}, enum []string) []interface{} {
+	values := make([]interface{}, len(enum))
+	for i, value := range enum {
+		values[i] = value
+		if schemaType == "integer" || schemaType == "number" {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				values[i] = number
+			}
+		}
+	}
+	return values
+}
+
+// TypeSchema returns the schema of a swagger type name: basic type, model Id or "array[...]"
+func TypeSchema(typeName string, ref RefFunc) Schema {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		itemType := typeName[len("array[") : len(typeName)-1]
+		if itemType == "byte" || itemType == "uint8" {
+			// encoding/json writes []byte as a base64 string
+			return Schema{"type": "string", "format": "byte"}
+		}
+		return Schema{
+			"type":  "array",
+			"items": TypeSchema(itemType, ref),
+		}
+	}
+	switch typeName {
+	case "bool":
+		return Schema{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+		return Schema{"type": "integer", "format": "int32"}
+	case "int64", "uint64", "uintptr":
+		return Schema{"type": "integer", "format": "int64"}
+	case "float32":
+		return Schema{"type": "number", "format": "float"}
+	case "float64", "complex64", "complex128":
+		return Schema{"type": "number", "format": "double"}
+	case "string", "error":
+		return Schema{"type": "string"}
+	case "Time":
+		return Schema{"type": "string", "format": "date-time"}
+	case "":
+		return Schema{}
+	}
+	if strings.Contains(typeName, "interface") {
+		return Schema{}
+	}
+	return Schema{"$ref": ref(typeName)}
+}
+
+// GenerateSchemas writes every parsed model as a standalone JSON Schema file into the outputSpec directory
+// (./schemas by default), plus index.json mapping model Ids to file names
+func GenerateSchemas(parser *parser.Parser, outputSpec *string) error {
+	dir := *outputSpec
+	if dir == "" {
+		dir = path.Join("./", "schemas")
+	}
+	if err := output.MkdirAll(dir, output.DirMode); err != nil {
+		return fmt.Errorf("Can not create schemas directory: %v\n", err)
+	}
+
+	models := parser.GetModels()
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	index := make(map[string]string, len(ids))
+	for _, id := range ids {
+		schema := ModelSchema(models[id], FileRef)
+		schema["$schema"] = SchemaVersion
+		schema["id"] = FileRef(id)
+		schema["title"] = id
+
+		if err := writeJson(path.Join(dir, FileRef(id)), schema); err != nil {
+			return err
+		}
+		index[id] = FileRef(id)
+	}
+
+	return writeJson(path.Join(dir, "index.json"), index)
+}
+
+func writeJson(filename string, v interface{}) error {
+	json, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return fmt.Errorf("Can not serialise %s to JSON: %v\n", filename, err)
+	}
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create %s file: %v\n", filename, err)
+	}
+	defer fd.Close()
+
+	fd.Write(json)
+	return nil
+}
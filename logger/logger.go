@@ -0,0 +1,115 @@
+// Package logger is the leveled logger of the generator. Messages are written to stderr,
+// either as text lines like the standard log package or as one JSON object per line.
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+var (
+	mutex      sync.Mutex
+	level      = LevelInfo
+	jsonFormat = false
+	output     = io.Writer(os.Stderr)
+)
+
+// SetLevel drops messages below l
+func SetLevel(l Level) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	level = l
+}
+
+// SetJSON switches to one JSON object per message: {"time": ..., "level": ..., "msg": ...}
+func SetJSON(enabled bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	jsonFormat = enabled
+}
+
+func SetOutput(w io.Writer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	output = w
+}
+
+// Enabled reports if messages of level l are written
+func Enabled(l Level) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return l >= level
+}
+
+func Debugf(format string, args ...interface{}) {
+	write(LevelDebug, format, args...)
+}
+
+func Infof(format string, args ...interface{}) {
+	write(LevelInfo, format, args...)
+}
+
+func Warnf(format string, args ...interface{}) {
+	write(LevelWarn, format, args...)
+}
+
+func Errorf(format string, args ...interface{}) {
+	write(LevelError, format, args...)
+}
+
+// Fatalf writes an error message and exits with status 1
+func Fatalf(format string, args ...interface{}) {
+	write(LevelError, format, args...)
+	os.Exit(1)
+}
+
+func write(l Level, format string, args ...interface{}) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if l < level {
+		return
+	}
+
+	now := time.Now()
+	message := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	if jsonFormat {
+		line, _ := json.Marshal(struct {
+			Time    string `json:"time"`
+			Level   string `json:"level"`
+			Message string `json:"msg"`
+		}{now.Format(time.RFC3339), l.String(), message})
+		fmt.Fprintf(output, "%s\n", line)
+		return
+	}
+
+	prefix := now.Format("2006/01/02 15:04:05 ")
+	if l != LevelInfo {
+		prefix += strings.ToUpper(l.String()) + ": "
+	}
+	fmt.Fprintf(output, "%s%s\n", prefix, message)
+}
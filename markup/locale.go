@@ -0,0 +1,165 @@
+package markup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// AVAILABLE_LOCALES are the locales of the built-in translations, the values of -locale
+const AVAILABLE_LOCALES = "en|fr|de|es"
+
+// Translations maps the English texts of the built-in layout to their translation.
+// Missing texts stay in English.
+type Translations map[string]string
+
+// text returns the translation of english
+func (t Translations) text(english string) string {
+	if translation, ok := t[english]; ok && translation != "" {
+		return translation
+	}
+	return english
+}
+
+var locales = map[string]Translations{
+	"en": {},
+	"fr": {
+		"Table of Contents":         "Table des matières",
+		"Specification":             "Spécification",
+		"Value":                     "Valeur",
+		"Resource Path":             "Chemin de la ressource",
+		"API Version":               "Version de l'API",
+		"BasePath for the API":      "Chemin de base de l'API",
+		"Consumes":                  "Consomme",
+		"Produces":                  "Produit",
+		"Operations":                "Opérations",
+		"Operation":                 "Opération",
+		"Description":               "Description",
+		"API":                       "API",
+		"Param Name":                "Paramètre",
+		"Param Type":                "Emplacement",
+		"Data Type":                 "Type de donnée",
+		"Required?":                 "Obligatoire ?",
+		"Yes":                       "Oui",
+		"Code":                      "Code",
+		"Type":                      "Type",
+		"Model":                     "Modèle",
+		"Message":                   "Message",
+		"Example request":           "Exemple de requête",
+		"Models":                    "Modèles",
+		"Field Name (alphabetical)": "Champ (ordre alphabétique)",
+		"Field Type":                "Type du champ",
+		"read only":                 "lecture seule",
+		"write only":                "écriture seule",
+		"WebSocket messages":        "Messages WebSocket",
+		"Direction":                 "Sens",
+		"Client to server":          "Du client au serveur",
+		"Server to client":          "Du serveur au client",
+		"Server-Sent Events":        "Événements serveur (SSE)",
+		"Event":                     "Événement",
+	},
+	"de": {
+		"Table of Contents":         "Inhaltsverzeichnis",
+		"Specification":             "Spezifikation",
+		"Value":                     "Wert",
+		"Resource Path":             "Ressourcenpfad",
+		"API Version":               "API-Version",
+		"BasePath for the API":      "Basispfad der API",
+		"Consumes":                  "Akzeptiert",
+		"Produces":                  "Liefert",
+		"Operations":                "Operationen",
+		"Operation":                 "Operation",
+		"Description":               "Beschreibung",
+		"API":                       "API",
+		"Param Name":                "Parameter",
+		"Param Type":                "Ort",
+		"Data Type":                 "Datentyp",
+		"Required?":                 "Pflicht?",
+		"Yes":                       "Ja",
+		"Code":                      "Code",
+		"Type":                      "Typ",
+		"Model":                     "Modell",
+		"Message":                   "Meldung",
+		"Example request":           "Beispielanfrage",
+		"Models":                    "Modelle",
+		"Field Name (alphabetical)": "Feld (alphabetisch)",
+		"Field Type":                "Feldtyp",
+		"read only":                 "nur lesend",
+		"write only":                "nur schreibend",
+		"WebSocket messages":        "WebSocket-Nachrichten",
+		"Direction":                 "Richtung",
+		"Client to server":          "Vom Client zum Server",
+		"Server to client":          "Vom Server zum Client",
+		"Server-Sent Events":        "Server-Sent Events",
+		"Event":                     "Ereignis",
+	},
+	"es": {
+		"Table of Contents":         "Índice",
+		"Specification":             "Especificación",
+		"Value":                     "Valor",
+		"Resource Path":             "Ruta del recurso",
+		"API Version":               "Versión de la API",
+		"BasePath for the API":      "Ruta base de la API",
+		"Consumes":                  "Consume",
+		"Produces":                  "Produce",
+		"Operations":                "Operaciones",
+		"Operation":                 "Operación",
+		"Description":               "Descripción",
+		"API":                       "API",
+		"Param Name":                "Parámetro",
+		"Param Type":                "Ubicación",
+		"Data Type":                 "Tipo de dato",
+		"Required?":                 "¿Obligatorio?",
+		"Yes":                       "Sí",
+		"Code":                      "Código",
+		"Type":                      "Tipo",
+		"Model":                     "Modelo",
+		"Message":                   "Mensaje",
+		"Example request":           "Ejemplo de petición",
+		"Models":                    "Modelos",
+		"Field Name (alphabetical)": "Campo (orden alfabético)",
+		"Field Type":                "Tipo del campo",
+		"read only":                 "solo lectura",
+		"write only":                "solo escritura",
+		"WebSocket messages":        "Mensajes WebSocket",
+		"Direction":                 "Dirección",
+		"Client to server":          "Del cliente al servidor",
+		"Server to client":          "Del servidor al cliente",
+		"Server-Sent Events":        "Eventos del servidor (SSE)",
+		"Event":                     "Evento",
+	},
+}
+
+// LoadTranslations returns the translations of locale (en, fr_FR, de-CH... only the language is used),
+// overridden by the JSON object of stringsFile, if not empty, which maps English texts to custom ones
+func LoadTranslations(locale, stringsFile string) (Translations, error) {
+	language := strings.ToLower(strings.SplitN(strings.Replace(locale, "_", "-", -1), "-", 2)[0])
+	if language == "" {
+		language = "en"
+	}
+	builtIn, ok := locales[language]
+	if !ok {
+		return nil, fmt.Errorf("Invalid -locale specified: %s. Must be one of %v.", locale, AVAILABLE_LOCALES)
+	}
+	translations := make(Translations, len(builtIn))
+	for english, translation := range builtIn {
+		translations[english] = translation
+	}
+	if stringsFile == "" {
+		return translations, nil
+	}
+
+	content, err := ioutil.ReadFile(stringsFile)
+	if err != nil {
+		return nil, fmt.Errorf("Can not read markup strings file: %v\n", err)
+	}
+	var custom map[string]string
+	if err := json.Unmarshal(content, &custom); err != nil {
+		return nil, fmt.Errorf("Can not parse markup strings file %s: %v\n", stringsFile, err)
+	}
+	for english, translation := range custom {
+		translations[english] = translation
+	}
+	return translations, nil
+}
@@ -3,14 +3,17 @@ package markup
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"path"
 	"sort"
 	"strings"
 
+	"github.com/yvasiyarov/swagger/output"
 	"github.com/yvasiyarov/swagger/parser"
 )
 
+// MODELS_ANCHOR is the anchor of the Models section
+const MODELS_ANCHOR = "models"
+
 const (
 	color_NORMAL_TEXT             = "black"
 	color_API_SECTION_HEADER_TEXT = "red"
@@ -35,151 +38,457 @@ type Markup interface {
 	tableRow(args ...string) string
 	tableFooter() string
 	colorSpan(content, foregroundColor, backgroundColor string) string
+	codeBlock(language, code string) string
+	fileLink(fileName, anchorName, linkText string) string
+	// escape escapes text, escapePath also escapes the variables of a path, paragraph renders a paragraph
+	escape(text string) string
+	escapePath(path string) string
+	paragraph(text string) string
+}
+
+// includer is a Markup which can include files. Split documents are then a master document including
+// the files of the resources and of the models, instead of an index linking to them.
+type includer interface {
+	include(fileName string) string
 }
 
-func GenerateMarkup(parser *parser.Parser, markup Markup, outputSpec *string, defaultFileExtension string) error {
+// createMarkupFile creates the file named by outputSpec, or ./API<defaultFileExtension> if it is empty
+func createMarkupFile(outputSpec *string, defaultFileExtension string) (output.File, error) {
 	var filename string
 	if *outputSpec == "" {
 		filename = path.Join("./", "API") + defaultFileExtension
 	} else {
 		filename = path.Join(*outputSpec)
 	}
-	fd, err := os.Create(filename)
+	fd, err := output.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Can not create document file: %v\n", err)
+	}
+	return fd, nil
+}
+
+// Options are the settings of the markup formats
+type Options struct {
+	// BaseUrl is the URL of the server the request samples call
+	BaseUrl string
+	// Samples are the languages of the request samples of the operations, see AVAILABLE_SAMPLES
+	Samples []string
+	// Translations of the texts of the layout, English if nil
+	Translations Translations
+	// Split writes every resource and the models to their own file, next to the index file
+	Split bool
+}
+
+// MODELS_FILE is the name of the models file of split documents, without extension
+const MODELS_FILE = "models"
+
+// document renders the sections of the documentation. Links to anchors of other files, in split
+// documents, point to these files.
+type document struct {
+	markup      Markup
+	options     Options
+	text        func(english string) string
+	anchorFiles map[string]string // file of the anchors which are not in the index file
+	currentFile string            // "" for the index file
+	levelOffset int               // split files start with level 1 sections
+}
+
+func (doc *document) sectionHeader(level int, text string) string {
+	return doc.markup.sectionHeader(level-doc.levelOffset, text)
+}
+
+func (doc *document) link(anchorName, linkText string) string {
+	if file := doc.anchorFiles[anchorName]; file != doc.currentFile {
+		return doc.markup.fileLink(file, anchorName, linkText)
+	}
+	return doc.markup.link(anchorName, linkText)
+}
+
+func (doc *document) modelText(fullyQualifiedModelName string) string {
+	if strings.HasPrefix(fullyQualifiedModelName, "array[") && strings.HasSuffix(fullyQualifiedModelName, "]") {
+		return doc.modelText(fullyQualifiedModelName[len("array["):len(fullyQualifiedModelName)-1]) + "[]"
+	}
+	shortName := parser.ShortModelName(fullyQualifiedModelName)
+	if fullyQualifiedModelName != shortName {
+		return doc.link(fullyQualifiedModelName, doc.markup.escape(shortName))
+	}
+	return doc.markup.escape(shortName)
+}
+
+// resourceFileName is the name of the file of a resource in split documents, without extension
+func resourceFileName(apiKey string) string {
+	if fileName := strings.Replace(strings.Trim(apiKey, "/"), "/", "-", -1); fileName != "" {
+		return fileName
+	}
+	return "root"
+}
+
+// GenerateMarkup writes the documentation to outputSpec or, with options.Split, the overview and the table of
+// contents to outputSpec and the resources and the models to their own files, in the directory of outputSpec.
+// If markup can include files, outputSpec is then a master document including them.
+func GenerateMarkup(parser *parser.Parser, markup Markup, options Options, outputSpec *string, defaultFileExtension string) error {
+	indexFile := *outputSpec
+	if indexFile == "" {
+		indexFile = path.Join("./", "API") + defaultFileExtension
+	}
+	doc := &document{markup: markup, options: options, anchorFiles: map[string]string{}}
+	doc.text = func(english string) string {
+		return markup.escape(options.Translations.text(english))
+	}
+	apiKeys := alphabeticalKeysOfApiDeclaration(parser.TopLevelApis)
+	models := parser.GetModels()
+
+	var buf bytes.Buffer
+	if !options.Split {
+		doc.writeOverview(&buf, parser)
+		for _, apiKey := range apiKeys {
+			doc.writeApi(&buf, apiKey, parser.TopLevelApis[apiKey])
+		}
+		doc.writeModels(&buf, models)
+		return doc.writeFile(indexFile, &buf)
+	}
+	if includer, ok := markup.(includer); ok {
+		return doc.writeIncludes(includer, indexFile, parser, defaultFileExtension)
+	}
+
+	for _, apiKey := range apiKeys {
+		fileName := resourceFileName(apiKey) + defaultFileExtension
+		doc.anchorFiles[apiKey] = fileName
+		for _, subapi := range parser.TopLevelApis[apiKey].Apis {
+			for _, op := range subapi.Operations {
+				doc.anchorFiles[op.Nickname] = fileName
+			}
+		}
+	}
+	doc.anchorFiles[MODELS_ANCHOR] = MODELS_FILE + defaultFileExtension
+	for modelKey := range models {
+		doc.anchorFiles[modelKey] = MODELS_FILE + defaultFileExtension
+	}
+	doc.writeOverview(&buf, parser)
+	if err := doc.writeFile(indexFile, &buf); err != nil {
+		return err
+	}
+
+	doc.levelOffset = 1
+	for _, apiKey := range apiKeys {
+		buf.Reset()
+		doc.currentFile = doc.anchorFiles[apiKey]
+		doc.writeApi(&buf, apiKey, parser.TopLevelApis[apiKey])
+		if err := doc.writeFile(path.Join(path.Dir(indexFile), doc.currentFile), &buf); err != nil {
+			return err
+		}
+	}
+	buf.Reset()
+	doc.currentFile = MODELS_FILE + defaultFileExtension
+	doc.writeModels(&buf, models)
+	return doc.writeFile(path.Join(path.Dir(indexFile), doc.currentFile), &buf)
+}
+
+// writeIncludes writes the master document indexFile, with the overview, including a file per resource
+// and, in the MODELS_FILE directory, a file per model. Links stay within the document.
+func (doc *document) writeIncludes(includer includer, indexFile string, p *parser.Parser, defaultFileExtension string) error {
+	dir := path.Dir(indexFile)
+	var master, buf bytes.Buffer
+	doc.writeOverview(&master, p)
+
+	for _, apiKey := range alphabeticalKeysOfApiDeclaration(p.TopLevelApis) {
+		fileName := resourceFileName(apiKey) + defaultFileExtension
+		buf.Reset()
+		doc.writeApi(&buf, apiKey, p.TopLevelApis[apiKey])
+		if err := doc.writeFile(path.Join(dir, fileName), &buf); err != nil {
+			return err
+		}
+		master.WriteString(includer.include(fileName))
+	}
+
+	models := p.GetModels()
+	doc.writeModelsHeader(&master)
+	if len(models) > 0 {
+		if err := output.MkdirAll(path.Join(dir, MODELS_FILE), output.DirMode); err != nil {
+			return fmt.Errorf("Can not create models directory: %v\n", err)
+		}
+	}
+	for _, modelKey := range alphabeticalKeysOfModels(models) {
+		fileName := path.Join(MODELS_FILE, parser.ShortModelName(modelKey)+defaultFileExtension)
+		if sameShortName(models, modelKey) {
+			fileName = path.Join(MODELS_FILE, modelKey+defaultFileExtension)
+		}
+		buf.Reset()
+		doc.writeModel(&buf, modelKey, models[modelKey])
+		if err := doc.writeFile(path.Join(dir, fileName), &buf); err != nil {
+			return err
+		}
+		master.WriteString(includer.include(fileName))
+	}
+	return doc.writeFile(indexFile, &master)
+}
+
+// sameShortName tells if another model has the short name of modelKey
+func sameShortName(models map[string]*parser.Model, modelKey string) bool {
+	for otherKey := range models {
+		if otherKey != modelKey && parser.ShortModelName(otherKey) == parser.ShortModelName(modelKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// listCloser is a Markup whose lists must be closed, at the end of the files
+type listCloser interface {
+	closeLists() string
+}
+
+// writeFile creates the file fileName with the content of buf
+func (doc *document) writeFile(fileName string, buf *bytes.Buffer) error {
+	if closer, ok := doc.markup.(listCloser); ok {
+		buf.WriteString(closer.closeLists())
+	}
+	return writeMarkupFile(fileName, buf.Bytes())
+}
+
+// writeMarkupFile creates the file fileName with content
+func writeMarkupFile(fileName string, content []byte) error {
+	fd, err := output.Create(fileName)
 	if err != nil {
 		return fmt.Errorf("Can not create document file: %v\n", err)
 	}
 	defer fd.Close()
+	if _, err := fd.Write(content); err != nil {
+		return fmt.Errorf("Can not write document file: %v\n", err)
+	}
+	return nil
+}
 
-	var buf bytes.Buffer
+// writeOverview writes the title and the description of the API and the table of contents
+func (doc *document) writeOverview(buf *bytes.Buffer, parser *parser.Parser) {
+	markup, text := doc.markup, doc.text
 
 	/***************************************************************
 	* Overall API
 	***************************************************************/
-	buf.WriteString(markup.sectionHeader(1, parser.Listing.Infos.Title))
-	buf.WriteString(fmt.Sprintf("%s\n\n", parser.Listing.Infos.Description))
+	buf.WriteString(markup.sectionHeader(1, markup.escape(parser.Listing.Infos.Title)))
+	buf.WriteString(fmt.Sprintf("%s\n\n", markup.paragraph(parser.Listing.Infos.Description)))
+	for _, line := range parser.Listing.Infos.Lines() {
+		buf.WriteString(fmt.Sprintf("%s\n\n", markup.paragraph(line)))
+	}
 
 	/***************************************************************
-	* Table of Contents (List of Sub-APIs)
+	* Table of Contents (List of Sub-APIs and of their operations)
 	***************************************************************/
-	buf.WriteString("Table of Contents\n\n")
+	buf.WriteString(markup.paragraph(doc.options.Translations.text("Table of Contents")) + "\n\n")
 	subApiKeys, subApiKeyIndex := alphabeticalKeysOfSubApis(parser.Listing.Apis)
 	for _, subApiKey := range subApiKeys {
-		buf.WriteString(markup.numberedItem(1, markup.link(subApiKey, parser.Listing.Apis[subApiKeyIndex[subApiKey]].Description)))
+		item := doc.link(subApiKey, markup.escape(subApiKey))
+		if description := parser.Listing.Apis[subApiKeyIndex[subApiKey]].Description; description != "" {
+			item += " - " + markup.escape(description)
+		}
+		buf.WriteString(markup.numberedItem(1, item))
+		if apiDescription, ok := parser.TopLevelApis[subApiKey]; ok {
+			for _, subapi := range apiDescription.Apis {
+				for _, op := range subapi.Operations {
+					buf.WriteString(markup.bulletedItem(2, doc.link(op.Nickname, op.HttpMethod+" "+markup.escapePath(subapi.Path))))
+				}
+			}
+		}
 	}
+	buf.WriteString(markup.numberedItem(1, doc.link(MODELS_ANCHOR, text("Models"))))
 	buf.WriteString("\n")
+}
 
-	for _, apiKey := range alphabeticalKeysOfApiDeclaration(parser.TopLevelApis) {
-
-		apiDescription := parser.TopLevelApis[apiKey]
-		/***************************************************************
-		* Sub-API Specifications
-		***************************************************************/
-		buf.WriteString(markup.anchor(apiKey))
-		buf.WriteString(markup.sectionHeader(2, markup.colorSpan(apiKey, color_API_SECTION_HEADER_TEXT, color_NORMAL_BACKGROUND)))
-
-		buf.WriteString(markup.tableHeader(""))
-		buf.WriteString(markup.tableHeaderRow("Specification", "Value"))
-		buf.WriteString(markup.tableRow("Resource Path", apiDescription.ResourcePath))
-		buf.WriteString(markup.tableRow("API Version", apiDescription.ApiVersion))
-		buf.WriteString(markup.tableRow("BasePath for the API", apiDescription.BasePath))
-		buf.WriteString(markup.tableRow("Consumes", strings.Join(apiDescription.Consumes, ", ")))
-		buf.WriteString(markup.tableRow("Produces", strings.Join(apiDescription.Produces, ", ")))
-		buf.WriteString(markup.tableFooter())
-
-		/***************************************************************
-		* Sub-API Operations (Summary)
-		***************************************************************/
-		buf.WriteString("\n")
-		buf.WriteString(markup.sectionHeader(3, "Operations"))
-		buf.WriteString("\n")
-
-		buf.WriteString(markup.tableHeader(""))
-		buf.WriteString(markup.tableHeaderRow("Resource Path", "Operation", "Description"))
-		for _, subapi := range apiDescription.Apis {
-			for _, op := range subapi.Operations {
-				pathString := strings.Replace(strings.Replace(subapi.Path, "{", "\\{", -1), "}", "\\}", -1)
-				buf.WriteString(markup.tableRow(pathString, markup.link(op.Nickname, op.HttpMethod), op.Summary))
-			}
+// writeApi writes the specification of a resource and of its operations
+func (doc *document) writeApi(buf *bytes.Buffer, apiKey string, apiDescription *parser.ApiDeclaration) {
+	markup, text, options := doc.markup, doc.text, doc.options
+
+	/***************************************************************
+	* Sub-API Specifications
+	***************************************************************/
+	buf.WriteString(markup.anchor(apiKey))
+	buf.WriteString(doc.sectionHeader(2, markup.colorSpan(markup.escape(apiKey), color_API_SECTION_HEADER_TEXT, color_NORMAL_BACKGROUND)))
+
+	buf.WriteString(markup.tableHeader(""))
+	buf.WriteString(markup.tableHeaderRow(text("Specification"), text("Value")))
+	buf.WriteString(markup.tableRow(text("Resource Path"), markup.escape(apiDescription.ResourcePath)))
+	buf.WriteString(markup.tableRow(text("API Version"), markup.escape(apiDescription.ApiVersion)))
+	buf.WriteString(markup.tableRow(text("BasePath for the API"), markup.escape(apiDescription.BasePath)))
+	buf.WriteString(markup.tableRow(text("Consumes"), markup.escape(strings.Join(apiDescription.Consumes, ", "))))
+	buf.WriteString(markup.tableRow(text("Produces"), markup.escape(strings.Join(apiDescription.Produces, ", "))))
+	buf.WriteString(markup.tableFooter())
+
+	/***************************************************************
+	* Sub-API Operations (Summary)
+	***************************************************************/
+	buf.WriteString("\n")
+	buf.WriteString(doc.sectionHeader(3, text("Operations")))
+	buf.WriteString("\n")
+
+	buf.WriteString(markup.tableHeader(""))
+	buf.WriteString(markup.tableHeaderRow(text("Resource Path"), text("Operation"), text("Description")))
+	for _, subapi := range apiDescription.Apis {
+		for _, op := range subapi.Operations {
+			buf.WriteString(markup.tableRow(markup.escapePath(subapi.Path), doc.link(op.Nickname, op.HttpMethod), markup.escape(op.Summary)))
 		}
-		buf.WriteString(markup.tableFooter())
-		buf.WriteString("\n")
+	}
+	buf.WriteString(markup.tableFooter())
+	buf.WriteString("\n")
 
-		/***************************************************************
-		* Sub-API Operations (Details)
-		***************************************************************/
-		for _, subapi := range apiDescription.Apis {
-			for _, op := range subapi.Operations {
-				buf.WriteString("\n")
-				operationString := fmt.Sprintf("%s (%s)", strings.Replace(strings.Replace(subapi.Path, "{", "\\{", -1), "}", "\\}", -1), op.HttpMethod)
-				buf.WriteString(markup.anchor(op.Nickname))
-				buf.WriteString(markup.sectionHeader(4, markup.colorSpan("API: "+operationString, color_NORMAL_TEXT, operationColor(op.HttpMethod))))
-				buf.WriteString("\n\n" + op.Summary + "\n\n\n")
-
-				if len(op.Parameters) > 0 {
-					buf.WriteString(markup.tableHeader(""))
-					buf.WriteString(markup.tableHeaderRow("Param Name", "Param Type", "Data Type", "Description", "Required?"))
-					for _, param := range op.Parameters {
-						isRequired := ""
-						if param.Required {
-							isRequired = "Yes"
-						}
-						buf.WriteString(markup.tableRow(param.Name, param.ParamType, modelText(markup, param.DataType), param.Description, isRequired))
+	/***************************************************************
+	* Sub-API Operations (Details)
+	***************************************************************/
+	for _, subapi := range apiDescription.Apis {
+		for _, op := range subapi.Operations {
+			buf.WriteString("\n")
+			operationString := fmt.Sprintf("%s (%s)", markup.escapePath(subapi.Path), op.HttpMethod)
+			buf.WriteString(markup.anchor(op.Nickname))
+			buf.WriteString(doc.sectionHeader(4, markup.colorSpan(text("API")+": "+operationString, color_NORMAL_TEXT, operationColor(op.HttpMethod))))
+			buf.WriteString("\n\n" + markup.paragraph(op.Summary) + "\n\n\n")
+
+			if len(op.Parameters) > 0 {
+				buf.WriteString(markup.tableHeader(""))
+				buf.WriteString(markup.tableHeaderRow(text("Param Name"), text("Param Type"), text("Data Type"), text("Description"), text("Required?")))
+				for _, param := range op.Parameters {
+					isRequired := ""
+					if param.Required {
+						isRequired = text("Yes")
+					}
+					dataType := doc.modelText(param.DataType)
+					if param.ContentType != "" {
+						dataType += " (" + markup.escape(param.ContentType) + ")"
 					}
-					buf.WriteString(markup.tableFooter())
+					buf.WriteString(markup.tableRow(markup.escape(param.Name), param.ParamType, dataType, markup.escape(param.Description), isRequired))
 				}
+				buf.WriteString(markup.tableFooter())
+			}
 
-				if len(op.ResponseMessages) > 0 {
-					buf.WriteString(markup.tableHeader(""))
-					buf.WriteString(markup.tableHeaderRow("Code", "Type", "Model", "Message"))
-					for _, msg := range op.ResponseMessages {
-						buf.WriteString(markup.tableRow(fmt.Sprintf("%v", msg.Code), msg.ResponseType, modelText(markup, msg.ResponseModel), msg.Message))
-					}
-					buf.WriteString(markup.tableFooter())
+			if len(op.ResponseMessages) > 0 {
+				buf.WriteString(markup.tableHeader(""))
+				buf.WriteString(markup.tableHeaderRow(text("Code"), text("Type"), text("Model"), text("Message")))
+				for _, msg := range op.ResponseMessages {
+					buf.WriteString(markup.tableRow(fmt.Sprintf("%v", msg.Code), msg.ResponseType, doc.modelText(msg.ResponseModel), markup.escape(msg.Message)))
 				}
+				buf.WriteString(markup.tableFooter())
 			}
-		}
-		buf.WriteString("\n")
-
-		/***************************************************************
-		* Models
-		***************************************************************/
-		buf.WriteString("\n")
-		buf.WriteString(markup.sectionHeader(3, "Models"))
-		buf.WriteString("\n")
-
-		for _, modelKey := range alphabeticalKeysOfModels(apiDescription.Models) {
-			model := apiDescription.Models[modelKey]
-			buf.WriteString(markup.anchor(modelKey))
-			buf.WriteString(markup.sectionHeader(4, markup.colorSpan(shortModelName(modelKey), color_MODEL_TEXT, color_NORMAL_BACKGROUND)))
-			buf.WriteString(markup.tableHeader(""))
-			buf.WriteString(markup.tableHeaderRow("Field Name (alphabetical)", "Field Type", "Description"))
-			for _, fieldName := range alphabeticalKeysOfFields(model.Properties) {
-				fieldProps := model.Properties[fieldName]
-				buf.WriteString(markup.tableRow(fieldName, fieldProps.Type, fieldProps.Description))
+
+			if op.Websocket != nil {
+				doc.writeWebsocket(buf, op.Websocket)
+			}
+			if len(op.Events) > 0 {
+				doc.writeEvents(buf, op.Events)
+			}
+
+			if len(options.Samples) > 0 {
+				request := newSampleRequest(apiDescription, options.BaseUrl, subapi.Path, op)
+				for _, language := range options.Samples {
+					sample := samples[language]
+					buf.WriteString(markup.paragraph(doc.options.Translations.text("Example request")+" ("+sample.title+"):") + "\n")
+					buf.WriteString(markup.codeBlock(sample.language, sample.render(request)))
+				}
 			}
-			buf.WriteString(markup.tableFooter())
 		}
-		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+}
 
+// writeWebsocket writes the messages exchanged on the WebSocket of an operation, those sent by the
+// client first
+func (doc *document) writeWebsocket(buf *bytes.Buffer, websocket *parser.Websocket) {
+	markup, text := doc.markup, doc.text
+	buf.WriteString("\n")
+	buf.WriteString(doc.sectionHeader(5, text("WebSocket messages")))
+	buf.WriteString("\n")
+	buf.WriteString(markup.tableHeader(""))
+	buf.WriteString(markup.tableHeaderRow(text("Direction"), text("Model"), text("Description")))
+	for _, message := range websocket.Client {
+		buf.WriteString(markup.tableRow(text("Client to server"), doc.modelText(message.Model), markup.escape(message.Description)))
 	}
+	for _, message := range websocket.Server {
+		buf.WriteString(markup.tableRow(text("Server to client"), doc.modelText(message.Model), markup.escape(message.Description)))
+	}
+	buf.WriteString(markup.tableFooter())
+}
 
-	fd.WriteString(buf.String())
+// writeEvents writes the events of the Server-Sent Events stream of an operation
+func (doc *document) writeEvents(buf *bytes.Buffer, events []parser.ServerSentEvent) {
+	markup, text := doc.markup, doc.text
+	buf.WriteString("\n")
+	buf.WriteString(doc.sectionHeader(5, text("Server-Sent Events")))
+	buf.WriteString("\n")
+	buf.WriteString(markup.tableHeader(""))
+	buf.WriteString(markup.tableHeaderRow(text("Event"), text("Model"), text("Description")))
+	for _, event := range events {
+		buf.WriteString(markup.tableRow(markup.escape(event.Name), doc.modelText(event.Model), markup.escape(event.Description)))
+	}
+	buf.WriteString(markup.tableFooter())
+}
 
-	return nil
+// writeModels writes the models, shared by the Sub-APIs so each one is documented once
+func (doc *document) writeModels(buf *bytes.Buffer, models map[string]*parser.Model) {
+	doc.writeModelsHeader(buf)
+	for _, modelKey := range alphabeticalKeysOfModels(models) {
+		doc.writeModel(buf, modelKey, models[modelKey])
+	}
+	buf.WriteString("\n")
 }
 
-func shortModelName(longModelName string) string {
-	parts := strings.Split(longModelName, ".")
-	return parts[len(parts)-1]
+func (doc *document) writeModelsHeader(buf *bytes.Buffer) {
+	buf.WriteString("\n")
+	buf.WriteString(doc.markup.anchor(MODELS_ANCHOR))
+	buf.WriteString(doc.sectionHeader(2, doc.text("Models")))
+	buf.WriteString("\n")
 }
 
-func modelText(markup Markup, fullyQualifiedModelName string) string {
-	shortName := shortModelName(fullyQualifiedModelName)
-	result := shortName
-	if fullyQualifiedModelName != shortName {
-		result = markup.link(fullyQualifiedModelName, shortName)
+func (doc *document) writeModel(buf *bytes.Buffer, modelKey string, model *parser.Model) {
+	markup, text := doc.markup, doc.text
+	buf.WriteString(markup.anchor(modelKey))
+	buf.WriteString(doc.sectionHeader(4, markup.colorSpan(markup.escape(parser.ShortModelName(modelKey)), color_MODEL_TEXT, color_NORMAL_BACKGROUND)))
+	if model.Description != "" {
+		buf.WriteString("\n" + markup.paragraph(model.Description) + "\n")
+	}
+	buf.WriteString(markup.tableHeader(""))
+	buf.WriteString(markup.tableHeaderRow(text("Field Name (alphabetical)"), text("Field Type"), text("Description")))
+	for _, fieldName := range alphabeticalKeysOfFields(model.Properties) {
+		fieldProps := model.Properties[fieldName]
+		buf.WriteString(markup.tableRow(markup.escape(fieldName), doc.modelText(propertyType(fieldProps)), markup.escape(fieldDescription(fieldProps, doc.options.Translations))))
+	}
+	buf.WriteString(markup.tableFooter())
+}
+
+// fieldDescription is the description of a model property, with its read and write only flags
+func fieldDescription(property *parser.ModelProperty, translations Translations) string {
+	description := property.Description
+	if property.ReadOnly {
+		description = strings.TrimSpace(description + " (" + translations.text("read only") + ")")
+	}
+	if property.WriteOnly {
+		description = strings.TrimSpace(description + " (" + translations.text("write only") + ")")
+	}
+	return description
+}
+
+// propertyType is the type of a model property, array[<type of the items>] for arrays
+func propertyType(property *parser.ModelProperty) string {
+	if property.Type != "array" {
+		return property.Type
+	}
+	itemType := property.Items.Ref
+	if itemType == "" {
+		itemType = property.Items.Type
 	}
-	return result
+	if itemType == "" {
+		return property.Type
+	}
+	return "array[" + itemType + "]"
+}
+
+// escapePath escapes the braces of the path variables, which are markup in the confluence format
+func escapePath(path string) string {
+	return strings.Replace(strings.Replace(path, "{", "\\{", -1), "}", "\\}", -1)
+}
+
+// modelText is the short name of a model, linked to its definition, or the name of a basic type.
+// Arrays of models, array[<model>], are rendered as <model>[].
+func modelText(markup Markup, fullyQualifiedModelName string) string {
+	doc := &document{markup: markup}
+	return doc.modelText(fullyQualifiedModelName)
 }
 
 func alphabeticalKeysOfSubApis(refs []*parser.ApiRef) ([]string, map[string]int) {
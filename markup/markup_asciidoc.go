@@ -71,3 +71,33 @@ func (this *MarkupAsciiDoc) tableRow(args ...string) string {
 func (this *MarkupAsciiDoc) colorSpan(content, foregroundColor, backgroundColor string) string {
 	return fmt.Sprintf("[%s,%s-background]#%s#", foregroundColor, backgroundColor, content)
 }
+
+// codeBlock renders code as a listing block, highlighted as language
+func (this *MarkupAsciiDoc) codeBlock(language, code string) string {
+	return fmt.Sprintf("\n[source,%s]\n----\n%s\n----\n\n", language, code)
+}
+
+// fileLink renders the linkText as a cross reference to the anchorName of the document fileName
+func (this *MarkupAsciiDoc) fileLink(fileName, anchorName, linkText string) string {
+	return fmt.Sprintf("<<%s#%s,%s>>", fileName, anchorName, linkText)
+}
+
+// include renders an include directive of the document fileName
+func (this *MarkupAsciiDoc) include(fileName string) string {
+	return fmt.Sprintf("\ninclude::%s[]\n", fileName)
+}
+
+// escape returns text: it has no markup to escape
+func (this *MarkupAsciiDoc) escape(text string) string {
+	return text
+}
+
+// escapePath escapes the braces of the path variables
+func (this *MarkupAsciiDoc) escapePath(path string) string {
+	return escapePath(path)
+}
+
+// paragraph returns text, the paragraphs are separated by empty lines
+func (this *MarkupAsciiDoc) paragraph(text string) string {
+	return text
+}
@@ -77,3 +77,35 @@ func (this *MarkupConfluence) colorSpan(content, foregroundColor, backgroundColo
 	return fmt.Sprintf("{color:%s}{bgcolor:%s}%s{bgcolor}{color}", foregroundColor, backgroundColor, content)
 
 }
+
+// codeBlock renders code with the code macro, highlighted as language. The macro has its own names of
+// some languages.
+func (this *MarkupConfluence) codeBlock(language, code string) string {
+	switch language {
+	case "sh":
+		language = "bash"
+	case "javascript":
+		language = "js"
+	}
+	return fmt.Sprintf("\n{code:language=%s}\n%s\n{code}\n\n", language, code)
+}
+
+// fileLink renders a link to anchorName: a confluence page is never split, so all anchors are in it
+func (this *MarkupConfluence) fileLink(fileName, anchorName, linkText string) string {
+	return this.link(anchorName, linkText)
+}
+
+// escape returns text: it has no markup to escape
+func (this *MarkupConfluence) escape(text string) string {
+	return text
+}
+
+// escapePath escapes the braces of the path variables
+func (this *MarkupConfluence) escapePath(path string) string {
+	return escapePath(path)
+}
+
+// paragraph returns text, the paragraphs are separated by empty lines
+func (this *MarkupConfluence) paragraph(text string) string {
+	return text
+}
@@ -0,0 +1,132 @@
+package markup
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// MarkupConfluenceStorage renders the Confluence storage format: XHTML with structured macros, which the
+// Confluence REST API and editors accept as is, unlike wiki markup which must be converted
+type MarkupConfluenceStorage struct {
+	lists []string // ol or ul, the open lists of each item level
+}
+
+// listItem renders an item of an ol or ul list at the given level, opening and closing the lists as needed
+func (this *MarkupConfluenceStorage) listItem(level int, tag string, text string) string {
+	var buf strings.Builder
+	for len(this.lists) > level || len(this.lists) == level && this.lists[level-1] != tag {
+		buf.WriteString("</li></" + this.lists[len(this.lists)-1] + ">")
+		this.lists = this.lists[:len(this.lists)-1]
+	}
+	if len(this.lists) == level {
+		buf.WriteString("</li>")
+	}
+	for len(this.lists) < level {
+		buf.WriteString("<" + tag + ">")
+		this.lists = append(this.lists, tag)
+	}
+	buf.WriteString("<li>" + text)
+	return buf.String()
+}
+
+// closeLists closes the open lists, before any other element
+func (this *MarkupConfluenceStorage) closeLists() string {
+	var buf strings.Builder
+	for len(this.lists) > 0 {
+		buf.WriteString("</li></" + this.lists[len(this.lists)-1] + ">\n")
+		this.lists = this.lists[:len(this.lists)-1]
+	}
+	return buf.String()
+}
+
+// anchor renders an anchor macro
+func (this *MarkupConfluenceStorage) anchor(anchorName string) string {
+	return fmt.Sprintf("%s<ac:structured-macro ac:name=\"anchor\"><ac:parameter ac:name=\"\">%s</ac:parameter></ac:structured-macro>\n", this.closeLists(), html.EscapeString(anchorName))
+}
+
+// sectionHeader renders a title (level 1) or subtitle (level 2..5)
+func (this *MarkupConfluenceStorage) sectionHeader(level int, text string) string {
+	return fmt.Sprintf("%s<h%d>%s</h%d>\n", this.closeLists(), level, text, level)
+}
+
+// numberedItem renders a numbered item at the given level
+func (this *MarkupConfluenceStorage) numberedItem(level int, text string) string {
+	return this.listItem(level, "ol", text)
+}
+
+// bulletedItem renders a bulleted item at the given level
+func (this *MarkupConfluenceStorage) bulletedItem(level int, text string) string {
+	return this.listItem(level, "ul", text)
+}
+
+// link renders the linkText as a link to the specified anchorName. If linktext is "", then anchorName is used as the linkText.
+func (this *MarkupConfluenceStorage) link(anchorName, linkText string) string {
+	if linkText == "" {
+		linkText = html.EscapeString(anchorName)
+	}
+	return fmt.Sprintf("<ac:link ac:anchor=\"%s\"><ac:link-body>%s</ac:link-body></ac:link>", html.EscapeString(anchorName), linkText)
+}
+
+// fileLink renders a link to anchorName: a confluence page is never split, so all anchors are in it
+func (this *MarkupConfluenceStorage) fileLink(fileName, anchorName, linkText string) string {
+	return this.link(anchorName, linkText)
+}
+
+// tableHeader starts a table
+func (this *MarkupConfluenceStorage) tableHeader(tableTitle string) string {
+	return this.closeLists() + "<table><tbody>\n"
+}
+
+// tableFooter ends a table
+func (this *MarkupConfluenceStorage) tableFooter() string {
+	return "</tbody></table>\n"
+}
+
+// tableHeaderRow issues a table header row
+func (this *MarkupConfluenceStorage) tableHeaderRow(args ...string) string {
+	return "<tr><th>" + strings.Join(args, "</th><th>") + "</th></tr>\n"
+}
+
+// tableRow issues a single table data row
+func (this *MarkupConfluenceStorage) tableRow(args ...string) string {
+	return "<tr><td>" + strings.Join(args, "</td><td>") + "</td></tr>\n"
+}
+
+func (this *MarkupConfluenceStorage) colorSpan(content, foregroundColor, backgroundColor string) string {
+	if foregroundColor == "black" && backgroundColor == "white" {
+		return content
+	}
+	return fmt.Sprintf("<span style=\"color: %s; background-color: %s;\">%s</span>", foregroundColor, backgroundColor, content)
+}
+
+// codeBlock renders code with the code macro, highlighted as language
+func (this *MarkupConfluenceStorage) codeBlock(language, code string) string {
+	switch language {
+	case "sh":
+		language = "bash"
+	case "javascript":
+		language = "js"
+	}
+	// ]]> ends the CDATA section, it is split over two sections
+	code = strings.Replace(code, "]]>", "]]]]><![CDATA[>", -1)
+	return fmt.Sprintf("%s<ac:structured-macro ac:name=\"code\"><ac:parameter ac:name=\"language\">%s</ac:parameter><ac:plain-text-body><![CDATA[%s]]></ac:plain-text-body></ac:structured-macro>\n", this.closeLists(), language, code)
+}
+
+// escape escapes the XML special characters of text
+func (this *MarkupConfluenceStorage) escape(text string) string {
+	return html.EscapeString(text)
+}
+
+// escapePath escapes the XML special characters of path, its variables are not markup
+func (this *MarkupConfluenceStorage) escapePath(path string) string {
+	return html.EscapeString(path)
+}
+
+// paragraph renders text in a p element, nothing if it is empty
+func (this *MarkupConfluenceStorage) paragraph(text string) string {
+	if text == "" {
+		return this.closeLists()
+	}
+	return this.closeLists() + "<p>" + html.EscapeString(text) + "</p>"
+}
@@ -70,3 +70,28 @@ func (this *MarkupMarkDown) tableFooter() string {
 func (this *MarkupMarkDown) colorSpan(content, foregroundColor, backgroundColor string) string {
 	return content
 }
+
+// codeBlock renders code as a fenced code block, highlighted as language
+func (this *MarkupMarkDown) codeBlock(language, code string) string {
+	return fmt.Sprintf("\n```%s\n%s\n```\n\n", language, code)
+}
+
+// fileLink renders the linkText as a link to the anchorName of the file fileName
+func (this *MarkupMarkDown) fileLink(fileName, anchorName, linkText string) string {
+	return fmt.Sprintf("[%s](%s#%s)", linkText, fileName, anchorName)
+}
+
+// escape returns text: it has no markup to escape
+func (this *MarkupMarkDown) escape(text string) string {
+	return text
+}
+
+// escapePath escapes the braces of the path variables
+func (this *MarkupMarkDown) escapePath(path string) string {
+	return escapePath(path)
+}
+
+// paragraph returns text, the paragraphs are separated by empty lines
+func (this *MarkupMarkDown) paragraph(text string) string {
+	return text
+}
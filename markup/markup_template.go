@@ -0,0 +1,91 @@
+package markup
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// MarkupTemplateData is the data the markup templates are executed with
+type MarkupTemplateData struct {
+	Listing *parser.ResourceListing
+	Apis    map[string]*parser.ApiDeclaration
+	BaseUrl string
+}
+
+// TemplateFuncs returns the functions available in markup templates: the formatting primitives of markup
+// (sectionHeader, tableRow, link, ...) and helpers to iterate over the parsed spec in a stable order.
+// curl and sample return the CurlCommand and the Sample of an operation, calling the server of options.BaseUrl,
+// and text translates the English texts of the built-in layout with options.Translations.
+func TemplateFuncs(markup Markup, options Options) template.FuncMap {
+	return template.FuncMap{
+		"sectionHeader":  markup.sectionHeader,
+		"bulletedItem":   markup.bulletedItem,
+		"numberedItem":   markup.numberedItem,
+		"anchor":         markup.anchor,
+		"link":           markup.link,
+		"tableHeader":    markup.tableHeader,
+		"tableHeaderRow": markup.tableHeaderRow,
+		"tableRow":       markup.tableRow,
+		"tableFooter":    markup.tableFooter,
+		"colorSpan":      markup.colorSpan,
+		"codeBlock":      markup.codeBlock,
+		"curl": func(api *parser.ApiDeclaration, apiPath string, op *parser.Operation) string {
+			return CurlCommand(api, options.BaseUrl, apiPath, op)
+		},
+		"sample": func(language string, api *parser.ApiDeclaration, apiPath string, op *parser.Operation) (string, error) {
+			return Sample(language, api, options.BaseUrl, apiPath, op)
+		},
+		"text": options.Translations.text,
+		"modelText": func(fullyQualifiedModelName string) string {
+			return modelText(markup, fullyQualifiedModelName)
+		},
+		"shortModelName": parser.ShortModelName,
+		"operationColor": operationColor,
+		"apiKeys":        alphabeticalKeysOfApiDeclaration,
+		"modelKeys":      alphabeticalKeysOfModels,
+		"fieldKeys":      alphabeticalKeysOfFields,
+		"escapePath":     markup.escapePath,
+		"escape":         markup.escape,
+		"paragraph":      markup.paragraph,
+		"propertyType":   propertyType,
+		"join":           strings.Join,
+		"str":            func(v interface{}) string { return fmt.Sprintf("%v", v) },
+	}
+}
+
+// GenerateMarkupFromTemplate renders the documentation with the user supplied text/template in templateFile
+// instead of the built-in layout. funcs are added to (and override) TemplateFuncs(markup, options).
+func GenerateMarkupFromTemplate(parser *parser.Parser, markup Markup, options Options, templateFile string, funcs template.FuncMap, outputSpec *string, defaultFileExtension string) error {
+	templateText, err := ioutil.ReadFile(templateFile)
+	if err != nil {
+		return fmt.Errorf("Can not read markup template: %v\n", err)
+	}
+
+	tmpl := template.New(templateFile).Funcs(TemplateFuncs(markup, options))
+	if funcs != nil {
+		tmpl = tmpl.Funcs(funcs)
+	}
+	if tmpl, err = tmpl.Parse(string(templateText)); err != nil {
+		return fmt.Errorf("Can not parse markup template: %v\n", err)
+	}
+
+	fd, err := createMarkupFile(outputSpec, defaultFileExtension)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	data := MarkupTemplateData{
+		Listing: parser.Listing,
+		Apis:    parser.TopLevelApis,
+		BaseUrl: options.BaseUrl,
+	}
+	if err := tmpl.Execute(fd, data); err != nil {
+		return fmt.Errorf("Can not execute markup template: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package markup
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// AVAILABLE_SAMPLES are the languages of the request samples, the values of -samples
+const AVAILABLE_SAMPLES = "curl|go|js|python"
+
+// TOKEN_VARIABLE is the variable of the bearer token in the request samples: an environment variable for
+// curl, Go and Python, a constant to define for JavaScript
+const TOKEN_VARIABLE = "API_TOKEN"
+
+// sample renders a request in a language
+type sample struct {
+	title    string
+	language string // language of the code block
+	render   func(request *sampleRequest) string
+}
+
+var samples = map[string]sample{
+	"curl":   {"curl", "sh", curlSample},
+	"go":     {"Go", "go", goSample},
+	"js":     {"JavaScript", "javascript", jsSample},
+	"python": {"Python", "python", pythonSample},
+}
+
+// ParseSamples checks a comma separated list of sample languages, none or "" for no samples
+func ParseSamples(list string) ([]string, error) {
+	var languages []string
+	for _, language := range strings.Split(list, ",") {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if language == "" || language == "none" {
+			continue
+		}
+		if _, ok := samples[language]; !ok {
+			return nil, fmt.Errorf("Invalid -samples specified: %s. Must be a list of %v.", language, AVAILABLE_SAMPLES)
+		}
+		languages = append(languages, language)
+	}
+	return languages, nil
+}
+
+// header is a request header, in the order of the parameters
+type header struct {
+	name, value string
+}
+
+// formField is a multipart form field, a file field is sent with the content of the file named like it
+type formField struct {
+	name, value string
+	isFile      bool
+	contentType string // first content type of the part, "" for the default one
+}
+
+// sampleRequest is an example request of an operation, as rendered by the samples
+type sampleRequest struct {
+	method  string
+	url     string
+	headers []header
+	form    []formField
+	body    interface{} // example of the JSON body, nil without body
+}
+
+// newSampleRequest returns the request calling op, at apiPath of api, on the server at baseUrl. Path and
+// required query parameters, headers and bodies get example values.
+func newSampleRequest(api *parser.ApiDeclaration, baseUrl string, apiPath string, op *parser.Operation) *sampleRequest {
+	request := &sampleRequest{method: strings.ToUpper(op.HttpMethod)}
+	if len(op.Produces) > 0 {
+		request.headers = append(request.headers, header{"Accept", op.Produces[0]})
+	}
+	query := url.Values{}
+	for _, param := range op.Parameters {
+		example := api.ExampleString(param.DataType)
+		switch param.ParamType {
+		case "path":
+			apiPath = strings.Replace(apiPath, "{"+param.Name+"}", url.PathEscape(example), -1)
+		case "query":
+			if param.Required {
+				query.Add(param.Name, example)
+			}
+		case "header":
+			request.headers = append(request.headers, header{param.Name, example})
+		case "form":
+			isFile := strings.ToLower(param.DataType) == "file"
+			if isFile {
+				example = param.Name
+			}
+			contentType := strings.TrimSpace(strings.Split(param.ContentType, ",")[0])
+			request.form = append(request.form, formField{param.Name, example, isFile, contentType})
+		case "body":
+			request.body = api.ExampleValue(param.DataType)
+			request.headers = append(request.headers, header{"Content-Type", parser.ContentTypeJson})
+		}
+	}
+
+	request.url = strings.TrimSuffix(baseUrl, "/") + apiPath
+	if len(query) > 0 {
+		request.url += "?" + query.Encode()
+	}
+	return request
+}
+
+func (request *sampleRequest) hasBody() bool {
+	return request.body != nil || len(request.form) > 0
+}
+
+func (request *sampleRequest) jsonBody(indent string) string {
+	raw, _ := json.MarshalIndent(request.body, "", "  ")
+	return strings.Replace(string(raw), "\n", "\n"+indent, -1)
+}
+
+// CurlCommand returns a curl command calling op, at apiPath of api, on the server at baseUrl, authenticated
+// with the bearer token of $API_TOKEN
+func CurlCommand(api *parser.ApiDeclaration, baseUrl string, apiPath string, op *parser.Operation) string {
+	return curlSample(newSampleRequest(api, baseUrl, apiPath, op))
+}
+
+// Sample returns the request sample of op, at apiPath of api, in language, one of AVAILABLE_SAMPLES
+func Sample(language string, api *parser.ApiDeclaration, baseUrl string, apiPath string, op *parser.Operation) (string, error) {
+	sample, ok := samples[language]
+	if !ok {
+		return "", fmt.Errorf("Unknown sample language %s, expected one of %v", language, AVAILABLE_SAMPLES)
+	}
+	return sample.render(newSampleRequest(api, baseUrl, apiPath, op)), nil
+}
+
+// curlType returns the ;type= of a curl -F part with contentType, "" for the default one
+func curlType(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	return ";type=" + contentType
+}
+
+func curlSample(request *sampleRequest) string {
+	lines := []string{"curl"}
+	if request.method != "GET" || request.hasBody() {
+		lines[0] += " -X " + request.method
+	}
+	lines[0] += " " + shellQuote(request.url)
+	lines = append(lines, fmt.Sprintf("-H \"Authorization: Bearer $%s\"", TOKEN_VARIABLE))
+	for _, header := range request.headers {
+		lines = append(lines, "-H "+shellQuote(header.name+": "+header.value))
+	}
+	for _, field := range request.form {
+		if field.isFile {
+			lines = append(lines, "-F "+shellQuote(field.name+"=@"+field.value+curlType(field.contentType)))
+		} else {
+			lines = append(lines, "-F "+shellQuote(field.name+"="+field.value+curlType(field.contentType)))
+		}
+	}
+	if request.body != nil {
+		lines = append(lines, "-d "+shellQuote(request.jsonBody("")))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+// goSample is a snippet of a main function, with the net/http package
+func goSample(request *sampleRequest) string {
+	var lines []string
+	body := "nil"
+	if request.body != nil {
+		lines = append(lines, "body := strings.NewReader("+goString(request.jsonBody(""))+")")
+		body = "body"
+	} else if len(request.form) > 0 {
+		lines = append(lines, "var body bytes.Buffer", "form := multipart.NewWriter(&body)")
+		for _, field := range request.form {
+			if field.isFile {
+				lines = append(lines,
+					fmt.Sprintf("part, _ := form.CreateFormFile(%q, %q)", field.name, field.value),
+					fmt.Sprintf("file, _ := os.Open(%q)", field.value),
+					"io.Copy(part, file)",
+					"file.Close()")
+			} else {
+				lines = append(lines, fmt.Sprintf("form.WriteField(%q, %q)", field.name, field.value))
+			}
+		}
+		lines = append(lines, "form.Close()")
+		body = "&body"
+	}
+	lines = append(lines,
+		fmt.Sprintf("request, err := http.NewRequest(%q, %q, %s)", request.method, request.url, body),
+		"if err != nil {",
+		"\tlog.Fatal(err)",
+		"}",
+		fmt.Sprintf("request.Header.Set(\"Authorization\", \"Bearer \"+os.Getenv(%q))", TOKEN_VARIABLE))
+	for _, header := range request.headers {
+		lines = append(lines, fmt.Sprintf("request.Header.Set(%q, %q)", header.name, header.value))
+	}
+	if len(request.form) > 0 {
+		lines = append(lines, "request.Header.Set(\"Content-Type\", form.FormDataContentType())")
+	}
+	lines = append(lines,
+		"response, err := http.DefaultClient.Do(request)",
+		"if err != nil {",
+		"\tlog.Fatal(err)",
+		"}",
+		"defer response.Body.Close()")
+	return strings.Join(lines, "\n")
+}
+
+// goString quotes s as a raw string literal if it can be one
+func goString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+// jsSample uses the Fetch API, in an async function. API_TOKEN is a constant of the reader.
+func jsSample(request *sampleRequest) string {
+	var lines []string
+	if request.body == nil && len(request.form) > 0 {
+		lines = append(lines, "const form = new FormData();")
+		for _, field := range request.form {
+			if field.isFile {
+				lines = append(lines, fmt.Sprintf("form.append(%q, document.querySelector(%q).files[0]);", field.name, "input[name="+field.value+"]"))
+			} else {
+				lines = append(lines, fmt.Sprintf("form.append(%q, %q);", field.name, field.value))
+			}
+		}
+	}
+	lines = append(lines,
+		fmt.Sprintf("const response = await fetch(%q, {", request.url),
+		fmt.Sprintf("  method: %q,", request.method),
+		"  headers: {",
+		fmt.Sprintf("    \"Authorization\": \"Bearer \" + %s,", TOKEN_VARIABLE))
+	for _, header := range request.headers {
+		lines = append(lines, fmt.Sprintf("    %q: %q,", header.name, header.value))
+	}
+	lines = append(lines, "  },")
+	if request.body != nil {
+		lines = append(lines, "  body: JSON.stringify("+request.jsonBody("  ")+"),")
+	} else if len(request.form) > 0 {
+		lines = append(lines, "  body: form,")
+	}
+	lines = append(lines, "});", "console.log(response.status, await response.text());")
+	return strings.Join(lines, "\n")
+}
+
+// pythonSample uses the requests library
+func pythonSample(request *sampleRequest) string {
+	lines := []string{"import os", "", "import requests", "", "response = requests.request("}
+	lines = append(lines,
+		fmt.Sprintf("    %q,", request.method),
+		fmt.Sprintf("    %q,", request.url),
+		"    headers={",
+		fmt.Sprintf("        \"Authorization\": \"Bearer \" + os.environ[%q],", TOKEN_VARIABLE))
+	for _, header := range request.headers {
+		if header.name != "Content-Type" {
+			lines = append(lines, fmt.Sprintf("        %q: %q,", header.name, header.value))
+		}
+	}
+	lines = append(lines, "    },")
+	if request.body != nil {
+		lines = append(lines, "    json="+pythonValue(request.body, "    ")+",")
+	}
+	var data, files []string
+	for _, field := range request.form {
+		if field.isFile {
+			files = append(files, fmt.Sprintf("%q: open(%q, \"rb\")", field.name, field.value))
+		} else {
+			data = append(data, fmt.Sprintf("%q: %q", field.name, field.value))
+		}
+	}
+	if len(data) > 0 {
+		lines = append(lines, "    data={"+strings.Join(data, ", ")+"},")
+	}
+	if len(files) > 0 {
+		lines = append(lines, "    files={"+strings.Join(files, ", ")+"},")
+	}
+	lines = append(lines, ")", "print(response.status_code, response.text)")
+	return strings.Join(lines, "\n")
+}
+
+// pythonValue writes an example value as a Python literal
+func pythonValue(value interface{}, indent string) string {
+	switch value := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if value {
+			return "True"
+		}
+		return "False"
+	case string:
+		return strconv.Quote(value)
+	case []interface{}:
+		if len(value) == 0 {
+			return "[]"
+		}
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = indent + "    " + pythonValue(item, indent+"    ") + ","
+		}
+		return "[\n" + strings.Join(items, "\n") + "\n" + indent + "]"
+	case map[string]interface{}:
+		if len(value) == 0 {
+			return "{}"
+		}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = indent + "    " + strconv.Quote(key) + ": " + pythonValue(value[key], indent+"    ") + ","
+		}
+		return "{\n" + strings.Join(items, "\n") + "\n" + indent + "}"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// shellQuote quotes s for sh: single quotes, with the single quotes of s closed, escaped and reopened
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
@@ -0,0 +1,82 @@
+// Package merge combines the specs of several services into one spec, e.g. for an API gateway
+package merge
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// Service is the spec of one service. Its resources are prefixed with Name in the merged spec
+type Service struct {
+	Name    string
+	Listing *parser.ResourceListing
+	Apis    map[string]*parser.ApiDeclaration
+}
+
+// ReadServices reads the specs of specList: comma separated directories written by -format=swagger,
+// each optionally preceded by "name=". The directory name is the default service name.
+func ReadServices(specList string) ([]*Service, error) {
+	var services []*Service
+	for _, spec := range strings.Split(specList, ",") {
+		if spec = strings.TrimSpace(spec); spec == "" {
+			continue
+		}
+		name, dir := filepath.Base(filepath.Clean(spec)), spec
+		if parts := strings.SplitN(spec, "=", 2); len(parts) == 2 {
+			name, dir = parts[0], parts[1]
+		}
+
+		listing, apis, err := parser.ReadSpec(dir)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, &Service{Name: name, Listing: listing, Apis: apis})
+	}
+	return services, nil
+}
+
+// Merge returns the combined spec of services. Resource /users of the "billing" service becomes
+// /billing-users, its description is prefixed with "billing: ". API declarations keep their
+// basePath, so operations are still sent to their service. API version and info are the ones of
+// the first service.
+func Merge(services []*Service) (*parser.ResourceListing, map[string]*parser.ApiDeclaration) {
+	listing := &parser.ResourceListing{
+		SwaggerVersion: parser.SwaggerVersion,
+		Apis:           make([]*parser.ApiRef, 0),
+	}
+	apis := make(map[string]*parser.ApiDeclaration)
+	if len(services) > 0 {
+		listing.ApiVersion = services[0].Listing.ApiVersion
+		listing.Infos = services[0].Listing.Infos
+	}
+
+	for _, service := range services {
+		apiKeys := make([]string, 0, len(service.Apis))
+		for apiKey := range service.Apis {
+			apiKeys = append(apiKeys, apiKey)
+		}
+		sort.Strings(apiKeys)
+
+		for _, apiKey := range apiKeys {
+			api := *service.Apis[apiKey]
+			mergedKey := service.Name + "-" + apiKey
+			api.ResourcePath = "/" + mergedKey
+			apis[mergedKey] = &api
+
+			description := service.Name
+			for _, ref := range service.Listing.Apis {
+				if strings.Trim(ref.Path, "/") == apiKey && ref.Description != "" {
+					description += ": " + ref.Description
+				}
+			}
+			listing.Apis = append(listing.Apis, &parser.ApiRef{
+				Path:        api.ResourcePath,
+				Description: description,
+			})
+		}
+	}
+	return listing, apis
+}
@@ -0,0 +1,164 @@
+// Package mock serves the parsed API with example payloads, so clients can be developed before the API
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// route is a documented operation
+type route struct {
+	method   string
+	segments []string // {var} segments match any value
+	status   int
+	typeName string // type of the response, "" for no body
+	api      *parser.ApiDeclaration
+}
+
+func (r *route) matches(segments []string) bool {
+	if len(segments) != len(r.segments) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if segment != segments[i] && !(strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			return false
+		}
+	}
+	return true
+}
+
+// Server answers every documented operation with an example of its success response
+type Server struct {
+	basePath string
+	routes   []*route
+}
+
+// NewServer returns the mock of the parsed API. Routes are served under the path of the API basePath.
+func NewServer(p *parser.Parser) *Server {
+	server := &Server{}
+	if basePath, err := url.Parse(p.BasePath); err == nil && strings.HasPrefix(basePath.Path, "/") {
+		server.basePath = strings.TrimSuffix(basePath.Path, "/")
+	}
+
+	apiKeys := make([]string, 0, len(p.TopLevelApis))
+	for apiKey := range p.TopLevelApis {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Strings(apiKeys)
+	for _, apiKey := range apiKeys {
+		api := p.TopLevelApis[apiKey]
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				status, typeName := successResponse(op)
+				server.routes = append(server.routes, &route{
+					method:   strings.ToUpper(op.HttpMethod),
+					segments: splitPath(subApi.Path),
+					status:   status,
+					typeName: typeName,
+					api:      api,
+				})
+			}
+		}
+	}
+	// static segments win over variables: /users/me before /users/{id}
+	sort.SliceStable(server.routes, func(i, j int) bool {
+		return variableCount(server.routes[i].segments) < variableCount(server.routes[j].segments)
+	})
+	return server
+}
+
+// successResponse returns the status and the type of the first 2xx response of op, or 200 and the type of op
+func successResponse(op *parser.Operation) (int, string) {
+	for _, response := range op.ResponseMessages {
+		if response.Code >= 200 && response.Code < 300 {
+			return response.Code, response.ResponseModel
+		}
+	}
+	typeName := op.Type
+	if typeName == "array" {
+		itemType := op.Items.Ref
+		if itemType == "" {
+			itemType = op.Items.Type
+		}
+		typeName = "array[" + itemType + "]"
+	}
+	if typeName == "void" {
+		typeName = ""
+	}
+	return http.StatusOK, typeName
+}
+
+func splitPath(path string) []string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func variableCount(segments []string) int {
+	count := 0
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "{") {
+			count++
+		}
+	}
+	return count
+}
+
+func (server *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// frontends are served from another origin
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == "OPTIONS" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	path := r.URL.Path
+	if server.basePath != "" {
+		if !strings.HasPrefix(path, server.basePath+"/") && path != server.basePath {
+			http.NotFound(w, r)
+			return
+		}
+		path = strings.TrimPrefix(path, server.basePath)
+	}
+	segments := splitPath(path)
+
+	isKnownPath := false
+	for _, route := range server.routes {
+		if !route.matches(segments) {
+			continue
+		}
+		isKnownPath = true
+		if route.method != r.Method {
+			continue
+		}
+		logger.Infof("%s %s: %d %s", r.Method, r.URL.Path, route.status, route.typeName)
+		if route.typeName == "" {
+			w.WriteHeader(route.status)
+			return
+		}
+		w.Header().Set("Content-Type", parser.ContentTypeJson)
+		w.WriteHeader(route.status)
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "    ")
+		encoder.Encode(route.api.ExampleValue(route.typeName))
+		return
+	}
+
+	if isKnownPath {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	http.NotFound(w, r)
+}
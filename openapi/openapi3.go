@@ -0,0 +1,361 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/jsonschema"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+const OpenAPIVersion = "3.0.3"
+
+// Document3 is an OpenAPI 3.0 document
+type Document3 struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]PathItem3 `json:"paths"`
+	Components Components           `json:"components,omitempty"`
+	Extensions parser.Extensions    `json:"-"`
+}
+
+type Server struct {
+	Url         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]jsonschema.Schema `json:"schemas,omitempty"`
+}
+
+// PathItem3 contains the operations of a path, by lower cased HTTP method
+type PathItem3 map[string]*Operation3
+
+type Operation3 struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationId string               `json:"operationId,omitempty"`
+	Parameters  []Parameter3         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]Response3 `json:"responses"`
+	Extensions  parser.Extensions    `json:"-"`
+	Websocket   *Websocket           `json:"-"` // written as the x-websocket extension
+	Events      []Event              `json:"-"` // written as the x-events extension
+}
+
+type Parameter3 struct {
+	Name        string            `json:"name"`
+	In          string            `json:"in"` // path, query or header
+	Description string            `json:"description,omitempty"`
+	Required    bool              `json:"required"`
+	Style       string            `json:"style,omitempty"`
+	Explode     *bool             `json:"explode,omitempty"`
+	Schema      jsonschema.Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response3 struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header3   `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header3 struct {
+	Description string            `json:"description,omitempty"`
+	Schema      jsonschema.Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema   jsonschema.Schema   `json:"schema,omitempty"`
+	Encoding map[string]Encoding `json:"encoding,omitempty"` // multipart/form-data parts only
+}
+
+// Encoding is the encoding of a part of a multipart/form-data request body
+type Encoding struct {
+	ContentType string `json:"contentType,omitempty"`
+}
+
+func (document Document3) MarshalJSON() ([]byte, error) {
+	type serialisedDocument Document3
+	data, err := json.Marshal(serialisedDocument(document))
+	if err != nil {
+		return nil, err
+	}
+	return parser.AppendExtensions(data, document.Extensions), nil
+}
+
+func (operation Operation3) MarshalJSON() ([]byte, error) {
+	type serialisedOperation Operation3
+	data, err := json.Marshal(serialisedOperation(operation))
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := operationExtensions(operation.Extensions, operation.Websocket, operation.Events)
+	if err != nil {
+		return nil, err
+	}
+	return parser.AppendExtensions(data, extensions), nil
+}
+
+// ComponentRef references a model in the schemas of the components of the document
+func ComponentRef(modelId string) string {
+	return "#/components/schemas/" + modelId
+}
+
+// Upgrade returns the OpenAPI 3.0 document of a Swagger 2.0 document: the body and form parameters
+// become request bodies, the schemas of the responses are given for every produced media type, and
+// the definitions become the schemas of the components
+func Upgrade(document *Document) *Document3 {
+	upgraded := &Document3{
+		OpenAPI:    OpenAPIVersion,
+		Info:       document.Info,
+		Tags:       document.Tags,
+		Paths:      make(map[string]PathItem3, len(document.Paths)),
+		Components: Components{Schemas: make(map[string]jsonschema.Schema, len(document.Definitions))},
+		Extensions: document.Extensions,
+	}
+	for _, scheme := range document.Schemes {
+		upgraded.Servers = append(upgraded.Servers, Server{Url: scheme + "://" + document.Host + document.BasePath})
+	}
+	if len(document.Schemes) == 0 && document.BasePath != "" {
+		upgraded.Servers = []Server{{Url: document.BasePath}}
+	}
+	// the @Server annotations replace the server of the host
+	if len(document.Servers) > 0 {
+		upgraded.Servers = nil
+		for _, server := range document.Servers {
+			upgradedServer := Server{Url: server.Url, Description: server.Description}
+			for name, variable := range server.Variables {
+				if upgradedServer.Variables == nil {
+					upgradedServer.Variables = make(map[string]ServerVariable, len(server.Variables))
+				}
+				upgradedServer.Variables[name] = ServerVariable(variable)
+			}
+			upgraded.Servers = append(upgraded.Servers, upgradedServer)
+		}
+	}
+
+	for path, pathItem := range document.Paths {
+		upgradedItem := make(PathItem3, len(pathItem))
+		for method, op := range pathItem {
+			upgradedItem[method] = upgradeOperation(op)
+		}
+		upgraded.Paths[path] = upgradedItem
+	}
+	for id, definition := range document.Definitions {
+		upgraded.Components.Schemas[id] = Schema3(definition)
+	}
+	return upgraded
+}
+
+func upgradeOperation(op *Operation) *Operation3 {
+	upgraded := &Operation3{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationId: op.OperationId,
+		Responses:   make(map[string]Response3, len(op.Responses)),
+		Extensions:  op.Extensions,
+		Websocket:   upgradeWebsocket(op.Websocket),
+		Events:      upgradeEvents(op.Events),
+	}
+
+	consumes := mediaTypes(op.Consumes)
+	form := jsonschema.Schema{"type": "object", "properties": map[string]interface{}{}}
+	var formRequired []string
+	encoding := make(map[string]Encoding)
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "body":
+			upgraded.RequestBody = &RequestBody{
+				Description: param.Description,
+				Required:    param.Required,
+				Content:     make(map[string]MediaType, len(consumes)),
+			}
+			for _, mediaType := range consumes {
+				upgraded.RequestBody.Content[mediaType] = MediaType{Schema: Schema3(param.Schema)}
+			}
+		case "formData":
+			form["properties"].(map[string]interface{})[param.Name] = parameterSchema(param)
+			if param.Required {
+				formRequired = append(formRequired, param.Name)
+			}
+			if param.ContentType != "" {
+				encoding[param.Name] = Encoding{ContentType: param.ContentType}
+			}
+		default:
+			upgraded.Parameters = append(upgraded.Parameters, upgradeParameter(param))
+		}
+	}
+	if properties := form["properties"].(map[string]interface{}); len(properties) > 0 && upgraded.RequestBody == nil {
+		if len(formRequired) > 0 {
+			form["required"] = formRequired
+		}
+		mediaType := "application/x-www-form-urlencoded"
+		for _, property := range properties {
+			if property.(jsonschema.Schema)["format"] == "binary" {
+				mediaType = "multipart/form-data"
+			}
+		}
+		for _, consumed := range op.Consumes {
+			if consumed == "multipart/form-data" {
+				mediaType = consumed
+			}
+		}
+		content := MediaType{Schema: form}
+		if mediaType == "multipart/form-data" && len(encoding) > 0 {
+			content.Encoding = encoding
+		}
+		upgraded.RequestBody = &RequestBody{
+			Required: len(formRequired) > 0,
+			Content:  map[string]MediaType{mediaType: content},
+		}
+	}
+
+	produces := mediaTypes(op.Produces)
+	for code, response := range op.Responses {
+		upgradedResponse := Response3{Description: response.Description}
+		for name, header := range response.Headers {
+			if upgradedResponse.Headers == nil {
+				upgradedResponse.Headers = make(map[string]Header3, len(response.Headers))
+			}
+			schema := jsonschema.Schema{"type": header.Type}
+			if header.Format != "" {
+				schema["format"] = header.Format
+			}
+			upgradedResponse.Headers[name] = Header3{Description: header.Description, Schema: schema}
+		}
+		if len(response.Schema) > 0 {
+			upgradedResponse.Content = make(map[string]MediaType, len(produces))
+			for _, mediaType := range produces {
+				upgradedResponse.Content[mediaType] = MediaType{Schema: Schema3(response.Schema)}
+			}
+		}
+		upgraded.Responses[code] = upgradedResponse
+	}
+	return upgraded
+}
+
+// mediaTypes returns the consumed or produced media types of an operation, JSON if there is none
+func mediaTypes(types []string) []string {
+	if len(types) == 0 {
+		return []string{"application/json"}
+	}
+	sorted := append([]string{}, types...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// upgradeParameter converts a path, query or header parameter: its type becomes a schema, and its
+// collection format a style
+func upgradeParameter(param Parameter) Parameter3 {
+	upgraded := Parameter3{
+		Name:        param.Name,
+		In:          param.In,
+		Description: param.Description,
+		Required:    param.Required,
+		Schema:      parameterSchema(param),
+	}
+	switch param.CollectionFormat {
+	case "multi":
+		explode := true
+		upgraded.Style, upgraded.Explode = "form", &explode
+	case "csv":
+		explode := false
+		upgraded.Style, upgraded.Explode = "form", &explode
+		if param.In == "path" || param.In == "header" {
+			upgraded.Style = "simple"
+		}
+	}
+	return upgraded
+}
+
+// parameterSchema returns the schema of the type and validations of a parameter which is not a body
+func parameterSchema(param Parameter) jsonschema.Schema {
+	if param.Type == "file" {
+		return jsonschema.Schema{"type": "string", "format": "binary"}
+	}
+	schema := jsonschema.Schema{"type": param.Type}
+	if param.Format != "" {
+		schema["format"] = param.Format
+	}
+	if len(param.Items) > 0 {
+		schema["items"] = Schema3(param.Items)
+	}
+	if param.Minimum != nil {
+		schema["minimum"] = *param.Minimum
+	}
+	if param.Maximum != nil {
+		schema["maximum"] = *param.Maximum
+	}
+	if param.MinLength != 0 {
+		schema["minLength"] = param.MinLength
+	}
+	if param.MaxLength != 0 {
+		schema["maxLength"] = param.MaxLength
+	}
+	if param.Pattern != "" {
+		schema["pattern"] = param.Pattern
+	}
+	return schema
+}
+
+// Schema3 converts a Swagger 2.0 schema to OpenAPI 3.0: definitions are referenced in the components,
+// x-nullable becomes nullable, nullable references are wrapped in an allOf, and the discriminator
+// property becomes a discriminator object
+func Schema3(schema jsonschema.Schema) jsonschema.Schema {
+	upgraded := make(jsonschema.Schema, len(schema))
+	for key, value := range schema {
+		switch typed := value.(type) {
+		case jsonschema.Schema:
+			upgraded[key] = Schema3(typed)
+		case map[string]interface{}:
+			properties := make(map[string]interface{}, len(typed))
+			for name, property := range typed {
+				if propertySchema, ok := property.(jsonschema.Schema); ok {
+					property = Schema3(propertySchema)
+				}
+				properties[name] = property
+			}
+			upgraded[key] = properties
+		case string:
+			switch key {
+			case "$ref":
+				upgraded[key] = ComponentRef(strings.TrimPrefix(typed, DefinitionRef("")))
+			case "discriminator":
+				upgraded[key] = map[string]string{"propertyName": typed}
+			default:
+				upgraded[key] = typed
+			}
+		default:
+			upgraded[key] = value
+		}
+	}
+
+	if nullable, _ := upgraded["x-nullable"].(bool); nullable {
+		delete(upgraded, "x-nullable")
+		upgraded["nullable"] = true
+		// the siblings of a $ref are ignored
+		if ref, ok := upgraded["$ref"]; ok {
+			delete(upgraded, "$ref")
+			upgraded["allOf"] = []jsonschema.Schema{{"$ref": ref}}
+		}
+	}
+	return upgraded
+}
@@ -0,0 +1,35 @@
+package openapi
+
+import (
+	"github.com/yvasiyarov/swagger/jsonschema"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// Event is an event of the Server-Sent Events stream of an operation, in its x-events extension
+type Event struct {
+	Name        string            `json:"name"`
+	Schema      jsonschema.Schema `json:"schema"`
+	Description string            `json:"description,omitempty"`
+}
+
+// convertEvents converts the events of a stream, their models are referenced in the definitions
+func convertEvents(events []parser.ServerSentEvent) []Event {
+	var converted []Event
+	for _, event := range events {
+		converted = append(converted, Event{
+			Name:        event.Name,
+			Schema:      Schema(jsonschema.TypeSchema(event.Model, DefinitionRef)),
+			Description: event.Description,
+		})
+	}
+	return converted
+}
+
+// upgradeEvents converts the schemas of the events of a stream to OpenAPI 3.0
+func upgradeEvents(events []Event) []Event {
+	var upgraded []Event
+	for _, event := range events {
+		upgraded = append(upgraded, Event{Name: event.Name, Schema: Schema3(event.Schema), Description: event.Description})
+	}
+	return upgraded
+}
@@ -0,0 +1,369 @@
+// Package openapi converts the parsed API to Swagger 2.0 (OpenAPI 2), for the viewers and
+// tools which do not read Swagger 1.2, and Swagger 2.0 documents to OpenAPI 3.0
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/jsonschema"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+const SwaggerVersion = "2.0"
+
+// Document is a Swagger 2.0 document
+type Document struct {
+	Swagger     string                       `json:"swagger"`
+	Info        Info                         `json:"info"`
+	Host        string                       `json:"host,omitempty"`
+	BasePath    string                       `json:"basePath,omitempty"`
+	Schemes     []string                     `json:"schemes,omitempty"`
+	Tags        []Tag                        `json:"tags,omitempty"`
+	Paths       map[string]PathItem          `json:"paths"`
+	Definitions map[string]jsonschema.Schema `json:"definitions,omitempty"`
+	Extensions  parser.Extensions            `json:"-"`
+	Servers     []parser.Server              `json:"-"` // written as the x-servers extension, Swagger 2.0 has one host
+}
+
+type Info struct {
+	Title          string   `json:"title"`
+	Description    string   `json:"description,omitempty"`
+	Version        string   `json:"version"`
+	TermsOfService string   `json:"termsOfService,omitempty"`
+	Contact        *Contact `json:"contact,omitempty"`
+	License        *License `json:"license,omitempty"`
+}
+
+type Contact struct {
+	Name  string `json:"name,omitempty"`
+	Url   string `json:"url,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type License struct {
+	Name string `json:"name"`
+	Url  string `json:"url,omitempty"`
+}
+
+// Tag groups the operations of a top level API
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem contains the operations of a path, by lower cased HTTP method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	OperationId string              `json:"operationId,omitempty"`
+	Consumes    []string            `json:"consumes,omitempty"`
+	Produces    []string            `json:"produces,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Extensions  parser.Extensions   `json:"-"`
+	Websocket   *Websocket          `json:"-"` // written as the x-websocket extension
+	Events      []Event             `json:"-"` // written as the x-events extension
+}
+
+type Parameter struct {
+	Name             string            `json:"name"`
+	In               string            `json:"in"` // path, query, header, formData or body
+	Description      string            `json:"description,omitempty"`
+	Required         bool              `json:"required"`
+	Schema           jsonschema.Schema `json:"schema,omitempty"` // body parameters only
+	Type             string            `json:"type,omitempty"`
+	Format           string            `json:"format,omitempty"`
+	Items            jsonschema.Schema `json:"items,omitempty"`
+	CollectionFormat string            `json:"collectionFormat,omitempty"`
+	Minimum          *float64          `json:"minimum,omitempty"`
+	Maximum          *float64          `json:"maximum,omitempty"`
+	MinLength        int               `json:"minLength,omitempty"`
+	MaxLength        int               `json:"maxLength,omitempty"`
+	Pattern          string            `json:"pattern,omitempty"`
+	ContentType      string            `json:"x-contentType,omitempty"` // content types of a multipart/form-data part
+}
+
+type Response struct {
+	Description string            `json:"description"`
+	Schema      jsonschema.Schema `json:"schema,omitempty"`
+	Headers     map[string]Header `json:"headers,omitempty"`
+}
+
+type Header struct {
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (document Document) MarshalJSON() ([]byte, error) {
+	type serialisedDocument Document
+	data, err := json.Marshal(serialisedDocument(document))
+	if err != nil {
+		return nil, err
+	}
+	extensions := document.Extensions
+	if len(document.Servers) > 0 {
+		if extensions, err = parser.WithExtension(extensions, "x-servers", document.Servers); err != nil {
+			return nil, err
+		}
+	}
+	return parser.AppendExtensions(data, extensions), nil
+}
+
+func (operation Operation) MarshalJSON() ([]byte, error) {
+	type serialisedOperation Operation
+	data, err := json.Marshal(serialisedOperation(operation))
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := operationExtensions(operation.Extensions, operation.Websocket, operation.Events)
+	if err != nil {
+		return nil, err
+	}
+	return parser.AppendExtensions(data, extensions), nil
+}
+
+// operationExtensions returns the extensions of an operation with its x-websocket and x-events extensions
+func operationExtensions(extensions parser.Extensions, websocket *Websocket, events []Event) (parser.Extensions, error) {
+	var err error
+	if websocket != nil {
+		if extensions, err = parser.WithExtension(extensions, "x-websocket", websocket); err != nil {
+			return nil, err
+		}
+	}
+	if len(events) > 0 {
+		if extensions, err = parser.WithExtension(extensions, "x-events", events); err != nil {
+			return nil, err
+		}
+	}
+	return extensions, nil
+}
+
+// DefinitionRef references a model in the definitions of the document
+func DefinitionRef(modelId string) string {
+	return "#/definitions/" + modelId
+}
+
+// Convert returns the Swagger 2.0 document of the parsed API, served at baseUrl
+func Convert(p *parser.Parser, baseUrl string) *Document {
+	info := p.Listing.Infos
+	document := &Document{
+		Swagger: SwaggerVersion,
+		Info: Info{
+			Title:          info.Title,
+			Description:    info.Description,
+			Version:        p.Listing.ApiVersion,
+			TermsOfService: info.TermsOfServiceUrl,
+		},
+		Paths:       make(map[string]PathItem),
+		Definitions: make(map[string]jsonschema.Schema),
+		Extensions:  p.Listing.Extensions,
+		Servers:     p.Listing.Servers,
+	}
+	if document.Info.Title == "" {
+		document.Info.Title = "API"
+	}
+	if info.Contact != "" || info.ContactName != "" || info.ContactUrl != "" {
+		document.Info.Contact = &Contact{Name: info.ContactName, Url: info.ContactUrl, Email: info.Contact}
+	}
+	if info.License != "" {
+		document.Info.License = &License{Name: info.License, Url: info.LicenseUrl}
+	}
+	if serverUrl, err := url.Parse(baseUrl); err == nil && serverUrl.Host != "" {
+		document.Host = serverUrl.Host
+		document.BasePath = serverUrl.Path
+		document.Schemes = []string{serverUrl.Scheme}
+	}
+
+	apiKeys := make([]string, 0, len(p.TopLevelApis))
+	for apiKey := range p.TopLevelApis {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Strings(apiKeys)
+
+	descriptions := make(map[string]string)
+	for _, ref := range p.Listing.Apis {
+		descriptions[strings.Trim(ref.Path, "/")] = ref.Description
+	}
+	for _, apiKey := range apiKeys {
+		document.Tags = append(document.Tags, Tag{Name: apiKey, Description: descriptions[apiKey]})
+		for _, subApi := range p.TopLevelApis[apiKey].Apis {
+			pathItem, ok := document.Paths[subApi.Path]
+			if !ok {
+				pathItem = make(PathItem)
+				document.Paths[subApi.Path] = pathItem
+			}
+			for _, op := range subApi.Operations {
+				pathItem[strings.ToLower(op.HttpMethod)] = convertOperation(apiKey, op)
+			}
+		}
+	}
+
+	for id, model := range p.GetModels() {
+		document.Definitions[id] = Schema(jsonschema.ModelSchema(model, DefinitionRef))
+		if model.Discriminator != "" {
+			document.Definitions[id]["discriminator"] = model.Discriminator
+		}
+	}
+	return document
+}
+
+func convertOperation(tag string, op *parser.Operation) *Operation {
+	operation := &Operation{
+		Tags:        []string{tag},
+		Summary:     op.Summary,
+		Description: op.Notes,
+		OperationId: op.Nickname,
+		Consumes:    op.Consumes,
+		Produces:    op.Produces,
+		Responses:   make(map[string]Response),
+		Extensions:  op.Extensions,
+		Websocket:   convertWebsocket(op.Websocket),
+		Events:      convertEvents(op.Events),
+	}
+	for _, param := range op.Parameters {
+		operation.Parameters = append(operation.Parameters, convertParameter(param))
+	}
+
+	hasSuccess := false
+	for _, response := range op.ResponseMessages {
+		description := response.Message
+		if description == "" {
+			description = http.StatusText(response.Code)
+		}
+		converted := Response{Description: description, Headers: convertHeaders(response.Headers)}
+		if response.ResponseModel != "" {
+			converted.Schema = Schema(jsonschema.TypeSchema(response.ResponseModel, DefinitionRef))
+		}
+		operation.Responses[strconv.Itoa(response.Code)] = converted
+		hasSuccess = hasSuccess || (response.Code >= 200 && response.Code < 300)
+	}
+	// the type of the operation is the type of its success response
+	if !hasSuccess {
+		response := Response{Description: http.StatusText(http.StatusOK)}
+		if typeName := operationType(op); typeName != "" && typeName != "void" {
+			response.Schema = Schema(jsonschema.TypeSchema(typeName, DefinitionRef))
+		}
+		operation.Responses[strconv.Itoa(http.StatusOK)] = response
+	}
+	return operation
+}
+
+// convertHeaders converts the headers of a response message, nil if there is none
+func convertHeaders(headers map[string]parser.ResponseHeader) map[string]Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	converted := make(map[string]Header, len(headers))
+	for name, header := range headers {
+		converted[name] = Header{Type: header.Type, Format: header.Format, Description: header.Description}
+	}
+	return converted
+}
+
+// operationType returns the type of the operation as a type name, "array[...]" for arrays
+func operationType(op *parser.Operation) string {
+	if op.Type != "array" {
+		return op.Type
+	}
+	if op.Items.Ref != "" {
+		return "array[" + op.Items.Ref + "]"
+	}
+	return "array[" + op.Items.Type + "]"
+}
+
+func convertParameter(param parser.Parameter) Parameter {
+	converted := Parameter{
+		Name:        param.Name,
+		In:          param.ParamType,
+		Description: strings.Trim(param.Description, "\""),
+		Required:    param.Required || param.ParamType == "path",
+		MinLength:   param.MinLength,
+		MaxLength:   param.MaxLength,
+		Pattern:     param.Pattern,
+	}
+	if param.ParamType == "form" {
+		converted.In = "formData"
+		converted.ContentType = param.ContentType
+	}
+	if param.ParamType == "body" {
+		converted.Schema = Schema(jsonschema.TypeSchema(param.DataType, DefinitionRef))
+		return converted
+	}
+
+	if strings.ToLower(param.DataType) == "file" {
+		converted.Type = "file"
+		converted.ContentType = param.ContentType
+		return converted
+	}
+	// other parameters have a simple type, models are sent as strings
+	typeSchema := jsonschema.TypeSchema(param.DataType, DefinitionRef)
+	converted.Type, _ = typeSchema["type"].(string)
+	converted.Format, _ = typeSchema["format"].(string)
+	if converted.Type == "" {
+		converted.Type, converted.Format = "string", ""
+	}
+	if param.Format != "" {
+		converted.Format = param.Format
+	}
+	if param.AllowMultiple {
+		converted.Items = jsonschema.Schema{"type": converted.Type}
+		if converted.Format != "" {
+			converted.Items["format"] = converted.Format
+		}
+		converted.Type, converted.Format = "array", ""
+		converted.CollectionFormat = "csv"
+		if converted.In == "query" || converted.In == "formData" {
+			converted.CollectionFormat = "multi"
+		}
+	}
+	if param.Minimum != 0 {
+		minimum := param.Minimum
+		converted.Minimum = &minimum
+	}
+	if param.Maximum != 0 {
+		maximum := param.Maximum
+		converted.Maximum = &maximum
+	}
+	return converted
+}
+
+// Schema converts a JSON Schema to the Swagger 2.0 subset: nullable types become x-nullable,
+// and type hierarchies rely on the discriminator instead of oneOf
+func Schema(schema jsonschema.Schema) jsonschema.Schema {
+	for key, value := range schema {
+		switch typed := value.(type) {
+		case jsonschema.Schema:
+			schema[key] = Schema(typed)
+		case map[string]interface{}:
+			for name, property := range typed {
+				if propertySchema, ok := property.(jsonschema.Schema); ok {
+					typed[name] = Schema(propertySchema)
+				}
+			}
+		}
+	}
+
+	if types, ok := schema["type"].([]string); ok {
+		schema["type"] = types[0]
+		schema["x-nullable"] = true
+	}
+	if oneOf, ok := schema["oneOf"].([]jsonschema.Schema); ok {
+		delete(schema, "oneOf")
+		// nullable references are a oneOf of the reference and null
+		if len(oneOf) == 2 && oneOf[1]["type"] == "null" {
+			schema["$ref"] = oneOf[0]["$ref"]
+			schema["x-nullable"] = true
+		}
+	}
+	delete(schema, "writeOnly")
+	return schema
+}
@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"github.com/yvasiyarov/swagger/jsonschema"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// Websocket is the x-websocket extension of an operation: the schemas of the messages exchanged on
+// the WebSocket it upgrades its connection to
+type Websocket struct {
+	Client []WebsocketMessage `json:"client,omitempty"` // messages sent by the client to the server
+	Server []WebsocketMessage `json:"server,omitempty"` // messages sent by the server to the client
+}
+
+type WebsocketMessage struct {
+	Schema      jsonschema.Schema `json:"schema"`
+	Description string            `json:"description,omitempty"`
+}
+
+// convertWebsocket converts the messages of a WebSocket, their models are referenced in the definitions
+func convertWebsocket(websocket *parser.Websocket) *Websocket {
+	if websocket == nil {
+		return nil
+	}
+	convert := func(messages []parser.WebsocketMessage) []WebsocketMessage {
+		var converted []WebsocketMessage
+		for _, message := range messages {
+			converted = append(converted, WebsocketMessage{
+				Schema:      Schema(jsonschema.TypeSchema(message.Model, DefinitionRef)),
+				Description: message.Description,
+			})
+		}
+		return converted
+	}
+	return &Websocket{Client: convert(websocket.Client), Server: convert(websocket.Server)}
+}
+
+// upgradeWebsocket converts the schemas of the messages of a WebSocket to OpenAPI 3.0
+func upgradeWebsocket(websocket *Websocket) *Websocket {
+	if websocket == nil {
+		return nil
+	}
+	upgrade := func(messages []WebsocketMessage) []WebsocketMessage {
+		var upgraded []WebsocketMessage
+		for _, message := range messages {
+			upgraded = append(upgraded, WebsocketMessage{Schema: Schema3(message.Schema), Description: message.Description})
+		}
+		return upgraded
+	}
+	return &Websocket{Client: upgrade(websocket.Client), Server: upgrade(websocket.Server)}
+}
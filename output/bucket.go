@@ -0,0 +1,224 @@
+package output
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schemes of the bucket locations -output accepts: s3://bucket/prefix and gs://bucket/prefix
+const (
+	S3Scheme  = "s3://"
+	GCSScheme = "gs://"
+)
+
+// BucketTimeout is the time the upload of a file to a bucket can take
+const BucketTimeout = 60 * time.Second
+
+// Bucket keeps the created files in memory, like Memory, and Upload uploads them to an S3 or a Google
+// Cloud Storage bucket, under the prefix. Credentials are read from the environment:
+//   - S3: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION (us-east-1 by
+//     default). AWS_ENDPOINT_URL selects an S3 compatible storage (MinIO...), with path style URLs.
+//   - GCS: an OAuth access token in GOOGLE_OAUTH_ACCESS_TOKEN, e.g. from gcloud auth print-access-token.
+//     STORAGE_EMULATOR_HOST selects an emulator.
+type Bucket struct {
+	*Memory
+	Location string // s3://bucket/prefix
+	Name     string
+	Prefix   string
+	Client   *http.Client
+}
+
+// IsBucket tells if the -output location is a bucket
+func IsBucket(location string) bool {
+	return strings.HasPrefix(location, S3Scheme) || strings.HasPrefix(location, GCSScheme)
+}
+
+// NewBucket returns the bucket of an s3://bucket/prefix or gs://bucket/prefix location
+func NewBucket(location string) (*Bucket, error) {
+	if !IsBucket(location) {
+		return nil, fmt.Errorf("Can not write to %s, expected: %sbucket/prefix or %sbucket/prefix", location, S3Scheme, GCSScheme)
+	}
+	parts := strings.SplitN(location[strings.Index(location, "://")+3:], "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf("Can not write to %s, the bucket name is missing", location)
+	}
+	bucket := &Bucket{Memory: NewMemory(), Location: location, Name: parts[0], Client: &http.Client{Timeout: BucketTimeout}}
+	if len(parts) == 2 {
+		bucket.Prefix = strings.Trim(parts[1], "/")
+	}
+	return bucket, nil
+}
+
+// Upload uploads the created files
+func (b *Bucket) Upload() error {
+	for _, name := range b.Names() {
+		content, _ := b.Content(name)
+		key := path.Join(b.Prefix, name)
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		var request *http.Request
+		var err error
+		if strings.HasPrefix(b.Location, S3Scheme) {
+			request, err = b.s3Request(key, content, contentType, time.Now().UTC())
+		} else {
+			request, err = b.gcsRequest(key, content, contentType)
+		}
+		if err != nil {
+			return fmt.Errorf("Can not upload %s: %v\n", key, err)
+		}
+		response, err := b.Client.Do(request)
+		if err != nil {
+			return fmt.Errorf("Can not upload %s: %v\n", key, err)
+		}
+		body, _ := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 300 {
+			return fmt.Errorf("Can not upload %s to %s: %s %s\n", key, b.Name, response.Status, strings.TrimSpace(string(body)))
+		}
+	}
+	return nil
+}
+
+// gcsRequest uploads an object with the JSON API of Google Cloud Storage
+func (b *Bucket) gcsRequest(key string, content []byte, contentType string) (*http.Request, error) {
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	endpoint := "https://storage.googleapis.com"
+	if emulator := os.Getenv("STORAGE_EMULATOR_HOST"); emulator != "" {
+		endpoint = strings.TrimSuffix(emulator, "/")
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	} else if token == "" {
+		return nil, fmt.Errorf("Uploading to Google Cloud Storage needs the $GOOGLE_OAUTH_ACCESS_TOKEN environment variable")
+	}
+
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	request, err := http.NewRequest("POST", endpoint+"/upload/storage/v1/b/"+url.PathEscape(b.Name)+"/o?"+query.Encode(), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentType)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	return request, nil
+}
+
+// s3Request puts an object, signed with AWS Signature Version 4
+func (b *Bucket) s3Request(key string, content []byte, contentType string, now time.Time) (*http.Request, error) {
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("Uploading to S3 needs the $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY environment variables")
+	}
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	objectUrl := "https://" + b.Name + ".s3." + region + ".amazonaws.com/" + uriEncode(key)
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		objectUrl = strings.TrimSuffix(endpoint, "/") + "/" + uriEncode(b.Name) + "/" + uriEncode(key)
+	}
+	request, err := http.NewRequest("PUT", objectUrl, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	payloadSum := sha256.Sum256(content)
+	payloadHash := hex.EncodeToString(payloadSum[:])
+	headers := map[string]string{
+		"content-type":         contentType,
+		"host":                 request.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           now.Format("20060102T150405Z"),
+	}
+	if token := os.Getenv("AWS_SESSION_TOKEN"); token != "" {
+		headers["x-amz-security-token"] = token
+	}
+	for name, value := range headers {
+		if name != "host" {
+			request.Header.Set(name, value)
+		}
+	}
+	request.Header.Set("Authorization", signV4("PUT", request.URL.EscapedPath(), headers, payloadHash, accessKey, secretKey, region, "s3", now))
+	return request, nil
+}
+
+// signV4 returns the Authorization header of a request signed with AWS Signature Version 4.
+// headers are the signed headers, with lower case names, payloadHash the hex SHA-256 of the body.
+func signV4(method, uri string, headers map[string]string, payloadHash, accessKey, secretKey, region, service string, now time.Time) string {
+	canonical, signedHeaders := canonicalRequest(method, uri, headers, payloadHash)
+	scope := now.Format("20060102") + "/" + region + "/" + service + "/aws4_request"
+	signature := hex.EncodeToString(hmacSha256(signingKey(secretKey, now, region, service), stringToSign(canonical, scope, now)))
+	return "AWS4-HMAC-SHA256 Credential=" + accessKey + "/" + scope + ", SignedHeaders=" + signedHeaders + ", Signature=" + signature
+}
+
+// canonicalRequest returns the canonical request of Signature Version 4 of a request without query
+// string, and its signed headers: the names of headers, sorted and joined by ;
+func canonicalRequest(method, uri string, headers map[string]string, payloadHash string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders bytes.Buffer
+	for _, name := range names {
+		// values are trimmed, and their sequential spaces replaced by a single space
+		canonicalHeaders.WriteString(name + ":" + strings.Join(strings.Fields(headers[name]), " ") + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	return strings.Join([]string{method, uri, "", canonicalHeaders.String(), signedHeaders, payloadHash}, "\n"), signedHeaders
+}
+
+// stringToSign returns the string to sign of Signature Version 4 of a canonical request, in the scope
+// date/region/service/aws4_request
+func stringToSign(canonicalRequest, scope string, now time.Time) string {
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	return strings.Join([]string{"AWS4-HMAC-SHA256", now.Format("20060102T150405Z"), scope, hex.EncodeToString(canonicalHash[:])}, "\n")
+}
+
+// signingKey derives the key of Signature Version 4 signing the requests of a day to a service of a region
+func signingKey(secretKey string, now time.Time, region, service string) []byte {
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{now.Format("20060102"), region, service, "aws4_request"} {
+		key = hmacSha256(key, part)
+	}
+	return key
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode escapes an object key like S3 does: everything but unreserved characters and slashes
+func uriEncode(key string) string {
+	var buf bytes.Buffer
+	for _, c := range []byte(key) {
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) != -1 {
+			buf.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buf, "%%%02X", c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,175 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Credentials, scope and time of the AWS Signature Version 4 test suite
+// (https://docs.aws.amazon.com/general/latest/gr/signature-v4-test-suite.html)
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "us-east-1"
+	testService   = "service"
+	testScope     = "20150830/us-east-1/service/aws4_request"
+	emptyHash     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var testTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSignV4TestSuite(t *testing.T) {
+	vectors := []struct {
+		name             string
+		method           string
+		uri              string
+		headers          map[string]string
+		payloadHash      string
+		canonicalRequest string
+		stringToSign     string
+		signature        string
+	}{
+		{
+			name:             "get-vanilla",
+			method:           "GET",
+			uri:              "/",
+			headers:          map[string]string{"host": "example.amazonaws.com", "x-amz-date": "20150830T123600Z"},
+			payloadHash:      emptyHash,
+			canonicalRequest: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n" + testScope + "\nbb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+			signature:        "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:             "post-vanilla",
+			method:           "POST",
+			uri:              "/",
+			headers:          map[string]string{"host": "example.amazonaws.com", "x-amz-date": "20150830T123600Z"},
+			payloadHash:      emptyHash,
+			canonicalRequest: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n" + testScope + "\n553f88c9e4d10fc9e109e2aeb65f030801b70c2f6468faca261d401ae622fc87",
+			signature:        "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:             "get-utf8",
+			method:           "GET",
+			uri:              uriEncode("/ሴ"),
+			headers:          map[string]string{"host": "example.amazonaws.com", "x-amz-date": "20150830T123600Z"},
+			payloadHash:      emptyHash,
+			canonicalRequest: "GET\n/%E1%88%B4\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n" + testScope + "\n2a0a97d02205e45ce2e994789806b19270cfbbb0921b278ccf58f5249ac42102",
+			signature:        "8318018e0b0f223aa2bbf98705b62bb787dc9c0e678f255a891fd03141be5d85",
+		},
+		{
+			name:             "get-unreserved",
+			method:           "GET",
+			uri:              uriEncode("/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"),
+			headers:          map[string]string{"host": "example.amazonaws.com", "x-amz-date": "20150830T123600Z"},
+			payloadHash:      emptyHash,
+			canonicalRequest: "GET\n/-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n" + testScope + "\n6a968768eefaa713e2a6b16b589a8ea192661f098f37349f4e2c0082757446f9",
+			signature:        "07ef7494c76fa4850883e2b006601f940f8a34d404d0cfa977f52a65bbf5f24f",
+		},
+		{
+			name:             "get-header-value-trim",
+			method:           "GET",
+			uri:              "/",
+			headers:          map[string]string{"host": "example.amazonaws.com", "my-header1": " value1", "my-header2": ` "a   b   c"`, "x-amz-date": "20150830T123600Z"},
+			payloadHash:      emptyHash,
+			canonicalRequest: "GET\n/\n\nhost:example.amazonaws.com\nmy-header1:value1\nmy-header2:\"a b c\"\nx-amz-date:20150830T123600Z\n\nhost;my-header1;my-header2;x-amz-date\n" + emptyHash,
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n" + testScope + "\na726db9b0df21c14f559d0a978e563112acb1b9e05476f0a6a1c7d68f28605c7",
+			signature:        "acc3ed3afb60bb290fc8d2dd0098b9911fcaa05412b367055dee359757a9c736",
+		},
+		{
+			name:             "post-x-www-form-urlencoded",
+			method:           "POST",
+			uri:              "/",
+			headers:          map[string]string{"content-type": "application/x-www-form-urlencoded", "host": "example.amazonaws.com", "x-amz-date": "20150830T123600Z"},
+			payloadHash:      "9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			canonicalRequest: "POST\n/\n\ncontent-type:application/x-www-form-urlencoded\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\ncontent-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			stringToSign:     "AWS4-HMAC-SHA256\n20150830T123600Z\n" + testScope + "\n42a5e5bb34198acb3e84da4f085bb7927f2bc277ca766e6d19c73c2154021281",
+			signature:        "ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, vector := range vectors {
+		canonical, signedHeaders := canonicalRequest(vector.method, vector.uri, vector.headers, vector.payloadHash)
+		assert.Equal(t, vector.canonicalRequest, canonical, "Wrong canonical request of %s", vector.name)
+		assert.Equal(t, vector.stringToSign, stringToSign(canonical, testScope, testTime), "Wrong string to sign of %s", vector.name)
+		authorization := signV4(vector.method, vector.uri, vector.headers, vector.payloadHash, testAccessKey, testSecretKey, testRegion, testService, testTime)
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential="+testAccessKey+"/"+testScope+", SignedHeaders="+signedHeaders+", Signature="+vector.signature, authorization, "Wrong signature of %s", vector.name)
+	}
+}
+
+func TestS3Upload(t *testing.T) {
+	type upload struct {
+		method, path, body string
+		header             http.Header
+		host               string
+	}
+	var uploads []upload
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		uploads = append(uploads, upload{r.Method, r.URL.EscapedPath(), string(body), r.Header, r.Host})
+		w.WriteHeader(status)
+		w.Write([]byte("<Error>AccessDenied</Error>"))
+	}))
+	defer server.Close()
+
+	for name, value := range map[string]string{
+		"AWS_ACCESS_KEY_ID":     testAccessKey,
+		"AWS_SECRET_ACCESS_KEY": testSecretKey,
+		"AWS_SESSION_TOKEN":     "session-token",
+		"AWS_REGION":            "eu-west-1",
+		"AWS_ENDPOINT_URL":      server.URL,
+	} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Setenv(name, value)
+	}
+
+	bucket, err := NewBucket("s3://docs/api/v1/")
+	if err != nil {
+		t.Fatalf("Can not create bucket: %v\n", err)
+	}
+	file, _ := bucket.Create("users/index.json")
+	file.WriteString(`{"swaggerVersion": "1.2"}`)
+	file.Close()
+	assert.Nil(t, bucket.Upload(), "Upload failed")
+
+	if assert.Len(t, uploads, 1, "Wrong number of uploaded files") {
+		put := uploads[0]
+		assert.Equal(t, "PUT", put.method, "Object is not PUT")
+		assert.Equal(t, "/docs/api/v1/users/index.json", put.path, "Object is not PUT under the prefix of the bucket")
+		assert.Equal(t, `{"swaggerVersion": "1.2"}`, put.body, "Wrong object content")
+		assert.Equal(t, "application/json", put.header.Get("Content-Type"), "Wrong content type")
+		assert.Equal(t, "session-token", put.header.Get("X-Amz-Security-Token"), "Session token is not sent")
+		payloadHash := sha256.Sum256([]byte(put.body))
+		assert.Equal(t, hex.EncodeToString(payloadHash[:]), put.header.Get("X-Amz-Content-Sha256"), "Wrong payload hash")
+
+		date, err := time.Parse("20060102T150405Z", put.header.Get("X-Amz-Date"))
+		assert.Nil(t, err, "Wrong X-Amz-Date")
+		headers := map[string]string{"host": put.host}
+		for _, name := range []string{"Content-Type", "X-Amz-Content-Sha256", "X-Amz-Date", "X-Amz-Security-Token"} {
+			headers[strings.ToLower(name)] = put.header.Get(name)
+		}
+		expected := signV4("PUT", put.path, headers, headers["x-amz-content-sha256"], testAccessKey, testSecretKey, "eu-west-1", "s3", date)
+		assert.Equal(t, expected, put.header.Get("Authorization"), "Signature does not match the received request")
+		assert.True(t, strings.HasPrefix(put.header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+testAccessKey+"/"+date.Format("20060102")+"/eu-west-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, "), "Wrong credential scope or signed headers")
+	}
+
+	status = http.StatusForbidden
+	err = bucket.Upload()
+	if assert.NotNil(t, err, "Rejected upload does not fail") {
+		assert.Contains(t, err.Error(), "403 Forbidden", "Status of the rejected upload is not reported")
+		assert.Contains(t, err.Error(), "AccessDenied", "Error of S3 is not reported")
+	}
+}
@@ -0,0 +1,134 @@
+// Package output is where the generators write their files. Files go to the disk, unless
+// Current is replaced, e.g. by a Memory file system for -dry-run.
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"sync"
+)
+
+// File is the part of *os.File the generators use
+type File interface {
+	io.WriteCloser
+	WriteString(s string) (int, error)
+}
+
+type FileSystem interface {
+	Create(name string) (File, error)
+	MkdirAll(dir string, perm os.FileMode) error
+}
+
+// Stdout is the file name of the standard output: file formats are written there with -output -
+const Stdout = "-"
+
+// Current is the file system Create and MkdirAll use
+var Current FileSystem = Disk{}
+
+// FileMode and DirMode are the permissions of the files and directories written on the disk. They
+// are also set on existing files and directories, so regenerating over a file created with other
+// permissions fixes them. The umask of the process does not apply.
+var (
+	FileMode os.FileMode = 0644
+	DirMode  os.FileMode = 0755
+)
+
+func Create(name string) (File, error) {
+	return Current.Create(name)
+}
+
+func MkdirAll(dir string, perm os.FileMode) error {
+	return Current.MkdirAll(dir, perm)
+}
+
+// Disk writes files to the OS file system
+type Disk struct{}
+
+func (Disk) Create(name string) (File, error) {
+	if name == Stdout {
+		return stdoutFile{os.Stdout}, nil
+	}
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, FileMode)
+	if err != nil {
+		return nil, err
+	}
+	// OpenFile keeps the permissions of an existing file
+	if err := f.Chmod(FileMode); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// stdoutFile is the standard output, which is not closed by the generators
+type stdoutFile struct {
+	*os.File
+}
+
+func (stdoutFile) Close() error {
+	return nil
+}
+
+func (Disk) MkdirAll(dir string, perm os.FileMode) error {
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+	// MkdirAll keeps the permissions of an existing directory
+	return os.Chmod(dir, perm)
+}
+
+// Memory keeps created files in memory, indexed by cleaned path
+type Memory struct {
+	mutex sync.Mutex
+	files map[string]*bytes.Buffer
+}
+
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string]*bytes.Buffer)}
+}
+
+type memoryFile struct {
+	*bytes.Buffer
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+func (m *Memory) Create(name string) (File, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	buf := new(bytes.Buffer)
+	m.files[path.Clean(name)] = buf
+	return memoryFile{buf}, nil
+}
+
+func (m *Memory) MkdirAll(dir string, perm os.FileMode) error {
+	return nil
+}
+
+// Names returns the sorted names of the created files
+func (m *Memory) Names() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	names := make([]string, 0, len(m.files))
+	for name := range m.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Content returns the content of a created file
+func (m *Memory) Content(name string) ([]byte, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	buf, ok := m.files[path.Clean(name)]
+	if !ok {
+		return nil, false
+	}
+	return buf.Bytes(), true
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskSetsModesOfExistingFiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows has no permission bits")
+	}
+	dir, err := ioutil.TempDir("", "swagger_output")
+	if err != nil {
+		t.Fatalf("Can not create directory: %v\n", err)
+	}
+	defer os.RemoveAll(dir)
+
+	docsDir := filepath.Join(dir, "docs")
+	if err := os.Mkdir(docsDir, 0700); err != nil {
+		t.Fatalf("Can not create directory: %v\n", err)
+	}
+	name := filepath.Join(docsDir, "docs.go")
+	if err := ioutil.WriteFile(name, []byte("package docs\n"), 0600); err != nil {
+		t.Fatalf("Can not write file: %v\n", err)
+	}
+
+	assert.Nil(t, Disk{}.MkdirAll(docsDir, DirMode), "Can not create existing directory")
+	f, err := Disk{}.Create(name)
+	if !assert.Nil(t, err, "Can not regenerate file") {
+		return
+	}
+	f.Close()
+
+	info, err := os.Stat(name)
+	if assert.Nil(t, err, "Regenerated file is missing") {
+		assert.Equal(t, FileMode, info.Mode().Perm(), "Wrong mode of the regenerated file")
+	}
+	info, err = os.Stat(docsDir)
+	if assert.Nil(t, err, "Directory is missing") {
+		assert.Equal(t, DirMode, info.Mode().Perm(), "Wrong mode of the existing directory")
+	}
+}
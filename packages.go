@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/generator"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// importPath returns the import path of the package of the directory dir, as `go list` sees it, which
+// knows modules. The GOPATH is searched when the go command can not list the package.
+func importPath(dir string) (string, error) {
+	command := exec.Command("go", "list", "-f", "{{.ImportPath}}", ".")
+	command.Dir = dir
+	if out, err := command.Output(); err == nil {
+		if listed := strings.TrimSpace(string(out)); listed != "" && listed != "." && !strings.HasPrefix(listed, "_") {
+			return listed, nil
+		}
+	}
+	return gopathImportPath(dir)
+}
+
+// gopathImportPath returns the import path of the directory dir, which must be in a $GOPATH/src directory
+func gopathImportPath(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("Can not resolve %s: %v\n", dir, err)
+	}
+	if evaluatedDir, err := filepath.EvalSymlinks(absDir); err == nil {
+		absDir = evaluatedDir
+	}
+	for _, gopath := range parser.Gopath() {
+		src, err := filepath.Abs(filepath.Join(gopath, "src"))
+		if err != nil {
+			continue
+		}
+		if evaluatedSrc, err := filepath.EvalSymlinks(src); err == nil {
+			src = evaluatedSrc
+		}
+		if relative, err := filepath.Rel(src, absDir); err == nil && relative != "." && !strings.HasPrefix(relative, "..") {
+			return filepath.ToSlash(relative), nil
+		}
+	}
+	return "", fmt.Errorf("%s is neither a package the go command can list nor in a $GOPATH/src directory\n", dir)
+}
+
+// resolveApiPackage returns the import paths of a comma separated -apiPackage. Paths relative to
+// the current directory are resolved, and an empty -apiPackage is the package of the current
+// directory, so `//go:generate swagger` needs no flag.
+func resolveApiPackage(apiPackage string) (string, error) {
+	if apiPackage == "" {
+		apiPackage = "."
+	}
+	packages := strings.Split(apiPackage, ",")
+	for i, packageName := range packages {
+		if packageName = strings.TrimSpace(packageName); !generator.IsRelativePath(packageName) {
+			continue
+		}
+		resolved, err := importPath(packageName)
+		if err != nil {
+			return "", err
+		}
+		packages[i] = resolved
+	}
+	return strings.Join(packages, ","), nil
+}
+
+// resolveMainApiFile returns -mainApiFile, a file of a package (github.com/acme/api/main.go) or a file
+// path. Paths relative to the current directory are made absolute. An empty -mainApiFile is main.go of
+// the first API package, or the file of the //go:generate directive ($GOFILE) when there is no main.go.
+func resolveMainApiFile(mainApiFile, apiPackage string) (string, error) {
+	if mainApiFile == "" {
+		firstPackage := strings.TrimSpace(strings.Split(apiPackage, ",")[0])
+		mainApiFile = path.Join(firstPackage, "main.go")
+		if goFile := os.Getenv("GOFILE"); goFile != "" && generator.FindMainApiFile(parser.NewParser(), mainApiFile) == "" {
+			mainApiFile = path.Join(firstPackage, goFile)
+		}
+		return mainApiFile, nil
+	}
+	if !generator.IsRelativePath(mainApiFile) {
+		return mainApiFile, nil
+	}
+	absFile, err := filepath.Abs(mainApiFile)
+	if err != nil {
+		return "", fmt.Errorf("Can not resolve %s: %v\n", mainApiFile, err)
+	}
+	return absFile, nil
+}
+
+// hasGoFiles tells if dir contains Go source files
+func hasGoFiles(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	return len(matches) > 0
+}
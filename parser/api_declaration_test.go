@@ -151,6 +151,36 @@ func (suite *ApiDeclarationSuite) TestAddOperation() {
 	assert.Len(suite.T(), api.Consumes, 2, "Second consumed type was not added")
 }
 
+func (suite *ApiDeclarationSuite) TestExampleValue() {
+	api := parser.NewApiDeclaration()
+
+	node := parser.NewModel(suite.parser)
+	node.Id = "test.Node"
+	node.Properties = map[string]*parser.ModelProperty{
+		"name":     &parser.ModelProperty{Type: "string"},
+		"weight":   &parser.ModelProperty{Type: "float64"},
+		"tags":     &parser.ModelProperty{Type: "array", Items: parser.ModelPropertyItems{Type: "string"}},
+		"children": &parser.ModelProperty{Type: "array", Items: parser.ModelPropertyItems{Ref: "test.Node"}},
+	}
+	api.Models[node.Id] = node
+
+	assert.Equal(suite.T(), 0, api.ExampleValue("int64"), "Basic type example not generated")
+	assert.Equal(suite.T(), []interface{}{"string"}, api.ExampleValue("array[string]"), "Array example not generated")
+
+	expected := map[string]interface{}{
+		"name":     "string",
+		"weight":   0.0,
+		"tags":     []interface{}{"string"},
+		"children": []interface{}{nil},
+	}
+	assert.Equal(suite.T(), expected, api.ExampleValue("test.Node"), "Recursive model example not generated")
+	assert.Nil(suite.T(), api.ExampleValue("test.Unknown"), "Unknown model must not have example")
+
+	assert.Equal(suite.T(), "0", api.ExampleString("int64"), "Basic type example string not generated")
+	assert.Equal(suite.T(), "string", api.ExampleString("string"), "String example must not be quoted")
+	assert.Equal(suite.T(), "", api.ExampleString("test.Unknown"), "Unknown model must not have example string")
+}
+
 func TestApiDeclarationSuite(t *testing.T) {
 	suite.Run(t, &ApiDeclarationSuite{})
 }
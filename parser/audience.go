@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"strings"
+)
+
+// parseAudiences returns the audiences of a comma separated @Audience annotation or audience tag
+func parseAudiences(audienceList string) []string {
+	var audiences []string
+	for _, audience := range strings.Split(audienceList, ",") {
+		if audience = strings.TrimSpace(audience); audience != "" {
+			audiences = append(audiences, audience)
+		}
+	}
+	return audiences
+}
+
+// hasAudience reports if audiences, of an operation or a model property, contain audience. They
+// are for everyone without audiences.
+func hasAudience(audiences []string, audience string) bool {
+	return len(audiences) == 0 || inListFold(audience, audiences)
+}
+
+// FilterAudience returns a copy of the parser with the operations and model properties of audience
+// only: the ones listing it in their @Audience annotation or audience tag, and the ones without.
+// Resources without such operations are dropped, and the models of the copy are copies without
+// the properties of the other audiences.
+func (parser *Parser) FilterAudience(audience string) *Parser {
+	filtered := parser.filterOperations(parser.Listing.ApiVersion, func(apiKey string, op *Operation) bool {
+		return hasAudience(op.Audiences, audience)
+	})
+
+	models := make(map[string]*Model)
+	audienceModel := func(model *Model) *Model {
+		if audienceModel, ok := models[model.Id]; ok {
+			return audienceModel
+		}
+		audienceModel := *model
+		audienceModel.Properties = make(map[string]*ModelProperty, len(model.Properties))
+		for name, property := range model.Properties {
+			if hasAudience(property.Audiences, audience) {
+				audienceModel.Properties[name] = property
+			}
+		}
+		audienceModel.Required = nil
+		for _, name := range model.Required {
+			if _, ok := audienceModel.Properties[name]; ok {
+				audienceModel.Required = append(audienceModel.Required, name)
+			}
+		}
+		models[model.Id] = &audienceModel
+		return &audienceModel
+	}
+
+	for _, api := range filtered.TopLevelApis {
+		for id, model := range api.Models {
+			api.Models[id] = audienceModel(model)
+		}
+	}
+	filtered.Models = make(map[string]*Model, len(models))
+	for id, model := range models {
+		filtered.Models[id] = model
+	}
+	return filtered
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// parseConstraints sets the constraints of a model property from the tags of its field:
+// minimum:"1" maximum:"100" for numbers, minLength:"1" maxLength:"64" pattern:"^[a-z]+$" for strings
+func (p *ModelProperty) parseConstraints(structTag reflect.StructTag) error {
+	for _, bound := range []struct {
+		tag   string
+		value *string
+	}{{"minimum", &p.Minimum}, {"maximum", &p.Maximum}} {
+		if value := structTag.Get(bound.tag); value != "" {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("%s %s is not a number", bound.tag, value)
+			}
+			*bound.value = value
+		}
+	}
+	for _, length := range []struct {
+		tag   string
+		value *int
+	}{{"minLength", &p.MinLength}, {"maxLength", &p.MaxLength}} {
+		if value := structTag.Get(length.tag); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 0 {
+				return fmt.Errorf("%s %s is not a length", length.tag, value)
+			}
+			*length.value = number
+		}
+	}
+	if pattern := structTag.Get("pattern"); pattern != "" {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("Can not parse pattern %s: %v", pattern, err)
+		}
+		p.Pattern = pattern
+	}
+	return nil
+}
+
+// setConstraints sets the constraints of a parameter from the attributes of its @Param:
+// minimum(1) maximum(100) minLength(1) maxLength(64) pattern(^[a-z]+$)
+func (p *Parameter) setConstraints(attributes map[string]string) error {
+	for _, bound := range []struct {
+		attribute string
+		value     *float64
+	}{{"minimum", &p.Minimum}, {"maximum", &p.Maximum}} {
+		if value, ok := attributes[bound.attribute]; ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s(%s) of param %s is not a number", bound.attribute, value, p.Name)
+			}
+			*bound.value = parsed
+		}
+	}
+	for _, length := range []struct {
+		attribute string
+		value     *int
+	}{{"minLength", &p.MinLength}, {"maxLength", &p.MaxLength}} {
+		if value, ok := attributes[length.attribute]; ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				return fmt.Errorf("%s(%s) of param %s is not a length", length.attribute, value, p.Name)
+			}
+			*length.value = parsed
+		}
+	}
+	if pattern, ok := attributes["pattern"]; ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("Can not parse pattern(%s) of param %s: %v", pattern, p.Name, err)
+		}
+		p.Pattern = pattern
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// CoverageItem is an operation or a model of the documentation coverage, Pos is the position of its declaration
+type CoverageItem struct {
+	Pos        token.Position
+	Kind       string // "operation" or "model"
+	Name       string
+	Documented bool
+}
+
+// Coverage is the documentation coverage of the parsed packages, sorted by position
+type Coverage []CoverageItem
+
+// Count returns the number of documented items of kind, all the kinds if kind is empty, and their total number
+func (coverage Coverage) Count(kind string) (documented int, total int) {
+	for _, item := range coverage {
+		if kind != "" && item.Kind != kind {
+			continue
+		}
+		total++
+		if item.Documented {
+			documented++
+		}
+	}
+	return documented, total
+}
+
+// Percent returns the percentage of documented items of kind, all the kinds if kind is empty: 100 if there is none
+func (coverage Coverage) Percent(kind string) float64 {
+	documented, total := coverage.Count(kind)
+	if total == 0 {
+		return 100
+	}
+	return float64(documented) * 100 / float64(total)
+}
+
+// Coverage reports which handlers of packageNames have no annotations, and which models used by the
+// operations have no description in their doc comment. It is called after ParseApi. Handlers are the
+// controllers of IsController and the exported functions taking an http.ResponseWriter; the ones
+// annotated with @Ignore are left out. The error is the one of a package which can not be parsed.
+func (parser *Parser) Coverage(packageNames string) (coverage Coverage, err error) {
+	defer recoverError(&err)
+	for _, packageName := range parser.ScanPackages(strings.Split(packageNames, ",")) {
+		if packageName == "" {
+			continue
+		}
+		astPackages := parser.packageAst(parser.realPackagePath(packageName))
+		for _, astPackage := range sortedPackages(astPackages) {
+			for _, astFile := range sortedFiles(astPackage) {
+				for _, astDescription := range astFile.Decls {
+					funcDeclaration, ok := astDescription.(*ast.FuncDecl)
+					if !ok || !parser.isHandler(funcDeclaration) || hasAnnotation(funcDeclaration.Doc, "@ignore") {
+						continue
+					}
+					name := funcDeclaration.Name.Name
+					if receiver := receiverTypeName(funcDeclaration); receiver != "" {
+						name = receiver + "." + name
+					}
+					coverage = append(coverage, CoverageItem{
+						Pos:        parser.FileSet.Position(funcDeclaration.Pos()),
+						Kind:       "operation",
+						Name:       name,
+						Documented: hasAnnotation(funcDeclaration.Doc, ""),
+					})
+				}
+			}
+		}
+	}
+
+	for id := range parser.Models {
+		source, ok := parser.modelSources[id]
+		if !ok {
+			continue
+		}
+		astTypeSpec := parser.GetModelDefinition(source.Name, source.Path)
+		if astTypeSpec == nil {
+			continue
+		}
+		coverage = append(coverage, CoverageItem{
+			Pos:        source.Pos,
+			Kind:       "model",
+			Name:       id,
+			Documented: hasDescription(astTypeSpec.Doc),
+		})
+	}
+
+	sort.SliceStable(coverage, func(i, j int) bool {
+		if coverage[i].Pos.Filename != coverage[j].Pos.Filename {
+			return coverage[i].Pos.Filename < coverage[j].Pos.Filename
+		}
+		return coverage[i].Pos.Line < coverage[j].Pos.Line
+	})
+	return coverage, nil
+}
+
+// isHandler tells if funcDeclaration is a controller, or an exported function taking an http.ResponseWriter
+func (parser *Parser) isHandler(funcDeclaration *ast.FuncDecl) bool {
+	if parser.IsController != nil && parser.IsController(funcDeclaration) {
+		return true
+	}
+	if funcDeclaration.Recv != nil || !funcDeclaration.Name.IsExported() {
+		return false
+	}
+	for _, param := range funcDeclaration.Type.Params.List {
+		if selector, ok := param.Type.(*ast.SelectorExpr); ok && selector.Sel.Name == "ResponseWriter" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnnotation tells if doc has the annotation, lower cased, or any operation annotation if it is empty
+func hasAnnotation(doc *ast.CommentGroup, annotation string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		fields := strings.Fields(strings.TrimLeft(comment.Text, "/"))
+		if len(fields) == 0 {
+			continue
+		}
+		attribute := strings.ToLower(fields[0])
+		if annotation == "" && inList(attribute, operationAnnotations) || attribute == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDescription tells if doc has a line of text which is not an annotation
+func hasDescription(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "@") {
+			return true
+		}
+	}
+	return false
+}
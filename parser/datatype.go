@@ -0,0 +1,316 @@
+package parser
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// dataTypes are the Swagger data types of Go basic types. Types are kept as Go names while parsing,
+// the other formats rely on them, and are converted when the spec is serialised: an int64 field is
+// an integer of int64 format, so clients use the right wire type. int is 64 bits on most platforms.
+var dataTypes = map[string]typeFormat{
+	"bool":    {"boolean", ""},
+	"int8":    {"integer", "int32"},
+	"int16":   {"integer", "int32"},
+	"int32":   {"integer", "int32"},
+	"uint8":   {"integer", "int32"},
+	"uint16":  {"integer", "int32"},
+	"byte":    {"integer", "int32"},
+	"rune":    {"integer", "int32"},
+	"int":     {"integer", "int64"},
+	"int64":   {"integer", "int64"},
+	"uint":    {"integer", "int64"},
+	"uint32":  {"integer", "int64"},
+	"uint64":  {"integer", "int64"},
+	"uintptr": {"integer", "int64"},
+	"float32": {"number", "float"},
+	"float64": {"number", "double"},
+}
+
+// goDataTypes are the Go types Swagger data types are read back as
+var goDataTypes = map[typeFormat]string{
+	{"boolean", ""}:      "bool",
+	{"integer", ""}:      "int",
+	{"integer", "int32"}: "int32",
+	{"integer", "int64"}: "int64",
+	{"number", ""}:       "float64",
+	{"number", "float"}:  "float32",
+	{"number", "double"}: "float64",
+}
+
+// SwaggerDataType returns the Swagger type and format of a Go type, e.g. integer and int64 for int64.
+// Other types, like string or model Ids, are returned as is. An explicit format is kept.
+func SwaggerDataType(typeName string, format string) (string, string) {
+	dataType, ok := dataTypes[typeName]
+	if !ok {
+		return typeName, format
+	}
+	if format == "" {
+		format = dataType.Format
+	}
+	return dataType.Type, format
+}
+
+// GoDataType is the reverse of SwaggerDataType, for specs read by ReadSpec
+func GoDataType(typeName string, format string) (string, string) {
+	if goType, ok := goDataTypes[typeFormat{typeName, format}]; ok {
+		return goType, ""
+	}
+	if goType, ok := goDataTypes[typeFormat{typeName, ""}]; ok {
+		return goType, format
+	}
+	return typeName, format
+}
+
+// MarshalJSON writes the properties of a model without any, like an interface, as an empty object:
+// the Swagger 1.2 schema requires them
+func (m Model) MarshalJSON() ([]byte, error) {
+	type model Model
+	serialised := model(m)
+	if serialised.Properties == nil {
+		serialised.Properties = make(map[string]*ModelProperty)
+	}
+	return json.Marshal(serialised)
+}
+
+// MarshalJSON leaves out the empty format and items, which the Swagger 1.2 schema rejects
+func (p ModelProperty) MarshalJSON() ([]byte, error) {
+	type property ModelProperty
+	serialised := struct {
+		property
+		Items  *ModelPropertyItems `json:"items,omitempty"`
+		Format string              `json:"format,omitempty"`
+	}{property: property(p)}
+	serialised.Type, serialised.Format = SwaggerDataType(p.Type, p.Format)
+	if p.Items != (ModelPropertyItems{}) {
+		serialised.Items = &p.Items
+	}
+	return json.Marshal(serialised)
+}
+
+func (p *ModelProperty) UnmarshalJSON(data []byte) error {
+	type property ModelProperty
+	if err := json.Unmarshal(data, (*property)(p)); err != nil {
+		return err
+	}
+	p.Type, p.Format = GoDataType(p.Type, p.Format)
+	return nil
+}
+
+// serialisedItems are items of arrays, with the format of their type
+type serialisedItems struct {
+	Ref    string `json:"$ref,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+func (items ModelPropertyItems) MarshalJSON() ([]byte, error) {
+	serialised := serialisedItems{Ref: items.Ref}
+	serialised.Type, serialised.Format = SwaggerDataType(items.Type, "")
+	return json.Marshal(serialised)
+}
+
+func (items *ModelPropertyItems) UnmarshalJSON(data []byte) error {
+	var serialised serialisedItems
+	if err := json.Unmarshal(data, &serialised); err != nil {
+		return err
+	}
+	items.Ref = serialised.Ref
+	items.Type, _ = GoDataType(serialised.Type, serialised.Format)
+	return nil
+}
+
+func (items OperationItems) MarshalJSON() ([]byte, error) {
+	return ModelPropertyItems(items).MarshalJSON()
+}
+
+func (items *OperationItems) UnmarshalJSON(data []byte) error {
+	return (*ModelPropertyItems)(items).UnmarshalJSON(data)
+}
+
+// MarshalJSON writes minimum and maximum as strings, as Swagger 1.2 does, and leaves them out when
+// they are not set, like the empty format
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	type parameter Parameter
+	serialised := struct {
+		parameter
+		Format  string `json:"format,omitempty"`
+		Minimum string `json:"minimum,omitempty"`
+		Maximum string `json:"maximum,omitempty"`
+	}{parameter: parameter(p)}
+	serialised.Type, serialised.Format = SwaggerDataType(p.Type, p.Format)
+	serialised.DataType, _ = SwaggerDataType(p.DataType, p.Format)
+	if p.Minimum != 0 {
+		serialised.Minimum = strconv.FormatFloat(p.Minimum, 'g', -1, 64)
+	}
+	if p.Maximum != 0 {
+		serialised.Maximum = strconv.FormatFloat(p.Maximum, 'g', -1, 64)
+	}
+	return json.Marshal(serialised)
+}
+
+// UnmarshalJSON reads minimum and maximum written as strings or, by older versions, as numbers
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+	type parameter Parameter
+	serialised := struct {
+		*parameter
+		Minimum json.Number `json:"minimum"`
+		Maximum json.Number `json:"maximum"`
+	}{parameter: (*parameter)(p)}
+	if err := json.Unmarshal(data, &serialised); err != nil {
+		return err
+	}
+	for _, bound := range []struct {
+		number json.Number
+		value  *float64
+	}{{serialised.Minimum, &p.Minimum}, {serialised.Maximum, &p.Maximum}} {
+		if bound.number == "" {
+			continue
+		}
+		parsed, err := bound.number.Float64()
+		if err != nil {
+			return err
+		}
+		*bound.value = parsed
+	}
+	format := p.Format
+	p.Type, p.Format = GoDataType(p.Type, format)
+	p.DataType, _ = GoDataType(p.DataType, format)
+	return nil
+}
+
+// MarshalJSON writes the method as "method", the key of Swagger 1.2, and "httpMethod", the one of
+// Swagger 1.1 older clients read. The parameters are always written, the schema requires them.
+func (operation Operation) MarshalJSON() ([]byte, error) {
+	type serialisedOperation Operation
+	serialised := struct {
+		serialisedOperation
+		Method     string          `json:"method"`
+		Type       string          `json:"type"`
+		Format     string          `json:"format,omitempty"`
+		Items      *OperationItems `json:"items,omitempty"`
+		Parameters []Parameter     `json:"parameters"`
+	}{serialisedOperation: serialisedOperation(operation), Method: operation.HttpMethod, Parameters: operation.Parameters}
+	serialised.Type, serialised.Format = SwaggerDataType(operation.Type, "")
+	if operation.Items != (OperationItems{}) {
+		serialised.Items = &operation.Items
+	}
+	if serialised.Parameters == nil {
+		serialised.Parameters = []Parameter{}
+	}
+	data, err := json.Marshal(serialised)
+	if err != nil {
+		return nil, err
+	}
+	extensions, err := operation.serialisedExtensions()
+	if err != nil {
+		return nil, err
+	}
+	return AppendExtensions(data, extensions), nil
+}
+
+func (operation *Operation) UnmarshalJSON(data []byte) error {
+	type serialisedOperation Operation
+	serialised := struct {
+		*serialisedOperation
+		Method string `json:"method"`
+		Format string `json:"format"`
+	}{serialisedOperation: (*serialisedOperation)(operation)}
+	if err := json.Unmarshal(data, &serialised); err != nil {
+		return err
+	}
+	if operation.HttpMethod == "" {
+		operation.HttpMethod = serialised.Method
+	}
+	operation.Type, _ = GoDataType(operation.Type, serialised.Format)
+	extensions, err := ReadExtensions(data)
+	if err != nil {
+		return err
+	}
+	operation.Extensions = extensions
+	return operation.readSerialisedExtensions()
+}
+
+// serialisedExtensions returns the extensions of the operation with its x-websocket and x-events extensions
+func (operation *Operation) serialisedExtensions() (Extensions, error) {
+	extensions := operation.Extensions
+	var err error
+	if operation.Websocket != nil {
+		if extensions, err = WithExtension(extensions, "x-websocket", operation.Websocket); err != nil {
+			return nil, err
+		}
+	}
+	if len(operation.Events) > 0 {
+		if extensions, err = WithExtension(extensions, "x-events", operation.Events); err != nil {
+			return nil, err
+		}
+	}
+	return extensions, nil
+}
+
+// readSerialisedExtensions moves the x-websocket and x-events extensions read from the spec to the
+// Websocket and the Events of the operation
+func (operation *Operation) readSerialisedExtensions() error {
+	if websocket, ok := operation.Extensions["x-websocket"]; ok {
+		operation.Websocket = &Websocket{}
+		if err := json.Unmarshal(websocket, operation.Websocket); err != nil {
+			return err
+		}
+		delete(operation.Extensions, "x-websocket")
+	}
+	if events, ok := operation.Extensions["x-events"]; ok {
+		if err := json.Unmarshal(events, &operation.Events); err != nil {
+			return err
+		}
+		delete(operation.Extensions, "x-events")
+	}
+	if len(operation.Extensions) == 0 {
+		operation.Extensions = nil
+	}
+	return nil
+}
+
+// MarshalJSON leaves the info out when it is empty, the Swagger 1.2 schema requires its title and description
+func (listing ResourceListing) MarshalJSON() ([]byte, error) {
+	type serialisedListing ResourceListing
+	serialised := struct {
+		serialisedListing
+		Infos *Infomation `json:"info,omitempty"`
+	}{serialisedListing: serialisedListing(listing)}
+	if listing.Infos != (Infomation{}) {
+		serialised.Infos = &listing.Infos
+	}
+	data, err := json.Marshal(serialised)
+	if err != nil {
+		return nil, err
+	}
+	extensions := listing.Extensions
+	if len(listing.Servers) > 0 {
+		if extensions, err = WithExtension(extensions, "x-servers", listing.Servers); err != nil {
+			return nil, err
+		}
+	}
+	return AppendExtensions(data, extensions), nil
+}
+
+func (listing *ResourceListing) UnmarshalJSON(data []byte) error {
+	type serialisedListing ResourceListing
+	if err := json.Unmarshal(data, (*serialisedListing)(listing)); err != nil {
+		return err
+	}
+	extensions, err := ReadExtensions(data)
+	if err != nil {
+		return err
+	}
+	listing.Extensions = extensions
+	if servers, ok := listing.Extensions["x-servers"]; ok {
+		if err := json.Unmarshal(servers, &listing.Servers); err != nil {
+			return err
+		}
+		delete(listing.Extensions, "x-servers")
+		if len(listing.Extensions) == 0 {
+			listing.Extensions = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Dialects of the annotations, set by Parser.Dialect
+const (
+	DialectNative      = "native"
+	DialectSwaggo      = "swaggo"    // github.com/swaggo/swag
+	DialectGoSwagger   = "goswagger" // github.com/go-swagger/go-swagger
+	AVAILABLE_DIALECTS = "native|swaggo|goswagger"
+)
+
+// swaggoGeneralAnnotations are the general annotations of swaggo, lower cased, and the annotations
+// of this parser they stand for: "" for the ones which can not be documented in Swagger 1.2
+var swaggoGeneralAnnotations = map[string]string{
+	"@title":                    "@apititle",
+	"@version":                  "@apiversion",
+	"@description":              "@apidescription",
+	"@termsofservice":           "@termsofserviceurl",
+	"@query.collection.format":  "",
+	"@accept":                   "",
+	"@produce":                  "",
+	"@tag.name":                 "",
+	"@tag.description":          "",
+	"@tag.docs.url":             "",
+	"@tag.docs.description":     "",
+	"@externaldocs.url":         "",
+	"@externaldocs.description": "",
+}
+
+// swaggoOperationAnnotations are the annotations of the operations of swaggo, lower cased, which are
+// not annotations of this parser, or have another meaning
+var swaggoOperationAnnotations = []string{"@summary", "@description", "@id", "@tags", "@header", "@security", "@deprecated", "@x-codesamples", "@state"}
+
+// swaggoTypes are the types of swaggo params and responses, and the Go types they stand for
+var swaggoTypes = map[string]string{
+	"integer": "int",
+	"number":  "float64",
+	"boolean": "bool",
+}
+
+// swaggoAttributeRegexp matches the attributes following the description of a swaggo @Param which
+// are not attributes of this parser: Enums(A, B), default(1), example(a)...
+var swaggoAttributeRegexp = regexp.MustCompile(`\s(Enums|default|example|collectionFormat|minItems|maxItems|uniqueItems|multipleOf|extensions)\([^)]*\)`)
+
+// swaggoAnnotation translates a swaggo annotation of an operation to the annotation of this parser, ""
+// for the annotations which can not be documented in Swagger 1.2, like @Security or @Header. The
+// array params of swaggo, @Param ids query []int true "IDs", are allowMultiple params, and its
+// @x-name {json} annotations are extensions.
+func swaggoAnnotation(commentLine string) (annotation string, allowMultiple bool) {
+	attribute := strings.Fields(commentLine)[0]
+	value := strings.TrimSpace(commentLine[len(attribute):])
+	switch lowerAttribute := strings.ToLower(attribute); {
+	case lowerAttribute == "@summary":
+		return "@Description " + value, false
+	case lowerAttribute == "@description":
+		return "@Notes " + value, false
+	case lowerAttribute == "@id":
+		return "@Title " + value, false
+	case lowerAttribute == "@tags":
+		return "@Resource " + strings.TrimSpace(strings.Split(value, ",")[0]), false
+	case lowerAttribute == "@param":
+		fields := strings.Fields(value)
+		if len(fields) < 4 {
+			return commentLine, false
+		}
+		name, paramType, dataType, required := fields[0], fields[1], fields[2], fields[3]
+		if paramType == "formData" {
+			paramType = "form"
+		}
+		if strings.HasPrefix(dataType, "[]") {
+			dataType, allowMultiple = dataType[len("[]"):], true
+		}
+		description := swaggoAttributeRegexp.ReplaceAllString(" "+afterFields(value, 4), "")
+		description = strings.NewReplacer("minlength(", "minLength(", "maxlength(", "maxLength(").Replace(description)
+		return strings.Join([]string{attribute, name, paramType, swaggoType(dataType), required, strings.TrimSpace(description)}, " "), allowMultiple
+	case lowerAttribute == "@success" || lowerAttribute == "@failure":
+		fields := strings.Fields(value)
+		if len(fields) < 3 {
+			return commentLine, false
+		}
+		code, responseType, dataType := fields[0], fields[1], fields[2]
+		if strings.HasPrefix(dataType, "[]") {
+			responseType, dataType = "{array}", dataType[len("[]"):]
+		}
+		message := afterFields(value, 3)
+		return strings.TrimSpace(strings.Join([]string{attribute, code, responseType, swaggoType(dataType), message}, " ")), false
+	case inList(lowerAttribute, swaggoOperationAnnotations):
+		return "", false
+	case strings.HasPrefix(lowerAttribute, "@x-"):
+		return "@Extension " + attribute[len("@"):] + " " + value, false
+	}
+	return commentLine, false
+}
+
+// afterFields returns what follows the first n fields of value
+func afterFields(value string, n int) string {
+	for i := 0; i < n; i++ {
+		value = strings.TrimLeftFunc(value, unicode.IsSpace)
+		if end := strings.IndexFunc(value, unicode.IsSpace); end >= 0 {
+			value = value[end:]
+		} else {
+			return ""
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// swaggoType returns the Go type of a swaggo type
+func swaggoType(dataType string) string {
+	if goType, ok := swaggoTypes[dataType]; ok {
+		return goType
+	}
+	return dataType
+}
+
+// swaggoGeneralAnnotation translates a swaggo general annotation, lower cased, to the annotation of
+// this parser, "" for the ones which can not be documented in Swagger 1.2
+func swaggoGeneralAnnotation(attribute string) string {
+	if annotation, ok := swaggoGeneralAnnotations[attribute]; ok {
+		return annotation
+	}
+	if strings.HasPrefix(attribute, "@securitydefinitions") || strings.HasPrefix(attribute, "@x-") {
+		return ""
+	}
+	return attribute
+}
@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"strconv"
+)
+
+// parseEnums collects the values of the constants of the named types of typeDefinitions, declared by
+// constDeclarations: they are the enum of the type.
+//
+//	type Status string
+//
+//	const (
+//		Active   Status = "active"
+//		Disabled Status = "disabled"
+//	)
+//
+// The constants of a block without value repeat the previous one, so iota enums are found too.
+// Constants whose value can not be computed from literals and iota are skipped.
+func (parser *Parser) parseEnums(constDeclarations []*ast.GenDecl, typeDefinitions map[string]*ast.TypeSpec) {
+	enums := make(map[*ast.TypeSpec][]string)
+	for _, constDeclaration := range constDeclarations {
+		var typeExpr ast.Expr
+		var values []ast.Expr
+		for iota, astSpec := range constDeclaration.Specs {
+			valueSpec := astSpec.(*ast.ValueSpec)
+			if len(valueSpec.Values) > 0 {
+				typeExpr, values = valueSpec.Type, valueSpec.Values
+			}
+			for i, name := range valueSpec.Names {
+				if name.Name == "_" || i >= len(values) {
+					continue
+				}
+				typeName := ""
+				value := values[i]
+				if ident, ok := typeExpr.(*ast.Ident); ok {
+					typeName = ident.Name
+				} else if call, ok := value.(*ast.CallExpr); ok && typeExpr == nil && len(call.Args) == 1 {
+					// Active = Status("active")
+					if ident, ok := call.Fun.(*ast.Ident); ok {
+						typeName, value = ident.Name, call.Args[0]
+					}
+				}
+				typeSpec, ok := typeDefinitions[typeName]
+				if !ok {
+					continue
+				}
+				if enumValue, ok := constValue(value, iota); ok {
+					enums[typeSpec] = append(enums[typeSpec], enumValue)
+				}
+			}
+		}
+	}
+	for typeSpec, values := range enums {
+		parser.enums[typeSpec] = values
+	}
+}
+
+// constValue returns the value of a constant expression made of literals and iota, as an enum value
+func constValue(expr ast.Expr, iota int) (enumValue string, ok bool) {
+	// go/constant panics on invalid operations, like "a" - 1 or 1 / 0
+	defer func() {
+		if recover() != nil {
+			enumValue, ok = "", false
+		}
+	}()
+	value := constExpr(expr, iota)
+	switch value.Kind() {
+	case constant.String:
+		return constant.StringVal(value), true
+	case constant.Int:
+		return value.ExactString(), true
+	case constant.Float:
+		float, _ := constant.Float64Val(value)
+		return strconv.FormatFloat(float, 'g', -1, 64), true
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(value)), true
+	}
+	return "", false
+}
+
+func constExpr(expr ast.Expr, iota int) constant.Value {
+	switch typed := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(typed.Value, typed.Kind, 0)
+	case *ast.Ident:
+		switch typed.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota))
+		case "true", "false":
+			return constant.MakeBool(typed.Name == "true")
+		}
+	case *ast.ParenExpr:
+		return constExpr(typed.X, iota)
+	case *ast.UnaryExpr:
+		return constant.UnaryOp(typed.Op, constExpr(typed.X, iota), 0)
+	case *ast.BinaryExpr:
+		x, y := constExpr(typed.X, iota), constExpr(typed.Y, iota)
+		if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+			return constant.MakeUnknown()
+		}
+		switch typed.Op {
+		case token.SHL, token.SHR:
+			if shift, ok := constant.Uint64Val(y); ok {
+				return constant.Shift(x, typed.Op, uint(shift))
+			}
+			return constant.MakeUnknown()
+		case token.QUO:
+			// the division of integer constants is an integer division
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+		}
+		return constant.BinaryOp(x, typed.Op, y)
+	}
+	return constant.MakeUnknown()
+}
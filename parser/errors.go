@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// parseError is the panic value of failf. The parser gives up on the errors it can not skip, and its
+// entry points return them instead of exiting, so that parsers can run side by side in a server.
+type parseError struct {
+	err error
+}
+
+// Error is the message of the error the parsing was aborted with
+func (abort parseError) Error() string {
+	return abort.err.Error()
+}
+
+// failf aborts the parsing with an error, which is returned by the entry point (ParseApi...)
+func failf(format string, args ...interface{}) {
+	panic(parseError{fmt.Errorf(format, args...)})
+}
+
+// recoverError sets *err to the error failf aborted the parsing with. Every exported method which
+// can reach failf defers it, the unexported ones let the abort through to their caller.
+func recoverError(err *error) {
+	if r := recover(); r != nil {
+		abort, ok := r.(parseError)
+		if !ok {
+			panic(r)
+		}
+		*err = abort
+	}
+}
+
+// isAborted reports if err is the one of failf, recovered by recoverError, rather than a malformed annotation
+func isAborted(err error) bool {
+	_, ok := err.(parseError)
+	return ok
+}
+
+// ErrorList is the errors of the annotations, which do not stop the parsing: they are collected
+// and returned together by ParseGeneralApiInfo and ParseApi, so that they can all be fixed at once
+type ErrorList []LintIssue
+
+// Error lists the errors sorted by position, grouped by file
+func (list ErrorList) Error() string {
+	sorted := append(ErrorList{}, list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Pos.Filename != sorted[j].Pos.Filename {
+			return sorted[i].Pos.Filename < sorted[j].Pos.Filename
+		}
+		return sorted[i].Pos.Line < sorted[j].Pos.Line
+	})
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "%d annotation error(s):\n", len(sorted))
+	for i, issue := range sorted {
+		if i == 0 || issue.Pos.Filename != sorted[i-1].Pos.Filename {
+			fmt.Fprintf(&message, "%s:\n", issue.Pos.Filename)
+		}
+		fmt.Fprintf(&message, "    %d:%d: %s\n", issue.Pos.Line, issue.Pos.Column, issue.Message)
+	}
+	return message.String()
+}
+
+// addError collects the error of the annotation at pos
+func (parser *Parser) addError(pos token.Position, err error) {
+	parser.errors = append(parser.errors, LintIssue{Pos: pos, Message: strings.TrimSpace(err.Error())})
+}
+
+// collectedErrors returns the errors collected so far, nil if there is none
+func (parser *Parser) collectedErrors() error {
+	if len(parser.errors) == 0 {
+		return nil
+	}
+	return append(ErrorList{}, parser.errors...)
+}
@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExampleValue returns a sample value of typeName (basic type, model Id or "array[...]"), ready for JSON serialisation.
+// Models are looked up in api.Models, recursive references are cut with nil.
+func (api *ApiDeclaration) ExampleValue(typeName string) interface{} {
+	return api.exampleValue(typeName, map[string]bool{})
+}
+
+// ExampleString returns the sample value of typeName as text, for the values of params and headers,
+// "" when there is none
+func (api *ApiDeclaration) ExampleString(typeName string) string {
+	switch example := api.ExampleValue(typeName).(type) {
+	case nil:
+		return ""
+	case string:
+		return example
+	default:
+		return fmt.Sprint(example)
+	}
+}
+
+func (api *ApiDeclaration) exampleValue(typeName string, seenModels map[string]bool) interface{} {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		return []interface{}{api.exampleValue(typeName[len("array["):len(typeName)-1], seenModels)}
+	}
+
+	switch typeName {
+	case "bool":
+		return true
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune", "uintptr":
+		return 0
+	case "float32", "float64", "complex64", "complex128":
+		return 0.0
+	case "string", "error":
+		return "string"
+	case "Time":
+		return "2006-01-02T15:04:05Z"
+	}
+
+	model, ok := api.Models[typeName]
+	if !ok {
+		if strings.Contains(typeName, "interface") {
+			return map[string]interface{}{}
+		}
+		return nil
+	}
+	if seenModels[model.Id] {
+		return nil
+	}
+	seenModels[model.Id] = true
+	defer delete(seenModels, model.Id)
+
+	example := make(map[string]interface{}, len(model.Properties))
+	for name, property := range model.Properties {
+		example[name] = api.propertyExampleValue(property, seenModels)
+	}
+	return example
+}
+
+func (api *ApiDeclaration) propertyExampleValue(property *ModelProperty, seenModels map[string]bool) interface{} {
+	if property.Type != "array" {
+		return api.exampleValue(property.Type, seenModels)
+	}
+	itemType := property.Items.Type
+	if itemType == "" {
+		itemType = property.Items.Ref
+	}
+	return []interface{}{api.exampleValue(itemType, seenModels)}
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Extensions are the x- vendor extensions of an object, like x-amazon-apigateway-integration, kept as
+// their JSON value to be written verbatim
+type Extensions map[string]json.RawMessage
+
+// parseExtension parses the value of an @Extension annotation: x-name and its JSON value
+//
+//	@Extension x-amazon-apigateway-integration {"type": "http_proxy", "httpMethod": "GET"}
+func parseExtension(value string) (string, json.RawMessage, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("Can not parse extension comment \"%s\", expected: @Extension x-name {json}", value)
+	}
+	name := fields[0]
+	if !strings.HasPrefix(name, "x-") {
+		return "", nil, fmt.Errorf("Extension %s must start with x-", name)
+	}
+	extension := json.RawMessage(strings.TrimSpace(value[len(name):]))
+	if !json.Valid(extension) {
+		return "", nil, fmt.Errorf("Value of extension %s is not valid JSON: %s", name, extension)
+	}
+	return name, extension, nil
+}
+
+// add adds the extension of an @Extension annotation, *extensions is created if needed
+func (extensions *Extensions) add(value string) error {
+	name, extension, err := parseExtension(value)
+	if err != nil {
+		return err
+	}
+	if *extensions == nil {
+		*extensions = make(Extensions)
+	}
+	(*extensions)[name] = extension
+	return nil
+}
+
+// WithExtension returns a copy of extensions with the extension name, the JSON value of value
+func WithExtension(extensions Extensions, name string, value interface{}) (Extensions, error) {
+	extension, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	copied := make(Extensions, len(extensions)+1)
+	for existing, existingExtension := range extensions {
+		copied[existing] = existingExtension
+	}
+	copied[name] = extension
+	return copied, nil
+}
+
+// AppendExtensions adds the extensions, sorted by name, to the end of the serialised JSON object data
+func AppendExtensions(data []byte, extensions Extensions) []byte {
+	if len(extensions) == 0 {
+		return data
+	}
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSuffix(bytes.TrimSpace(data), []byte("}")))
+	for i, name := range names {
+		if i > 0 || !bytes.HasSuffix(bytes.TrimSpace(buf.Bytes()), []byte("{")) {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(extensions[name])
+	}
+	buf.WriteString("}")
+	return buf.Bytes()
+}
+
+// ReadExtensions returns the x- members of the serialised JSON object data
+func ReadExtensions(data []byte) (Extensions, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	var extensions Extensions
+	for name, value := range members {
+		if strings.HasPrefix(name, "x-") {
+			if extensions == nil {
+				extensions = make(Extensions)
+			}
+			extensions[name] = value
+		}
+	}
+	return extensions, nil
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Filter returns a copy of the parser with the resources (the tags of the Swagger 2.0 and OpenAPI 3.0
+// specs) of includeTags only, or all of them if includeTags is empty, without the operations
+// whose path is one of excludePaths or under one of them. Resources left without operations are dropped.
+func (parser *Parser) Filter(includeTags, excludePaths []string) *Parser {
+	return parser.filterOperations(parser.Listing.ApiVersion, func(apiKey string, op *Operation) bool {
+		if len(includeTags) > 0 && !inListFold(apiKey, includeTags) {
+			return false
+		}
+		for _, excludedPath := range excludePaths {
+			if excludedPath = "/" + strings.Trim(excludedPath, "/"); op.Path == excludedPath || strings.HasPrefix(op.Path, strings.TrimSuffix(excludedPath, "/")+"/") {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// filterOperations returns a copy of the parser with the operations keep returns true for, with
+// apiVersion as API version. Resources without such operations are dropped.
+func (parser *Parser) filterOperations(apiVersion string, keep func(apiKey string, op *Operation) bool) *Parser {
+	filtered := *parser
+	filtered.Listing = &ResourceListing{
+		ApiVersion:     apiVersion,
+		SwaggerVersion: parser.Listing.SwaggerVersion,
+		Apis:           make([]*ApiRef, 0),
+		Infos:          parser.Listing.Infos,
+	}
+	filtered.TopLevelApis = make(map[string]*ApiDeclaration)
+
+	for apiKey, api := range parser.TopLevelApis {
+		filteredApi := NewApiDeclaration()
+		filteredApi.ApiVersion = apiVersion
+		filteredApi.SwaggerVersion = api.SwaggerVersion
+		filteredApi.BasePath = api.BasePath
+		filteredApi.ResourcePath = api.ResourcePath
+
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				if keep(apiKey, op) {
+					filteredApi.AddOperation(op)
+				}
+			}
+		}
+		if len(filteredApi.Apis) > 0 {
+			filtered.TopLevelApis[apiKey] = filteredApi
+		}
+	}
+
+	for _, ref := range parser.Listing.Apis {
+		if _, ok := filtered.TopLevelApis[strings.Trim(ref.Path, "/")]; ok {
+			filtered.Listing.Apis = append(filtered.Listing.Apis, ref)
+		}
+	}
+	return &filtered
+}
+
+// inListFold reports if value is in list, ignoring case
+func inListFold(value string, list []string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
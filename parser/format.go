@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// typeFormat is the swagger type and format of a Go type
+type typeFormat struct {
+	Type   string
+	Format string
+}
+
+// knownFormats are common types marshaled as formatted strings, they are not parsed as models
+var knownFormats = map[string]typeFormat{
+	"uuid.UUID":     {"string", "uuid"}, // github.com/google/uuid, github.com/satori/go.uuid
+	"uuid.NullUUID": {"string", "uuid"}, // github.com/google/uuid
+	"net.IP":        {"string", "ip"},   // IPv4 or IPv6
+	"url.URL":       {"string", "uri"},
+}
+
+// paramAttributeRegexp matches the start of an attribute following the description of a @Param,
+// like format(uuid) or pattern(^[a-z]+$)
+var paramAttributeRegexp = regexp.MustCompile(`\s(format|minimum|maximum|minLength|maxLength|pattern|contentType)\(`)
+
+// KnownFormat returns the swagger type and format of typeName, e.g. string and uuid for uuid.UUID
+func KnownFormat(typeName string) (string, string, bool) {
+	known, ok := knownFormats[strings.TrimPrefix(typeName, "*")]
+	return known.Type, known.Format, ok
+}
+
+// parseParamAttributes removes the attributes following the description of a @Param,
+// `"User ID" format(uuid) minLength(36)`, and returns them by name. The value of an attribute is
+// everything up to the closing parenthesis of the next attribute, so patterns may contain parentheses.
+func parseParamAttributes(description string) (string, map[string]string) {
+	attributes := make(map[string]string)
+	for {
+		description = strings.TrimRightFunc(description, unicode.IsSpace)
+		starts := paramAttributeRegexp.FindAllStringSubmatchIndex(description, -1)
+		if len(starts) == 0 || !strings.HasSuffix(description, ")") {
+			return description, attributes
+		}
+		last := starts[len(starts)-1]
+		attributes[description[last[2]:last[3]]] = strings.TrimSpace(description[last[1] : len(description)-1])
+		description = description[:last[0]]
+	}
+}
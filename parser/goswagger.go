@@ -0,0 +1,296 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
+)
+
+// goSwaggerStruct is a struct annotated with swagger:parameters or swagger:response, in packageName
+type goSwaggerStruct struct {
+	fields      []*ast.Field
+	description string
+	packageName string
+}
+
+// goSwaggerSectionRegexp matches the start of a section of a swagger:route comment, like "Responses:"
+var goSwaggerSectionRegexp = regexp.MustCompile(`^(?i)(responses|consumes|produces|schemes|security|deprecated|parameters|extensions):\s*(.*)$`)
+
+// parseGoSwaggerDeclarations collects the structs of packageName annotated with swagger:parameters
+// (or swagger:params) and swagger:response, which swagger:route comments refer to. The
+// swagger:model annotations need nothing: the models are the types the operations use.
+func (parser *Parser) parseGoSwaggerDeclarations(packageName string) {
+	astPackages := parser.packageAst(parser.realPackagePath(packageName))
+	for _, astPackage := range sortedPackages(astPackages) {
+		for _, astFile := range sortedFiles(astPackage) {
+			for _, astDeclaration := range astFile.Decls {
+				generalDeclaration, ok := astDeclaration.(*ast.GenDecl)
+				if !ok || generalDeclaration.Tok != token.TYPE {
+					continue
+				}
+				for _, astSpec := range generalDeclaration.Specs {
+					typeSpec := astSpec.(*ast.TypeSpec)
+					structType, ok := typeSpec.Type.(*ast.StructType)
+					doc := typeSpec.Doc
+					if doc == nil && len(generalDeclaration.Specs) == 1 {
+						doc = generalDeclaration.Doc
+					}
+					if !ok || doc == nil {
+						continue
+					}
+					parser.addGoSwaggerStruct(doc.Text(), goSwaggerStruct{fields: structType.Fields.List, packageName: packageName})
+				}
+			}
+		}
+	}
+}
+
+func (parser *Parser) addGoSwaggerStruct(doc string, declaration goSwaggerStruct) {
+	var description []string
+	var parameters []string
+	response := ""
+	for _, line := range strings.Split(doc, "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "swagger:parameters" || fields[0] == "swagger:params":
+			parameters = append(parameters, fields[1:]...)
+		case fields[0] == "swagger:response" && len(fields) > 1:
+			response = fields[1]
+		case !strings.HasPrefix(fields[0], "swagger:"):
+			description = append(description, strings.TrimSpace(line))
+		}
+	}
+	declaration.description = strings.Join(description, " ")
+	for _, operationId := range parameters {
+		parser.goSwaggerParameters[operationId] = append(parser.goSwaggerParameters[operationId], declaration)
+	}
+	if response != "" {
+		parser.goSwaggerResponses[response] = declaration
+	}
+}
+
+// parseGoSwaggerRoutes adds the operations of the swagger:route comments of astFile:
+//
+//	swagger:route GET /pets/{id} pets getPet
+//
+//	Summary of the operation.
+//
+//	Description of the operation.
+//
+//	Produces:
+//	- application/json
+//
+//	Responses:
+//	  200: petResponse
+//	  404: body:APIError
+func (parser *Parser) parseGoSwaggerRoutes(packageName string, astFile *ast.File) {
+	for _, astComment := range astFile.Comments {
+		lines := strings.Split(astComment.Text(), "\n")
+		for i, line := range lines {
+			if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "swagger:route" {
+				parser.parseGoSwaggerRoute(packageName, lines[i:], astComment.Pos())
+				break
+			}
+		}
+	}
+}
+
+func (parser *Parser) parseGoSwaggerRoute(packageName string, lines []string, pos token.Pos) {
+	route := strings.Fields(lines[0])
+	if len(route) < 4 {
+		parser.addError(parser.FileSet.Position(pos), fmt.Errorf("Can not parse route comment %q, expected: swagger:route METHOD /path [tags] operationId", lines[0]))
+		return
+	}
+	operationId := route[len(route)-1]
+	operation := NewOperation(parser, packageName)
+	annotations := []string{
+		fmt.Sprintf("@Router %s [%s]", route[2], route[1]),
+		"@Title " + operationId,
+	}
+	if tags := route[3 : len(route)-1]; len(tags) > 0 {
+		annotations = append(annotations, "@Resource "+tags[0])
+	}
+
+	// the summary is the first paragraph, the description the next ones, up to the first section
+	var paragraphs []string
+	section, paragraph := "", ""
+	sections := map[string][]string{}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if matches := goSwaggerSectionRegexp.FindStringSubmatch(line); matches != nil {
+			section = strings.ToLower(matches[1])
+			line = matches[2]
+		}
+		switch {
+		case section != "":
+			if line = strings.TrimSpace(strings.TrimPrefix(line, "-")); line != "" {
+				sections[section] = append(sections[section], line)
+			}
+		case line == "" && paragraph != "":
+			paragraphs, paragraph = append(paragraphs, paragraph), ""
+		case line != "":
+			paragraph = strings.TrimSpace(paragraph + " " + line)
+		}
+	}
+	if paragraph != "" {
+		paragraphs = append(paragraphs, paragraph)
+	}
+	if len(paragraphs) > 0 {
+		annotations = append(annotations, "@Description "+paragraphs[0])
+	}
+	if len(paragraphs) > 1 {
+		annotations = append(annotations, "@Notes "+strings.Join(paragraphs[1:], "\n\n"))
+	}
+	if consumes := sections["consumes"]; len(consumes) > 0 {
+		annotations = append(annotations, "@Accept "+strings.Join(consumes, ","))
+	}
+	if produces := sections["produces"]; len(produces) > 0 {
+		annotations = append(annotations, "@Produce "+strings.Join(produces, ","))
+	}
+	for _, annotation := range annotations {
+		parser.parseAnnotation(operation, annotation, pos, operationId)
+	}
+
+	for _, declaration := range parser.goSwaggerParameters[operationId] {
+		for _, field := range declaration.fields {
+			parser.parseGoSwaggerParameter(operation, field, declaration.packageName)
+		}
+	}
+	for _, response := range sections["responses"] {
+		parser.parseGoSwaggerResponse(operation, response, pos)
+	}
+	if operation.Path != "" {
+		parser.AddOperation(operation)
+	}
+}
+
+// parseGoSwaggerParameter adds the param of a field of a swagger:parameters struct, its location
+// and if it is required are given by the "in: query" and "required: true" lines of its doc comment
+func (parser *Parser) parseGoSwaggerParameter(operation *Operation, field *ast.Field, packageName string) {
+	if len(field.Names) == 0 {
+		logger.Warnf("Embedded field %s of the parameters of %s is skipped\n", annotationType(field.Type), operation.Nickname)
+		return
+	}
+	name := field.Names[0].Name
+	if field.Tag != nil {
+		if jsonName := strings.Split(reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json"), ",")[0]; jsonName == "-" {
+			return
+		} else if jsonName != "" {
+			name = jsonName
+		}
+	}
+
+	paramType, required := "query", false
+	var description []string
+	if field.Doc != nil {
+		for _, line := range strings.Split(field.Doc.Text(), "\n") {
+			key, value := "", strings.TrimSpace(line)
+			if colon := strings.Index(value, ":"); colon > 0 {
+				key = strings.ToLower(value[:colon])
+			}
+			switch key {
+			case "in":
+				paramType = strings.TrimSpace(value[len("in:"):])
+			case "required":
+				required, _ = strconv.ParseBool(strings.TrimSpace(value[len("required:"):]))
+			default:
+				if value != "" {
+					description = append(description, value)
+				}
+			}
+		}
+	}
+	if paramType == "formData" {
+		paramType = "form"
+	}
+
+	dataType := annotationType(field.Type)
+	allowMultiple := strings.HasPrefix(dataType, "[]")
+	dataType = strings.TrimPrefix(dataType, "[]")
+	annotation := fmt.Sprintf("@Param %s %s %s %t \"%s\"", name, paramType, dataType, required, strings.Replace(strings.Join(description, " "), `"`, `\"`, -1))
+
+	count := len(operation.Parameters)
+	parser.parseDeclarationAnnotation(operation, annotation, field.Pos(), packageName)
+	if len(operation.Parameters) > count {
+		operation.Parameters[count].AllowMultiple = allowMultiple
+	}
+}
+
+// parseGoSwaggerResponse adds a response of the Responses section of a route, "200: petResponse" for
+// the body of a swagger:response struct, "404: body:APIError" for a model
+func (parser *Parser) parseGoSwaggerResponse(operation *Operation, response string, pos token.Pos) {
+	parts := strings.SplitN(response, ":", 2)
+	code, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || len(parts) < 2 {
+		logger.Debugf("Response %q of %s is skipped, Swagger 1.2 responses need a code", response, operation.Nickname)
+		return
+	}
+
+	name := strings.TrimSpace(parts[1])
+	if strings.HasPrefix(name, "body:") {
+		parser.addGoSwaggerResponse(operation, code, strings.TrimPrefix(name, "body:"), "", operation.packageName, pos)
+		return
+	}
+	declaration, ok := parser.goSwaggerResponses[name]
+	if !ok {
+		parser.addError(parser.FileSet.Position(pos), fmt.Errorf("Can not find the swagger:response %s of %s", name, operation.Nickname))
+		return
+	}
+	for _, field := range declaration.fields {
+		if len(field.Names) > 0 && (field.Names[0].Name == "Body" || field.Doc != nil && strings.Contains(field.Doc.Text(), "in: body")) {
+			parser.addGoSwaggerResponse(operation, code, annotationType(field.Type), declaration.description, declaration.packageName, field.Pos())
+			return
+		}
+	}
+	// responses without body have no model
+	operation.ResponseMessages = append(operation.ResponseMessages, ResponseMessage{Code: code, Message: declaration.description})
+}
+
+func (parser *Parser) addGoSwaggerResponse(operation *Operation, code int, dataType string, message string, packageName string, pos token.Pos) {
+	responseType := "{object}"
+	if strings.HasPrefix(dataType, "[]") {
+		responseType, dataType = "{array}", strings.TrimPrefix(dataType, "[]")
+	}
+	annotation := fmt.Sprintf("@Success %d %s %s \"%s\"", code, responseType, dataType, strings.Replace(message, `"`, `'`, -1))
+	parser.parseDeclarationAnnotation(operation, annotation, pos, packageName)
+}
+
+// parseDeclarationAnnotation parses an annotation of operation built from a declaration of
+// packageName: its types are the ones of that package
+func (parser *Parser) parseDeclarationAnnotation(operation *Operation, annotation string, pos token.Pos, packageName string) {
+	defer func(currentPackage string) {
+		parser.CurrentPackage = currentPackage
+	}(parser.CurrentPackage)
+	parser.CurrentPackage = packageName
+	parser.parseAnnotation(operation, annotation, pos, operation.Nickname)
+}
+
+// annotationType returns the type of a Go type expression as annotations give it: int, Model,
+// package.Model, []Model, Time for time.Time
+func annotationType(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.StarExpr:
+		return annotationType(typed.X)
+	case *ast.ArrayType:
+		return "[]" + annotationType(typed.Elt)
+	case *ast.SelectorExpr:
+		if packageIdent, ok := typed.X.(*ast.Ident); ok {
+			if packageIdent.Name == "time" && typed.Sel.Name == "Time" {
+				return "Time"
+			}
+			return packageIdent.Name + "." + typed.Sel.Name
+		}
+	case *ast.Ident:
+		return typed.Name
+	case *ast.InterfaceType:
+		return "interface"
+	}
+	return fmt.Sprint(expr)
+}
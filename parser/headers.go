@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// headerTypes are the types of response headers
+var headerTypes = []string{"string", "integer", "number", "boolean"}
+
+// ResponseHeader is a header of a response message. The headers are written to the spec as the
+// x-headers extension of the response message, by name.
+type ResponseHeader struct {
+	Type        string `json:"type"`
+	Format      string `json:"format,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// StandardHeader is a response header declared once for many operations, like the X-RateLimit-* or
+// Link pagination headers. It is added to the responses of the operations of Tags, all of them if
+// empty, whose code is one of Codes, the success responses if empty.
+type StandardHeader struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // string if empty
+	Format      string   `json:"format,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"` // resources
+	Codes       []int    `json:"codes,omitempty"`
+}
+
+// matches reports whether the header is added to a response of code of an operation of the resource apiKey
+func (header StandardHeader) matches(apiKey string, code int) bool {
+	if len(header.Tags) > 0 && !inListFold(apiKey, header.Tags) {
+		return false
+	}
+	if len(header.Codes) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, headerCode := range header.Codes {
+		if headerCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// AddStandardHeaders adds the standard headers to the declared responses of the parsed operations. A
+// header documented on a response already is left as it is.
+func (parser *Parser) AddStandardHeaders(headers []StandardHeader) error {
+	for i, header := range headers {
+		if strings.TrimSpace(header.Name) == "" {
+			return fmt.Errorf("Standard header %d has no name", i+1)
+		}
+		if header.Type == "" {
+			headers[i].Type = "string"
+		} else if !inList(header.Type, headerTypes) {
+			return fmt.Errorf("Invalid type %s of header %s. Must be one of %v.", header.Type, header.Name, headerTypes)
+		}
+	}
+
+	for apiKey, api := range parser.TopLevelApis {
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				for i := range op.ResponseMessages {
+					response := &op.ResponseMessages[i]
+					for _, header := range headers {
+						if !header.matches(apiKey, response.Code) {
+							continue
+						}
+						if _, ok := response.Headers[header.Name]; ok {
+							continue
+						}
+						if response.Headers == nil {
+							response.Headers = make(map[string]ResponseHeader)
+						}
+						response.Headers[header.Name] = ResponseHeader{
+							Type:        header.Type,
+							Format:      header.Format,
+							Description: header.Description,
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
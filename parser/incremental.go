@@ -0,0 +1,324 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/output"
+)
+
+// manifestFile is the manifest of an incremental cache directory
+const manifestFile = "manifest.json"
+
+// incrementalManifest lists the packages of an incremental cache, with the Go files their specs were
+// parsed from
+type incrementalManifest struct {
+	Key      string                    `json:"key"`
+	Packages map[string]*cachedPackage `json:"packages"` // by import path
+}
+
+type cachedPackage struct {
+	File string            `json:"file"` // spec of the package, in the cache directory
+	Dirs map[string]string `json:"dirs"` // directory -> hash of its Go files: the package and the packages of its types
+}
+
+// packageSpec is the spec of the operations of a package, as it is cached. It is encoded with gob,
+// which keeps the Go types of the params and properties, and the fields the spec does not serialise but
+// the filters and the formats need, like the consumed media types or the audiences.
+type packageSpec struct {
+	Listing *ResourceListing
+	Apis    map[string]*ApiDeclaration
+}
+
+// ParseApiIncremental parses the API like ParseApi, one package at a time: the spec of each package is
+// cached in cacheDir, and only the packages whose Go files changed since the previous run, or the ones
+// of the packages of their types, are parsed again. key identifies what all the specs depend on, like
+// the main API file and the options of the parser: the cache is dropped when it changes. The general
+// API info must be parsed first. The routes registered with gorilla/mux must be in the package of their
+// handlers.
+func (parser *Parser) ParseApiIncremental(packageNames string, cacheDir string, key string) (err error) {
+	defer recoverError(&err)
+	if err := output.MkdirAll(cacheDir, output.DirMode); err != nil {
+		return fmt.Errorf("Can not create incremental cache directory: %v\n", err)
+	}
+	manifest := readManifest(cacheDir, key)
+	updated := &incrementalManifest{Key: key, Packages: make(map[string]*cachedPackage)}
+	hashes := make(map[string]string)
+
+	parsed, cached := 0, 0
+	for _, packageName := range parser.ScanPackages(strings.Split(packageNames, ",")) {
+		if packageName == "" {
+			continue
+		}
+		entry := manifest.Packages[packageName]
+		spec := entry.read(cacheDir, hashes)
+		if spec != nil {
+			cached++
+		} else {
+			var packageErr error
+			spec, entry, packageErr = parser.parsePackageSpec(packageName, hashes)
+			var annotationErrors ErrorList
+			if errors.As(packageErr, &annotationErrors) {
+				// the package is parsed again next time, to report its errors
+				parser.errors = append(parser.errors, annotationErrors...)
+				entry = nil
+			} else if packageErr != nil {
+				return packageErr
+			} else if err := entry.write(cacheDir, packageName, spec); err != nil {
+				return err
+			}
+			parsed++
+		}
+		if entry != nil {
+			updated.Packages[packageName] = entry
+		}
+		parser.addPackageSpec(spec)
+	}
+	parser.SortApis()
+
+	data, err := json.MarshalIndent(updated, "", "    ")
+	if err == nil {
+		err = writeCacheFile(filepath.Join(cacheDir, manifestFile), data)
+	}
+	if err != nil {
+		return fmt.Errorf("Can not write incremental cache manifest: %v\n", err)
+	}
+	logger.Infof("Parsed %d changed packages, %d packages unchanged since the previous run", parsed, cached)
+	return parser.collectedErrors()
+}
+
+// readManifest reads the manifest of cacheDir, an empty one if there is none or if its key is not key
+func readManifest(cacheDir string, key string) *incrementalManifest {
+	manifest := &incrementalManifest{}
+	content, err := ioutil.ReadFile(filepath.Join(cacheDir, manifestFile))
+	if err == nil {
+		err = json.Unmarshal(content, manifest)
+	}
+	if err != nil || manifest.Key != key {
+		if err == nil {
+			logger.Infof("Options or main API file changed, parsing all the packages")
+		}
+		return &incrementalManifest{Key: key}
+	}
+	return manifest
+}
+
+// read reads the cached spec of a package, nil if it is not cached or if its Go files changed
+func (entry *cachedPackage) read(cacheDir string, hashes map[string]string) *packageSpec {
+	if entry == nil {
+		return nil
+	}
+	for dir, hash := range entry.Dirs {
+		if hashDir(dir, hashes) != hash {
+			return nil
+		}
+	}
+	file, err := os.Open(filepath.Join(cacheDir, entry.File))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	spec := &packageSpec{}
+	if err := gob.NewDecoder(file).Decode(spec); err != nil {
+		return nil
+	}
+	return spec
+}
+
+// write writes the spec of a package to its file of cacheDir
+func (entry *cachedPackage) write(cacheDir string, packageName string, spec *packageSpec) error {
+	entry.File = strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(packageName) + ".gob"
+	var data bytes.Buffer
+	err := gob.NewEncoder(&data).Encode(spec)
+	if err == nil {
+		err = writeCacheFile(filepath.Join(cacheDir, entry.File), data.Bytes())
+	}
+	if err != nil {
+		return fmt.Errorf("Can not write incremental cache of %s: %v\n", packageName, err)
+	}
+	return nil
+}
+
+// writeCacheFile writes a file of the cache with the output package, so the cache is not written
+// with -dry-run and has the permissions of -filemode
+func writeCacheFile(name string, data []byte) error {
+	file, err := output.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// parsePackageSpec parses the operations of a package, not the ones of its sub packages, with a parser
+// which has the options and the general API info of parser
+func (parser *Parser) parsePackageSpec(packageName string, hashes map[string]string) (*packageSpec, *cachedPackage, error) {
+	logger.Debugf("Parsing changed package %s", packageName)
+	packageParser := NewParser()
+	listing := *parser.Listing
+	listing.Apis = make([]*ApiRef, 0)
+	packageParser.Listing = &listing
+	packageParser.BasePath, packageParser.Host, packageParser.Schemes = parser.BasePath, parser.Host, parser.Schemes
+	packageParser.Wrapper = parser.Wrapper
+	packageParser.IsController = parser.IsController
+	for typeName, swaggerType := range parser.TypesImplementingMarshalInterface {
+		packageParser.TypesImplementingMarshalInterface[typeName] = swaggerType
+	}
+	packageParser.Strict, packageParser.ModelNaming, packageParser.PointerOptional = parser.Strict, parser.ModelNaming, parser.PointerOptional
+	packageParser.Sort, packageParser.IncludeInternal, packageParser.IncludeTests = parser.Sort, parser.IncludeInternal, parser.IncludeTests
+	packageParser.BuildTags, packageParser.Dialect = parser.BuildTags, parser.Dialect
+	// what the go command knows of the packages does not depend on the parsed package
+	packageParser.PackagePathCache, packageParser.listedPackages, packageParser.listedDirs = parser.PackagePathCache, parser.listedPackages, parser.listedDirs
+
+	err := packageParser.parsePackage(packageName)
+	var annotationErrors ErrorList
+	if err != nil && !errors.As(err, &annotationErrors) {
+		return nil, nil, err
+	}
+	parser.PackageTimings = append(parser.PackageTimings, packageParser.PackageTimings...)
+
+	entry := &cachedPackage{Dirs: make(map[string]string)}
+	goroot := filepath.Clean(build.Default.GOROOT) + string(filepath.Separator)
+	for dir := range packageParser.TypeDefinitions {
+		// the standard library changes with the go command, which is not a change of the package
+		if dir != "" && !strings.HasPrefix(filepath.Clean(dir), goroot) {
+			entry.Dirs[dir] = hashDir(dir, hashes)
+		}
+	}
+	return &packageSpec{packageParser.Listing, packageParser.TopLevelApis}, entry, err
+}
+
+// parsePackage runs the passes of ParseApi over one package
+func (parser *Parser) parsePackage(packageName string) (err error) {
+	defer recoverError(&err)
+	parser.timePackage(packageName, parser.parseTypeDefinitions)
+	parser.timePackage(packageName, parser.parseRoutes)
+	if parser.Dialect == DialectGoSwagger {
+		parser.timePackage(packageName, parser.parseGoSwaggerDeclarations)
+	}
+	parser.timePackage(packageName, parser.parseApiDescription)
+	parser.ResolveModelCollisions()
+	return parser.collectedErrors()
+}
+
+// addPackageSpec adds the resources, operations and models of the spec of a package to the parsed API.
+// The operations of a resource documented in several packages are merged.
+func (parser *Parser) addPackageSpec(spec *packageSpec) {
+	resources := make([]string, 0, len(spec.Apis))
+	for resource := range spec.Apis {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		packageApi := spec.Apis[resource]
+		api, ok := parser.TopLevelApis[resource]
+		if !ok {
+			api = NewApiDeclaration()
+			api.ApiVersion, api.SwaggerVersion = packageApi.ApiVersion, packageApi.SwaggerVersion
+			api.ResourcePath, api.BasePath = packageApi.ResourcePath, packageApi.BasePath
+			parser.TopLevelApis[resource] = api
+		}
+		for _, packageSubApi := range packageApi.Apis {
+			var subApi *Api
+			for _, existing := range api.Apis {
+				if existing.Path == packageSubApi.Path {
+					subApi = existing
+				}
+			}
+			if subApi == nil {
+				subApi = NewApi()
+				subApi.Path, subApi.Description = packageSubApi.Path, packageSubApi.Description
+				api.Apis = append(api.Apis, subApi)
+			}
+			for _, op := range packageSubApi.Operations {
+				op.parser, op.Path = parser, packageSubApi.Path
+				for i, model := range op.Models {
+					op.Models[i] = parser.sharedModel(model)
+				}
+				parser.operationCount++
+				op.order = parser.operationCount
+				subApi.Operations = append(subApi.Operations, op)
+				api.AddConsumedTypes(op)
+				api.AddProducesTypes(op)
+			}
+		}
+		for id, model := range packageApi.Models {
+			if _, ok := api.Models[id]; !ok {
+				api.Models[id] = parser.sharedModel(model)
+			}
+		}
+	}
+
+	for _, packageRef := range spec.Listing.Apis {
+		found := false
+		for _, ref := range parser.Listing.Apis {
+			if ref.Path == packageRef.Path {
+				found = true
+				if ref.Description == "" {
+					ref.Description = packageRef.Description
+				}
+			}
+		}
+		if !found {
+			parser.Listing.Apis = append(parser.Listing.Apis, packageRef)
+		}
+	}
+}
+
+// sharedModel returns the model of the parsed API with the Id of model, which becomes it if there is
+// none: the top level APIs share their models, as when they are parsed together
+func (parser *Parser) sharedModel(model *Model) *Model {
+	if model == nil {
+		return nil
+	}
+	if shared, ok := parser.Models[model.Id]; ok {
+		return shared
+	}
+	model.parser = parser
+	parser.Models[model.Id] = model
+	return model
+}
+
+// hashDir returns the hash of the Go files of dir, "" if it can not be read. The hashes of the
+// directories are computed once, in hashes.
+func hashDir(dir string, hashes map[string]string) string {
+	if hash, ok := hashes[dir]; ok {
+		return hash
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		hashes[dir] = ""
+		return ""
+	}
+	hash := sha256.New()
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			hashes[dir] = ""
+			return ""
+		}
+		fmt.Fprintf(hash, "%s %d\n", file.Name(), len(content))
+		hash.Write(content)
+	}
+	hashes[dir] = hex.EncodeToString(hash.Sum(nil))
+	return hashes[dir]
+}
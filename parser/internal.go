@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// isInternalComment reports if doc has an @Internal annotation
+func isInternalComment(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if fields := strings.Fields(strings.TrimLeft(comment.Text, "/")); len(fields) > 0 && strings.ToLower(fields[0]) == "@internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// internalControllers returns the names of the types of astPackages with an @Internal annotation in
+// their doc comment: all the operations of these controllers are internal
+func internalControllers(astPackages map[string]*ast.Package) map[string]bool {
+	controllers := make(map[string]bool)
+	for _, astPackage := range astPackages {
+		for _, astFile := range astPackage.Files {
+			for _, astDeclaration := range astFile.Decls {
+				if genDeclaration, ok := astDeclaration.(*ast.GenDecl); ok {
+					for _, spec := range genDeclaration.Specs {
+						if typeSpec, ok := spec.(*ast.TypeSpec); ok && (isInternalComment(typeSpec.Doc) || len(genDeclaration.Specs) == 1 && isInternalComment(genDeclaration.Doc)) {
+							controllers[typeSpec.Name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return controllers
+}
+
+// receiverTypeName returns the name of the receiver type of a method, "" for a function
+func receiverTypeName(funcDeclaration *ast.FuncDecl) string {
+	if funcDeclaration.Recv == nil || len(funcDeclaration.Recv.List) == 0 {
+		return ""
+	}
+	receiverType := funcDeclaration.Recv.List[0].Type
+	if starExpression, ok := receiverType.(*ast.StarExpr); ok {
+		receiverType = starExpression.X
+	}
+	if ident, ok := receiverType.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
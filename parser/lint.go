@@ -0,0 +1,252 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LintIssue is a problem of an annotation, Pos is the position of the offending comment
+type LintIssue struct {
+	Pos     token.Position
+	Message string
+}
+
+func (issue LintIssue) String() string {
+	return fmt.Sprintf("%s: %s", issue.Pos, issue.Message)
+}
+
+// Lint checks the annotations of the packages without generating anything. Unlike ParseApi
+// it never fails: malformed annotations and unknown types are returned, sorted by position,
+// and so is the error of a package which can not be parsed.
+func (parser *Parser) Lint(packageNames string) []LintIssue {
+	issues, err := parser.lintPackages(packageNames)
+	if err != nil {
+		issues = append(issues, LintIssue{Message: strings.TrimSpace(err.Error())})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Pos.Filename != issues[j].Pos.Filename {
+			return issues[i].Pos.Filename < issues[j].Pos.Filename
+		}
+		return issues[i].Pos.Line < issues[j].Pos.Line
+	})
+	return issues
+}
+
+// lintPackages returns the issues of the packages, the ones found until a package can not be parsed
+func (parser *Parser) lintPackages(packageNames string) (issues []LintIssue, err error) {
+	defer recoverError(&err)
+
+	packages := parser.ScanPackages(strings.Split(packageNames, ","))
+	for _, packageName := range packages {
+		parser.parseTypeDefinitions(packageName)
+	}
+	for _, packageName := range packages {
+		parser.parseRoutes(packageName)
+	}
+
+	for _, packageName := range packages {
+		astPackages := parser.packageAst(parser.realPackagePath(packageName))
+		for _, astPackage := range sortedPackages(astPackages) {
+			for _, astFile := range sortedFiles(astPackage) {
+				for _, astDescription := range astFile.Decls {
+					if funcDeclaration, ok := astDescription.(*ast.FuncDecl); ok && parser.IsController(funcDeclaration) {
+						issues = append(issues, parser.lintOperation(funcDeclaration, packageName)...)
+					}
+				}
+				for _, astComment := range astFile.Comments {
+					for _, comment := range astComment.List {
+						commentLine := strings.TrimSpace(strings.TrimLeft(comment.Text, "//"))
+						if strings.HasPrefix(commentLine, "@SubApi") && subApiCommentRegexp.FindStringSubmatch(strings.TrimSpace(commentLine[len("@SubApi"):])) == nil {
+							issues = append(issues, parser.lintIssue(comment, "malformed @SubApi, expected: @SubApi Description [/path]"))
+						}
+					}
+				}
+			}
+		}
+	}
+	return issues, nil
+}
+
+func (parser *Parser) lintIssue(comment *ast.Comment, format string, args ...interface{}) LintIssue {
+	return LintIssue{
+		Pos:     parser.FileSet.Position(comment.Pos()),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (parser *Parser) lintOperation(funcDeclaration *ast.FuncDecl, packageName string) []LintIssue {
+	if funcDeclaration.Doc == nil {
+		return nil
+	}
+
+	// ignored operations are not documented, their annotations do not matter
+	for _, comment := range funcDeclaration.Doc.List {
+		if fields := strings.Fields(strings.TrimLeft(comment.Text, "/")); len(fields) > 0 && strings.ToLower(fields[0]) == "@ignore" {
+			return nil
+		}
+	}
+
+	var issues []LintIssue
+	var firstAnnotation, router *ast.Comment
+	var path string
+	hasTitle := false
+	pathParams := map[string]*ast.Comment{}
+
+	for _, comment := range funcDeclaration.Doc.List {
+		commentLine := strings.TrimSpace(strings.TrimLeft(comment.Text, "//"))
+		if !strings.HasPrefix(commentLine, "@") {
+			continue
+		}
+		if parser.Dialect == DialectSwaggo {
+			if commentLine, _ = swaggoAnnotation(commentLine); commentLine == "" {
+				continue
+			}
+		}
+		attribute := strings.Fields(commentLine)[0]
+		value := strings.TrimSpace(commentLine[len(attribute):])
+		if firstAnnotation == nil && inList(strings.ToLower(attribute), operationAnnotations) {
+			firstAnnotation = comment
+		}
+
+		if parser.Strict && !inList(strings.ToLower(attribute), operationAnnotations) {
+			issues = append(issues, parser.lintIssue(comment, "%v", unknownAnnotationError(attribute, operationAnnotations)))
+		}
+
+		switch strings.ToLower(attribute) {
+		case "@router":
+			router = comment
+			matches := routerCommentRegexp.FindStringSubmatch(value)
+			if matches == nil {
+				issues = append(issues, parser.lintIssue(comment, "malformed @Router, expected: @Router /path [method]"))
+				continue
+			}
+			path = PathTemplate(matches[1])
+			if method := strings.ToUpper(matches[2]); !inList(method, validMethods) {
+				issues = append(issues, parser.lintIssue(comment, "unknown HTTP method %s in @Router, must be one of %s", matches[2], strings.Join(validMethods, ", ")))
+			}
+		case "@wrapper":
+			if err := checkWrapper(value); err != nil {
+				issues = append(issues, parser.lintIssue(comment, "%v", err))
+			}
+		case "@title":
+			hasTitle = true
+			if !validNickname.MatchString(value) {
+				issues = append(issues, parser.lintIssue(comment, "@Title %q is not a valid nickname, it must match %s", value, validNickname))
+			}
+		case "@param":
+			matches := paramCommentRegexp.FindStringSubmatch(value)
+			if matches == nil {
+				issues = append(issues, parser.lintIssue(comment, `malformed @Param, expected: @Param name paramType dataType required "description"`))
+				continue
+			}
+			if !inList(matches[2], validParamTypes) {
+				issues = append(issues, parser.lintIssue(comment, "unknown param type %s in @Param, must be one of %s", matches[2], strings.Join(validParamTypes, ", ")))
+			}
+			if matches[2] == "path" {
+				pathParams[matches[1]] = comment
+			}
+			if required := strings.ToLower(matches[4]); !inList(required, []string{"true", "false", "required", "optional"}) {
+				issues = append(issues, parser.lintIssue(comment, "@Param %s must be true or false, got %s", matches[1], matches[4]))
+			}
+			if IsFileType(matches[3]) {
+				if matches[2] != "form" {
+					issues = append(issues, parser.lintIssue(comment, "file param %s must be a form param, got %s", matches[1], matches[2]))
+				}
+			} else if err := parser.lintType(matches[3], packageName); err != nil {
+				issues = append(issues, parser.lintIssue(comment, "unknown type %s in @Param: %v", matches[3], err))
+			}
+		case "@websocket":
+			matches := websocketCommentRegexp.FindStringSubmatch(value)
+			if matches == nil {
+				issues = append(issues, parser.lintIssue(comment, `malformed @Websocket, expected: @Websocket client|server {object} dataType "description"`))
+				continue
+			}
+			if err := parser.lintType(matches[3], packageName); err != nil {
+				issues = append(issues, parser.lintIssue(comment, "unknown type %s in @Websocket: %v", matches[3], err))
+			}
+		case "@event":
+			matches := eventCommentRegexp.FindStringSubmatch(value)
+			if matches == nil {
+				issues = append(issues, parser.lintIssue(comment, `malformed @Event, expected: @Event name {object} dataType "description"`))
+				continue
+			}
+			if err := parser.lintType(matches[3], packageName); err != nil {
+				issues = append(issues, parser.lintIssue(comment, "unknown type %s in @Event: %v", matches[3], err))
+			}
+		case "@success", "@failure":
+			value, err := resolveStatusCode(value)
+			if err != nil {
+				issues = append(issues, parser.lintIssue(comment, "%v", err))
+				continue
+			}
+			matches := responseCommentRegexp.FindStringSubmatch(value)
+			if matches == nil {
+				issues = append(issues, parser.lintIssue(comment, `malformed %s, expected: %s code {object} dataType "message"`, attribute, attribute))
+				continue
+			}
+			if code, err := strconv.Atoi(matches[1]); err != nil || code < 100 || code > 599 {
+				issues = append(issues, parser.lintIssue(comment, "%s is not a valid HTTP status code", matches[1]))
+			}
+			if err := parser.lintType(matches[3], packageName); err != nil {
+				issues = append(issues, parser.lintIssue(comment, "unknown type %s in %s: %v", matches[3], attribute, err))
+			}
+		}
+	}
+
+	if firstAnnotation == nil {
+		return issues
+	}
+	handlerName := funcDeclaration.Name.String()
+	if _, ok := parser.MuxRoutes[MuxHandlerKey(packageName, receiverTypeName(funcDeclaration), handlerName)]; router == nil && !ok {
+		issues = append(issues, parser.lintIssue(firstAnnotation, "%s has annotations but no @Router", handlerName))
+	}
+	// swaggo operations are named after their handler without @ID
+	if !hasTitle && parser.Dialect != DialectSwaggo {
+		issues = append(issues, parser.lintIssue(firstAnnotation, "%s has no @Title, operations need it as nickname", handlerName))
+	}
+	if router != nil {
+		for _, name := range pathParamNames(path) {
+			if pathParams[name] == nil {
+				issues = append(issues, parser.lintIssue(router, "path param %s has no @Param", name))
+			}
+		}
+		for name, comment := range pathParams {
+			if !strings.Contains(path, "{"+name+"}") {
+				issues = append(issues, parser.lintIssue(comment, "path param %s is not a part of path %s", name, path))
+			}
+		}
+	}
+	return issues
+}
+
+// lintType checks that a type used in an annotation is a basic type or a known model
+func (parser *Parser) lintType(typeName string, packageName string) error {
+	if strings.Contains(typeName, "{") {
+		base, fields, err := parseComposedType(typeName)
+		if err != nil {
+			return err
+		}
+		if err := parser.lintType(base, packageName); err != nil {
+			return err
+		}
+		for _, field := range fields {
+			if err := parser.lintType(strings.TrimPrefix(field.Type, "[]"), packageName); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if _, ok := parser.typeDefTranslations[typeName]; ok || IsBasicType(typeName) {
+		return nil
+	}
+	if _, _, ok := KnownFormat(typeName); ok {
+		return nil
+	}
+	_, _, err := parser.resolveModelDefinition(typeName, packageName)
+	return err
+}
@@ -0,0 +1,303 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
+)
+
+// marshalerMethods are the methods of types which choose their own JSON representation,
+// with the swagger type they are documented as when it is not configured. The type of
+// MarshalJSON is the one inferred from its body.
+var marshalerMethods = map[string]string{
+	"MarshalJSON": "",
+	"MarshalText": "string", // text is always a JSON string
+}
+
+// IsImplementMarshalInterface reports if the type marshals itself, so it is not documented as a model
+func (parser *Parser) IsImplementMarshalInterface(typeName string, currentPackage string) bool {
+	_, ok := parser.MarshalType(typeName, currentPackage)
+	return ok
+}
+
+// MarshalType returns the swagger type of a type implementing json.Marshaler or encoding.TextMarshaler.
+// TypesImplementingMarshalInterface is looked up first, as written (sql.NullString) then without its
+// package (NullString). The types found by ParseTypeDefinitions are looked up by the package typeName
+// refers to from currentPackage, so a type of another package with the same name does not match.
+func (parser *Parser) MarshalType(typeName string, currentPackage string) (string, bool) {
+	names := []string{typeName}
+	if index := strings.LastIndex(typeName, "."); index != -1 {
+		names = append(names, typeName[index+1:])
+	}
+	for _, name := range names {
+		if swaggerType, ok := parser.TypesImplementingMarshalInterface[name]; ok {
+			return swaggerType, true
+		}
+	}
+	if len(parser.detectedMarshalers) == 0 {
+		return "", false
+	}
+	astTypeSpec, typePackage, err := parser.lookupModelDefinition(typeName, currentPackage)
+	if err != nil {
+		return "", false
+	}
+	swaggerType := parser.detectedMarshalers[parser.marshalerKey(typePackage, astTypeSpec.Name.Name)]
+	return swaggerType, swaggerType != ""
+}
+
+// marshalerKey is the key of a type in detectedMarshalers: the path of its package and its name
+func (parser *Parser) marshalerKey(packageName string, typeName string) string {
+	return parser.CheckRealPackagePath(packageName) + "." + typeName
+}
+
+// detectMarshaler registers the receiver type of MarshalJSON and MarshalText methods. The JSON type of
+// MarshalJSON is inferred from its body, typeDefinitions are the types of the package of the method.
+// When it can not be inferred, the type is registered without a swagger type: it is documented as
+// parsed, and its MarshalText method ignored.
+func (parser *Parser) detectMarshaler(funcDeclaration *ast.FuncDecl, packageName string, typeDefinitions map[string]*ast.TypeSpec) {
+	swaggerType, ok := marshalerMethods[funcDeclaration.Name.Name]
+	if !ok || funcDeclaration.Recv == nil || len(funcDeclaration.Recv.List) == 0 {
+		return
+	}
+	receiver := funcDeclaration.Recv.List[0]
+	receiverType := receiver.Type
+	if starExpression, ok := receiverType.(*ast.StarExpr); ok {
+		receiverType = starExpression.X
+	}
+	receiverIdent, ok := receiverType.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	typeName := receiverIdent.Name
+	key := parser.marshalerKey(packageName, typeName)
+	if funcDeclaration.Name.Name == "MarshalJSON" {
+		receiverName := ""
+		if len(receiver.Names) > 0 {
+			receiverName = receiver.Names[0].Name
+		}
+		swaggerType = inferMarshalJSONType(funcDeclaration.Body, receiverName, typeDefinitions[typeName])
+	} else if _, exists := parser.detectedMarshalers[key]; exists {
+		// encoding/json prefers MarshalJSON to MarshalText
+		return
+	}
+	if swaggerType == "" {
+		logger.Debugf("%s.%s implements %s, its JSON type is unknown: documented as parsed", packageName, typeName, funcDeclaration.Name.Name)
+	} else {
+		logger.Debugf("%s.%s implements %s, documented as %s", packageName, typeName, funcDeclaration.Name.Name, swaggerType)
+	}
+	parser.detectedMarshalers[key] = swaggerType
+}
+
+// inferMarshalJSONType returns the JSON type the returned values of a MarshalJSON body clearly are,
+// like json.Marshal(id.String()) or []byte(strconv.FormatInt(int64(id), 10)), or "" if it is not clear
+func inferMarshalJSONType(body *ast.BlockStmt, receiverName string, typeSpec *ast.TypeSpec) string {
+	if body == nil {
+		return ""
+	}
+	inferredType, isClear := "", true
+	ast.Inspect(body, func(node ast.Node) bool {
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false
+		}
+		returnStmt, ok := node.(*ast.ReturnStmt)
+		if !ok || len(returnStmt.Results) == 0 {
+			return true
+		}
+		if ident, ok := returnStmt.Results[0].(*ast.Ident); ok && ident.Name == "nil" {
+			return true
+		}
+		jsonType := jsonTypeOfResult(returnStmt.Results[0], receiverName, typeSpec)
+		if jsonType == "" || (inferredType != "" && inferredType != jsonType) {
+			isClear = false
+		}
+		inferredType = jsonType
+		return true
+	})
+	if !isClear {
+		return ""
+	}
+	return inferredType
+}
+
+// jsonTypeOfResult returns the JSON type of a []byte returned by MarshalJSON
+func jsonTypeOfResult(expr ast.Expr, receiverName string, typeSpec *ast.TypeSpec) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	switch callName(call) {
+	case "json.Marshal":
+		if len(call.Args) == 1 {
+			return goValueType(call.Args[0], receiverName, typeSpec)
+		}
+	case "strconv.AppendQuote":
+		return "string"
+	case "strconv.AppendInt", "strconv.AppendUint":
+		return "int64"
+	case "strconv.AppendFloat":
+		return "float64"
+	case "strconv.AppendBool":
+		return "bool"
+	}
+	// []byte(text) conversion
+	if arrayType, ok := call.Fun.(*ast.ArrayType); ok && len(call.Args) == 1 {
+		if elementType, ok := arrayType.Elt.(*ast.Ident); ok && elementType.Name == "byte" {
+			return jsonTypeOfText(call.Args[0])
+		}
+	}
+	return ""
+}
+
+// jsonTypeOfText returns the JSON type of a JSON text built by the expression
+func jsonTypeOfText(expr ast.Expr) string {
+	switch value := expr.(type) {
+	case *ast.BasicLit:
+		if text, err := strconv.Unquote(value.Value); err == nil {
+			return jsonTypeOfLiteral(text)
+		}
+	case *ast.BinaryExpr:
+		// `"` + id + `"`: the leftmost operand tells
+		if value.Op == token.ADD {
+			return jsonTypeOfText(value.X)
+		}
+	case *ast.CallExpr:
+		switch callName(value) {
+		case "strconv.Quote":
+			return "string"
+		case "strconv.Itoa", "strconv.FormatInt", "strconv.FormatUint":
+			return "int64"
+		case "strconv.FormatFloat":
+			return "float64"
+		case "strconv.FormatBool":
+			return "bool"
+		case "fmt.Sprintf":
+			if len(value.Args) > 0 {
+				if format, ok := value.Args[0].(*ast.BasicLit); ok {
+					if text, err := strconv.Unquote(format.Value); err == nil {
+						return jsonTypeOfFormat(text)
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// jsonTypeOfLiteral returns the JSON type of the start of a JSON text
+func jsonTypeOfLiteral(text string) string {
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(text, `"`):
+		return "string"
+	case text == "true" || text == "false":
+		return "bool"
+	case text == "":
+		return ""
+	}
+	if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return "int64"
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return "float64"
+	}
+	return ""
+}
+
+// jsonTypeOfFormat returns the JSON type of the text a fmt format produces
+func jsonTypeOfFormat(format string) string {
+	switch {
+	case strings.HasPrefix(format, `"`), strings.HasPrefix(format, "%q"):
+		return "string"
+	case format == "%d":
+		return "int64"
+	case format == "%f", format == "%g":
+		return "float64"
+	case format == "%t":
+		return "bool"
+	}
+	return ""
+}
+
+// goValueType returns the JSON type json.Marshal gives to a Go value
+func goValueType(expr ast.Expr, receiverName string, typeSpec *ast.TypeSpec) string {
+	switch value := expr.(type) {
+	case *ast.BasicLit:
+		switch value.Kind {
+		case token.STRING, token.CHAR:
+			return "string"
+		case token.INT:
+			return "int64"
+		case token.FLOAT:
+			return "float64"
+		}
+	case *ast.CallExpr:
+		// conversions like string(id) or int64(id)
+		if ident, ok := value.Fun.(*ast.Ident); ok && IsBasicType(ident.Name) && ident.Name != "error" {
+			return basicJsonType(ident.Name)
+		}
+		if selector, ok := value.Fun.(*ast.SelectorExpr); ok && selector.Sel.Name == "String" && len(value.Args) == 0 {
+			return "string"
+		}
+		switch callName(value) {
+		case "fmt.Sprintf", "fmt.Sprint", "strconv.Itoa", "strconv.FormatInt", "strconv.FormatUint", "strconv.FormatFloat", "strconv.Quote", "strings.ToLower", "strings.ToUpper":
+			return "string"
+		}
+	case *ast.SelectorExpr:
+		// a field of the receiver: json.Marshal(id.value)
+		if ident, ok := value.X.(*ast.Ident); ok && ident.Name == receiverName && typeSpec != nil {
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						if fieldType, ok := field.Type.(*ast.Ident); ok && name.Name == value.Sel.Name && IsBasicType(fieldType.Name) {
+							return basicJsonType(fieldType.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// basicJsonType is the swagger type of a basic Go type marshaled to JSON
+func basicJsonType(typeName string) string {
+	switch typeName {
+	case "byte", "rune", "uintptr", "uint", "uint8", "uint16", "uint32", "uint64", "int", "int8", "int16", "int32":
+		return "int64"
+	case "float32", "complex64", "complex128":
+		return "float64"
+	}
+	return typeName
+}
+
+// callName returns "package.Function" of a call like strconv.Quote(s)
+func callName(call *ast.CallExpr) string {
+	if selector, ok := call.Fun.(*ast.SelectorExpr); ok {
+		if ident, ok := selector.X.(*ast.Ident); ok {
+			return ident.Name + "." + selector.Sel.Name
+		}
+	}
+	return ""
+}
+
+// ParseMarshalTypes parses a comma separated list of type=swaggerType pairs,
+// e.g. "NullString=string,decimal.Decimal=float64"
+func ParseMarshalTypes(list string) (map[string]string, error) {
+	marshalTypes := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("Can not parse marshal type \"%s\", expected: type=swaggerType", pair)
+		}
+		marshalTypes[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return marshalTypes, nil
+}
@@ -3,17 +3,26 @@ package parser
 import (
 	"fmt"
 	"go/ast"
-	"log"
 	"reflect"
 	"regexp"
 	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
 )
 
 type Model struct {
-	Id         string                    `json:"id"`
-	Required   []string                  `json:"required,omitempty"`
-	Properties map[string]*ModelProperty `json:"properties"`
-	parser     *Parser
+	Id            string                    `json:"id"`
+	Required      []string                  `json:"required,omitempty"`
+	Properties    map[string]*ModelProperty `json:"properties"`
+	SubTypes      []string                  `json:"subTypes,omitempty"`
+	Discriminator string                    `json:"discriminator,omitempty"`
+	Description   string                    `json:"description,omitempty"`
+	parser        *Parser
+	// subTypeNames are the @SubTypes of the model, defined in modelPackage
+	subTypeNames []string
+	modelPackage string
+	// underlying is the schema of a named type which is not a struct, like `type UserID int64`
+	underlying *ModelProperty
 }
 
 func NewModel(p *Parser) *Model {
@@ -22,21 +31,44 @@ func NewModel(p *Parser) *Model {
 	}
 }
 
+// Equal reports if m and other are the same definition
+func (m *Model) Equal(other *Model) bool {
+	return m.Id == other.Id &&
+		reflect.DeepEqual(m.Required, other.Required) &&
+		reflect.DeepEqual(m.Properties, other.Properties) &&
+		reflect.DeepEqual(m.SubTypes, other.SubTypes) &&
+		m.Discriminator == other.Discriminator &&
+		m.Description == other.Description
+}
+
 // modelName is something like package.subpackage.SomeModel or just "subpackage.SomeModel"
-func (m *Model) ParseModel(modelName string, currentPackage string, knownModelNames map[string]bool) (error, []*Model) {
+func (m *Model) ParseModel(modelName string, currentPackage string, knownModelNames map[string]bool) (err error, models []*Model) {
+	defer recoverError(&err)
+	return m.parseModel(modelName, currentPackage, knownModelNames)
+}
+
+// parseModel is ParseModel, which aborts the parsing on an unknown model
+func (m *Model) parseModel(modelName string, currentPackage string, knownModelNames map[string]bool) (error, []*Model) {
 	knownModelNames[modelName] = true
 	//log.Printf("Before parse model |%s|, package: |%s|\n", modelName, currentPackage)
 
-	astTypeSpec, modelPackage := m.parser.FindModelDefinition(modelName, currentPackage)
-
-	modelNameParts := strings.Split(modelName, ".")
-	m.Id = strings.Join(append(strings.Split(modelPackage, "/"), modelNameParts[len(modelNameParts)-1]), ".")
+	astTypeSpec, modelPackage := m.parser.findModelDefinition(modelName, currentPackage)
+	m.Id = m.parser.uniqueModelId(modelPackage, astTypeSpec.Name.Name, astTypeSpec.Pos())
+	knownModelNames[m.Id] = true
+	// embedded structs are parsed with their own known models, a struct embedding itself would never end
+	if m.parser.parsingModels[m.Id] {
+		logger.Warnf("Model %s embeds itself, the circular embedding is skipped\n", m.Id)
+		return nil, nil
+	}
+	m.parser.parsingModels[m.Id] = true
+	defer delete(m.parser.parsingModels, m.Id)
 
 	var innerModelList []*Model
-	if astTypeDef, ok := astTypeSpec.Type.(*ast.Ident); ok {
-		typeDefTranslations[astTypeSpec.Name.String()] = astTypeDef.Name
-	} else if astStructType, ok := astTypeSpec.Type.(*ast.StructType); ok {
-		m.ParseFieldList(astStructType.Fields.List, modelPackage)
+	if astStructType, ok := astTypeSpec.Type.(*ast.StructType); ok {
+		m.parseFieldList(astStructType.Fields.List, modelPackage)
+		if err := m.ParseModelComments(astTypeSpec.Doc, modelPackage); err != nil {
+			return err, nil
+		}
 		usedTypes := make(map[string]bool)
 
 		for _, property := range m.Properties {
@@ -48,13 +80,18 @@ func (m *Model) ParseModel(modelName string, currentPackage string, knownModelNa
 					typeName = property.Items.Ref
 				}
 			}
-			if translation, ok := typeDefTranslations[typeName]; ok {
+			if translation, ok := m.parser.typeDefTranslations[typeName]; ok {
 				typeName = translation
 			}
-			if IsBasicType(typeName) || m.parser.IsImplementMarshalInterface(typeName) {
+			if IsBasicType(typeName) || m.parser.IsImplementMarshalInterface(typeName, modelPackage) {
 				continue
 			}
 			if _, exists := knownModelNames[typeName]; exists {
+				// self and mutually referencing models are referred to by their Id
+				m.referKnownModel(property, typeName, modelPackage)
+				continue
+			}
+			if m.isNamedType(typeName, modelPackage) {
 				continue
 			}
 
@@ -62,11 +99,10 @@ func (m *Model) ParseModel(modelName string, currentPackage string, knownModelNa
 		}
 
 		//log.Printf("Before parse inner model list: %#v\n (%s)", usedTypes, modelName)
-		innerModelList = make([]*Model, 0, len(usedTypes))
 
-		for typeName, _ := range usedTypes {
+		for _, typeName := range sortedSet(usedTypes) {
 			typeModel := NewModel(m.parser)
-			if err, typeInnerModels := typeModel.ParseModel(typeName, modelPackage, knownModelNames); err != nil {
+			if err, typeInnerModels := typeModel.parseModel(typeName, modelPackage, knownModelNames); err != nil {
 				//log.Printf("Parse Inner Model error %#v \n", err)
 				return err, nil
 			} else {
@@ -91,16 +127,128 @@ func (m *Model) ParseModel(modelName string, currentPackage string, knownModelNa
 				//log.Printf("innerModelList: %#v\n, typeInnerModels: %#v, usedTypes: %#v \n", innerModelList, typeInnerModels, usedTypes)
 			}
 		}
+		// named types are documented as their underlying type
+		for _, property := range m.Properties {
+			err, namedTypeModels := m.resolveNamedType(property, modelPackage, knownModelNames)
+			if err != nil {
+				return err, nil
+			}
+			innerModelList = append(innerModelList, namedTypeModels...)
+		}
 		//log.Printf("After parse inner model list: %#v\n (%s)", usedTypes, modelName)
 		// log.Fatalf("Inner model list: %#v\n", innerModelList)
 
+	} else if !isInterfaceType(astTypeSpec) {
+		if astTypeDef, ok := astTypeSpec.Type.(*ast.Ident); ok {
+			m.parser.typeDefTranslations[astTypeSpec.Name.String()] = astTypeDef.Name
+		}
+		return m.parseUnderlyingType(astTypeSpec, modelPackage, knownModelNames)
 	}
 
 	//log.Printf("ParseModel finished %s \n", modelName)
 	return nil, innerModelList
 }
 
-func (m *Model) ParseFieldList(fieldList []*ast.Field, modelPackage string) {
+// ParseModelComments handles the model doc comment. Its text is the description of the model, and
+// the base model of a type hierarchy names the property which tells the subtypes apart, and its subtypes:
+//
+//	// @Discriminator kind
+//	// @SubTypes Cat,Dog
+//
+// Subtypes are parsed by ParseSubTypes.
+func (m *Model) ParseModelComments(doc *ast.CommentGroup, modelPackage string) error {
+	if doc == nil {
+		return nil
+	}
+	m.Description = m.parser.docDescription(doc)
+	for _, commentLine := range strings.Split(doc.Text(), "\n") {
+		fields := strings.Fields(commentLine)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToLower(fields[0]) {
+		case "@discriminator":
+			if len(fields) != 2 {
+				return fmt.Errorf("Can not parse discriminator comment \"%s\" of model %s, expected: @Discriminator property", commentLine, m.Id)
+			}
+			m.Discriminator = fields[1]
+			if _, ok := m.Properties[m.Discriminator]; !ok {
+				return fmt.Errorf("Discriminator %s is not a property of model %s", m.Discriminator, m.Id)
+			}
+			// the discriminator is always set, Swagger requires it
+			isRequired := false
+			for _, name := range m.Required {
+				isRequired = isRequired || name == m.Discriminator
+			}
+			if !isRequired {
+				m.Required = append(m.Required, m.Discriminator)
+			}
+		case "@subtypes":
+			m.subTypeNames = strings.FieldsFunc(commentLine[len(fields[0]):], func(r rune) bool {
+				return r == ',' || r == ' ' || r == '\t'
+			})
+			m.modelPackage = modelPackage
+		}
+	}
+	if len(m.subTypeNames) > 0 && m.Discriminator == "" {
+		return fmt.Errorf("Model %s has @SubTypes but no @Discriminator", m.Id)
+	}
+	return nil
+}
+
+// docDescription returns the text of a doc comment without its annotations: the lines of a paragraph
+// are joined, and paragraphs are separated by a blank line
+func (parser *Parser) docDescription(doc *ast.CommentGroup) string {
+	var paragraphs []string
+	paragraph := ""
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "@") || parser.Dialect == DialectGoSwagger && strings.HasPrefix(line, "swagger:"):
+		case line == "":
+			if paragraph != "" {
+				paragraphs, paragraph = append(paragraphs, paragraph), ""
+			}
+		default:
+			paragraph = strings.TrimSpace(paragraph + " " + line)
+		}
+	}
+	if paragraph != "" {
+		paragraphs = append(paragraphs, paragraph)
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// ParseSubTypes parses the @SubTypes of the model. It is not a part of ParseModel, because
+// subtypes usually embed their base model, which would parse its subtypes again.
+func (m *Model) ParseSubTypes() (err error, models []*Model) {
+	defer recoverError(&err)
+	return m.parseSubTypes()
+}
+
+func (m *Model) parseSubTypes() (error, []*Model) {
+	var subTypeModels []*Model
+	m.SubTypes = make([]string, 0, len(m.subTypeNames))
+	for _, subTypeName := range m.subTypeNames {
+		subTypeModel := NewModel(m.parser)
+		err, innerModels := subTypeModel.parseModel(subTypeName, m.modelPackage, map[string]bool{})
+		if err != nil {
+			return err, nil
+		}
+		m.SubTypes = append(m.SubTypes, subTypeModel.Id)
+		subTypeModels = append(append(subTypeModels, subTypeModel), innerModels...)
+	}
+	return nil, subTypeModels
+}
+
+// ParseFieldList parses the properties of the model from the fields of its struct
+func (m *Model) ParseFieldList(fieldList []*ast.Field, modelPackage string) (err error) {
+	defer recoverError(&err)
+	m.parseFieldList(fieldList, modelPackage)
+	return nil
+}
+
+func (m *Model) parseFieldList(fieldList []*ast.Field, modelPackage string) {
 	if fieldList == nil {
 		return
 	}
@@ -108,33 +256,29 @@ func (m *Model) ParseFieldList(fieldList []*ast.Field, modelPackage string) {
 
 	m.Properties = make(map[string]*ModelProperty)
 	for _, field := range fieldList {
-		m.ParseModelProperty(field, modelPackage)
+		m.parseModelProperty(field, modelPackage)
 	}
 }
 
-func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) {
+// ParseModelProperty parses the property of field, or the properties of the struct it embeds
+func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) (err error) {
+	defer recoverError(&err)
+	m.parseModelProperty(field, modelPackage)
+	return nil
+}
+
+func (m *Model) parseModelProperty(field *ast.Field, modelPackage string) {
 	var name string
 	var innerModel *Model
 
-	property := NewModelProperty()
-
-	typeAsString := property.GetTypeAsString(field.Type)
-	//log.Printf("Get type as string %s \n", typeAsString)
-
-	// Sometimes reflection reports an object as "&{foo Bar}" rather than just "foo.Bar"
-	// The next 2 lines of code normalize them to foo.Bar
-	reInternalRepresentation := regexp.MustCompile("&\\{(\\w*) (\\w*)\\}")
-	typeAsString = string(reInternalRepresentation.ReplaceAll([]byte(typeAsString), []byte("$1.$2")))
-
-	if strings.HasPrefix(typeAsString, "[]") {
-		property.Type = "array"
-		property.SetItemType(typeAsString[2:])
-	} else if typeAsString == "time.Time" {
-		property.Type = "Time"
-	} else {
-		property.Type = typeAsString
+	// fields tagged swaggerignore:"true" are not documented, whatever their json tag is
+	if field.Tag != nil && reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("swaggerignore") == "true" {
+		return
 	}
 
+	property := NewModelProperty()
+	m.setPropertyType(property, field.Type, modelPackage)
+
 	if len(field.Names) == 0 {
 
 		if astSelectorExpr, ok := field.Type.(*ast.SelectorExpr); ok {
@@ -151,13 +295,13 @@ func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) {
 				name = astIdent.Name
 			}
 		} else {
-			log.Fatalf("Something goes wrong: %#v", field.Type)
+			failf("Something goes wrong: %#v", field.Type)
 		}
 		innerModel = NewModel(m.parser)
 		//log.Printf("Try to parse embeded type %s \n", name)
 		//log.Fatalf("DEBUG: field: %#v\n, selector.X: %#v\n selector.Sel: %#v\n", field, astSelectorExpr.X, astSelectorExpr.Sel)
 		knownModelNames := map[string]bool{}
-		innerModel.ParseModel(name, modelPackage, knownModelNames)
+		innerModel.parseModel(name, modelPackage, knownModelNames)
 
 		for innerFieldName, innerField := range innerModel.Properties {
 			m.Properties[innerFieldName] = innerField
@@ -170,6 +314,15 @@ func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) {
 	}
 
 	//log.Printf("ParseModelProperty: %s, CurrentPackage %s, type: %s \n", name, modelPackage, property.Type)
+	_, isPointer := field.Type.(*ast.StarExpr)
+	isRequired, isOptional := false, isPointer
+	// the doc comment or the trailing line comment of the field describes it, unless its tag does
+	for _, comment := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if comment != nil && property.Description == "" && m.parser != nil {
+			property.Description = m.parser.docDescription(comment)
+		}
+	}
+
 	//Analyse struct fields annotations
 	if field.Tag != nil {
 		structTag := reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
@@ -182,7 +335,6 @@ func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) {
 		}
 
 		tagValues := strings.Split(tagText, ",")
-		var isRequired = false
 
 		for _, v := range tagValues {
 			if v != "" && v != "required" && v != "omitempty" {
@@ -191,6 +343,9 @@ func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) {
 			if v == "required" {
 				isRequired = true
 			}
+			if v == "omitempty" {
+				isOptional = true
+			}
 			// We will not document at all any fields with a json tag of "-"
 			if v == "-" {
 				return
@@ -198,19 +353,87 @@ func (m *Model) ParseModelProperty(field *ast.Field, modelPackage string) {
 		}
 		if required := structTag.Get("required"); required != "" || isRequired {
 			m.Required = append(m.Required, name)
+			isRequired = true
 		}
 		if desc := structTag.Get("description"); desc != "" {
 			property.Description = desc
 		}
+		if enum := structTag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		if format := structTag.Get("format"); format != "" {
+			property.Format = format
+		}
+		if err := property.parseConstraints(structTag); err != nil {
+			logger.Warnf("Field %s of %s: %v\n", name, m.Id, err)
+		}
+		property.Audiences = parseAudiences(structTag.Get("audience"))
+		property.ReadOnly = structTag.Get("readonly") == "true"
+		property.WriteOnly = structTag.Get("writeonly") == "true"
+	}
+	// pointers and omitempty fields are the optional ones, other fields are always set
+	if m.parser != nil && m.parser.PointerOptional {
+		property.Nullable = isPointer
+		if !isRequired && !isOptional {
+			m.Required = append(m.Required, name)
+		}
 	}
 	m.Properties[name] = property
 }
 
+// setPropertyType sets the type of property, and its item type for slices, from a Go type expression
+// of modelPackage
+func (m *Model) setPropertyType(property *ModelProperty, fieldType ast.Expr, modelPackage string) {
+	typeAsString := property.GetTypeAsString(fieldType)
+	//log.Printf("Get type as string %s \n", typeAsString)
+
+	// Sometimes reflection reports an object as "&{foo Bar}" rather than just "foo.Bar"
+	// The next 2 lines of code normalize them to foo.Bar
+	reInternalRepresentation := regexp.MustCompile("&\\{(\\w*) (\\w*)\\}")
+	typeAsString = string(reInternalRepresentation.ReplaceAll([]byte(typeAsString), []byte("$1.$2")))
+
+	if strings.HasPrefix(typeAsString, "[]") {
+		property.Type = "array"
+		property.SetItemType(typeAsString[2:])
+	} else if typeAsString == "time.Time" {
+		property.Type = "Time"
+	} else {
+		property.Type = typeAsString
+	}
+	// common types with a string format, uuid.UUID is a string in uuid format
+	if property.Type == "array" && property.Items.Ref != "" {
+		if swaggerType, _, ok := KnownFormat(property.Items.Ref); ok {
+			property.SetItemType(swaggerType)
+		}
+	} else if swaggerType, format, ok := KnownFormat(property.Type); ok {
+		property.Type = swaggerType
+		property.Format = format
+	}
+	// types marshaling themselves are documented as their JSON type
+	if property.Type == "array" && property.Items.Ref != "" {
+		if swaggerType, ok := m.parser.MarshalType(property.Items.Ref, modelPackage); ok {
+			property.SetItemType(swaggerType)
+		}
+	} else if swaggerType, ok := m.parser.MarshalType(property.Type, modelPackage); ok && !IsBasicType(property.Type) {
+		property.Type = swaggerType
+	}
+}
+
 type ModelProperty struct {
 	Type        string             `json:"type"`
 	Description string             `json:"description"`
 	Items       ModelPropertyItems `json:"items,omitempty"`
 	Format      string             `json:"format"`
+	Enum        []string           `json:"enum,omitempty"`
+	ReadOnly    bool               `json:"readOnly,omitempty"`  // only in responses, like server generated IDs
+	WriteOnly   bool               `json:"writeOnly,omitempty"` // only in requests, like passwords
+	Nullable    bool               `json:"-"`                   // Swagger 1.2 has no null
+	Minimum     string             `json:"minimum,omitempty"`   // numbers are strings in Swagger 1.2 properties
+	Maximum     string             `json:"maximum,omitempty"`
+	MinLength   int                `json:"minLength,omitempty"`
+	MaxLength   int                `json:"maxLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Audiences   []string           `json:"-"` // audience tag, the property is for everyone without
 }
 type ModelPropertyItems struct {
 	Ref  string `json:"$ref,omitempty"`
@@ -246,8 +469,6 @@ var basicTypes = map[string]bool{
 	"Time":       true,
 }
 
-var typeDefTranslations = map[string]string{}
-
 func IsBasicType(typeName string) bool {
 	_, ok := basicTypes[typeName]
 	return ok || strings.Contains(typeName, "interface")
@@ -1,10 +1,14 @@
 package parser_test
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"github.com/yvasiyarov/swagger/parser"
 	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"path"
 	"strings"
 	"testing"
 )
@@ -74,6 +78,52 @@ func (suite *ModelSuite) TestSimpleAlias() {
 	assert.Len(suite.T(), m.Properties, 0, "Can not parse SimpleAlias definition")
 }
 
+func (suite *ModelSuite) TestNamedTypes() {
+	m := parser.NewModel(suite.parser)
+	err, innerModels := m.ParseModel("StructureWithNamedTypes", ExamplePackageName, map[string]bool{})
+	assert.Nil(suite.T(), err, "Can not parse StructureWithNamedTypes definition")
+	if assert.Len(suite.T(), innerModels, 1, "Named slice of structs does not refer to the struct model") {
+		assert.True(suite.T(), strings.HasSuffix(innerModels[0].Id, "SimpleStructure"), "Named slice of structs does not refer to the struct model")
+	}
+
+	assert.Equal(suite.T(), "int64", m.Properties["Id"].Type, "Named scalar type is not resolved")
+	assert.Equal(suite.T(), "UserID identifies a user", m.Properties["Id"].Description, "Named type doc is not the description")
+	assert.Equal(suite.T(), "array", m.Properties["Emails"].Type, "Named slice type is not resolved")
+	assert.Equal(suite.T(), "string", m.Properties["Emails"].Items.Type, "Named slice type is not resolved")
+	assert.Equal(suite.T(), "int64", m.Properties["Friends"].Items.Type, "Slice of named type is not resolved")
+	assert.Equal(suite.T(), "array", m.Properties["Structure"].Type, "Named slice of structs is not resolved")
+	assert.Equal(suite.T(), innerModels[0].Id, m.Properties["Structure"].Items.Ref, "Named slice of structs is not resolved")
+}
+
+func (suite *ModelSuite) TestCircularReferences() {
+	m := parser.NewModel(suite.parser)
+	err, innerModels := m.ParseModel("Node", ExamplePackageName, map[string]bool{})
+	assert.Nil(suite.T(), err, "Can not parse Node definition")
+	assert.Len(suite.T(), innerModels, 0, "Self referencing model is parsed twice")
+	assert.Equal(suite.T(), m.Id, m.Properties["Children"].Items.Ref, "Self reference is not the model Id")
+
+	m = parser.NewModel(suite.parser)
+	err, innerModels = m.ParseModel("Team", ExamplePackageName, map[string]bool{})
+	assert.Nil(suite.T(), err, "Can not parse Team definition")
+	if assert.Len(suite.T(), innerModels, 1, "Mutually referencing models are not parsed once") {
+		assert.Equal(suite.T(), innerModels[0].Id, m.Properties["Members"].Items.Ref, "Reference is not the model Id")
+		assert.Equal(suite.T(), m.Id, innerModels[0].Properties["Team"].Type, "Back reference is not the model Id")
+	}
+}
+
+func (suite *ModelSuite) TestFormats() {
+	m := parser.NewModel(suite.parser)
+	err, innerModels := m.ParseModel("StructureWithFormats", ExamplePackageName, map[string]bool{})
+	assert.Nil(suite.T(), err, "Can not parse StructureWithFormats definition")
+	assert.Len(suite.T(), innerModels, 0, "Known format types are parsed as models")
+
+	assert.Equal(suite.T(), "uuid", m.Properties["Id"].Format, "Can not parse format tag")
+	assert.Equal(suite.T(), "string", m.Properties["Address"].Type, "net.IP is not a string")
+	assert.Equal(suite.T(), "ip", m.Properties["Address"].Format, "net.IP has no format")
+	assert.Equal(suite.T(), "uri", m.Properties["Homepage"].Format, "url.URL has no format")
+	assert.Equal(suite.T(), "string", m.Properties["Links"].Items.Type, "[]url.URL is not an array of strings")
+}
+
 func (suite *ModelSuite) TestSimpleStructure() {
 	m := parser.NewModel(suite.parser)
 	err, innerModels := m.ParseModel("SimpleStructure", ExamplePackageName, suite.knownModelNames)
@@ -146,6 +196,228 @@ func (suite *ModelSuite) TestStructureWithEmbededPointer() {
 	assert.Equal(suite.T(), m.Properties["Name"].Items.Type, "byte", "Can not parse StructureWithEmbededPointer definition")
 }
 
+func (suite *ModelSuite) TestEnumTag() {
+	astFile, err := goparser.ParseFile(token.NewFileSet(), "model.go", `
+package test
+
+type User struct {
+	Status string `+"`"+`json:"status" enum:"active,blocked"`+"`"+`
+}
+`, 0)
+	if err != nil {
+		suite.T().Fatalf("Can not parse model source: %v", err)
+	}
+	structType := astFile.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+
+	m := parser.NewModel(suite.parser)
+	m.ParseFieldList(structType.Fields.List, "test")
+	assert.Equal(suite.T(), []string{"active", "blocked"}, m.Properties["status"].Enum, "Can not parse enum struct tag")
+}
+
+func (suite *ModelSuite) TestSwaggerIgnoreTag() {
+	structType := suite.GetExampleModelDefinition("StructureWithIgnoredField").Type.(*ast.StructType)
+
+	m := parser.NewModel(suite.parser)
+	m.ParseFieldList(structType.Fields.List, ExamplePackageName)
+	assert.Len(suite.T(), m.Properties, 1, "Field tagged swaggerignore is documented")
+	assert.NotNil(suite.T(), m.Properties["name"], "Can not parse model with swaggerignore tag")
+}
+
+func (suite *ModelSuite) TestReadOnlyWriteOnlyTags() {
+	structType := suite.GetExampleModelDefinition("StructureWithAccessTags").Type.(*ast.StructType)
+
+	m := parser.NewModel(suite.parser)
+	m.ParseFieldList(structType.Fields.List, ExamplePackageName)
+	assert.True(suite.T(), m.Properties["id"].ReadOnly, "Can not parse readonly struct tag")
+	assert.False(suite.T(), m.Properties["id"].WriteOnly, "Can not parse readonly struct tag")
+	assert.True(suite.T(), m.Properties["password"].WriteOnly, "Can not parse writeonly struct tag")
+	assert.False(suite.T(), m.Properties["password"].ReadOnly, "Can not parse writeonly struct tag")
+}
+
+func (suite *ModelSuite) TestPointerOptional() {
+	structType := suite.GetExampleModelDefinition("StructureWithOptionalFields").Type.(*ast.StructType)
+
+	m := parser.NewModel(suite.parser)
+	m.ParseFieldList(structType.Fields.List, ExamplePackageName)
+	assert.Empty(suite.T(), m.Required, "Fields are required by default")
+	assert.False(suite.T(), m.Properties["email"].Nullable, "Pointers are nullable by default")
+
+	optionalParser := parser.NewParser()
+	optionalParser.PointerOptional = true
+	m = parser.NewModel(optionalParser)
+	m.ParseFieldList(structType.Fields.List, ExamplePackageName)
+	assert.Equal(suite.T(), []string{"id"}, m.Required, "Only fields which are neither pointers nor omitempty are required")
+	assert.True(suite.T(), m.Properties["email"].Nullable, "Pointer is not nullable")
+	assert.False(suite.T(), m.Properties["id"].Nullable, "Field is nullable")
+}
+
+func (suite *ModelSuite) TestMarshalTypes() {
+	swaggerType, ok := suite.parser.MarshalType("Money", ExamplePackageName)
+	assert.True(suite.T(), ok, "MarshalJSON method is not detected")
+	assert.Equal(suite.T(), "string", swaggerType, "MarshalJSON method is not detected")
+	swaggerType, _ = suite.parser.MarshalType("OrderID", ExamplePackageName)
+	assert.Equal(suite.T(), "int64", swaggerType, "Can not infer the type of json.Marshal(field)")
+	swaggerType, _ = suite.parser.MarshalType("Rating", ExamplePackageName)
+	assert.Equal(suite.T(), "float64", swaggerType, "Can not infer the type of strconv.FormatFloat")
+
+	marshalParser := parser.NewParser()
+	marshalTypes, err := parser.ParseMarshalTypes("Money=float64, sql.NullInt64=int64")
+	assert.Nil(suite.T(), err, "Can not parse marshal types")
+	for typeName, swaggerType := range marshalTypes {
+		marshalParser.TypesImplementingMarshalInterface[typeName] = swaggerType
+	}
+	swaggerType, _ = marshalParser.MarshalType("example.Money", ExamplePackageName)
+	assert.Equal(suite.T(), "float64", swaggerType, "Marshal type is not configured")
+	swaggerType, _ = marshalParser.MarshalType("sql.NullInt64", ExamplePackageName)
+	assert.Equal(suite.T(), "int64", swaggerType, "Marshal type is not configured")
+
+	_, err = parser.ParseMarshalTypes("Money")
+	assert.NotNil(suite.T(), err, "Marshal type without swagger type accepted")
+}
+
+func (suite *ModelSuite) TestMarshalJSONOfUnknownType() {
+	_, ok := suite.parser.MarshalType("Coordinates", ExamplePackageName)
+	assert.False(suite.T(), ok, "MarshalJSON of an alias is documented as a scalar")
+
+	m := parser.NewModel(suite.parser)
+	err, _ := m.ParseModel("Coordinates", ExamplePackageName, suite.knownModelNames)
+	assert.Nil(suite.T(), err, "Can not parse Coordinates definition")
+	assert.Len(suite.T(), m.Properties, 2, "Model of MarshalJSON of an alias is not parsed")
+	assert.Equal(suite.T(), "float64", m.Properties["lat"].Type, "Model of MarshalJSON of an alias is not parsed")
+}
+
+func (suite *ModelSuite) TestMarshalTypesOfSameName() {
+	marshalParser := parser.NewParser()
+	subpackageName := ExamplePackageName + "/subpackage"
+	marshalParser.ParseTypeDefinitions(ExamplePackageName)
+	marshalParser.ParseTypeDefinitions(subpackageName)
+
+	swaggerType, ok := marshalParser.MarshalType("Money", ExamplePackageName)
+	assert.True(suite.T(), ok, "MarshalJSON method is not detected")
+	assert.Equal(suite.T(), "string", swaggerType, "MarshalJSON method is not detected")
+	_, ok = marshalParser.MarshalType("Money", subpackageName)
+	assert.False(suite.T(), ok, "Type of another package with the same name is a marshaler")
+	_, ok = marshalParser.MarshalType("subpackage.Money", ExamplePackageName)
+	assert.False(suite.T(), ok, "Type of another package with the same name is a marshaler")
+
+	m := parser.NewModel(marshalParser)
+	err, _ := m.ParseModel("Money", subpackageName, map[string]bool{})
+	assert.Nil(suite.T(), err, "Can not parse subpackage.Money definition")
+	assert.Len(suite.T(), m.Properties, 2, "Type of another package with the same name is a marshaler")
+}
+
+func (suite *ModelSuite) TestDiscriminator() {
+	m := parser.NewModel(suite.parser)
+	err, _ := m.ParseModel("Pet", ExamplePackageName, suite.knownModelNames)
+	assert.Nil(suite.T(), err, "Can not parse Pet definition")
+	assert.Equal(suite.T(), "kind", m.Discriminator, "Can not parse discriminator")
+	assert.Equal(suite.T(), []string{"kind"}, m.Required, "Discriminator is not required")
+
+	err, subTypeModels := m.ParseSubTypes()
+	assert.Nil(suite.T(), err, "Can not parse subtypes")
+	assert.Equal(suite.T(), []string{"github.com.yvasiyarov.swagger.example.Cat", "github.com.yvasiyarov.swagger.example.Dog"}, m.SubTypes, "Can not parse subtypes")
+	if assert.Len(suite.T(), subTypeModels, 2, "Can not parse subtypes") {
+		assert.Len(suite.T(), subTypeModels[0].Properties, 3, "Subtype does not embed base model")
+	}
+}
+
+func (suite *ModelSuite) TestModelDescription() {
+	m := parser.NewModel(suite.parser)
+	err, _ := m.ParseModel("Pet", ExamplePackageName, suite.knownModelNames)
+	assert.Nil(suite.T(), err, "Can not parse Pet definition")
+	assert.Equal(suite.T(), "Pet is the base model of a type hierarchy", m.Description, "Doc comment is not the model description")
+
+	m = parser.NewModel(suite.parser)
+	err, _ = m.ParseModel("APIError", ExamplePackageName, suite.knownModelNames)
+	assert.Nil(suite.T(), err, "Can not parse APIError definition")
+	assert.Empty(suite.T(), m.Description, "Model without doc comment has a description")
+}
+
+func (suite *ModelSuite) TestFieldComments() {
+	m := parser.NewModel(suite.parser)
+	err, _ := m.ParseModel("StructureWithComments", ExamplePackageName, suite.knownModelNames)
+	assert.Nil(suite.T(), err, "Can not parse StructureWithComments definition")
+	assert.Equal(suite.T(), "Street and number", m.Properties["Street"].Description, "Doc comment is not the property description")
+	assert.Equal(suite.T(), "Name of the city", m.Properties["City"].Description, "Line comment is not the property description")
+	assert.Equal(suite.T(), "Postal code", m.Properties["Zip"].Description, "Description tag is overridden")
+}
+
+func (suite *ModelSuite) TestConstEnums() {
+	m := parser.NewModel(suite.parser)
+	err, _ := m.ParseModel("StructureWithEnums", ExamplePackageName, suite.knownModelNames)
+	assert.Nil(suite.T(), err, "Can not parse StructureWithEnums definition")
+	assert.Equal(suite.T(), []string{"active", "disabled"}, m.Properties["Status"].Enum, "String constants are not the enum")
+	assert.Equal(suite.T(), []string{"1", "2", "4"}, m.Properties["Level"].Enum, "Iota constants are not the enum")
+}
+
+func (suite *ModelSuite) TestModelNaming() {
+	namingParser := parser.NewParser()
+	id, err := namingParser.ModelId("github.com/acme/api/models", "User")
+	assert.Nil(suite.T(), err, "Can not name the model")
+	assert.Equal(suite.T(), "github.com.acme.api.models.User", id, "Wrong default model name")
+
+	for naming, expected := range map[string]string{
+		parser.ModelNamingFull:    "github.com.acme.api.models.User",
+		parser.ModelNamingPackage: "models.User",
+		parser.ModelNamingShort:   "User",
+		"{{.Package}}_{{.Name}}":  "models_User",
+	} {
+		namingParser := parser.NewParser()
+		namingParser.ModelNaming = naming
+		assert.Nil(suite.T(), parser.CheckModelNaming(naming), "Valid model naming rejected")
+		id, err := namingParser.ModelId("github.com/acme/api/models", "User")
+		assert.Nil(suite.T(), err, "Can not name the model")
+		assert.Equal(suite.T(), expected, id, "Wrong model name")
+	}
+
+	namingParser = parser.NewParser()
+	namingParser.ModelNaming = "{{.Name"
+	_, err = namingParser.ModelId("github.com/acme/api/models", "User")
+	assert.NotNil(suite.T(), err, "Invalid model naming template must be returned as an error")
+
+	assert.NotNil(suite.T(), parser.CheckModelNaming("qualified"), "Unknown model naming accepted")
+	assert.NotNil(suite.T(), parser.CheckModelNaming("{{.Name"), "Invalid model naming template accepted")
+}
+
+func (suite *ModelSuite) TestShortModelNames() {
+	names := parser.ShortModelNames([]string{"github.com.a.shop.Order", "github.com.b.shop.Order", "github.com.a.shop.User", "Order"})
+	assert.Equal(suite.T(), map[string]string{
+		"github.com.a.shop.Order": "a_shop_Order",
+		"github.com.b.shop.Order": "b_shop_Order",
+		"github.com.a.shop.User":  "User",
+		"Order":                   "Order",
+	}, names, "Wrong short model names")
+	assert.Equal(suite.T(), "Order", parser.ShortModelName("github.com.a.shop.Order"), "Wrong short model name")
+	assert.Equal(suite.T(), "Order", parser.ShortModelName("Order"), "Wrong short model name of a model without package")
+}
+
+func (suite *ModelSuite) TestModelCollisions() {
+	collisionParser := parser.NewParser()
+	collisionParser.ModelNaming = parser.ModelNamingShort
+	for _, packageName := range []string{ExamplePackageName, ExamplePackageName + "/subpackage"} {
+		collisionParser.ParseTypeDefinitions(packageName)
+
+		m := parser.NewModel(collisionParser)
+		err, _ := m.ParseModel("SimpleStructure", packageName, map[string]bool{})
+		assert.Nil(suite.T(), err, "Can not parse SimpleStructure definition")
+
+		op := parser.NewOperation(collisionParser, packageName)
+		op.Path = "/" + path.Base(packageName)
+		op.Type = m.Id
+		op.Models = append(op.Models, m)
+		collisionParser.AddOperation(op)
+	}
+	collisionParser.ResolveModelCollisions()
+
+	for resource, id := range map[string]string{"example": "example.SimpleStructure", "subpackage": "subpackage.SimpleStructure"} {
+		api := collisionParser.TopLevelApis[resource]
+		assert.Equal(suite.T(), id, api.Apis[0].Operations[0].Type, "Colliding model is not qualified")
+		if assert.NotNil(suite.T(), api.Models[id], "Colliding model is not qualified") {
+			assert.Equal(suite.T(), id, api.Models[id].Id, "Colliding model is not qualified")
+		}
+	}
+}
+
 //TODO:
 //embeded structures from other packages
 //arrays of arrays
@@ -153,3 +425,41 @@ func (suite *ModelSuite) TestStructureWithEmbededPointer() {
 func TestModelSuite(t *testing.T) {
 	suite.Run(t, &ModelSuite{})
 }
+
+func (suite *ModelSuite) TestDataTypes() {
+	for goType, expected := range map[string]string{
+		"int64":   `"type":"integer","description":"","format":"int64"`,
+		"int":     `"type":"integer","description":"","format":"int64"`,
+		"uint16":  `"type":"integer","description":"","format":"int32"`,
+		"float32": `"type":"number","description":"","format":"float"`,
+		"float64": `"type":"number","description":"","format":"double"`,
+		"bool":    `"type":"boolean","description":""`,
+		"string":  `"type":"string","description":""`,
+	} {
+		serialised, err := json.Marshal(&parser.ModelProperty{Type: goType})
+		assert.Nil(suite.T(), err, "Can not serialise %s property", goType)
+		assert.Equal(suite.T(), "{"+expected+"}", string(serialised), "Wrong data type of %s", goType)
+	}
+
+	serialised, _ := json.Marshal(&parser.ModelProperty{Type: "array", Items: parser.ModelPropertyItems{Type: "float32"}})
+	assert.Contains(suite.T(), string(serialised), `"items":{"type":"number","format":"float"}`, "Wrong data type of items")
+
+	property := &parser.ModelProperty{}
+	err := json.Unmarshal([]byte(`{"type":"integer","format":"int64","items":{"type":"number","format":"float"}}`), property)
+	assert.Nil(suite.T(), err, "Can not read property")
+	assert.Equal(suite.T(), "int64", property.Type, "Data type is not read back as Go type")
+	assert.Equal(suite.T(), "", property.Format, "Data type format is not read back as Go type")
+	assert.Equal(suite.T(), "float32", property.Items.Type, "Item data type is not read back as Go type")
+}
+
+func (suite *ModelSuite) TestConstraints() {
+	m := parser.NewModel(suite.parser)
+	err, _ := m.ParseModel("StructureWithConstraints", ExamplePackageName, map[string]bool{})
+	assert.Nil(suite.T(), err, "Can not parse StructureWithConstraints definition")
+
+	assert.Equal(suite.T(), "18", m.Properties["Age"].Minimum, "Can not parse minimum tag")
+	assert.Equal(suite.T(), "130", m.Properties["Age"].Maximum, "Can not parse maximum tag")
+	assert.Equal(suite.T(), 1, m.Properties["Name"].MinLength, "Can not parse minLength tag")
+	assert.Equal(suite.T(), 64, m.Properties["Name"].MaxLength, "Can not parse maxLength tag")
+	assert.Equal(suite.T(), "^[A-Z](a|b)*$", m.Properties["Name"].Pattern, "Can not parse pattern tag")
+}
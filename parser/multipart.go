@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// fileTypes are the data types of the file parts of multipart/form-data requests
+var fileTypes = map[string]bool{
+	"file":                  true,
+	"File":                  true,
+	"multipart.File":        true,
+	"multipart.FileHeader":  true,
+	"*multipart.FileHeader": true,
+}
+
+// IsFileType reports whether typeName is the data type of a file part, like file or *multipart.FileHeader
+func IsFileType(typeName string) bool {
+	return fileTypes[typeName]
+}
+
+// registerParamType registers the data type of a param of paramType. Files are File form params, and
+// the operation consumes multipart/form-data, so file parts and field parts are sent together.
+func (operation *Operation) registerParamType(paramType string, typeName string) (string, error) {
+	if !IsFileType(typeName) {
+		return operation.registerType(typeName)
+	}
+	if paramType != "form" {
+		return "", fmt.Errorf("File params must be form params, got %s", paramType)
+	}
+	if !inList(ContentTypeMultiPartFormData, operation.Consumes) {
+		operation.Consumes = append(operation.Consumes, ContentTypeMultiPartFormData)
+	}
+	return "File", nil
+}
+
+// setContentType sets the content types of a part of a multipart/form-data request from the
+// contentType(image/png,image/jpeg) attribute
+func (p *Parameter) setContentType(attributes map[string]string) error {
+	contentType, ok := attributes["contentType"]
+	if !ok {
+		return nil
+	}
+	if p.ParamType != "form" {
+		return fmt.Errorf("contentType(%s) of param %s: only the parts of form params have a content type", contentType, p.Name)
+	}
+	var contentTypes []string
+	for _, part := range strings.Split(contentType, ",") {
+		part = strings.TrimSpace(part)
+		mediaType, _, err := mime.ParseMediaType(part)
+		if err == nil && !strings.Contains(mediaType, "/") {
+			err = fmt.Errorf("%s is not a type/subtype", part)
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid contentType(%s) of param %s: %v", contentType, p.Name, err)
+		}
+		contentTypes = append(contentTypes, part)
+	}
+	p.ContentType = strings.Join(contentTypes, ", ")
+	return nil
+}
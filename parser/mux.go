@@ -0,0 +1,455 @@
+package parser
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// MuxRoute is a route registered on a gorilla/mux router, e.g.
+// router.HandleFunc("/users/{id}", h).Methods("GET")
+type MuxRoute struct {
+	Path    string
+	Methods []string
+}
+
+// MuxHandlerKey is the key of the routes of a handler in MuxRoutes: the import path of its package,
+// the receiver type of a method and its name, like github.com/acme/api.UserController.Get. The Get
+// methods of two controllers, or the List functions of two packages, have their own routes.
+func MuxHandlerKey(packageName string, receiver string, name string) string {
+	if receiver == "" {
+		return packageName + "." + name
+	}
+	return packageName + "." + receiver + "." + name
+}
+
+// ParseMuxRoutes collects gorilla/mux route registrations from the function bodies of astFile, a file
+// of packageName. Routes are indexed by the MuxHandlerKey of their handler, so they can be merged with
+// the handler annotations later.
+func (parser *Parser) ParseMuxRoutes(astFile *ast.File, packageName string) {
+	resolver := &muxHandlerResolver{
+		parser:      parser,
+		packageName: packageName,
+		imports:     fileImports(astFile),
+	}
+	for _, astDeclaration := range astFile.Decls {
+		funcDeclaration, ok := astDeclaration.(*ast.FuncDecl)
+		if !ok || funcDeclaration.Body == nil {
+			continue
+		}
+		resolver.variables = make(map[string]muxReceiver)
+		resolver.addFieldList(funcDeclaration.Recv)
+		resolver.addFieldList(funcDeclaration.Type.Params)
+
+		// Path prefixes of subrouters: s := r.PathPrefix("/api").Subrouter()
+		prefixes := make(map[string]string)
+		ast.Inspect(funcDeclaration.Body, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == 1 && len(n.Rhs) == 1 {
+					if ident, ok := n.Lhs[0].(*ast.Ident); ok {
+						if chain := newMuxCallChain(n.Rhs[0], prefixes); chain != nil && chain.isSubrouter {
+							prefixes[ident.Name] = chain.prefix
+						} else if receiver, ok := resolver.valueType(n.Rhs[0]); ok {
+							resolver.variables[ident.Name] = receiver
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				for i, name := range n.Names {
+					if n.Type != nil {
+						if receiver, ok := resolver.typeOf(n.Type); ok {
+							resolver.variables[name.Name] = receiver
+						}
+					} else if i < len(n.Values) {
+						if receiver, ok := resolver.valueType(n.Values[i]); ok {
+							resolver.variables[name.Name] = receiver
+						}
+					}
+				}
+			case *ast.CallExpr:
+				chain := newMuxCallChain(n, prefixes)
+				if chain == nil || chain.handler == nil || chain.path == "" {
+					return true
+				}
+				handlerKey := resolver.handlerKey(chain.handler)
+				if handlerKey == "" {
+					return false
+				}
+				route := &MuxRoute{
+					Path:    NormalizeMuxPath(chain.prefix + chain.path),
+					Methods: chain.methods,
+				}
+				parser.MuxRoutes[handlerKey] = append(parser.MuxRoutes[handlerKey], route)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// ApplyMuxRoutes fills path, method and path params of operation from the routes registered for its
+// handler funcDeclaration. Explicit @Router annotation always wins. One operation is returned per route method.
+func (parser *Parser) ApplyMuxRoutes(operation *Operation, funcDeclaration *ast.FuncDecl) []*Operation {
+	handlerName := funcDeclaration.Name.String()
+	routes, ok := parser.MuxRoutes[MuxHandlerKey(operation.packageName, receiverTypeName(funcDeclaration), handlerName)]
+	if operation.Path != "" || !ok {
+		return []*Operation{operation}
+	}
+
+	operations := make([]*Operation, 0, len(routes))
+	for _, route := range routes {
+		methods := route.Methods
+		if len(methods) == 0 {
+			methods = []string{"GET"}
+		}
+		for _, method := range methods {
+			routeOperation := operation.routeCopy()
+			routeOperation.Path = route.Path
+			routeOperation.HttpMethod = strings.ToUpper(method)
+			if routeOperation.Nickname == "" {
+				routeOperation.Nickname = handlerName
+			}
+			routeOperation.AddMuxPathParams()
+			operations = append(operations, routeOperation)
+		}
+	}
+	return operations
+}
+
+// routeCopy copies operation for one of its routes: the slices and maps are copied too, so that
+// changing the operation of a route, e.g. adding a header to a response, does not change the others.
+// Models are shared, they are the registered models of the parser.
+func (operation *Operation) routeCopy() *Operation {
+	routeOperation := *operation
+	routeOperation.Parameters = append([]Parameter(nil), operation.Parameters...)
+	routeOperation.ResponseMessages = append([]ResponseMessage(nil), operation.ResponseMessages...)
+	for i, responseMessage := range routeOperation.ResponseMessages {
+		if responseMessage.Headers != nil {
+			headers := make(map[string]ResponseHeader, len(responseMessage.Headers))
+			for name, header := range responseMessage.Headers {
+				headers[name] = header
+			}
+			routeOperation.ResponseMessages[i].Headers = headers
+		}
+	}
+	routeOperation.Consumes = append([]string(nil), operation.Consumes...)
+	routeOperation.Produces = append([]string(nil), operation.Produces...)
+	routeOperation.Authorizations = append([]Authorization(nil), operation.Authorizations...)
+	for i, authorization := range routeOperation.Authorizations {
+		routeOperation.Authorizations[i].LocalOAuth.Scopes = append([]string(nil), authorization.LocalOAuth.Scopes...)
+		if authorization.LocalOAuth.GrantTypes != nil {
+			grantTypes := make(map[string]GrantType, len(authorization.LocalOAuth.GrantTypes))
+			for name, grantType := range authorization.LocalOAuth.GrantTypes {
+				grantTypes[name] = grantType
+			}
+			routeOperation.Authorizations[i].LocalOAuth.GrantTypes = grantTypes
+		}
+	}
+	routeOperation.Protocols = append([]Protocol(nil), operation.Protocols...)
+	routeOperation.Versions = append([]string(nil), operation.Versions...)
+	routeOperation.Audiences = append([]string(nil), operation.Audiences...)
+	routeOperation.Events = append([]ServerSentEvent(nil), operation.Events...)
+	routeOperation.Models = append([]*Model(nil), operation.Models...)
+	if operation.Extensions != nil {
+		routeOperation.Extensions = make(Extensions, len(operation.Extensions))
+		for name, extension := range operation.Extensions {
+			routeOperation.Extensions[name] = append(json.RawMessage(nil), extension...)
+		}
+	}
+	if operation.Websocket != nil {
+		routeOperation.Websocket = &Websocket{
+			Client: append([]WebsocketMessage(nil), operation.Websocket.Client...),
+			Server: append([]WebsocketMessage(nil), operation.Websocket.Server...),
+		}
+	}
+	return &routeOperation
+}
+
+// AddMuxPathParams adds path params for every {var} of the operation path which is not annotated with @Param
+func (operation *Operation) AddMuxPathParams() {
+	for _, name := range pathParamNames(operation.Path) {
+		isExists := false
+		for _, param := range operation.Parameters {
+			if param.ParamType == "path" && param.Name == name {
+				isExists = true
+				break
+			}
+		}
+		if !isExists {
+			operation.Parameters = append(operation.Parameters, Parameter{
+				ParamType: "path",
+				Name:      name,
+				Type:      "string",
+				DataType:  "string",
+				Required:  true,
+			})
+		}
+	}
+}
+
+// NormalizeMuxPath strips gorilla/mux regexps from path variables: /users/{id:[0-9]+} => /users/{id}
+func NormalizeMuxPath(muxPath string) string {
+	var result []byte
+	depth := 0
+	skip := false
+	for i := 0; i < len(muxPath); i++ {
+		c := muxPath[i]
+		switch {
+		case c == '{':
+			depth++
+			if depth > 1 {
+				continue
+			}
+		case c == '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			skip = false
+		case c == ':' && depth == 1:
+			skip = true
+		}
+		if !skip {
+			result = append(result, c)
+		}
+	}
+	return strings.Replace(string(result), "//", "/", -1)
+}
+
+type muxCallChain struct {
+	path        string
+	prefix      string
+	handler     ast.Expr
+	methods     []string
+	isSubrouter bool
+}
+
+// newMuxCallChain flattens router.HandleFunc(...).Methods(...) style call chains.
+// It returns nil if expression is not a call chain.
+func newMuxCallChain(expression ast.Expr, prefixes map[string]string) *muxCallChain {
+	callExpr, ok := expression.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	chain := &muxCallChain{}
+
+	var current ast.Expr = callExpr
+	for {
+		call, ok := current.(*ast.CallExpr)
+		if !ok {
+			break
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		switch selector.Sel.Name {
+		case "HandleFunc", "Handle":
+			if len(call.Args) == 2 {
+				chain.path = stringLiteral(call.Args[0])
+				chain.handler = handlerExpression(call.Args[1])
+			}
+		case "Path":
+			if len(call.Args) == 1 {
+				chain.path = stringLiteral(call.Args[0])
+			}
+		case "HandlerFunc", "Handler":
+			if len(call.Args) == 1 {
+				chain.handler = handlerExpression(call.Args[0])
+			}
+		case "Methods":
+			for _, arg := range call.Args {
+				if method := stringLiteral(arg); method != "" {
+					chain.methods = append(chain.methods, method)
+				}
+			}
+		case "PathPrefix":
+			if len(call.Args) == 1 {
+				chain.prefix = stringLiteral(call.Args[0]) + chain.prefix
+			}
+		case "Subrouter":
+			chain.isSubrouter = true
+		}
+		current = selector.X
+	}
+
+	if ident, ok := current.(*ast.Ident); ok {
+		chain.prefix = prefixes[ident.Name] + chain.prefix
+	}
+	return chain
+}
+
+func stringLiteral(expression ast.Expr) string {
+	if basicLit, ok := expression.(*ast.BasicLit); ok && basicLit.Kind == token.STRING {
+		if value, err := strconv.Unquote(basicLit.Value); err == nil {
+			return value
+		}
+	}
+	return ""
+}
+
+// handlerExpression returns the function used as handler: h, pkg.H, c.H, (*Context).H of
+// http.HandlerFunc(h) and the like
+func handlerExpression(expression ast.Expr) ast.Expr {
+	switch e := expression.(type) {
+	case *ast.Ident, *ast.SelectorExpr:
+		return e
+	case *ast.CallExpr:
+		if len(e.Args) == 1 {
+			return handlerExpression(e.Args[0])
+		}
+	case *ast.ParenExpr:
+		return handlerExpression(e.X)
+	}
+	return nil
+}
+
+// muxReceiver is a named type, the receiver of the methods registered as handlers
+type muxReceiver struct {
+	packageName string
+	name        string
+}
+
+// muxHandlerResolver resolves the handlers registered in the functions of a file of packageName to their
+// MuxHandlerKey. Without type information, the receivers of methods are the types of the variables of
+// the function: its params, and the variables assigned a composite literal, a new() or declared with a type.
+type muxHandlerResolver struct {
+	parser      *Parser
+	packageName string
+	imports     map[string]string      // import name -> import path
+	variables   map[string]muxReceiver // variables of the current function
+	typed       *TypedPackage
+	typeChecked bool
+}
+
+// handlerKey returns the MuxHandlerKey of the handler expression, an empty string if it can not be resolved
+func (resolver *muxHandlerResolver) handlerKey(handler ast.Expr) string {
+	var name *ast.Ident
+	switch e := handler.(type) {
+	case *ast.Ident:
+		name = e
+	case *ast.SelectorExpr:
+		name = e.Sel
+	}
+	// handlers are resolved with go/types when the package type checks
+	if typed := resolver.typedPackage(); typed != nil {
+		if function, ok := typed.Info.Uses[name].(*types.Func); ok && function.Pkg() != nil {
+			return MuxHandlerKey(function.Pkg().Path(), funcReceiverName(function), function.Name())
+		}
+	}
+
+	selector, ok := handler.(*ast.SelectorExpr)
+	if !ok {
+		return MuxHandlerKey(resolver.packageName, "", name.Name)
+	}
+	if ident, ok := selector.X.(*ast.Ident); ok {
+		if receiver, ok := resolver.variables[ident.Name]; ok {
+			return MuxHandlerKey(receiver.packageName, receiver.name, name.Name)
+		}
+		if importPath, ok := resolver.imports[ident.Name]; ok {
+			return MuxHandlerKey(importPath, "", name.Name)
+		}
+	}
+	// method expressions: (*Context).H, Context.H
+	if receiver, ok := resolver.typeOf(selector.X); ok {
+		return MuxHandlerKey(receiver.packageName, receiver.name, name.Name)
+	}
+	return ""
+}
+
+// typedPackage returns packageName type checked, nil if it is not a package go/types can check. The
+// package is only type checked once it registers a route.
+func (resolver *muxHandlerResolver) typedPackage() *TypedPackage {
+	if !resolver.typeChecked && resolver.parser.CheckRealPackagePath(resolver.packageName) != "" {
+		resolver.typed, _ = resolver.parser.TypeCheck(resolver.packageName)
+	}
+	resolver.typeChecked = true
+	return resolver.typed
+}
+
+// typeOf returns the named type of a type expression: T, *T or pkg.T
+func (resolver *muxHandlerResolver) typeOf(expression ast.Expr) (muxReceiver, bool) {
+	switch e := expression.(type) {
+	case *ast.Ident:
+		return muxReceiver{packageName: resolver.packageName, name: e.Name}, true
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok {
+			if importPath, ok := resolver.imports[ident.Name]; ok {
+				return muxReceiver{packageName: importPath, name: e.Sel.Name}, true
+			}
+		}
+	case *ast.StarExpr:
+		return resolver.typeOf(e.X)
+	case *ast.ParenExpr:
+		return resolver.typeOf(e.X)
+	}
+	return muxReceiver{}, false
+}
+
+// valueType returns the named type of a value expression: &T{}, T{} or new(T)
+func (resolver *muxHandlerResolver) valueType(expression ast.Expr) (muxReceiver, bool) {
+	switch e := expression.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return resolver.valueType(e.X)
+		}
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return resolver.typeOf(e.Type)
+		}
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok && ident.Name == "new" && len(e.Args) == 1 {
+			return resolver.typeOf(e.Args[0])
+		}
+	}
+	return muxReceiver{}, false
+}
+
+// addFieldList adds the named params of a function, or its receiver, to the variables of the resolver
+func (resolver *muxHandlerResolver) addFieldList(fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		if receiver, ok := resolver.typeOf(field.Type); ok {
+			for _, name := range field.Names {
+				resolver.variables[name.Name] = receiver
+			}
+		}
+	}
+}
+
+// funcReceiverName returns the name of the receiver type of a method, an empty string for functions
+func funcReceiverName(function *types.Func) string {
+	receiver := function.Type().(*types.Signature).Recv()
+	if receiver == nil {
+		return ""
+	}
+	receiverType := receiver.Type()
+	if pointer, ok := receiverType.(*types.Pointer); ok {
+		receiverType = pointer.Elem()
+	}
+	if named, ok := receiverType.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// fileImports returns the import paths of astFile by the name they are referred to with: their alias,
+// or their last path element
+func fileImports(astFile *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, importSpec := range astFile.Imports {
+		importPath := strings.Trim(importSpec.Path.Value, "\"")
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if importSpec.Name != nil {
+			name = importSpec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
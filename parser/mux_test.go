@@ -0,0 +1,236 @@
+package parser_test
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+type MuxSuite struct {
+	suite.Suite
+	parser   *parser.Parser
+	handlers map[string]*ast.FuncDecl // handlers of muxHandlersSource, by receiver.name
+}
+
+var muxRouterSource = `
+package api
+
+import (
+	"net/http"
+
+	"github.com/acme/api/admin"
+)
+
+func InitRouter() *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id:[0-9]+}", GetUser).Methods("GET")
+	c := &Context{}
+	router.HandleFunc("/users", c.CreateUser).Methods("POST", "PUT")
+	router.Path("/status").HandlerFunc(http.HandlerFunc(Status))
+
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/orders/{order_nr}", (*Context).GetOrder).Methods("GET")
+
+	var orders OrderController
+	users := new(UserController)
+	api.HandleFunc("/users/{id}", users.Get).Methods("GET")
+	api.HandleFunc("/orders/{id}", orders.Get).Methods("GET", "DELETE")
+	api.HandleFunc("/admin/users", admin.List).Methods("GET")
+	return router
+}
+`
+
+var muxHandlersSource = `
+package api
+
+func GetUser() {}
+
+func (c *Context) CreateUser() {}
+
+func (c *UserController) Get() {}
+
+func (c OrderController) Get() {}
+
+func List() {}
+`
+
+func (suite *MuxSuite) SetupSuite() {
+	suite.parser = parser.NewParser()
+
+	astFile, err := goparser.ParseFile(token.NewFileSet(), "router.go", muxRouterSource, 0)
+	if err != nil {
+		suite.T().Fatalf("Can not parse router source: %v", err)
+	}
+	suite.parser.ParseMuxRoutes(astFile, "test")
+
+	handlersFile, err := goparser.ParseFile(token.NewFileSet(), "handlers.go", muxHandlersSource, 0)
+	if err != nil {
+		suite.T().Fatalf("Can not parse handlers source: %v", err)
+	}
+	suite.handlers = make(map[string]*ast.FuncDecl)
+	for _, declaration := range handlersFile.Decls {
+		funcDeclaration := declaration.(*ast.FuncDecl)
+		receiver := ""
+		if funcDeclaration.Recv != nil {
+			receiverType := funcDeclaration.Recv.List[0].Type
+			if star, ok := receiverType.(*ast.StarExpr); ok {
+				receiverType = star.X
+			}
+			receiver = receiverType.(*ast.Ident).Name
+		}
+		suite.handlers[receiver+"."+funcDeclaration.Name.Name] = funcDeclaration
+	}
+}
+
+func (suite *MuxSuite) TestNormalizeMuxPath() {
+	assert.Equal(suite.T(), "/users/{id}", parser.NormalizeMuxPath("/users/{id:[0-9]+}"), "Regexp not stripped")
+	assert.Equal(suite.T(), "/users/{id}/x", parser.NormalizeMuxPath("/users/{id:[0-9]{3}}/x"), "Nested braces not stripped")
+	assert.Equal(suite.T(), "/api/orders", parser.NormalizeMuxPath("/api//orders"), "Double slash not removed")
+}
+
+func (suite *MuxSuite) TestParseMuxRoutes() {
+	assert.Len(suite.T(), suite.parser.MuxRoutes, 7, "Routes not parsed")
+
+	assert.Equal(suite.T(), "/users/{id}", suite.parser.MuxRoutes["test.GetUser"][0].Path, "Route path not parsed")
+	assert.Equal(suite.T(), []string{"GET"}, suite.parser.MuxRoutes["test.GetUser"][0].Methods, "Route methods not parsed")
+	assert.Equal(suite.T(), []string{"POST", "PUT"}, suite.parser.MuxRoutes["test.Context.CreateUser"][0].Methods, "Route methods not parsed")
+	assert.Equal(suite.T(), "/status", suite.parser.MuxRoutes["test.Status"][0].Path, "Path().HandlerFunc() route not parsed")
+	assert.Equal(suite.T(), "/api/orders/{order_nr}", suite.parser.MuxRoutes["test.Context.GetOrder"][0].Path, "Subrouter prefix not applied")
+
+	// handlers with the same name are told apart by their receiver type and package
+	assert.Equal(suite.T(), "/api/users/{id}", suite.parser.MuxRoutes["test.UserController.Get"][0].Path, "Route of a new() receiver not parsed")
+	assert.Equal(suite.T(), "/api/orders/{id}", suite.parser.MuxRoutes["test.OrderController.Get"][0].Path, "Route of a var receiver not parsed")
+	assert.Equal(suite.T(), "/api/admin/users", suite.parser.MuxRoutes["github.com/acme/api/admin.List"][0].Path, "Route of an imported handler not parsed")
+}
+
+func (suite *MuxSuite) TestApplyMuxRoutes() {
+	op := parser.NewOperation(suite.parser, "test")
+	op.Summary = "Get user"
+	ops := suite.parser.ApplyMuxRoutes(op, suite.handlers[".GetUser"])
+	assert.Len(suite.T(), ops, 1, "Operation not created from route")
+	assert.Equal(suite.T(), "/users/{id}", ops[0].Path, "Path not inferred")
+	assert.Equal(suite.T(), "GET", ops[0].HttpMethod, "Method not inferred")
+	assert.Equal(suite.T(), "GetUser", ops[0].Nickname, "Nickname not inferred")
+	assert.Equal(suite.T(), "Get user", ops[0].Summary, "Annotations not kept")
+	assert.Len(suite.T(), ops[0].Parameters, 1, "Path param not inferred")
+	assert.Equal(suite.T(), "id", ops[0].Parameters[0].Name, "Path param not inferred")
+
+	op2 := parser.NewOperation(suite.parser, "test")
+	ops2 := suite.parser.ApplyMuxRoutes(op2, suite.handlers["Context.CreateUser"])
+	assert.Len(suite.T(), ops2, 2, "Operation per method not created")
+	assert.Equal(suite.T(), "POST", ops2[0].HttpMethod, "Method not inferred")
+	assert.Equal(suite.T(), "PUT", ops2[1].HttpMethod, "Method not inferred")
+
+	op3 := parser.NewOperation(suite.parser, "test")
+	op3.ParseRouterComment("@Router /explicit/{id} [post]")
+	ops3 := suite.parser.ApplyMuxRoutes(op3, suite.handlers[".GetUser"])
+	assert.Len(suite.T(), ops3, 1, "Annotated operation changed")
+	assert.Equal(suite.T(), "/explicit/{id}", ops3[0].Path, "Explicit @Router must win")
+	assert.Equal(suite.T(), "POST", ops3[0].HttpMethod, "Explicit @Router must win")
+}
+
+// TestApplyMuxRoutesCopies changes the operation of a route, the operation of the other route of the
+// handler and the annotated operation must not change
+func (suite *MuxSuite) TestApplyMuxRoutesCopies() {
+	op := parser.NewOperation(suite.parser, "test")
+	op.ParseParamComment(`name query string false "Name"`)
+	op.ParseResponseComment(`200 {object} string "OK"`)
+	op.ResponseMessages[0].Headers = map[string]parser.ResponseHeader{"ETag": {Type: "string"}}
+	op.Produces = []string{"application/json"}
+	op.Consumes = []string{"application/json"}
+	op.Versions = []string{"v1"}
+	op.Audiences = []string{"public"}
+	op.Authorizations = []parser.Authorization{{LocalOAuth: parser.OAuth{Scopes: []string{"read"}}}}
+	op.Protocols = []parser.Protocol{{}}
+	op.Events = []parser.ServerSentEvent{{Name: "message", Model: "string"}}
+	op.Extensions = parser.Extensions{"x-owner": []byte(`"team"`)}
+	op.Websocket = &parser.Websocket{Client: []parser.WebsocketMessage{{Model: "string"}}}
+
+	ops := suite.parser.ApplyMuxRoutes(op, suite.handlers["Context.CreateUser"])
+	if !assert.Len(suite.T(), ops, 2, "Operation per method not created") {
+		return
+	}
+	put := ops[1]
+	put.Parameters[0].Name = "changed"
+	put.ResponseMessages[0].Message = "changed"
+	put.ResponseMessages[0].Headers["ETag"] = parser.ResponseHeader{Type: "integer"}
+	put.Produces[0] = "changed"
+	put.Consumes[0] = "changed"
+	put.Versions[0] = "changed"
+	put.Audiences[0] = "changed"
+	put.Authorizations[0].LocalOAuth.Scopes[0] = "changed"
+	put.Protocols = append(put.Protocols, parser.Protocol{})
+	put.Events[0].Name = "changed"
+	put.Extensions["x-owner"][1] = 'X'
+	put.Websocket.Client[0].Model = "changed"
+
+	for _, unchanged := range []*parser.Operation{op, ops[0]} {
+		assert.Equal(suite.T(), "name", unchanged.Parameters[0].Name, "Parameters shared")
+		assert.Equal(suite.T(), "OK", unchanged.ResponseMessages[0].Message, "Response messages shared")
+		assert.Equal(suite.T(), "string", unchanged.ResponseMessages[0].Headers["ETag"].Type, "Response headers shared")
+		assert.Equal(suite.T(), []string{"application/json"}, unchanged.Produces, "Produces shared")
+		assert.Equal(suite.T(), []string{"application/json"}, unchanged.Consumes, "Consumes shared")
+		assert.Equal(suite.T(), []string{"v1"}, unchanged.Versions, "Versions shared")
+		assert.Equal(suite.T(), []string{"public"}, unchanged.Audiences, "Audiences shared")
+		assert.Equal(suite.T(), []string{"read"}, unchanged.Authorizations[0].LocalOAuth.Scopes, "Authorizations shared")
+		assert.Len(suite.T(), unchanged.Protocols, 1, "Protocols shared")
+		assert.Equal(suite.T(), "message", unchanged.Events[0].Name, "Events shared")
+		assert.Equal(suite.T(), `"team"`, string(unchanged.Extensions["x-owner"]), "Extensions shared")
+		assert.Equal(suite.T(), "string", unchanged.Websocket.Client[0].Model, "Websocket shared")
+	}
+}
+
+func (suite *MuxSuite) TestApplyMuxRoutesSameName() {
+	users := suite.parser.ApplyMuxRoutes(parser.NewOperation(suite.parser, "test"), suite.handlers["UserController.Get"])
+	if assert.Len(suite.T(), users, 1, "Routes of another receiver applied") {
+		assert.Equal(suite.T(), "/api/users/{id}", users[0].Path, "Route of another receiver applied")
+		assert.Equal(suite.T(), "Get", users[0].Nickname, "Nickname not inferred")
+	}
+
+	orders := suite.parser.ApplyMuxRoutes(parser.NewOperation(suite.parser, "test"), suite.handlers["OrderController.Get"])
+	if assert.Len(suite.T(), orders, 2, "Routes of another receiver applied") {
+		assert.Equal(suite.T(), "/api/orders/{id}", orders[0].Path, "Route of another receiver applied")
+		assert.Equal(suite.T(), "DELETE", orders[1].HttpMethod, "Method not inferred")
+	}
+
+	// the List handler of the admin package is not the one of this package
+	list := suite.parser.ApplyMuxRoutes(parser.NewOperation(suite.parser, "test"), suite.handlers[".List"])
+	if assert.Len(suite.T(), list, 1, "Routes of another package applied") {
+		assert.Equal(suite.T(), "", list[0].Path, "Routes of another package applied")
+	}
+}
+
+func (suite *MuxSuite) TestParseRoutesTypeChecked() {
+	packageName := "github.com/yvasiyarov/swagger/parser/testdata/mux"
+	muxParser := parser.NewParser()
+	muxParser.IsController = func(funcDeclaration *ast.FuncDecl) bool {
+		return funcDeclaration.Doc != nil
+	}
+	if !assert.Nil(suite.T(), muxParser.ParseApi(packageName), "Can not parse the mux package") {
+		return
+	}
+
+	nicknames := make(map[string]string)
+	for _, api := range muxParser.TopLevelApis {
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				nicknames[op.HttpMethod+" "+op.Path] = op.Nickname
+			}
+		}
+	}
+	assert.Equal(suite.T(), map[string]string{
+		"GET /users/{id}":  "getUser",
+		"GET /orders/{id}": "getOrder",
+		"GET /users":       "listUsers",
+		"GET /admin/users": "listAdmins",
+	}, nicknames, "Handlers with the same name get the routes of one another")
+}
+
+func TestMuxSuite(t *testing.T) {
+	suite.Run(t, &MuxSuite{})
+}
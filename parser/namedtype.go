@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// resolveNamedType replaces the type of property, or its item type, by the underlying type when it is a
+// named type which is not a struct: a UserID field of `type UserID int64` is documented as an int64, an
+// Emails field of `type Emails []string` as an array of strings. The models the underlying type refers
+// to are returned.
+func (m *Model) resolveNamedType(property *ModelProperty, modelPackage string, knownModelNames map[string]bool) (error, []*Model) {
+	isItem := property.Type == "array"
+	typeName := property.Type
+	if isItem {
+		typeName = property.Items.Ref
+	}
+	if typeName == "" || IsBasicType(typeName) || m.parser.IsImplementMarshalInterface(typeName, modelPackage) {
+		return nil, nil
+	}
+	if !m.isNamedType(typeName, modelPackage) {
+		return nil, nil
+	}
+	astTypeSpec, typePackage, _ := m.parser.resolveModelDefinition(typeName, modelPackage)
+
+	typeModel := NewModel(m.parser)
+	err, models := typeModel.parseUnderlyingType(astTypeSpec, typePackage, knownModelNames)
+	if err != nil || typeModel.underlying == nil {
+		return err, nil
+	}
+	underlying := typeModel.underlying
+	if isItem {
+		// Swagger 1.2 has no nested arrays, []Emails is documented as an array of strings as well
+		if underlying.Type == "array" {
+			property.Items = underlying.Items
+		} else {
+			property.SetItemType(underlying.Type)
+		}
+	} else {
+		property.Type = underlying.Type
+		property.Items = underlying.Items
+		if property.Format == "" {
+			property.Format = underlying.Format
+		}
+	}
+	if property.Description == "" {
+		property.Description = underlying.Description
+	}
+	if len(property.Enum) == 0 && !isItem {
+		property.Enum = underlying.Enum
+	}
+	return nil, models
+}
+
+// isNamedType reports if typeName is defined as a named type which is neither a struct nor an interface
+func (m *Model) isNamedType(typeName string, modelPackage string) bool {
+	astTypeSpec, _, err := m.parser.resolveModelDefinition(typeName, modelPackage)
+	if err != nil {
+		return false
+	}
+	_, isStruct := astTypeSpec.Type.(*ast.StructType)
+	return !isStruct && !isInterfaceType(astTypeSpec)
+}
+
+// isInterfaceType reports if astTypeSpec defines an interface, like `type InterfaceType interface{}`.
+// Interfaces have no underlying schema, they stay models without properties.
+func isInterfaceType(astTypeSpec *ast.TypeSpec) bool {
+	_, isInterface := astTypeSpec.Type.(*ast.InterfaceType)
+	return isInterface
+}
+
+// parseUnderlyingType sets the underlying schema of the named type astTypeSpec, defined in typePackage.
+// The doc comment of the type becomes the description of the fields of that type, and the values of its
+// constants their enum.
+func (m *Model) parseUnderlyingType(astTypeSpec *ast.TypeSpec, typePackage string, knownModelNames map[string]bool) (error, []*Model) {
+	key := typePackage + "." + astTypeSpec.Name.Name
+	if m.parser.resolvingTypes[key] {
+		return nil, nil
+	}
+	m.parser.resolvingTypes[key] = true
+	defer delete(m.parser.resolvingTypes, key)
+
+	property := NewModelProperty()
+	m.setPropertyType(property, astTypeSpec.Type, typePackage)
+	if astTypeSpec.Doc != nil && !strings.Contains(astTypeSpec.Doc.Text(), "@") {
+		property.Description = strings.TrimSpace(astTypeSpec.Doc.Text())
+	}
+	property.Enum = m.parser.enums[astTypeSpec]
+
+	// named types of named types, like `type AdminID UserID`
+	err, models := m.resolveNamedType(property, typePackage, knownModelNames)
+	if err != nil {
+		return err, nil
+	}
+
+	// structs are referred to by their model Id
+	refName := property.Type
+	if refName == "array" {
+		refName = property.Items.Ref
+	}
+	if refName != "" && !IsBasicType(refName) && !m.parser.IsImplementMarshalInterface(refName, typePackage) {
+		modelId, refModels, err := m.parseReferencedModel(refName, typePackage, knownModelNames)
+		if err != nil {
+			return err, nil
+		}
+		if property.Type == "array" {
+			property.SetItemType(modelId)
+		} else {
+			property.Type = modelId
+		}
+		models = append(models, refModels...)
+	}
+
+	m.underlying = property
+	return nil, models
+}
+
+// parseReferencedModel returns the Id of the struct refName, and its models unless it is already known,
+// as the models being parsed are
+func (m *Model) parseReferencedModel(refName string, typePackage string, knownModelNames map[string]bool) (string, []*Model, error) {
+	astTypeSpec, modelPackage, err := m.parser.resolveModelDefinition(refName, typePackage)
+	if err != nil {
+		return "", nil, err
+	}
+	modelId := m.parser.uniqueModelId(modelPackage, astTypeSpec.Name.Name, astTypeSpec.Pos())
+	if knownModelNames[modelId] || knownModelNames[refName] {
+		return modelId, nil, nil
+	}
+
+	refModel := NewModel(m.parser)
+	err, innerModels := refModel.parseModel(refName, typePackage, knownModelNames)
+	if err != nil {
+		return "", nil, err
+	}
+	return refModel.Id, append([]*Model{refModel}, innerModels...), nil
+}
@@ -0,0 +1,266 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/yvasiyarov/swagger/logger"
+)
+
+// Model naming strategies of Parser.ModelNaming. Any other value containing {{ is a text/template
+// executed with a ModelName, e.g. "{{.Package}}_{{.Name}}".
+const (
+	// ModelNamingFull is the import path and the type name, dot separated: github.com.acme.api.models.User
+	ModelNamingFull = "full"
+	// ModelNamingPackage is the package name and the type name: models.User, as swaggo/swag names definitions
+	ModelNamingPackage = "package"
+	// ModelNamingShort is the type name only: User
+	ModelNamingShort = "short"
+)
+
+// ModelName is what model naming templates are executed with
+type ModelName struct {
+	Name    string // type name, User
+	Package string // package name, models
+	Path    string // import path, github.com/acme/api/models
+}
+
+// CheckModelNaming returns an error if naming is neither a known strategy nor a valid template
+func CheckModelNaming(naming string) error {
+	switch naming {
+	case "", ModelNamingFull, ModelNamingPackage, ModelNamingShort:
+		return nil
+	}
+	if !strings.Contains(naming, "{{") {
+		return fmt.Errorf("Unknown model naming %s, must be one of %s|%s|%s or a template", naming, ModelNamingFull, ModelNamingPackage, ModelNamingShort)
+	}
+	_, err := parseModelNamingTemplate(naming)
+	return err
+}
+
+func parseModelNamingTemplate(naming string) (*template.Template, error) {
+	tmpl, err := template.New("modelNaming").Parse(naming)
+	if err != nil {
+		return nil, fmt.Errorf("Can not parse model naming template %s: %v", naming, err)
+	}
+	return tmpl, nil
+}
+
+// ModelId returns the Id of the model typeName, defined in package modelPackage, following ModelNaming
+func (parser *Parser) ModelId(modelPackage string, typeName string) (_ string, err error) {
+	defer recoverError(&err)
+	return parser.modelId(modelPackage, typeName), nil
+}
+
+// modelId is ModelId, which aborts the parsing if the ModelNaming template fails
+func (parser *Parser) modelId(modelPackage string, typeName string) string {
+	name := ModelName{Name: typeName, Package: path.Base(modelPackage), Path: modelPackage}
+	switch parser.ModelNaming {
+	case "", ModelNamingFull:
+		return strings.Join(append(strings.Split(name.Path, "/"), name.Name), ".")
+	case ModelNamingPackage:
+		return name.Package + "." + name.Name
+	case ModelNamingShort:
+		return name.Name
+	}
+
+	if parser.modelNamingTemplate == nil {
+		tmpl, err := parseModelNamingTemplate(parser.ModelNaming)
+		if err != nil {
+			failf("%v\n", err)
+		}
+		parser.modelNamingTemplate = tmpl
+	}
+	var buf bytes.Buffer
+	if err := parser.modelNamingTemplate.Execute(&buf, name); err != nil {
+		failf("Can not name model %s.%s: %v\n", name.Path, name.Name, err)
+	}
+	return buf.String()
+}
+
+// modelSource is where a model is defined
+type modelSource struct {
+	Path string
+	Name string
+	Pos  token.Position
+}
+
+func (source modelSource) String() string {
+	return fmt.Sprintf("%s.%s (%s)", source.Path, source.Name, source.Pos)
+}
+
+// uniqueModelId is ModelId, unless another type already has that Id: then the model gets a longer,
+// qualified Id, and the collision is resolved by ResolveModelCollisions once everything is parsed.
+func (parser *Parser) uniqueModelId(modelPackage string, typeName string, pos token.Pos) string {
+	source := modelSource{Path: modelPackage, Name: typeName, Pos: parser.FileSet.Position(pos)}
+	id := parser.modelId(modelPackage, typeName)
+
+	registered, ok := parser.modelSources[id]
+	if !ok {
+		parser.modelSources[id] = source
+		return id
+	}
+	if registered.Path == source.Path && registered.Name == source.Name {
+		return id
+	}
+
+	sources := parser.modelCollisions[id]
+	if len(sources) == 0 {
+		sources = []modelSource{registered}
+	}
+	isKnown := false
+	for _, collision := range sources {
+		isKnown = isKnown || (collision.Path == source.Path && collision.Name == source.Name)
+	}
+	if !isKnown {
+		sources = append(sources, source)
+	}
+	parser.modelCollisions[id] = sources
+	return parser.qualifiedModelId(source)
+}
+
+// qualifiedModelId is the Id of a colliding model: the package qualified Id if the model
+// names are short, the full one otherwise
+func (parser *Parser) qualifiedModelId(source modelSource) string {
+	if parser.ModelNaming == ModelNamingShort {
+		id := path.Base(source.Path) + "." + source.Name
+		if registered, ok := parser.modelSources[id]; !ok || (registered.Path == source.Path && registered.Name == source.Name) {
+			parser.modelSources[id] = source
+			return id
+		}
+	}
+	return strings.Join(append(strings.Split(source.Path, "/"), source.Name), ".")
+}
+
+// ResolveModelCollisions renames every model whose Id collides with the Id of another type, so all
+// of them get a qualified Id, whatever the parsing order was. A warning lists where they are defined.
+func (parser *Parser) ResolveModelCollisions() {
+	renames := make(map[string]string)
+	ids := make([]string, 0, len(parser.modelCollisions))
+	for id := range parser.modelCollisions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		sources := parser.modelCollisions[id]
+		sort.Slice(sources, func(i, j int) bool {
+			return sources[i].Path < sources[j].Path
+		})
+		var descriptions []string
+		for _, source := range sources {
+			descriptions = append(descriptions, source.String())
+		}
+		// the first parsed model has the colliding Id, the other ones are already qualified
+		first := parser.modelSources[id]
+		delete(parser.modelSources, id)
+		renames[id] = parser.qualifiedModelId(first)
+		logger.Warnf("Model name %s is used by %s, they are qualified with their package\n", id, strings.Join(descriptions, " and "))
+	}
+	if len(renames) > 0 {
+		parser.renameModels(renames)
+	}
+}
+
+// renameModels changes the Ids of models, and the references to them
+func (parser *Parser) renameModels(renames map[string]string) {
+	rename := func(typeName string) string {
+		if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+			if newId, ok := renames[typeName[len("array["):len(typeName)-1]]; ok {
+				return "array[" + newId + "]"
+			}
+		}
+		if newId, ok := renames[typeName]; ok {
+			return newId
+		}
+		return typeName
+	}
+
+	renamed := make(map[*Model]bool)
+	renameModel := func(model *Model) {
+		if renamed[model] {
+			return
+		}
+		renamed[model] = true
+		model.Id = rename(model.Id)
+		for _, property := range model.Properties {
+			property.Type = rename(property.Type)
+			property.Items.Ref = rename(property.Items.Ref)
+		}
+		for i, subType := range model.SubTypes {
+			model.SubTypes[i] = rename(subType)
+		}
+	}
+	renameModels := func(models map[string]*Model) {
+		for id, model := range models {
+			renameModel(model)
+			if model.Id != id {
+				delete(models, id)
+				models[model.Id] = model
+			}
+		}
+	}
+
+	renameModels(parser.Models)
+	for _, api := range parser.TopLevelApis {
+		renameModels(api.Models)
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				for _, model := range op.Models {
+					renameModel(model)
+				}
+				op.Type = rename(op.Type)
+				op.Items.Ref = rename(op.Items.Ref)
+				for i := range op.Parameters {
+					op.Parameters[i].Type = rename(op.Parameters[i].Type)
+					op.Parameters[i].DataType = rename(op.Parameters[i].DataType)
+				}
+				for i := range op.ResponseMessages {
+					op.ResponseMessages[i].ResponseModel = rename(op.ResponseMessages[i].ResponseModel)
+				}
+			}
+		}
+	}
+}
+
+// ShortModelName returns the last part of a model Id, the name of the model type in its package
+func ShortModelName(id string) string {
+	parts := strings.Split(id, ".")
+	return parts[len(parts)-1]
+}
+
+// ShortModelNames names the models of ids after the last part of their Id, prefixed with the parts
+// before it, joined by _, while other models have the same name: the Order models of the packages a/shop
+// and b/shop are a_shop_Order and b_shop_Order, the formats needing a type name per model use them.
+func ShortModelNames(ids []string) map[string]string {
+	names := make(map[string]string)
+	for parts := 1; len(names) < len(ids); parts++ {
+		counts := make(map[string]int)
+		candidates := make(map[string]string)
+		for _, id := range ids {
+			if _, ok := names[id]; ok {
+				continue
+			}
+			idParts := strings.Split(id, ".")
+			if parts < len(idParts) {
+				idParts = idParts[len(idParts)-parts:]
+			}
+			candidates[id] = strings.Join(idParts, "_")
+			counts[candidates[id]]++
+		}
+		for _, name := range names {
+			counts[name]++
+		}
+		for id, name := range candidates {
+			if counts[name] == 1 || parts >= len(strings.Split(id, ".")) {
+				names[id] = name
+			}
+		}
+	}
+	return names
+}
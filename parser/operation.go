@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
 )
 
 type Operation struct {
@@ -24,10 +26,29 @@ type Operation struct {
 	Protocols        []Protocol        `json:"protocols,omitempty"`
 	Path             string            `json:"-"`
 	ForceResource    string            `json:"-"`
+	Versions         []string          `json:"-"`
+	Audiences        []string          `json:"-"`
+	Wrapper          string            `json:"-"`
+	Ignored          bool              `json:"-"`
+	Internal         bool              `json:"-"`
+	Extensions       Extensions        `json:"-"` // x- vendor extensions, added to the serialised operation
+	Websocket        *Websocket        `json:"-"` // written as the x-websocket extension
+	Events           []ServerSentEvent `json:"-"` // written as the x-events extension
 	parser           *Parser
+	order            int      // order of the declaration in the source, see SortBySource
 	Models           []*Model `json:"-"`
 	packageName      string
 }
+
+var (
+	paramCommentRegexp    = regexp.MustCompile(`([-\w]+)[\s]+([\w]+)[\s]+([\w\-\.\/\{\}\[\]=,]+)[\s]+([\w]+)[\s]*(.*)?`)
+	routerCommentRegexp   = regexp.MustCompile(`([^ ]+)[^\[]+\[([^\]]+)`)
+	responseCommentRegexp = regexp.MustCompile(`([\d]+)[\s]+([\w\{\}]+)[\s]+([\w\-\.\/\{\}\[\]=,]+)[\s]*(.*)?`)
+)
+
+// operationAnnotations are the annotations ParseComment understands, lower cased
+var operationAnnotations = []string{"@router", "@resource", "@version", "@audience", "@wrapper", "@ignore", "@internal", "@title", "@description", "@notes", "@success", "@failure", "@param", "@accept", "@consume", "@produce", "@extension", "@websocket", "@event"}
+
 type OperationItems struct {
 	Ref  string `json:"$ref,omitempty"`
 	Type string `json:"type,omitempty"`
@@ -50,11 +71,20 @@ func (operation *Operation) SetItemsType(itemsType string) {
 	}
 }
 
-func (operation *Operation) ParseComment(comment string) error {
+// ParseComment parses an annotation of the operation. The error is the one of a malformed annotation,
+// or the one the parsing was aborted with, like an unknown model.
+func (operation *Operation) ParseComment(comment string) (err error) {
+	defer recoverError(&err)
 	commentLine := strings.TrimSpace(strings.TrimLeft(comment, "//"))
 	if len(commentLine) == 0 {
 		return nil
 	}
+	allowMultiple := false
+	if operation.parser != nil && operation.parser.Dialect == DialectSwaggo && strings.HasPrefix(commentLine, "@") {
+		if commentLine, allowMultiple = swaggoAnnotation(commentLine); commentLine == "" {
+			return nil
+		}
+	}
 	attribute := strings.Fields(commentLine)[0]
 	switch strings.ToLower(attribute) {
 	case "@router":
@@ -67,6 +97,24 @@ func (operation *Operation) ParseComment(comment string) error {
 			resource = resource[1:]
 		}
 		operation.ForceResource = resource
+	case "@version":
+		for _, version := range strings.Split(commentLine[len(attribute):], ",") {
+			if version = strings.TrimSpace(version); version != "" {
+				operation.Versions = append(operation.Versions, version)
+			}
+		}
+	case "@audience":
+		operation.Audiences = append(operation.Audiences, parseAudiences(commentLine[len(attribute):])...)
+	case "@wrapper":
+		wrapper := strings.TrimSpace(commentLine[len(attribute):])
+		if err := checkWrapper(wrapper); err != nil {
+			return err
+		}
+		operation.Wrapper = wrapper
+	case "@ignore":
+		operation.Ignored = true
+	case "@internal":
+		operation.Internal = true
 	case "@title":
 		operation.Nickname = strings.TrimSpace(commentLine[len(attribute):])
 	case "@description":
@@ -77,10 +125,23 @@ func (operation *Operation) ParseComment(comment string) error {
 		if err := operation.ParseResponseComment(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
 			return err
 		}
+	case "@extension":
+		if err := operation.Extensions.add(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
+			return err
+		}
+	case "@websocket":
+		if err := operation.ParseWebsocketComment(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
+			return err
+		}
+	case "@event":
+		if err := operation.ParseEventComment(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
+			return err
+		}
 	case "@param":
 		if err := operation.ParseParamComment(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
 			return err
 		}
+		operation.Parameters[len(operation.Parameters)-1].AllowMultiple = allowMultiple
 	case "@accept", "@consume":
 		if err := operation.ParseAcceptComment(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
 			return err
@@ -89,6 +150,10 @@ func (operation *Operation) ParseComment(comment string) error {
 		if err := operation.ParseProduceComment(strings.TrimSpace(commentLine[len(attribute):])); err != nil {
 			return err
 		}
+	default:
+		if strings.HasPrefix(attribute, "@") && operation.parser != nil && operation.parser.Strict {
+			return unknownAnnotationError(attribute, operationAnnotations)
+		}
 	}
 
 	operation.Models = operation.getUniqueModels()
@@ -96,6 +161,47 @@ func (operation *Operation) ParseComment(comment string) error {
 	return nil
 }
 
+// unknownAnnotationError suggests the closest known annotation, typos like @Sucess are the usual cause
+func unknownAnnotationError(attribute string, knownAnnotations []string) error {
+	suggestion, bestDistance := "", 3
+	for _, known := range knownAnnotations {
+		if distance := editDistance(strings.ToLower(attribute), known); distance < bestDistance {
+			suggestion, bestDistance = known, distance
+		}
+	}
+	if suggestion != "" {
+		return fmt.Errorf("Unknown annotation %s, did you mean %s?", attribute, suggestion)
+	}
+	return fmt.Errorf("Unknown annotation %s", attribute)
+}
+
+// editDistance is the Levenshtein distance of a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
 func (operation *Operation) getUniqueModels() []*Model {
 
 	uniqueModels := make([]*Model, 0, len(operation.Models))
@@ -115,44 +221,77 @@ func (operation *Operation) getUniqueModels() []*Model {
 func (operation *Operation) registerType(typeName string) (string, error) {
 	registerType := ""
 
-	if translation, ok := typeDefTranslations[typeName]; ok {
+	if strings.Contains(typeName, "{") {
+		return operation.registerComposedType(typeName)
+	} else if translation, ok := operation.parser.typeDefTranslations[typeName]; ok {
 		registerType = translation
 	} else if IsBasicType(typeName) {
 		registerType = typeName
+	} else if swaggerType, _, ok := KnownFormat(typeName); ok {
+		registerType = swaggerType
+	} else if swaggerType, ok := operation.parser.MarshalType(typeName, operation.parser.CurrentPackage); ok {
+		registerType = swaggerType
 	} else {
 		model := NewModel(operation.parser)
 		knownModelNames := map[string]bool{}
 
-		err, innerModels := model.ParseModel(typeName, operation.parser.CurrentPackage, knownModelNames)
+		err, innerModels := model.parseModel(typeName, operation.parser.CurrentPackage, knownModelNames)
 		if err != nil {
 			return registerType, err
 		}
-		if translation, ok := typeDefTranslations[typeName]; ok {
+		if model.underlying != nil {
+			// named types like `type Emails []string` are documented as their underlying type
+			registerType = model.underlying.Type
+			if registerType == "array" {
+				registerType = "array[" + model.underlying.Items.Type + model.underlying.Items.Ref + "]"
+			}
+			operation.Models = append(operation.Models, innerModels...)
+		} else if translation, ok := operation.parser.typeDefTranslations[typeName]; ok {
 			registerType = translation
 		} else {
 			registerType = model.Id
 
 			operation.Models = append(operation.Models, model)
 			operation.Models = append(operation.Models, innerModels...)
+			if err := operation.registerSubTypes(); err != nil {
+				return registerType, err
+			}
 		}
 	}
 
 	return registerType, nil
 }
 
+// registerSubTypes registers the subtypes of the models, and the subtypes of those
+func (operation *Operation) registerSubTypes() error {
+	parsed := map[string]bool{}
+	for i := 0; i < len(operation.Models); i++ {
+		model := operation.Models[i]
+		if len(model.subTypeNames) == 0 || model.SubTypes != nil || parsed[model.Id] {
+			continue
+		}
+		parsed[model.Id] = true
+		err, subTypeModels := model.parseSubTypes()
+		if err != nil {
+			return err
+		}
+		operation.Models = append(operation.Models, subTypeModels...)
+	}
+	return nil
+}
+
 // Parse params return []string of param properties
 // @Param	queryText		form	      string	  true		        "The email for login"
 // 			[param name]    [param type] [data type]  [is mandatory?]   [Comment]
-func (operation *Operation) ParseParamComment(commentLine string) error {
+func (operation *Operation) ParseParamComment(commentLine string) (err error) {
+	defer recoverError(&err)
 	swaggerParameter := Parameter{}
 	paramString := commentLine
 
-	re := regexp.MustCompile(`([-\w]+)[\s]+([\w]+)[\s]+([\w\-\.\/\{\}\[\]]+)[\s]+([\w]+)[\s]*(.*)?`)
-
-	if matches := re.FindStringSubmatch(paramString); len(matches) != 6 {
+	if matches := paramCommentRegexp.FindStringSubmatch(paramString); len(matches) != 6 {
 		return fmt.Errorf("Can not parse param comment \"%s\", skipped.", paramString)
 	} else {
-		typeName, err := operation.registerType(matches[3])
+		typeName, err := operation.registerParamType(matches[2], matches[3])
 		if err != nil {
 			return err
 		}
@@ -163,7 +302,20 @@ func (operation *Operation) ParseParamComment(commentLine string) error {
 		swaggerParameter.DataType = typeName
 		requiredText := strings.ToLower(matches[4])
 		swaggerParameter.Required = (requiredText == "true" || requiredText == "required")
-		swaggerParameter.Description = strings.Replace(matches[5], `\"`, `"`, -1)
+		description, attributes := parseParamAttributes(matches[5])
+		swaggerParameter.Description = strings.Replace(description, `\"`, `"`, -1)
+		if _, format, ok := KnownFormat(matches[3]); ok {
+			swaggerParameter.Format = format
+		}
+		if format, ok := attributes["format"]; ok {
+			swaggerParameter.Format = format
+		}
+		if err := swaggerParameter.setConstraints(attributes); err != nil {
+			return err
+		}
+		if err := swaggerParameter.setContentType(attributes); err != nil {
+			return err
+		}
 
 		operation.Parameters = append(operation.Parameters, swaggerParameter)
 	}
@@ -206,34 +358,40 @@ func (operation *Operation) ParseProduceComment(commentLine string) error {
 			operation.Produces = append(operation.Produces, ContentTypeHtml)
 		case "mpfd", "multipart/form-data":
 			operation.Produces = append(operation.Produces, ContentTypeMultiPartFormData)
+		case "event-stream", "text/event-stream":
+			operation.Produces = append(operation.Produces, ContentTypeEventStream)
 		}
 	}
 	return nil
 }
 
 // @Router /customer/get-wishilist/:wishlist_id:int [get]
+// The path is converted to a path template: /customer/get-wishilist/{wishlist_id}
 func (operation *Operation) ParseRouterComment(commentLine string) error {
 	sourceString := strings.TrimSpace(commentLine[len("@Router"):])
 
-	re := regexp.MustCompile(`([^ ]+)[^\[]+\[([^\]]+)`)
 	var matches []string
 
-	if matches = re.FindStringSubmatch(sourceString); len(matches) != 3 {
+	if matches = routerCommentRegexp.FindStringSubmatch(sourceString); len(matches) != 3 {
 		return fmt.Errorf("Can not parse router comment \"%s\", skipped.", commentLine)
 	}
 
-	operation.Path = matches[1]
-	fmt.Printf("%8s %s\n", strings.ToUpper(matches[2]), matches[1])
+	operation.Path = PathTemplate(matches[1])
+	logger.Debugf("Found operation %s %s", strings.ToUpper(matches[2]), operation.Path)
 	operation.HttpMethod = strings.ToUpper(matches[2])
 	return nil
 }
 
 // @Success 200 {object} model.OrderRow "Error message, if code != 200"
-func (operation *Operation) ParseResponseComment(commentLine string) error {
-	re := regexp.MustCompile(`([\d]+)[\s]+([\w\{\}]+)[\s]+([\w\-\.\/\{\}\[\]]+)[\s]*(.*)?`)
+func (operation *Operation) ParseResponseComment(commentLine string) (err error) {
+	defer recoverError(&err)
 	var matches []string
 
-	if matches = re.FindStringSubmatch(commentLine); len(matches) != 5 {
+	commentLine, err = resolveStatusCode(commentLine)
+	if err != nil {
+		return err
+	}
+	if matches = responseCommentRegexp.FindStringSubmatch(commentLine); len(matches) != 5 {
 		return fmt.Errorf("Can not parse response comment \"%s\", skipped.", commentLine)
 	}
 
@@ -245,14 +403,23 @@ func (operation *Operation) ParseResponseComment(commentLine string) error {
 	}
 	response.Message = strings.Trim(matches[4], "\"")
 
-	typeName, err := operation.registerType(matches[3])
+	// success responses are put in the @Wrapper model
+	dataType, isArray := matches[3], matches[2] == "{array}"
+	if wrapper := operation.wrapper(); wrapper != "" && response.Code >= 200 && response.Code < 300 {
+		dataType, isArray = wrapType(wrapper, dataType, isArray), false
+	}
+
+	typeName, err := operation.registerType(dataType)
 	if err != nil {
 		return err
 	}
 
 	response.ResponseType = strings.Trim(matches[2], "{}")
+	if !isArray && response.ResponseType == "array" {
+		response.ResponseType = "object"
+	}
 
-	if matches[2] == "{array}" {
+	if isArray {
 		operation.SetItemsType(typeName)
 		response.ResponseModel = "array[" + typeName + "]"
 	} else {
@@ -260,7 +427,7 @@ func (operation *Operation) ParseResponseComment(commentLine string) error {
 	}
 
 	if response.Code == 200 {
-		if matches[2] == "{array}" {
+		if isArray {
 			operation.SetItemsType(typeName)
 			operation.Type = "array[" + typeName + "]"
 		} else {
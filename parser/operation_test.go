@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -32,6 +33,64 @@ func (suite *OperationSuite) TestSetItemsType() {
 	assert.Equal(suite.T(), op2.Items.Ref, "SomeType", "Can no set item type to custom type")
 }
 
+func (suite *OperationSuite) TestParseMultipartParams() {
+	op := parser.NewOperation(suite.parser, "test")
+	err := op.ParseParamComment(`avatar form file true "Avatar" contentType(image/png, image/jpeg)`)
+	assert.Nil(suite.T(), err, "Can not parse file param")
+	err = op.ParseParamComment(`name form string true "Name"`)
+	assert.Nil(suite.T(), err, "Can not parse field param")
+	assert.Len(suite.T(), op.Parameters, 2, "Can not parse multipart params")
+	assert.Equal(suite.T(), "File", op.Parameters[0].DataType, "Wrong data type of file part")
+	assert.Equal(suite.T(), "image/png, image/jpeg", op.Parameters[0].ContentType, "Wrong content type of file part")
+	assert.Equal(suite.T(), `"Avatar"`, op.Parameters[0].Description, "Content type is not removed from the description")
+	assert.Equal(suite.T(), "", op.Parameters[1].ContentType, "Wrong content type of field part")
+	assert.Equal(suite.T(), []string{parser.ContentTypeMultiPartFormData}, op.Consumes, "File params must consume multipart/form-data")
+
+	assert.NotNil(suite.T(), op.ParseParamComment(`avatar query file true "Avatar"`), "File params must be form params")
+	assert.NotNil(suite.T(), op.ParseParamComment(`avatar form file true "Avatar" contentType(image)`), "Invalid content type must fail")
+}
+
+func (suite *OperationSuite) TestParseWebsocketComment() {
+	op := parser.NewOperation(suite.parser, "test")
+	assert.Nil(suite.T(), op.ParseComment(`// @Websocket client {object} string "Chat message"`), "Can not parse client message")
+	assert.Nil(suite.T(), op.ParseComment(`// @Websocket server {array} string "Chat history"`), "Can not parse server message")
+	assert.NotNil(suite.T(), op.ParseComment(`// @Websocket both {object} string`), "Unknown direction accepted")
+	expected := &parser.Websocket{
+		Client: []parser.WebsocketMessage{{Model: "string", Description: "Chat message"}},
+		Server: []parser.WebsocketMessage{{Model: "array[string]", Description: "Chat history"}},
+	}
+	assert.Equal(suite.T(), expected, op.Websocket, "Can not parse websocket comment")
+
+	serialised, _ := json.Marshal(op)
+	assert.Contains(suite.T(), string(serialised), `"x-websocket":{"client":[{"model":"string","description":"Chat message"}]`, "Websocket is not serialised as x-websocket")
+	var read parser.Operation
+	if assert.Nil(suite.T(), json.Unmarshal(serialised, &read), "Can not read operation with websocket") {
+		assert.Equal(suite.T(), expected, read.Websocket, "Websocket is not read from x-websocket")
+		assert.Nil(suite.T(), read.Extensions, "x-websocket must not be read as an extension")
+	}
+}
+
+func (suite *OperationSuite) TestParseEventComment() {
+	op := parser.NewOperation(suite.parser, "test")
+	assert.Nil(suite.T(), op.ParseComment(`// @Event message {object} string "A chat message"`), "Can not parse event")
+	assert.Nil(suite.T(), op.ParseComment(`// @Event user-joined {array} string`), "Can not parse event without description")
+	assert.NotNil(suite.T(), op.ParseComment(`// @Event message string`), "Malformed event accepted")
+	expected := []parser.ServerSentEvent{
+		{Name: "message", Model: "string", Description: "A chat message"},
+		{Name: "user-joined", Model: "array[string]"},
+	}
+	assert.Equal(suite.T(), expected, op.Events, "Can not parse event comment")
+	assert.Equal(suite.T(), []string{parser.ContentTypeEventStream}, op.Produces, "Events must produce text/event-stream")
+
+	serialised, _ := json.Marshal(op)
+	assert.Contains(suite.T(), string(serialised), `"x-events":[{"name":"message","model":"string","description":"A chat message"}`, "Events are not serialised as x-events")
+	var read parser.Operation
+	if assert.Nil(suite.T(), json.Unmarshal(serialised, &read), "Can not read operation with events") {
+		assert.Equal(suite.T(), expected, read.Events, "Events are not read from x-events")
+		assert.Nil(suite.T(), read.Extensions, "x-events must not be read as an extension")
+	}
+}
+
 func (suite *OperationSuite) TestParseAcceptComment() {
 	op := parser.NewOperation(suite.parser, "test")
 	err := op.ParseAcceptComment("@Accept json")
@@ -62,6 +121,24 @@ func (suite *OperationSuite) TestParseRouterComment() {
 	assert.Equal(suite.T(), op2.HttpMethod, "POST", "Can not parse router comment")
 }
 
+func (suite *OperationSuite) TestParseRouterCommentWildcards() {
+	routes := map[string]string{
+		"/customer/get-wishlist/:wishlist_id:int": "/customer/get-wishlist/{wishlist_id}",
+		"/users/?:id":               "/users/{id}",
+		"/users/:id([0-9]+)/orders": "/users/{id}/orders",
+		"/static/*":                 "/static/{splat}",
+		"/download/*.*":             "/download/{path}.{ext}",
+		"/files/{path:.*}":          "/files/{path}",
+		"/users/{id:[0-9]{3}}":      "/users/{id}",
+	}
+	for route, expected := range routes {
+		op := parser.NewOperation(suite.parser, "test")
+		err := op.ParseRouterComment("@Router " + route + " [get]")
+		assert.Nil(suite.T(), err, "Can not parse router comment %s", route)
+		assert.Equal(suite.T(), expected, op.Path, "Wrong path template of %s", route)
+	}
+}
+
 func (suite *OperationSuite) TestParseParamComment() {
 	op := parser.NewOperation(suite.parser, "test")
 	err := op.ParseParamComment("@Param   order_nr     path    string  true	\"Order number\"")
@@ -76,6 +153,41 @@ func (suite *OperationSuite) TestParseParamComment() {
 	assert.Equal(suite.T(), op.Parameters[0].Description, "Order number", "Can not parse param comment")
 }
 
+func (suite *OperationSuite) TestParamFormat() {
+	op := parser.NewOperation(suite.parser, "test")
+	err := op.ParseParamComment("user_id     path    string  true	\"User ID\" format(uuid)")
+	assert.Nil(suite.T(), err, "Can not parse param comment")
+	assert.Equal(suite.T(), "uuid", op.Parameters[0].Format, "Can not parse param format")
+	assert.Equal(suite.T(), "\"User ID\"", op.Parameters[0].Description, "Param format is not removed from the description")
+
+	err = op.ParseParamComment("homepage     query    url.URL  false	\"Homepage\"")
+	assert.Nil(suite.T(), err, "Can not parse param comment")
+	assert.Equal(suite.T(), "string", op.Parameters[1].Type, "Known format type is not a string")
+	assert.Equal(suite.T(), "uri", op.Parameters[1].Format, "Known format type has no format")
+}
+
+func (suite *OperationSuite) TestParamConstraints() {
+	op := parser.NewOperation(suite.parser, "test")
+	err := op.ParseParamComment("limit     query    int  false	\"Page size\" minimum(1) maximum(100)")
+	assert.Nil(suite.T(), err, "Can not parse param comment")
+	assert.Equal(suite.T(), 1.0, op.Parameters[0].Minimum, "Can not parse param minimum")
+	assert.Equal(suite.T(), 100.0, op.Parameters[0].Maximum, "Can not parse param maximum")
+	assert.Equal(suite.T(), "\"Page size\"", op.Parameters[0].Description, "Param constraints are not removed from the description")
+
+	err = op.ParseParamComment("name     query    string  true	\"Name\" minLength(2) pattern(^(a|b)+$)")
+	assert.Nil(suite.T(), err, "Can not parse param comment")
+	assert.Equal(suite.T(), 2, op.Parameters[1].MinLength, "Can not parse param minLength")
+	assert.Equal(suite.T(), "^(a|b)+$", op.Parameters[1].Pattern, "Can not parse param pattern")
+
+	err = op.ParseParamComment("ratio     query    float64  false	\"Ratio\" minimum(0.5) maximum(2.5)")
+	assert.Nil(suite.T(), err, "Can not parse param comment")
+	assert.Equal(suite.T(), 0.5, op.Parameters[2].Minimum, "Can not parse float param minimum")
+	assert.Equal(suite.T(), 2.5, op.Parameters[2].Maximum, "Can not parse float param maximum")
+
+	err = op.ParseParamComment("limit     query    int  false	\"Page size\" minimum(one)")
+	assert.NotNil(suite.T(), err, "Invalid param minimum accepted")
+}
+
 func (suite *OperationSuite) TestParseResponseComment() {
 	op := parser.NewOperation(suite.parser, "test")
 	err := op.ParseResponseComment("200 {simple} string")
@@ -106,6 +218,18 @@ func (suite *OperationSuite) TestParseResponseComment() {
 	assert.Equal(suite.T(), op3.Items.Type, "string", "Can not parse response comment")
 }
 
+func (suite *OperationSuite) TestParseStatusConstant() {
+	op := parser.NewOperation(suite.parser, "test")
+	err := op.ParseResponseComment("http.StatusCreated {simple} string \"Created\"")
+	assert.Nil(suite.T(), err, "Can not parse response comment with a status constant")
+	if assert.Len(suite.T(), op.ResponseMessages, 1, "Can not parse response comment with a status constant") {
+		assert.Equal(suite.T(), 201, op.ResponseMessages[0].Code, "Status constant is not resolved")
+	}
+
+	err = op.ParseResponseComment("http.StatusOkay {simple} string")
+	assert.NotNil(suite.T(), err, "Unknown status constant accepted")
+}
+
 func (suite *OperationSuite) TestParseComment() {
 	operationComment := `
 // @Title getOrderByNumber
@@ -148,6 +272,26 @@ func (suite *OperationSuite) TestParseComment() {
 	assert.Equal(suite.T(), op.ResponseMessages[1].Message, "Order ID must be specified", "Can not parse operation comment")
 }
 
+func (suite *OperationSuite) TestStrictUnknownAnnotation() {
+	op := parser.NewOperation(suite.parser, "test")
+	assert.Nil(suite.T(), op.ParseComment("// @Sucess 200 {object} string"), "Unknown annotations must be skipped by default")
+
+	strictParser := parser.NewParser()
+	strictParser.Strict = true
+	op = parser.NewOperation(strictParser, "test")
+	err := op.ParseComment("// @Sucess 200 {object} string")
+	if assert.NotNil(suite.T(), err, "Unknown annotation accepted in strict mode") {
+		assert.Equal(suite.T(), "Unknown annotation @Sucess, did you mean @success?", err.Error())
+	}
+	assert.Nil(suite.T(), op.ParseComment("// @Title GetUser"), "Known annotation rejected in strict mode")
+}
+
+func (suite *OperationSuite) TestIgnore() {
+	op := parser.NewOperation(suite.parser, "test")
+	assert.Nil(suite.T(), op.ParseComment("// @Ignore"), "Can not parse @Ignore")
+	assert.True(suite.T(), op.Ignored, "Can not parse @Ignore")
+}
+
 func TestOperationSuite(t *testing.T) {
 	suite.Run(t, &OperationSuite{})
 }
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// overlayIdentities are the keys identifying the objects of the lists of a spec: an object of an
+// overlay list is merged into the object of the generated list with the same value, others are added
+var overlayIdentities = []string{"path", "httpMethod", "code", "name"}
+
+// ApplyOverlay deep merges a hand-written spec fragment into the parsed API, for what annotations can
+// not express. Its "info" is merged into the info of the resource listing, and its "apis" into the API
+// declarations by resource: objects are merged, lists are merged by path, httpMethod, code or name, and
+// other values replace the generated ones. Resources which are not generated are added.
+func (parser *Parser) ApplyOverlay(overlay map[string]interface{}) error {
+	for key := range overlay {
+		if key != "info" && key != "apis" {
+			return fmt.Errorf("Invalid overlay key %s. Must be one of info|apis.", key)
+		}
+	}
+
+	if info, ok := overlay["info"]; ok {
+		if err := mergeInto(&parser.Listing.Infos, info); err != nil {
+			return fmt.Errorf("Can not apply the overlay info: %v\n", err)
+		}
+	}
+
+	apis, ok := overlay["apis"].(map[string]interface{})
+	if !ok && overlay["apis"] != nil {
+		return fmt.Errorf("Invalid overlay apis: must be the API declarations by resource\n")
+	}
+	apiKeys := make([]string, 0, len(apis))
+	for apiKey := range apis {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Strings(apiKeys)
+	for _, apiKey := range apiKeys {
+		resource := strings.Trim(apiKey, "/")
+		api, ok := parser.TopLevelApis[resource]
+		if !ok {
+			api = NewApiDeclaration()
+			api.ApiVersion = parser.Listing.ApiVersion
+			api.SwaggerVersion = SwaggerVersion
+			api.ResourcePath = "/" + resource
+			api.BasePath = parser.BasePath
+			parser.TopLevelApis[resource] = api
+			parser.Listing.Apis = append(parser.Listing.Apis, &ApiRef{Path: api.ResourcePath})
+		}
+		if err := mergeInto(api, apis[apiKey]); err != nil {
+			return fmt.Errorf("Can not apply the overlay of %s: %v\n", resource, err)
+		}
+		// the operations of the overlay are where they are declared
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				if op.parser == nil {
+					op.parser, op.Path = parser, subApi.Path
+				}
+			}
+		}
+		for id, model := range api.Models {
+			model.parser = parser
+			parser.Models[id] = model
+		}
+	}
+	return nil
+}
+
+// mergeInto deep merges overlay into v, through their JSON documents. v is decoded in place: the
+// generated objects, kept at the same index of their lists, keep their fields which are not serialised.
+func mergeInto(v interface{}, overlay interface{}) error {
+	generated, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var document interface{}
+	if err := json.Unmarshal(generated, &document); err != nil {
+		return err
+	}
+	merged, err := json.Marshal(mergeValue(document, overlay))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(merged, v)
+}
+
+func mergeValue(generated interface{}, overlay interface{}) interface{} {
+	switch overlayValue := overlay.(type) {
+	case map[string]interface{}:
+		generatedObject, ok := generated.(map[string]interface{})
+		if !ok {
+			return overlay
+		}
+		for key, value := range overlayValue {
+			generatedObject[key] = mergeValue(generatedObject[key], value)
+		}
+		return generatedObject
+	case []interface{}:
+		generatedList, ok := generated.([]interface{})
+		if !ok {
+			return overlay
+		}
+		for _, value := range overlayValue {
+			if i := indexOfIdentity(generatedList, value); i >= 0 {
+				generatedList[i] = mergeValue(generatedList[i], value)
+			} else {
+				generatedList = append(generatedList, value)
+			}
+		}
+		return generatedList
+	}
+	return overlay
+}
+
+// indexOfIdentity returns the index of the object of list with the identity of value, or of the value
+// itself if it is not an object, -1 if there is none
+func indexOfIdentity(list []interface{}, value interface{}) int {
+	object, ok := value.(map[string]interface{})
+	for i, generated := range list {
+		if !ok {
+			if fmt.Sprint(generated) == fmt.Sprint(value) {
+				return i
+			}
+			continue
+		}
+		generatedObject, _ := generated.(map[string]interface{})
+		for _, key := range overlayIdentities {
+			if identity, ok := object[key]; ok {
+				if strings.EqualFold(fmt.Sprint(generatedObject[key]), fmt.Sprint(identity)) {
+					return i
+				}
+				break
+			}
+		}
+	}
+	return -1
+}
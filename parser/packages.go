@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"golang.org/x/tools/go/packages"
+)
+
+// TypedPackage is a package type checked with go/types: its types are resolved as the compiler
+// resolves them, across packages, aliases and modules
+type TypedPackage struct {
+	Types *types.Package
+	Info  *types.Info
+	Files []*ast.File
+}
+
+// packagesConfig is the configuration of go/packages loading mode: packages are loaded with the build
+// tags of the parser, the way the go command builds them, in module or GOPATH mode
+func (parser *Parser) packagesConfig(mode packages.LoadMode) *packages.Config {
+	return &packages.Config{
+		Mode:       mode,
+		BuildFlags: []string{"-tags=" + strings.Join(parser.BuildTags, ",")},
+		Fset:       parser.FileSet,
+	}
+}
+
+// listPackage returns the package of importPath loaded by go/packages, nil if it can not be loaded,
+// e.g. without a go command in $PATH. With IncludeTests, it is the package as compiled for its tests,
+// with its _test.go files. Its dependencies are listed at the same time: results are cached.
+func (parser *Parser) listPackage(importPath string) *packages.Package {
+	if listed, ok := parser.listedPackages[importPath]; ok || importPath == "" {
+		return listed
+	}
+	parser.listedPackages[importPath] = nil
+
+	config := parser.packagesConfig(packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps)
+	config.Tests = parser.IncludeTests
+	loaded, err := packages.Load(config, importPath)
+	if err != nil {
+		logger.Debugf("Can not list package %s: %v", importPath, err)
+		return nil
+	}
+	// with IncludeTests, the variant of the package compiled for its tests, not its x_test package or test binary
+	var root *packages.Package
+	for _, listed := range loaded {
+		if listed.PkgPath == importPath && (root == nil || listed.ID != listed.PkgPath) {
+			root = listed
+		}
+	}
+	if root == nil {
+		return nil
+	}
+	packages.Visit([]*packages.Package{root}, nil, func(listed *packages.Package) {
+		for _, listError := range listed.Errors {
+			logger.Debugf("Can not list package %s: %v", listed.PkgPath, listError)
+		}
+		if listed.Dir == "" || len(listed.GoFiles) == 0 || listed != root && (listed.ID != listed.PkgPath || parser.listedPackages[listed.PkgPath] != nil) {
+			return
+		}
+		if evaluatedDir, err := filepath.EvalSymlinks(listed.Dir); err == nil {
+			listed.Dir = evaluatedDir
+		}
+		parser.listedPackages[listed.PkgPath] = listed
+		parser.listedDirs[listed.Dir] = listed
+	})
+	return parser.listedPackages[importPath]
+}
+
+// subPackages returns the import paths of the packages in the directory tree of packageName, as the
+// go command lists them in module or GOPATH mode. The directories are walked when it can not list them.
+func (parser *Parser) subPackages(packageName string) []string {
+	var subPackages []string
+	if loaded, err := packages.Load(parser.packagesConfig(packages.NeedName), packageName+"/..."); err == nil && len(loaded) > 0 {
+		for _, listed := range loaded {
+			if listed.PkgPath != "" && listed.PkgPath != packageName {
+				subPackages = append(subPackages, listed.PkgPath)
+			}
+		}
+		return subPackages
+	}
+
+	root := parser.CheckRealPackagePath(packageName)
+	if root == "" {
+		return subPackages
+	}
+	filepath.Walk(root, func(dir string, info os.FileInfo, err error) error {
+		if err == nil && info.IsDir() && dir != root {
+			subPackages = append(subPackages, SubPackagePath(packageName, root, dir))
+		}
+		return nil
+	})
+	return subPackages
+}
+
+// SubPackagePath returns the import path of dir, a directory in root, the directory of the package
+// packageName: github.com/acme/api and C:\src\api\admin in C:\src\api give github.com/acme/api/admin
+func SubPackagePath(packageName, root, dir string) string {
+	relative, err := filepath.Rel(root, dir)
+	if err != nil {
+		return ""
+	}
+	return path.Join(packageName, filepath.ToSlash(relative))
+}
+
+// Gopath returns the GOPATH directories of the go command: $GOPATH when it is set, its default
+// ($HOME/go) otherwise, so the $GOPATH environment variable does not need to be set
+func Gopath() []string {
+	if out, err := exec.Command("go", "env", "GOPATH").Output(); err == nil {
+		return filepath.SplitList(strings.TrimSpace(string(out)))
+	}
+	return filepath.SplitList(os.Getenv("GOPATH"))
+}
+
+// parseListedPackage parses the files of a listed package, the way goparser.ParseDir does for all
+// the files of a directory
+func (parser *Parser) parseListedPackage(listed *packages.Package) (map[string]*ast.Package, error) {
+	astPackage := &ast.Package{
+		Name:  listed.Name,
+		Files: make(map[string]*ast.File),
+	}
+	for _, fileName := range listed.GoFiles {
+		astFile, err := goparser.ParseFile(parser.FileSet, fileName, nil, goparser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		astPackage.Files[fileName] = astFile
+	}
+	return map[string]*ast.Package{listed.Name: astPackage}, nil
+}
+
+// fileFilter is the filter of the files of dir parsed when go list can not list its package: like
+// go list, it applies the build constraints of BuildTags, and keeps the _test.go files with IncludeTests
+func (parser *Parser) fileFilter(dir string) func(os.FileInfo) bool {
+	buildContext := build.Default
+	buildContext.BuildTags = parser.BuildTags
+	return func(info os.FileInfo) bool {
+		if !ParserFileFilter(info) && !(parser.IncludeTests && strings.HasSuffix(info.Name(), "_test.go")) {
+			return false
+		}
+		match, err := buildContext.MatchFile(dir, info.Name())
+		return err == nil && match
+	}
+}
+
+// TypeCheck returns the package of importPath type checked by go/packages. Its dependencies are
+// not type checked from source: their types are read from the export data of the go command, built
+// once in its build cache. Type errors are logged in verbose mode only: the types which can be
+// resolved are. The files type checked are the ones of GetPackageAst, so the types of their
+// expressions are known. Results are cached.
+func (parser *Parser) TypeCheck(importPath string) (_ *TypedPackage, err error) {
+	defer recoverError(&err)
+	if typed, ok := parser.typedPackages[importPath]; ok {
+		return typed, nil
+	}
+	dir := parser.CheckRealPackagePath(importPath)
+	if dir == "" {
+		return nil, fmt.Errorf("Can not find package %s\n", importPath)
+	}
+
+	parsedFiles := make(map[string]*ast.File)
+	for _, astPackage := range parser.packageAst(dir) {
+		for fileName, astFile := range astPackage.Files {
+			parsedFiles[filepath.Base(fileName)] = astFile
+		}
+	}
+	config := parser.packagesConfig(packages.NeedName | packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo)
+	config.ParseFile = func(fileSet *token.FileSet, fileName string, src []byte) (*ast.File, error) {
+		if astFile, ok := parsedFiles[filepath.Base(fileName)]; ok {
+			return astFile, nil
+		}
+		return goparser.ParseFile(fileSet, fileName, src, goparser.ParseComments)
+	}
+	loaded, err := packages.Load(config, importPath)
+	if err != nil || len(loaded) != 1 || loaded[0].Types == nil || len(loaded[0].Syntax) == 0 {
+		return nil, fmt.Errorf("Can not type check package %s\n", importPath)
+	}
+	for _, typeError := range loaded[0].Errors {
+		logger.Debugf("Type checking %s: %v", importPath, typeError)
+	}
+
+	typed := &TypedPackage{Types: loaded[0].Types, Info: loaded[0].TypesInfo, Files: loaded[0].Syntax}
+	parser.typedPackages[importPath] = typed
+	return typed, nil
+}
+
+// typedModelDefinition resolves modelName, referred to in currentPackage, with go/types: Model is
+// a type of currentPackage or of a package it dot imports, package.Model a type of the package
+// imported by currentPackage under that name, whatever its path is, or else of the sibling package
+// of currentPackage (controllers and models directories next to each other), and
+// github.com.acme.models.Model a type of an absolute package. Type aliases are followed to the type
+// definition, in whatever package it is.
+func (parser *Parser) typedModelDefinition(modelName string, currentPackage string) (*ast.TypeSpec, string) {
+	nameParts := strings.Split(modelName, ".")
+	name := nameParts[len(nameParts)-1]
+
+	var scopes []*types.Package
+	switch len(nameParts) {
+	case 1:
+		if typed, err := parser.TypeCheck(currentPackage); err == nil {
+			scopes = append([]*types.Package{typed.Types}, importedPackages(typed, ".")...)
+		}
+	case 2:
+		if typed, err := parser.TypeCheck(currentPackage); err == nil {
+			scopes = importedPackages(typed, nameParts[0])
+		}
+		if len(scopes) == 0 {
+			if typed, err := parser.TypeCheck(path.Join(path.Dir(currentPackage), nameParts[0])); err == nil {
+				scopes = append(scopes, typed.Types)
+			}
+		}
+	default:
+		if typed, err := parser.TypeCheck(strings.Join(nameParts[:len(nameParts)-1], "/")); err == nil {
+			scopes = append(scopes, typed.Types)
+		}
+	}
+
+	for _, scope := range scopes {
+		typeName, ok := scope.Scope().Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if named, ok := types.Unalias(typeName.Type()).(*types.Named); ok {
+			typeName = named.Obj()
+		}
+		if typeName.Pkg() == nil {
+			continue
+		}
+
+		packageName := typeName.Pkg().Path()
+		if parser.GetModelDefinition(typeName.Name(), packageName) == nil {
+			parser.parseTypeDefinitionsOf(packageName)
+		}
+		if model := parser.GetModelDefinition(typeName.Name(), packageName); model != nil && !model.Assign.IsValid() {
+			return model, packageName
+		}
+	}
+	return nil, ""
+}
+
+// parseTypeDefinitionsOf parses the type definitions of packageName, keeping the current package,
+// even if the parsing fails
+func (parser *Parser) parseTypeDefinitionsOf(packageName string) {
+	defer func(currentPackage string) {
+		parser.CurrentPackage = currentPackage
+	}(parser.CurrentPackage)
+	parser.parseTypeDefinitions(packageName)
+}
+
+// importedPackages returns the packages imported by the files of typed under name, "." for the dot
+// imported packages
+func importedPackages(typed *TypedPackage, name string) []*types.Package {
+	var packages []*types.Package
+	for _, astFile := range typed.Files {
+		for _, importSpec := range astFile.Imports {
+			object := typed.Info.Implicits[importSpec]
+			if importSpec.Name != nil && typed.Info.Defs[importSpec.Name] != nil {
+				object = typed.Info.Defs[importSpec.Name]
+			}
+			if pkgName, ok := object.(*types.PkgName); ok && (pkgName.Name() == name || name == "." && importSpec.Name != nil && importSpec.Name.Name == ".") {
+				packages = append(packages, pkgName.Imported())
+			}
+		}
+	}
+	return packages
+}
@@ -2,15 +2,19 @@ package parser
 
 import (
 	"encoding/json"
+	"fmt"
 	"go/ast"
 	goparser "go/parser"
 	"go/token"
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
+	"text/template"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"golang.org/x/tools/go/packages"
 )
 
 type Parser struct {
@@ -22,8 +26,39 @@ type Parser struct {
 	PackagePathCache                  map[string]string
 	PackageImports                    map[string]map[string][]string
 	BasePath                          string
+	Host                              string
+	Schemes                           []string
+	Wrapper                           string
 	IsController                      func(*ast.FuncDecl) bool
-	TypesImplementingMarshalInterface map[string]string
+	TypesImplementingMarshalInterface map[string]string // type name -> swagger type, overrides detectedMarshalers
+	detectedMarshalers                map[string]string // types with MarshalJSON or MarshalText methods
+	MuxRoutes                         map[string][]*MuxRoute
+	Models                            map[string]*Model // shared by all top level APIs, indexed by model Id
+	FileSet                           *token.FileSet
+	Strict                            bool
+	ModelNaming                       string          // how model Ids are formed, ModelNamingFull by default
+	PointerOptional                   bool            // pointer fields are optional and nullable, other fields without omitempty are required
+	Sort                              string          // order of the paths and operations of the resources, SortByPath by default
+	IncludeInternal                   bool            // operations and controllers annotated with @Internal are parsed too
+	IncludeTests                      bool            // _test.go files are parsed too
+	BuildTags                         []string        // build tags of the parsed files, as go build -tags
+	Dialect                           string          // annotation dialect, DialectNative by default
+	PackageTimings                    []PackageTiming // time spent parsing each package, in the order of ParseApi
+	operationCount                    int             // operations added, gives their order in the source
+	modelNamingTemplate               *template.Template
+	modelSources                      map[string]modelSource       // model Id -> type definition
+	modelCollisions                   map[string][]modelSource     // colliding model Id -> type definitions
+	resolvingTypes                    map[string]bool              // named types being resolved, `type Tree []Tree` is resolved once
+	parsingModels                     map[string]bool              // Ids of the models being parsed, to detect circular references
+	circularModels                    map[string]bool              // "A B" if A and B refer to each other, reported once
+	typeDefTranslations               map[string]string            // `type Name string` type name -> basic type
+	listedPackages                    map[string]*packages.Package // import path -> package listed by go/packages, nil if it can not be
+	listedDirs                        map[string]*packages.Package // directory -> package listed by go/packages
+	typedPackages                     map[string]*TypedPackage     // import path -> package type checked by TypeCheck
+	errors                            ErrorList                    // errors of the annotations, returned at the end of the parsing
+	goSwaggerParameters               map[string][]goSwaggerStruct // operation id -> its swagger:parameters structs
+	goSwaggerResponses                map[string]goSwaggerStruct   // name -> swagger:response struct
+	enums                             map[*ast.TypeSpec][]string   // named type -> values of its typed constants
 }
 
 func NewParser() *Parser {
@@ -38,21 +73,33 @@ func NewParser() *Parser {
 		PackagePathCache:                  make(map[string]string),
 		PackageImports:                    make(map[string]map[string][]string),
 		TypesImplementingMarshalInterface: make(map[string]string),
+		detectedMarshalers:                make(map[string]string),
+		MuxRoutes:                         make(map[string][]*MuxRoute),
+		Models:                            make(map[string]*Model),
+		modelSources:                      make(map[string]modelSource),
+		modelCollisions:                   make(map[string][]modelSource),
+		resolvingTypes:                    make(map[string]bool),
+		parsingModels:                     make(map[string]bool),
+		circularModels:                    make(map[string]bool),
+		typeDefTranslations:               make(map[string]string),
+		listedPackages:                    make(map[string]*packages.Package),
+		listedDirs:                        make(map[string]*packages.Package),
+		typedPackages:                     make(map[string]*TypedPackage),
+		goSwaggerParameters:               make(map[string][]goSwaggerStruct),
+		goSwaggerResponses:                make(map[string]goSwaggerStruct),
+		enums:                             make(map[*ast.TypeSpec][]string),
+		FileSet:                           token.NewFileSet(),
 	}
 }
 
-func (parser *Parser) IsImplementMarshalInterface(typeName string) bool {
-	_, ok := parser.TypesImplementingMarshalInterface[typeName]
-	return ok
-}
-
 //Read web/main.go to get General info
-func (parser *Parser) ParseGeneralApiInfo(mainApiFile string) {
+func (parser *Parser) ParseGeneralApiInfo(mainApiFile string) (err error) {
+	defer recoverError(&err)
 
 	fileSet := token.NewFileSet()
 	fileTree, err := goparser.ParseFile(fileSet, mainApiFile, nil, goparser.ParseComments)
 	if err != nil {
-		log.Fatalf("Can not parse general API information: %v\n", err)
+		failf("Can not parse general API information: %v\n", err)
 	}
 
 	parser.Listing.SwaggerVersion = SwaggerVersion
@@ -60,23 +107,107 @@ func (parser *Parser) ParseGeneralApiInfo(mainApiFile string) {
 		for _, comment := range fileTree.Comments {
 			for _, commentLine := range strings.Split(comment.Text(), "\n") {
 				attribute := strings.ToLower(strings.Split(commentLine, " ")[0])
+				value := strings.TrimSpace(commentLine[len(attribute):])
+				if parser.Dialect == DialectSwaggo {
+					attribute = swaggoGeneralAnnotation(attribute)
+				}
+				if inList(attribute, generalAnnotations) {
+					value = expandEnv(value)
+				}
 				switch attribute {
 				case "@apiversion":
-					parser.Listing.ApiVersion = strings.TrimSpace(commentLine[len(attribute):])
+					parser.Listing.ApiVersion = value
 				case "@apititle":
-					parser.Listing.Infos.Title = strings.TrimSpace(commentLine[len(attribute):])
+					parser.Listing.Infos.Title = value
 				case "@apidescription":
-					parser.Listing.Infos.Description = strings.TrimSpace(commentLine[len(attribute):])
+					parser.Listing.Infos.Description = value
 				case "@termsofserviceurl":
-					parser.Listing.Infos.TermsOfServiceUrl = strings.TrimSpace(commentLine[len(attribute):])
-				case "@contact":
-					parser.Listing.Infos.Contact = strings.TrimSpace(commentLine[len(attribute):])
-				case "@licenseurl":
-					parser.Listing.Infos.LicenseUrl = strings.TrimSpace(commentLine[len(attribute):])
-				case "@license":
-					parser.Listing.Infos.License = strings.TrimSpace(commentLine[len(attribute):])
+					parser.Listing.Infos.TermsOfServiceUrl = value
+				case "@contact", "@contact.email":
+					parser.Listing.Infos.Contact = value
+				case "@contact.name":
+					parser.Listing.Infos.ContactName = value
+				case "@contact.url":
+					parser.Listing.Infos.ContactUrl = value
+				case "@licenseurl", "@license.url":
+					parser.Listing.Infos.LicenseUrl = value
+				case "@license", "@license.name":
+					parser.Listing.Infos.License = value
+				case "@wrapper":
+					if err := checkWrapper(value); err != nil {
+						parser.addError(fileSet.Position(comment.Pos()), err)
+					}
+					parser.Wrapper = value
+				case "@basepath":
+					parser.BasePath = value
+				case "@host":
+					parser.Host = value
+				case "@schemes":
+					parser.Schemes = strings.FieldsFunc(value, func(r rune) bool {
+						return r == ',' || r == ' ' || r == '\t'
+					})
+				case "@extension":
+					if err := parser.Listing.Extensions.add(value); err != nil {
+						parser.addError(fileSet.Position(comment.Pos()), err)
+					}
+				case "@server":
+					if err := parser.Listing.addServer(value); err != nil {
+						parser.addError(fileSet.Position(comment.Pos()), err)
+					}
+				case "@servervariable":
+					if err := parser.Listing.addServerVariable(value); err != nil {
+						parser.addError(fileSet.Position(comment.Pos()), err)
+					}
 				}
 			}
+			if parser.Strict {
+				parser.checkGeneralAnnotations(fileSet, comment)
+			}
+		}
+	}
+	if err := parser.Listing.checkServers(); err != nil {
+		parser.addError(fileSet.Position(fileTree.Package), err)
+	}
+	return parser.collectedErrors()
+}
+
+// envRegexp matches the ${VAR} placeholders of general annotations
+var envRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces the ${VAR} placeholders of value by the environment variables,
+// so build pipelines can inject the host, base path or version of an environment
+func expandEnv(value string) string {
+	return envRegexp.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := envRegexp.FindStringSubmatch(placeholder)[1]
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			logger.Warnf("Environment variable %s of %s is not set\n", name, value)
+		}
+		return envValue
+	})
+}
+
+// generalAnnotations are the annotations ParseGeneralApiInfo understands, lower cased
+var generalAnnotations = []string{"@apiversion", "@apititle", "@apidescription", "@termsofserviceurl", "@contact", "@contact.email", "@contact.name", "@contact.url", "@licenseurl", "@license", "@license.name", "@license.url", "@wrapper", "@basepath", "@host", "@schemes", "@subapi", "@extension", "@server", "@servervariable"}
+
+// checkGeneralAnnotations reports the annotations of the main API file, which are neither general nor operation annotations
+func (parser *Parser) checkGeneralAnnotations(fileSet *token.FileSet, comment *ast.CommentGroup) {
+	knownAnnotations := append(append([]string{}, generalAnnotations...), operationAnnotations...)
+	if parser.Dialect == DialectSwaggo {
+		knownAnnotations = append(knownAnnotations, swaggoOperationAnnotations...)
+	}
+	for _, line := range comment.List {
+		commentLine := strings.TrimSpace(strings.TrimLeft(line.Text, "//"))
+		if !strings.HasPrefix(commentLine, "@") {
+			continue
+		}
+		attribute := strings.Fields(commentLine)[0]
+		known := strings.ToLower(attribute)
+		if parser.Dialect == DialectSwaggo {
+			known = swaggoGeneralAnnotation(known)
+		}
+		if known != "" && !inList(known, knownAnnotations) {
+			parser.addError(fileSet.Position(line.Pos()), unknownAnnotationError(attribute, knownAnnotations))
 		}
 	}
 }
@@ -84,7 +215,7 @@ func (parser *Parser) ParseGeneralApiInfo(mainApiFile string) {
 func (parser *Parser) GetResourceListingJson() []byte {
 	json, err := json.MarshalIndent(parser.Listing, "", "    ")
 	if err != nil {
-		log.Fatalf("Can not serialise ResourceListing to JSON: %v\n", err)
+		logger.Errorf("Can not serialise ResourceListing to JSON: %v\n", err)
 	}
 	return json
 }
@@ -92,11 +223,22 @@ func (parser *Parser) GetResourceListingJson() []byte {
 func (parser *Parser) GetApiDescriptionJson() []byte {
 	json, err := json.MarshalIndent(parser.TopLevelApis, "", "    ")
 	if err != nil {
-		log.Fatalf("Can not serialise []ApiDescription to JSON: %v\n", err)
+		logger.Errorf("Can not serialise []ApiDescription to JSON: %v\n", err)
 	}
 	return json
 }
 
+// GetModels returns models of all top level APIs, indexed by model Id
+func (parser *Parser) GetModels() map[string]*Model {
+	models := make(map[string]*Model)
+	for _, api := range parser.TopLevelApis {
+		for id, model := range api.Models {
+			models[id] = model
+		}
+	}
+	return models
+}
+
 func (parser *Parser) CheckRealPackagePath(packagePath string) string {
 	packagePath = strings.Trim(packagePath, "\"")
 
@@ -104,16 +246,16 @@ func (parser *Parser) CheckRealPackagePath(packagePath string) string {
 		return cachedResult
 	}
 
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		log.Fatalf("Please, set $GOPATH environment variable\n")
+	// first ask the go command, which knows modules and vendor directories
+	if listed := parser.listPackage(packagePath); listed != nil {
+		parser.PackagePathCache[packagePath] = listed.Dir
+		return listed.Dir
 	}
 
-	// first check GOPATH
+	// next check GOPATH
 	pkgRealpath := ""
-	gopathsList := filepath.SplitList(gopath)
-	for _, path := range gopathsList {
-		if evalutedPath, err := filepath.EvalSymlinks(filepath.Join(path, "src", packagePath)); err == nil {
+	for _, path := range Gopath() {
+		if evalutedPath, err := filepath.EvalSymlinks(filepath.Join(path, "src", filepath.FromSlash(packagePath))); err == nil {
 			if _, err := os.Stat(evalutedPath); err == nil {
 				pkgRealpath = evalutedPath
 				break
@@ -124,10 +266,7 @@ func (parser *Parser) CheckRealPackagePath(packagePath string) string {
 	// next, check GOROOT (/src)
 	if pkgRealpath == "" {
 		goroot := filepath.Clean(runtime.GOROOT())
-		if goroot == "" {
-			log.Fatalf("Please, set $GOROOT environment variable\n")
-		}
-		if evalutedPath, err := filepath.EvalSymlinks(filepath.Join(goroot, "src", packagePath)); err == nil {
+		if evalutedPath, err := filepath.EvalSymlinks(filepath.Join(goroot, "src", filepath.FromSlash(packagePath))); err == nil {
 			if _, err := os.Stat(evalutedPath); err == nil {
 				pkgRealpath = evalutedPath
 			}
@@ -135,7 +274,7 @@ func (parser *Parser) CheckRealPackagePath(packagePath string) string {
 
 		// next, check GOROOT (/src/pkg) (for golang < v1.4)
 		if pkgRealpath == "" {
-			if evalutedPath, err := filepath.EvalSymlinks(filepath.Join(goroot, "src", "pkg", packagePath)); err == nil {
+			if evalutedPath, err := filepath.EvalSymlinks(filepath.Join(goroot, "src", "pkg", filepath.FromSlash(packagePath))); err == nil {
 				if _, err := os.Stat(evalutedPath); err == nil {
 					pkgRealpath = evalutedPath
 				}
@@ -146,25 +285,65 @@ func (parser *Parser) CheckRealPackagePath(packagePath string) string {
 	return pkgRealpath
 }
 
-func (parser *Parser) GetRealPackagePath(packagePath string) string {
+// FindInGopath returns the path of name, a slash separated path relative to $GOPATH/src, in the first
+// GOPATH directory it exists in, or "" if there is none. The GOPATH is the one of `go env GOPATH`, a
+// list of directories separated by os.PathListSeparator, ":" on Unix and ";" on Windows.
+func FindInGopath(name string) string {
+	for _, gopath := range Gopath() {
+		fileName := filepath.Join(gopath, "src", filepath.FromSlash(name))
+		if _, err := os.Stat(fileName); err == nil {
+			return fileName
+		}
+	}
+	return ""
+}
+
+// GetRealPackagePath is CheckRealPackagePath, with an error if the package can not be found
+func (parser *Parser) GetRealPackagePath(packagePath string) (string, error) {
 	pkgRealpath := parser.CheckRealPackagePath(packagePath)
 	if pkgRealpath == "" {
-		log.Fatalf("Can not find package %s \n", packagePath)
+		return "", fmt.Errorf("Can not find package %s \n", packagePath)
 	}
 
+	return pkgRealpath, nil
+}
+
+// realPackagePath is GetRealPackagePath, which aborts the parsing on an unknown package
+func (parser *Parser) realPackagePath(packagePath string) string {
+	pkgRealpath, err := parser.GetRealPackagePath(packagePath)
+	if err != nil {
+		failf("%v", err)
+	}
 	return pkgRealpath
 }
 
-func (parser *Parser) GetPackageAst(packagePath string) map[string]*ast.Package {
+// GetPackageAst returns the parsed files of the package in the directory packagePath
+func (parser *Parser) GetPackageAst(packagePath string) (_ map[string]*ast.Package, err error) {
+	defer recoverError(&err)
+	return parser.packageAst(packagePath), nil
+}
+
+// packageAst is GetPackageAst, which aborts the parsing if the package can not be parsed
+func (parser *Parser) packageAst(packagePath string) map[string]*ast.Package {
 	//log.Printf("Parse %s package\n", packagePath)
 	if cache, ok := parser.PackagesCache[packagePath]; ok {
 		return cache
 	} else {
-		fileSet := token.NewFileSet()
-
-		astPackages, err := goparser.ParseDir(fileSet, packagePath, ParserFileFilter, goparser.ParseComments)
+		// the files of the current build if go list knows the package, all of them otherwise
+		var astPackages map[string]*ast.Package
+		var err error
+		if listed, ok := parser.listedDirs[packagePath]; ok {
+			astPackages, err = parser.parseListedPackage(listed)
+		} else {
+			astPackages, err = goparser.ParseDir(parser.FileSet, packagePath, parser.fileFilter(packagePath), goparser.ParseComments)
+			for name := range astPackages {
+				if strings.HasSuffix(name, "_test") {
+					delete(astPackages, name)
+				}
+			}
+		}
 		if err != nil {
-			log.Fatalf("Parse of %s pkg cause error: %s\n", packagePath, err)
+			failf("Parse of %s pkg cause error: %s\n", packagePath, err)
 		}
 		parser.PackagesCache[packagePath] = astPackages
 		return astPackages
@@ -210,16 +389,71 @@ func (parser *Parser) AddOperation(op *Operation) {
 		parser.Listing.Apis = append(parser.Listing.Apis, apiRef)
 	}
 
+	parser.operationCount++
+	op.order = parser.operationCount
+	parser.shareModels(op)
 	api.AddOperation(op)
 }
 
-func (parser *Parser) ParseApi(packageNames string) {
+// shareModels replaces the models of op by the definitions already registered by other operations,
+// so a model used by several top level APIs is defined once. Another definition with the same Id
+// is dropped with a warning, instead of each API getting its own variant.
+func (parser *Parser) shareModels(op *Operation) {
+	for i, model := range op.Models {
+		shared, ok := parser.Models[model.Id]
+		if !ok {
+			parser.Models[model.Id] = model
+			continue
+		}
+		if shared != model && !model.Equal(shared) {
+			logger.Warnf("Model %s of operation %s %s differs from its first definition, which is used instead\n", model.Id, op.HttpMethod, op.Path)
+		}
+		op.Models[i] = shared
+	}
+}
+
+// ParseApi parses the operations of packageNames and their sub packages, and the models they use.
+// The errors of the annotations are returned together as an ErrorList, with the ones of
+// ParseGeneralApiInfo: the operations and models which have none are parsed nonetheless.
+func (parser *Parser) ParseApi(packageNames string) (err error) {
+	defer recoverError(&err)
 	packages := parser.ScanPackages(strings.Split(packageNames, ","))
 	for _, packageName := range packages {
-		parser.ParseTypeDefinitions(packageName)
+		parser.timePackage(packageName, parser.parseTypeDefinitions)
+	}
+	for _, packageName := range packages {
+		parser.timePackage(packageName, parser.parseRoutes)
+	}
+	if parser.Dialect == DialectGoSwagger {
+		for _, packageName := range packages {
+			parser.timePackage(packageName, parser.parseGoSwaggerDeclarations)
+		}
 	}
+	progress := newProgress(packages)
 	for _, packageName := range packages {
-		parser.ParseApiDescription(packageName)
+		progress.next(packageName)
+		parser.timePackage(packageName, parser.parseApiDescription)
+	}
+	parser.ResolveModelCollisions()
+	parser.SortApis()
+	return parser.collectedErrors()
+}
+
+// ParseRoutes collects routes registered in the package code (gorilla/mux), so handlers can be documented without @Router
+func (parser *Parser) ParseRoutes(packageName string) (err error) {
+	defer recoverError(&err)
+	parser.parseRoutes(packageName)
+	return nil
+}
+
+func (parser *Parser) parseRoutes(packageName string) {
+	pkgRealPath := parser.realPackagePath(packageName)
+
+	astPackages := parser.packageAst(pkgRealPath)
+	for _, astPackage := range sortedPackages(astPackages) {
+		for _, astFile := range sortedFiles(astPackage) {
+			parser.ParseMuxRoutes(astFile, packageName)
+		}
 	}
 }
 
@@ -232,97 +466,120 @@ func (parser *Parser) ScanPackages(packages []string) []string {
 			// Add package
 			existsPackages[packageName] = true
 			res = append(res, packageName)
-			// get it's real path
-			pkgRealPath := parser.GetRealPackagePath(packageName)
-			// Then walk
-			var walker filepath.WalkFunc = func(path string, info os.FileInfo, err error) error {
-				if info.IsDir() {
-					if idx := strings.Index(path, packageName); idx != -1 {
-						pack := path[idx:]
-						if v, ok := existsPackages[pack]; !ok || v == false {
-							existsPackages[pack] = true
-							res = append(res, pack)
-						}
-					}
+			// Then its sub-packages
+			for _, pack := range parser.subPackages(packageName) {
+				if v, ok := existsPackages[pack]; !ok || v == false {
+					existsPackages[pack] = true
+					res = append(res, pack)
 				}
-				return nil
 			}
-			filepath.Walk(pkgRealPath, walker)
 		}
 	}
 	return res
 }
 
-func (parser *Parser) ParseTypeDefinitions(packageName string) {
+// ParseTypeDefinitions collects the types, marshalers and enums of packageName and of the packages it imports
+func (parser *Parser) ParseTypeDefinitions(packageName string) (err error) {
+	defer recoverError(&err)
+	parser.parseTypeDefinitions(packageName)
+	return nil
+}
+
+func (parser *Parser) parseTypeDefinitions(packageName string) {
 	parser.CurrentPackage = packageName
-	pkgRealPath := parser.GetRealPackagePath(packageName)
+	pkgRealPath := parser.realPackagePath(packageName)
 	//	log.Printf("Parse type definition of %#v\n", packageName)
 
 	if _, ok := parser.TypeDefinitions[pkgRealPath]; !ok {
 		parser.TypeDefinitions[pkgRealPath] = make(map[string]*ast.TypeSpec)
 	}
 
-	astPackages := parser.GetPackageAst(pkgRealPath)
-	for _, astPackage := range astPackages {
-		for _, astFile := range astPackage.Files {
+	astPackages := parser.packageAst(pkgRealPath)
+	var funcDeclarations []*ast.FuncDecl
+	var constDeclarations []*ast.GenDecl
+	for _, astPackage := range sortedPackages(astPackages) {
+		for _, astFile := range sortedFiles(astPackage) {
 			for _, astDeclaration := range astFile.Decls {
-				if generalDeclaration, ok := astDeclaration.(*ast.GenDecl); ok && generalDeclaration.Tok == token.TYPE {
+				if generalDeclaration, ok := astDeclaration.(*ast.GenDecl); ok && generalDeclaration.Tok == token.CONST {
+					constDeclarations = append(constDeclarations, generalDeclaration)
+				} else if generalDeclaration, ok := astDeclaration.(*ast.GenDecl); ok && generalDeclaration.Tok == token.TYPE {
 					for _, astSpec := range generalDeclaration.Specs {
 						if typeSpec, ok := astSpec.(*ast.TypeSpec); ok {
+							// the doc comment of "type X struct" belongs to the declaration
+							if typeSpec.Doc == nil && len(generalDeclaration.Specs) == 1 {
+								typeSpec.Doc = generalDeclaration.Doc
+							}
 							parser.TypeDefinitions[pkgRealPath][typeSpec.Name.String()] = typeSpec
 						}
 					}
+				} else if funcDeclaration, ok := astDeclaration.(*ast.FuncDecl); ok {
+					funcDeclarations = append(funcDeclarations, funcDeclaration)
 				}
 			}
 		}
 	}
+	// methods and constants are inspected once all the types of the package are known
+	for _, funcDeclaration := range funcDeclarations {
+		parser.detectMarshaler(funcDeclaration, packageName, parser.TypeDefinitions[pkgRealPath])
+	}
+	parser.parseEnums(constDeclarations, parser.TypeDefinitions[pkgRealPath])
 
 	//log.Fatalf("Type definition parsed %#v\n", parser.ParseImportStatements(packageName))
 
-	for importedPackage, _ := range parser.ParseImportStatements(packageName) {
+	for _, importedPackage := range sortedSet(parser.parseImportStatements(packageName)) {
 		//log.Printf("Import: %v, %v\n", importedPackage, v)
-		parser.ParseTypeDefinitions(importedPackage)
+		parser.parseTypeDefinitions(importedPackage)
 	}
 }
 
-func (parser *Parser) ParseImportStatements(packageName string) map[string]bool {
+// ParseImportStatements collects the imports of packageName, and returns the ones whose types are not parsed yet
+func (parser *Parser) ParseImportStatements(packageName string) (_ map[string]bool, err error) {
+	defer recoverError(&err)
+	return parser.parseImportStatements(packageName), nil
+}
+
+func (parser *Parser) parseImportStatements(packageName string) map[string]bool {
 
 	parser.CurrentPackage = packageName
-	pkgRealPath := parser.GetRealPackagePath(packageName)
+	pkgRealPath := parser.realPackagePath(packageName)
 
 	imports := make(map[string]bool)
-	astPackages := parser.GetPackageAst(pkgRealPath)
+	astPackages := parser.packageAst(pkgRealPath)
 
 	parser.PackageImports[pkgRealPath] = make(map[string][]string)
-	for _, astPackage := range astPackages {
-		for _, astFile := range astPackage.Files {
+	for _, astPackage := range sortedPackages(astPackages) {
+		for _, astFile := range sortedFiles(astPackage) {
 			for _, astImport := range astFile.Imports {
 				importedPackageName := strings.Trim(astImport.Path.Value, "\"")
 				if !IsIgnoredPackage(importedPackageName) {
-					realPath := parser.GetRealPackagePath(importedPackageName)
+					realPath := parser.realPackagePath(importedPackageName)
 					//log.Printf("path: %#v, original path: %#v", realPath, astImport.Path.Value)
 					if _, ok := parser.TypeDefinitions[realPath]; !ok {
 						imports[importedPackageName] = true
 						//log.Printf("Parse %s, Add new import definition:%s\n", packageName, astImport.Path.Value)
 					}
 
-					var importedPackageAlias string
-					if astImport.Name != nil && astImport.Name.Name != "." && astImport.Name.Name != "_" {
-						importedPackageAlias = astImport.Name.Name
-					} else {
-						importPath := strings.Split(importedPackageName, "/")
-						importedPackageAlias = importPath[len(importPath)-1]
+					// an import is referred to by its last path element, an aliased import by its alias,
+					// and the types of a dot import by their name alone too
+					importPath := strings.Split(importedPackageName, "/")
+					importedPackageAliases := []string{importPath[len(importPath)-1]}
+					if astImport.Name != nil && astImport.Name.Name == "." {
+						importedPackageAliases = append(importedPackageAliases, ".")
+					} else if astImport.Name != nil && astImport.Name.Name != "_" {
+						importedPackageAliases = []string{astImport.Name.Name}
 					}
 
-					isExists := false
-					for _, v := range parser.PackageImports[pkgRealPath][importedPackageAlias] {
-						if v == importedPackageName {
-							isExists = true
+					for _, importedPackageAlias := range importedPackageAliases {
+						isExists := false
+						for _, v := range parser.PackageImports[pkgRealPath][importedPackageAlias] {
+							if v == importedPackageName {
+								isExists = true
+							}
 						}
-					}
 
-					if !isExists {
-						parser.PackageImports[pkgRealPath][importedPackageAlias] = append(parser.PackageImports[pkgRealPath][importedPackageAlias], importedPackageName)
+						if !isExists {
+							parser.PackageImports[pkgRealPath][importedPackageAlias] = append(parser.PackageImports[pkgRealPath][importedPackageAlias], importedPackageName)
+						}
 					}
 				}
 			}
@@ -345,17 +602,53 @@ func (parser *Parser) GetModelDefinition(model string, packageName string) *ast.
 	return astTypeSpec
 }
 
-func (parser *Parser) FindModelDefinition(modelName string, currentPackage string) (*ast.TypeSpec, string) {
+// findModelDefinition is LookupModelDefinition, which aborts the parsing on an unknown model
+func (parser *Parser) findModelDefinition(modelName string, currentPackage string) (*ast.TypeSpec, string) {
+	model, modelPackage, err := parser.resolveModelDefinition(modelName, currentPackage)
+	if err != nil {
+		failf("%v", err)
+	}
+	return model, modelPackage
+}
+
+// LookupModelDefinition returns the definition of modelName and its package, or an error if it is unknown.
+// Models which can not be found by name, and type aliases, are resolved with go/types.
+func (parser *Parser) LookupModelDefinition(modelName string, currentPackage string) (_ *ast.TypeSpec, _ string, err error) {
+	defer recoverError(&err)
+	return parser.resolveModelDefinition(modelName, currentPackage)
+}
+
+// resolveModelDefinition is LookupModelDefinition, which aborts the parsing if the package of a
+// type resolved with go/types can not be parsed
+func (parser *Parser) resolveModelDefinition(modelName string, currentPackage string) (*ast.TypeSpec, string, error) {
+	model, modelPackage, err := parser.lookupModelDefinition(modelName, currentPackage)
+	if err == nil && !model.Assign.IsValid() {
+		return model, modelPackage, nil
+	}
+	if typedModel, typedPackage := parser.typedModelDefinition(modelName, currentPackage); typedModel != nil {
+		return typedModel, typedPackage, nil
+	}
+	return model, modelPackage, err
+}
+
+// lookupModelDefinition finds modelName, Model, package.Model or an absolute
+// github.com.acme.models.Model, in the type definitions of the parsed packages
+func (parser *Parser) lookupModelDefinition(modelName string, currentPackage string) (*ast.TypeSpec, string, error) {
 	var model *ast.TypeSpec
 	var modelPackage string
 
 	modelNameParts := strings.Split(modelName, ".")
 
-	//if no dot in name - it can be only model from current package
+	//if no dot in name - it can be only model from current package, or from a dot imported package
 	if len(modelNameParts) == 1 {
 		modelPackage = currentPackage
 		if model = parser.GetModelDefinition(modelName, currentPackage); model == nil {
-			log.Fatalf("Can not find definition of %s model. Current package %s", modelName, currentPackage)
+			for _, packageName := range parser.PackageImports[parser.CheckRealPackagePath(currentPackage)]["."] {
+				if model = parser.GetModelDefinition(modelName, packageName); model != nil {
+					return model, packageName, nil
+				}
+			}
+			return nil, "", fmt.Errorf("Can not find definition of %s model. Current package %s", modelName, currentPackage)
 		}
 	} else {
 		//first try to assume what name is absolute
@@ -367,15 +660,15 @@ func (parser *Parser) FindModelDefinition(modelName string, currentPackage strin
 
 			//can not get model by absolute name.
 			if len(modelNameParts) > 2 {
-				log.Fatalf("Can not find definition of %s model. Name looks like absolute, but model not found in %s package", modelNameFromPath, absolutePackageName)
+				return nil, "", fmt.Errorf("Can not find definition of %s model. Name looks like absolute, but model not found in %s package", modelNameFromPath, absolutePackageName)
 			}
 
 			// lets try to find it in imported packages
 			pkgRealPath := parser.CheckRealPackagePath(currentPackage)
 			if imports, ok := parser.PackageImports[pkgRealPath]; !ok {
-				log.Fatalf("Can not find definition of %s model. Package %s dont import anything", modelNameFromPath, pkgRealPath)
+				return nil, "", fmt.Errorf("Can not find definition of %s model. Package %s dont import anything", modelNameFromPath, pkgRealPath)
 			} else if relativePackage, ok := imports[modelNameParts[0]]; !ok {
-				log.Fatalf("Package %s is not imported to %s, Imported: %#v\n", modelNameParts[0], currentPackage, imports)
+				return nil, "", fmt.Errorf("Package %s is not imported to %s, Imported: %#v", modelNameParts[0], currentPackage, imports)
 			} else {
 				var modelFound bool
 
@@ -389,21 +682,52 @@ func (parser *Parser) FindModelDefinition(modelName string, currentPackage strin
 				}
 
 				if !modelFound {
-					log.Fatalf("Can not find definition of %s model in package %s", modelNameFromPath, relativePackage)
+					return nil, "", fmt.Errorf("Can not find definition of %s model in package %s", modelNameFromPath, relativePackage)
 				}
 			}
 		}
 	}
-	return model, modelPackage
+	return model, modelPackage, nil
+}
+
+// parseOperationComment parses an annotation of operation. Its error is a malformed annotation, aborted
+// an error which fails the parsing, like an unknown model: both are collected, and the next annotations parsed.
+func (parser *Parser) parseOperationComment(operation *Operation, comment string) (err error, aborted error) {
+	if err = operation.ParseComment(comment); isAborted(err) {
+		return nil, err
+	}
+	return err, nil
+}
+
+// parseAnnotation parses an annotation of operation, at pos in the function name. The errors are
+// collected, but the malformed annotations which are only skipped and logged if the parser is not strict.
+func (parser *Parser) parseAnnotation(operation *Operation, annotation string, pos token.Pos, name string) {
+	err, aborted := parser.parseOperationComment(operation, annotation)
+	if aborted != nil {
+		parser.addError(parser.FileSet.Position(pos), aborted)
+	} else if err != nil && parser.Strict {
+		parser.addError(parser.FileSet.Position(pos), err)
+	} else if err != nil {
+		logger.Warnf("Can not parse comment for function: %v, package: %v, got error: %v\n", name, operation.packageName, err)
+	}
+}
+
+// ParseApiDescription parses the operations of packageName and the sub api descriptions of its comments
+func (parser *Parser) ParseApiDescription(packageName string) (err error) {
+	defer recoverError(&err)
+	parser.parseApiDescription(packageName)
+	return nil
 }
 
-func (parser *Parser) ParseApiDescription(packageName string) {
+func (parser *Parser) parseApiDescription(packageName string) {
 	parser.CurrentPackage = packageName
-	pkgRealPath := parser.GetRealPackagePath(packageName)
+	pkgRealPath := parser.realPackagePath(packageName)
 
-	astPackages := parser.GetPackageAst(pkgRealPath)
-	for _, astPackage := range astPackages {
-		for _, astFile := range astPackage.Files {
+	astPackages := parser.packageAst(pkgRealPath)
+	internal := internalControllers(astPackages)
+	for _, astPackage := range sortedPackages(astPackages) {
+		for _, astFile := range sortedFiles(astPackage) {
+			logger.Debugf("Parsing annotations of %s", parser.FileSet.Position(astFile.Pos()).Filename)
 			for _, astDescription := range astFile.Decls {
 				switch astDeclaration := astDescription.(type) {
 				case *ast.FuncDecl:
@@ -411,13 +735,28 @@ func (parser *Parser) ParseApiDescription(packageName string) {
 						operation := NewOperation(parser, packageName)
 						if astDeclaration.Doc != nil && astDeclaration.Doc.List != nil {
 							for _, comment := range astDeclaration.Doc.List {
-								if err := operation.ParseComment(comment.Text); err != nil {
-									log.Printf("Can not parse comment for function: %v, package: %v, got error: %v\n", astDeclaration.Name.String(), packageName, err)
-								}
+								parser.parseAnnotation(operation, comment.Text, comment.Pos(), astDeclaration.Name.String())
 							}
 						}
-						if operation.Path != "" {
-							parser.AddOperation(operation)
+						// swaggo operations without @ID are named after their handler
+						if parser.Dialect == DialectSwaggo && operation.Nickname == "" {
+							operation.Nickname = astDeclaration.Name.String()
+						}
+						if operation.Ignored {
+							logger.Debugf("Ignoring operation %s", astDeclaration.Name.String())
+							continue
+						}
+						if internal[receiverTypeName(astDeclaration)] {
+							operation.Internal = true
+						}
+						if operation.Internal && !parser.IncludeInternal {
+							logger.Debugf("Skipping internal operation %s", astDeclaration.Name.String())
+							continue
+						}
+						for _, routeOperation := range parser.ApplyMuxRoutes(operation, astDeclaration) {
+							if routeOperation.Path != "" {
+								parser.AddOperation(routeOperation)
+							}
 						}
 					}
 				}
@@ -427,10 +766,15 @@ func (parser *Parser) ParseApiDescription(packageName string) {
 					parser.ParseSubApiDescription(commentLine)
 				}
 			}
+			if parser.Dialect == DialectGoSwagger {
+				parser.parseGoSwaggerRoutes(packageName, astFile)
+			}
 		}
 	}
 }
 
+var subApiCommentRegexp = regexp.MustCompile(`([^\[]+)\[{1}([\w\_\-/]+)`)
+
 // Parse sub api declaration
 // @SubApi Very fancy API [/fancy-api]
 func (parser *Parser) ParseSubApiDescription(commentLine string) {
@@ -439,10 +783,8 @@ func (parser *Parser) ParseSubApiDescription(commentLine string) {
 	} else {
 		commentLine = strings.TrimSpace(commentLine[len("@SubApi"):])
 	}
-	re := regexp.MustCompile(`([^\[]+)\[{1}([\w\_\-/]+)`)
-
-	if matches := re.FindStringSubmatch(commentLine); len(matches) != 3 {
-		log.Printf("Can not parse sub api description %s, skipped", commentLine)
+	if matches := subApiCommentRegexp.FindStringSubmatch(commentLine); len(matches) != 3 {
+		logger.Warnf("Can not parse sub api description %s, skipped", commentLine)
 	} else {
 		found := false
 		for _, ref := range parser.Listing.Apis {
@@ -1,14 +1,20 @@
 package parser_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"github.com/yvasiyarov/swagger/jsonpatch"
+	"github.com/yvasiyarov/swagger/output"
 	"github.com/yvasiyarov/swagger/parser"
 	"go/ast"
+	"io/ioutil"
 	//	"log"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -64,9 +70,16 @@ func (suite *ParserSuite) TestTopLevelAPI() {
 		assert.NotEmpty(suite.T(), topApi.SwaggerVersion, "Swagger version not filled")
 		assert.Equal(suite.T(), topApi.ResourcePath, "/testapi", "Resource path invalid")
 
-		expectedTypes := []string{parser.ContentTypeJson}
-		assert.Equal(suite.T(), topApi.Produces, expectedTypes, "Produced types not added correctly")
-		assert.Equal(suite.T(), topApi.Consumes, expectedTypes, "Consumed types not added correctly")
+		// the event stream adds its content type to the resource, not to its other operations
+		assert.Equal(suite.T(), []string{parser.ContentTypeEventStream}, topApi.Produces, "Produced types not added correctly")
+		for _, subApi := range topApi.Apis {
+			if subApi.Path != "/testapi/events" {
+				for _, op := range subApi.Operations {
+					assert.NotContains(suite.T(), op.Produces, parser.ContentTypeEventStream, "Operation %s produces the event stream", op.Nickname)
+				}
+			}
+		}
+		assert.Equal(suite.T(), topApi.Consumes, []string{parser.ContentTypeJson, parser.ContentTypeMultiPartFormData}, "Consumed types not added correctly")
 
 		suite.CheckSubApiList(topApi)
 		suite.CheckModelList(topApi)
@@ -74,7 +87,7 @@ func (suite *ParserSuite) TestTopLevelAPI() {
 }
 
 func (suite *ParserSuite) CheckSubApiList(topApi *parser.ApiDeclaration) {
-	assert.Len(suite.T(), topApi.Apis, 9, "Sub API was not parsed corectly")
+	assert.Len(suite.T(), topApi.Apis, 12, "Sub API was not parsed corectly")
 
 	for _, subApi := range topApi.Apis {
 		switch subApi.Path {
@@ -123,6 +136,21 @@ func (suite *ParserSuite) CheckSubApiList(topApi *parser.ApiDeclaration) {
 			assert.Len(suite.T(), subApi.Operations, 1, "Operations not parsed correctly")
 			suite.CheckGetStruct3(subApi.Operations[0])
 
+		case "/testapi/upload-attachment":
+			assert.Equal(suite.T(), subApi.Description, "upload an attachment with its metadata", "Description was not parsed properly")
+			assert.Len(suite.T(), subApi.Operations, 1, "Operations not parsed correctly")
+			suite.CheckUploadAttachment(subApi.Operations[0])
+
+		case "/testapi/chat":
+			assert.Equal(suite.T(), subApi.Description, "chat with the other clients", "Description was not parsed properly")
+			assert.Len(suite.T(), subApi.Operations, 1, "Operations not parsed correctly")
+			suite.CheckChatWebsocket(subApi.Operations[0])
+
+		case "/testapi/events":
+			assert.Equal(suite.T(), subApi.Description, "stream the events of the shop", "Description was not parsed properly")
+			assert.Len(suite.T(), subApi.Operations, 1, "Operations not parsed correctly")
+			suite.CheckStreamEvents(subApi.Operations[0])
+
 		default:
 			suite.T().Fatalf("Undefined sub API: %#v", subApi)
 		}
@@ -282,6 +310,55 @@ func (suite *ParserSuite) CheckGetStruct3(op *parser.Operation) {
 	assert.Len(suite.T(), op.Models, 2, "Models not parsed %#v", op.Models)
 }
 
+func (suite *ParserSuite) CheckUploadAttachment(op *parser.Operation) {
+	assert.Equal(suite.T(), "POST", op.HttpMethod, "Http method not parsed")
+	assert.Equal(suite.T(), "UploadAttachment", op.Nickname, "Nickname not parsed")
+	assert.Equal(suite.T(), "string", op.Type, "Type not parsed")
+
+	assert.Equal(suite.T(), op.Path, "/testapi/upload-attachment", "Resource path invalid")
+	assert.Equal(suite.T(), []string{parser.ContentTypeMultiPartFormData}, op.Consumes, "File params must consume multipart/form-data")
+
+	if assert.Len(suite.T(), op.Parameters, 3, "Params not parsed") {
+		assert.Equal(suite.T(), "File", op.Parameters[0].DataType, "Wrong data type of file part")
+		assert.Equal(suite.T(), "form", op.Parameters[0].ParamType, "Wrong param type of file part")
+		assert.Equal(suite.T(), "image/png, application/pdf", op.Parameters[0].ContentType, "Wrong content type of file part")
+		assert.Equal(suite.T(), "string", op.Parameters[1].DataType, "Wrong data type of field part")
+		assert.Equal(suite.T(), "", op.Parameters[1].ContentType, "Wrong content type of field part")
+		assert.False(suite.T(), op.Parameters[2].Required, "Optional field part is required")
+	}
+	assert.Len(suite.T(), op.ResponseMessages, 2, "Response message not parsed")
+}
+
+func (suite *ParserSuite) CheckChatWebsocket(op *parser.Operation) {
+	assert.Equal(suite.T(), "GET", op.HttpMethod, "Http method not parsed")
+	assert.Equal(suite.T(), "ChatWebsocket", op.Nickname, "Nickname not parsed")
+	assert.Equal(suite.T(), op.Path, "/testapi/chat", "Resource path invalid")
+
+	expected := &parser.Websocket{
+		Client: []parser.WebsocketMessage{{Model: "github.com.yvasiyarov.swagger.example.SimpleStructure", Description: "Message sent to the other clients"}},
+		Server: []parser.WebsocketMessage{{Model: "array[github.com.yvasiyarov.swagger.example.SimpleStructure]", Description: "Messages of the other clients"}},
+	}
+	assert.Equal(suite.T(), expected, op.Websocket, "Websocket messages not parsed")
+	if assert.Len(suite.T(), op.ResponseMessages, 1, "Response message not parsed") {
+		assert.Equal(suite.T(), 101, op.ResponseMessages[0].Code, "Switching protocols response not parsed")
+	}
+	assert.Len(suite.T(), op.Models, 1, "Models not parsed %#v", op.Models)
+}
+
+func (suite *ParserSuite) CheckStreamEvents(op *parser.Operation) {
+	assert.Equal(suite.T(), "GET", op.HttpMethod, "Http method not parsed")
+	assert.Equal(suite.T(), "StreamEvents", op.Nickname, "Nickname not parsed")
+	assert.Equal(suite.T(), op.Path, "/testapi/events", "Resource path invalid")
+	assert.Equal(suite.T(), []string{parser.ContentTypeEventStream}, op.Produces, "Events must produce text/event-stream")
+
+	expected := []parser.ServerSentEvent{
+		{Name: "order-created", Model: "int64", Description: "An order was created"},
+		{Name: "ratings", Model: "array[float64]", Description: "Ratings of the last minute"},
+	}
+	assert.Equal(suite.T(), expected, op.Events, "Events not parsed")
+	assert.Len(suite.T(), op.ResponseMessages, 1, "Response message not parsed")
+}
+
 func (suite *ParserSuite) CheckModelList(topApi *parser.ApiDeclaration) {
 	assert.Len(suite.T(), topApi.Models, 7, "Models was not parsed corectly")
 
@@ -308,6 +385,10 @@ func (suite *ParserSuite) CheckModelList(topApi *parser.ApiDeclaration) {
 		case "github.com.yvasiyarov.swagger.example.SimpleStructureWithAnnotations":
 			assert.Len(suite.T(), model.Properties, 2, "Model not parsed correctly")
 
+		// the messages of the chat WebSocket
+		case "github.com.yvasiyarov.swagger.example.SimpleStructure":
+			assert.Len(suite.T(), model.Properties, 2, "Model not parsed correctly")
+
 		default:
 			suite.T().Errorf("Model %#v", model)
 		}
@@ -333,6 +414,841 @@ func (suite *ParserSuite) TestAPIListing() {
 
 }
 
+func (suite *ParserSuite) TestInfoLines() {
+	info := parser.Infomation{
+		Description:       "Example API",
+		Contact:           "api@example.com",
+		ContactName:       "API team",
+		TermsOfServiceUrl: "http://example.com/terms",
+		License:           "BSD",
+		LicenseUrl:        "http://example.com/license",
+	}
+	assert.Equal(suite.T(), []string{
+		"Contact: API team <api@example.com>",
+		"Terms of service: http://example.com/terms",
+		"License: BSD (http://example.com/license)",
+	}, info.Lines(), "Info lines are wrong")
+	assert.Equal(suite.T(), "Example API\n\nContact: API team <api@example.com>\n\nTerms of service: http://example.com/terms\n\nLicense: BSD (http://example.com/license)", info.FullDescription(), "Full description is wrong")
+	assert.Empty(suite.T(), parser.Infomation{}.FullDescription(), "Empty info has a description")
+}
+
+func (suite *ParserSuite) TestGeneralApiInfoEnv() {
+	mainFile, err := ioutil.TempFile("", "swagger_main")
+	if err != nil {
+		suite.T().Fatalf("Can not create main API file: %v\n", err)
+	}
+	defer os.Remove(mainFile.Name())
+	mainFile.WriteString("// @APIVersion ${SWAGGER_TEST_VERSION}\n// @Host ${SWAGGER_TEST_HOST}:8080\n// @BasePath /api\npackage main\n")
+	mainFile.Close()
+
+	os.Setenv("SWAGGER_TEST_VERSION", "2.1.0")
+	os.Setenv("SWAGGER_TEST_HOST", "staging.example.com")
+	defer os.Unsetenv("SWAGGER_TEST_VERSION")
+	defer os.Unsetenv("SWAGGER_TEST_HOST")
+
+	envParser := parser.NewParser()
+	envParser.ParseGeneralApiInfo(mainFile.Name())
+	assert.Equal(suite.T(), "2.1.0", envParser.Listing.ApiVersion, "Version is not expanded")
+	assert.Equal(suite.T(), "staging.example.com:8080", envParser.Host, "Host is not expanded")
+	assert.Equal(suite.T(), "/api", envParser.BasePath, "Base path is changed")
+}
+
+func (suite *ParserSuite) TestExtensions() {
+	mainFile, err := ioutil.TempFile("", "swagger_main")
+	if err != nil {
+		suite.T().Fatalf("Can not create main API file: %v\n", err)
+	}
+	defer os.Remove(mainFile.Name())
+	mainFile.WriteString("// @APIVersion 1.0\n// @Extension x-logo {\"url\": \"logo.png\"}\npackage main\n")
+	mainFile.Close()
+
+	extensionParser := parser.NewParser()
+	assert.Nil(suite.T(), extensionParser.ParseGeneralApiInfo(mainFile.Name()), "Can not parse general extension")
+	listing, _ := json.Marshal(extensionParser.Listing)
+	assert.Contains(suite.T(), string(listing), `,"x-logo":{"url":"logo.png"}}`, "General extension is not in the resource listing")
+
+	op := parser.NewOperation(extensionParser, "test")
+	assert.Nil(suite.T(), op.ParseComment(`// @Extension x-amazon-apigateway-integration {"type": "http_proxy"}`), "Can not parse extension")
+	assert.NotNil(suite.T(), op.ParseComment(`// @Extension amazon {}`), "Extension without x- accepted")
+	assert.NotNil(suite.T(), op.ParseComment(`// @Extension x-amazon {"type": }`), "Extension with invalid JSON accepted")
+	serialised, _ := json.Marshal(op)
+	var read parser.Operation
+	if assert.Nil(suite.T(), json.Unmarshal(serialised, &read), "Can not read operation with extension") {
+		assert.Equal(suite.T(), parser.Extensions{"x-amazon-apigateway-integration": json.RawMessage(`{"type":"http_proxy"}`)}, read.Extensions, "Extension is not serialised")
+	}
+}
+
+func (suite *ParserSuite) TestServers() {
+	mainFile, err := ioutil.TempFile("", "swagger_main")
+	if err != nil {
+		suite.T().Fatalf("Can not create main API file: %v\n", err)
+	}
+	defer os.Remove(mainFile.Name())
+	mainFile.WriteString(`// @APIVersion 1.0
+// @Server https://{region}.api.example.com/{version} Production
+// @ServerVariable region eu|us "Region of the datacenter"
+// @ServerVariable version v1
+// @Server http://localhost:8080
+package main
+`)
+	mainFile.Close()
+
+	serverParser := parser.NewParser()
+	assert.Nil(suite.T(), serverParser.ParseGeneralApiInfo(mainFile.Name()), "Can not parse servers")
+	expected := []parser.Server{
+		{
+			Url:         "https://{region}.api.example.com/{version}",
+			Description: "Production",
+			Variables: map[string]parser.ServerVariable{
+				"region":  {Default: "eu", Enum: []string{"eu", "us"}, Description: "Region of the datacenter"},
+				"version": {Default: "v1"},
+			},
+		},
+		{Url: "http://localhost:8080"},
+	}
+	assert.Equal(suite.T(), expected, serverParser.Listing.Servers, "Can not parse servers")
+
+	listing, _ := json.Marshal(serverParser.Listing)
+	var read parser.ResourceListing
+	if assert.Nil(suite.T(), json.Unmarshal(listing, &read), "Can not read listing with servers") {
+		assert.Equal(suite.T(), expected, read.Servers, "Servers are not serialised as x-servers")
+		assert.Nil(suite.T(), read.Extensions, "x-servers must not be read as an extension")
+	}
+
+	undeclared, _ := ioutil.TempFile("", "swagger_main")
+	defer os.Remove(undeclared.Name())
+	undeclared.WriteString("// @Server https://{region}.api.example.com\npackage main\n")
+	undeclared.Close()
+	assert.NotNil(suite.T(), parser.NewParser().ParseGeneralApiInfo(undeclared.Name()), "Server variable without @ServerVariable accepted")
+}
+
+func (suite *ParserSuite) TestFindInGopath() {
+	emptyGopath, err := ioutil.TempDir("", "swagger_gopath")
+	if err != nil {
+		suite.T().Fatalf("Can not create GOPATH directory: %v\n", err)
+	}
+	defer os.RemoveAll(emptyGopath)
+	gopath, err := ioutil.TempDir("", "swagger_gopath")
+	if err != nil {
+		suite.T().Fatalf("Can not create GOPATH directory: %v\n", err)
+	}
+	defer os.RemoveAll(gopath)
+	mainFile := filepath.Join(gopath, "src", "example.com", "api", "main.go")
+	os.MkdirAll(filepath.Dir(mainFile), 0777)
+	ioutil.WriteFile(mainFile, []byte("package main\n"), 0666)
+
+	defer os.Setenv("GOPATH", os.Getenv("GOPATH"))
+	os.Setenv("GOPATH", emptyGopath+string(os.PathListSeparator)+gopath)
+	assert.Equal(suite.T(), mainFile, parser.FindInGopath("example.com/api/main.go"), "File of the second GOPATH directory is not found")
+	assert.Equal(suite.T(), filepath.Dir(mainFile), parser.FindInGopath("example.com/api"), "Directory is not found")
+	assert.Equal(suite.T(), "", parser.FindInGopath("example.com/other"), "Missing file is found")
+}
+
+func (suite *ParserSuite) TestScanPackagesModule() {
+	module, err := ioutil.TempDir("", "swagger_module")
+	if err != nil {
+		suite.T().Fatalf("Can not create module directory: %v\n", err)
+	}
+	defer os.RemoveAll(module)
+	files := map[string]string{
+		"go.mod": "module example.com/shop\n",
+		"handlers/users.go": `package handlers
+
+type Context struct{}
+
+// @Title getUser
+// @Success 200 {string} string
+// @Router /users/{id} [get]
+func (c *Context) GetUser() {}
+`,
+		"handlers/admin/admin.go": `package admin
+
+type Context struct{}
+
+// @Title getStats
+// @Success 200 {string} string
+// @Router /admin/stats [get]
+func (c *Context) GetStats() {}
+`,
+	}
+	for name, content := range files {
+		suite.writeIncrementalFile(filepath.Join(module, filepath.FromSlash(name)), content)
+	}
+
+	// the module is out of $GOPATH, its packages are only found by the go command in module mode
+	for name, value := range map[string]string{"GO111MODULE": "on", "GOFLAGS": "-mod=mod", "GOPROXY": "off", "GOWORK": "off"} {
+		defer os.Setenv(name, os.Getenv(name))
+		os.Setenv(name, value)
+	}
+	workingDir, _ := os.Getwd()
+	defer os.Chdir(workingDir)
+	os.Chdir(module)
+
+	moduleParser := parser.NewParser()
+	moduleParser.IsController = IsController
+	assert.Contains(suite.T(), moduleParser.ScanPackages([]string{"example.com/shop/handlers"}), "example.com/shop/handlers/admin", "Sub-package of the module is not found")
+	if assert.Nil(suite.T(), moduleParser.ParseApi("example.com/shop/handlers"), "Can not parse the module") {
+		assert.Contains(suite.T(), moduleParser.TopLevelApis, "users", "Operation of the package is not parsed")
+		assert.Contains(suite.T(), moduleParser.TopLevelApis, "admin", "Operation of the sub-package is not parsed")
+	}
+}
+
+func (suite *ParserSuite) TestSubPackagePath() {
+	root := filepath.Join("src", "shop", "handlers")
+	assert.Equal(suite.T(), "example.com/shop/handlers/admin/v2", parser.SubPackagePath("example.com/shop/handlers", root, filepath.Join(root, "admin", "v2")), "Wrong import path of a sub-package")
+	assert.Equal(suite.T(), "example.com/shop/handlers", parser.SubPackagePath("example.com/shop/handlers", root, root), "Wrong import path of the package")
+	if filepath.Separator == '\\' {
+		assert.Equal(suite.T(), "example.com/shop/handlers/admin", parser.SubPackagePath("example.com/shop/handlers", `C:\src\shop\handlers`, `C:\src\shop\handlers\admin`), "Wrong import path of a Windows directory")
+	}
+}
+
+func (suite *ParserSuite) TestTypeCheck() {
+	typed, err := parser.NewParser().TypeCheck("github.com/yvasiyarov/swagger/example")
+	if assert.Nil(suite.T(), err, "Can not type check the example package") {
+		assert.Equal(suite.T(), "example", typed.Types.Name(), "Package name is wrong")
+		assert.NotEmpty(suite.T(), typed.Files, "Files are not parsed")
+		if object := typed.Types.Scope().Lookup("SimpleStructure"); assert.NotNil(suite.T(), object, "Type is not resolved") {
+			assert.Equal(suite.T(), "github.com/yvasiyarov/swagger/example.SimpleStructure", object.Type().String(), "Type is wrong")
+		}
+	}
+
+	_, err = parser.NewParser().TypeCheck("github.com/yvasiyarov/swagger/missing")
+	assert.NotNil(suite.T(), err, "Missing package is type checked")
+}
+
+func (suite *ParserSuite) TestTypedModelDefinition() {
+	// nothing is parsed, the imports of the package are only known to go/types
+	typedParser := parser.NewParser()
+	model, modelPackage, err := typedParser.LookupModelDefinition("subpackage.SimpleStructure", "github.com/yvasiyarov/swagger/example")
+	if assert.Nil(suite.T(), err, "Model of an imported package is not resolved") {
+		assert.Equal(suite.T(), "SimpleStructure", model.Name.Name, "Model is wrong")
+		assert.Equal(suite.T(), "github.com/yvasiyarov/swagger/example/subpackage", modelPackage, "Model package is wrong")
+	}
+	_, _, err = typedParser.LookupModelDefinition("subpackage.Missing", "github.com/yvasiyarov/swagger/example")
+	assert.NotNil(suite.T(), err, "Missing model is resolved")
+}
+
+func (suite *ParserSuite) TestImportAliases() {
+	controllersPackage := "github.com/yvasiyarov/swagger/parser/testdata/imports/controllers"
+	modelsPackage := "github.com/yvasiyarov/swagger/parser/testdata/imports/models"
+	aliasParser := parser.NewParser()
+	aliasParser.ParseTypeDefinitions(controllersPackage)
+
+	for _, modelName := range []string{"m.User", "Order", "models.Order"} {
+		model, modelPackage, err := aliasParser.LookupModelDefinition(modelName, controllersPackage)
+		if assert.Nil(suite.T(), err, "Can not find %s", modelName) {
+			assert.Equal(suite.T(), modelsPackage, modelPackage, "Package of %s is wrong", modelName)
+			assert.NotNil(suite.T(), model, "Model %s is not found", modelName)
+		}
+	}
+
+	model := parser.NewModel(aliasParser)
+	err, models := model.ParseModel("Profile", controllersPackage, map[string]bool{})
+	if assert.Nil(suite.T(), err, "Can not parse a model with aliased and dot imported fields") {
+		assert.Len(suite.T(), models, 2, "Models of the aliased and dot imported fields are not parsed")
+	}
+}
+
+func (suite *ParserSuite) TestBuildTags() {
+	types := func(buildTags []string, includeTests bool) []string {
+		tagsParser := parser.NewParser()
+		tagsParser.BuildTags, tagsParser.IncludeTests = buildTags, includeTests
+		tagsParser.ParseTypeDefinitions("github.com/yvasiyarov/swagger/parser/testdata/tags")
+		var names []string
+		for _, definitions := range tagsParser.TypeDefinitions {
+			for name := range definitions {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	assert.Equal(suite.T(), []string{"User"}, types(nil, false), "Files of other builds or tests are parsed")
+	assert.Equal(suite.T(), []string{"Premium", "User"}, types([]string{"premium"}, false), "Files of the build tags are not parsed")
+	assert.Equal(suite.T(), []string{"Fake", "Premium", "User"}, types([]string{"premium"}, true), "Test files are not parsed")
+}
+
+func (suite *ParserSuite) TestConcurrentParsers() {
+	gopath := os.Getenv("GOPATH")
+	results := make(chan string, 2)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			concurrentParser := parser.NewParser()
+			concurrentParser.BasePath = exampleBasePath
+			concurrentParser.IsController = IsController
+			if err := concurrentParser.ParseGeneralApiInfo(path.Join(gopath, "src", "github.com/yvasiyarov/swagger/example/web/main.go")); err != nil {
+				results <- err.Error()
+				return
+			}
+			if err := concurrentParser.ParseApi("github.com/yvasiyarov/swagger/example"); err != nil {
+				results <- err.Error()
+				return
+			}
+			results <- string(concurrentParser.GetApiDescriptionJson())
+		}()
+	}
+
+	expected := string(suite.parser.GetApiDescriptionJson())
+	for i := 0; i < cap(results); i++ {
+		assert.Equal(suite.T(), expected, <-results, "Parsers running concurrently give another spec")
+	}
+}
+
+func (suite *ParserSuite) TestParseErrors() {
+	errorParser := parser.NewParser()
+	assert.NotNil(suite.T(), errorParser.ParseGeneralApiInfo("missing/main.go"), "Missing main API file is not an error")
+	assert.NotNil(suite.T(), errorParser.ParseApi("github.com/yvasiyarov/swagger/missing"), "Missing package is not an error")
+}
+
+func (suite *ParserSuite) TestAnnotationErrors() {
+	errorsParser := parser.NewParser()
+	errorsParser.IsController = IsController
+	errorsParser.Strict = true
+	err := errorsParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/errors")
+
+	errorList, ok := err.(parser.ErrorList)
+	if assert.True(suite.T(), ok, "Annotation errors are not collected: %v", err) && assert.Len(suite.T(), errorList, 2, "Annotation errors are missing") {
+		assert.Equal(suite.T(), 10, errorList[0].Pos.Line, "Malformed @Param is not reported")
+		assert.Equal(suite.T(), 16, errorList[1].Pos.Line, "Unknown model is not reported")
+		assert.Contains(suite.T(), err.Error(), "testdata/errors/api.go:\n    10:", "Errors are not grouped by file")
+	}
+	assert.NotNil(suite.T(), errorsParser.TopLevelApis["users"], "Operations without errors are not parsed")
+}
+
+func (suite *ParserSuite) TestCoverage() {
+	coverageParser := parser.NewParser()
+	coverageParser.IsController = IsController
+	packageName := "github.com/yvasiyarov/swagger/parser/testdata/coverage"
+	if !assert.Nil(suite.T(), coverageParser.ParseApi(packageName), "Can not parse the coverage package") {
+		return
+	}
+
+	coverage, err := coverageParser.Coverage(packageName)
+	if !assert.Nil(suite.T(), err, "Can not compute the coverage") {
+		return
+	}
+	var undocumented []string
+	for _, item := range coverage {
+		if !item.Documented {
+			undocumented = append(undocumented, item.Kind+" "+item.Name)
+		}
+	}
+	assert.Equal(suite.T(), []string{
+		"model github.com.yvasiyarov.swagger.parser.testdata.coverage.Group",
+		"operation Context.DeleteUser",
+		"operation Health",
+	}, undocumented, "Undocumented handlers and models are wrong")
+
+	documented, total := coverage.Count("operation")
+	assert.Equal(suite.T(), []int{2, 4}, []int{documented, total}, "Operation coverage is wrong")
+	assert.Equal(suite.T(), 50.0, coverage.Percent(""), "Total coverage is wrong")
+}
+
+func (suite *ParserSuite) TestApplyOverlay() {
+	overlayParser := parser.NewParser()
+	overlayParser.IsController = IsController
+	if !assert.Nil(suite.T(), overlayParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/coverage"), "Can not parse the coverage package") {
+		return
+	}
+	err := overlayParser.ApplyOverlay(map[string]interface{}{
+		"info": map[string]interface{}{"title": "Overlay API"},
+		"apis": map[string]interface{}{
+			"users": map[string]interface{}{
+				"apis": []interface{}{
+					map[string]interface{}{"path": "/users/{id}", "operations": []interface{}{
+						map[string]interface{}{"httpMethod": "GET", "responseMessages": []interface{}{
+							map[string]interface{}{"code": 404, "message": "Not found"},
+						}},
+						map[string]interface{}{"httpMethod": "DELETE", "nickname": "deleteUser", "type": "void"},
+					}},
+				},
+			},
+			"health": map[string]interface{}{
+				"apis": []interface{}{
+					map[string]interface{}{"path": "/health", "operations": []interface{}{
+						map[string]interface{}{"httpMethod": "GET", "nickname": "health", "type": "void"},
+					}},
+				},
+			},
+		},
+	})
+	if !assert.Nil(suite.T(), err, "Can not apply the overlay") {
+		return
+	}
+
+	assert.Equal(suite.T(), "Overlay API", overlayParser.Listing.Infos.Title, "Info is not merged")
+	users := overlayParser.TopLevelApis["users"]
+	if assert.Len(suite.T(), users.Apis, 1, "Paths are not merged") && assert.Len(suite.T(), users.Apis[0].Operations, 2, "Operations are not merged") {
+		get := users.Apis[0].Operations[0]
+		assert.Equal(suite.T(), "getUser", get.Nickname, "Generated operation is changed")
+		assert.Len(suite.T(), get.Models, 1, "Fields which are not serialised are lost")
+		assert.Equal(suite.T(), 404, get.ResponseMessages[len(get.ResponseMessages)-1].Code, "Response is not added")
+		assert.Equal(suite.T(), "deleteUser", users.Apis[0].Operations[1].Nickname, "Operation is not added")
+	}
+	assert.NotNil(suite.T(), overlayParser.TopLevelApis["health"], "Resource is not added")
+	assert.Error(suite.T(), overlayParser.ApplyOverlay(map[string]interface{}{"paths": nil}), "Unknown overlay key is not an error")
+}
+
+func (suite *ParserSuite) TestApplyPatch() {
+	patchParser := parser.NewParser()
+	patchParser.IsController = IsController
+	if !assert.Nil(suite.T(), patchParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/coverage"), "Can not parse the coverage package") {
+		return
+	}
+	userId := "github.com.yvasiyarov.swagger.parser.testdata.coverage.User"
+	err := patchParser.ApplyPatch([]jsonpatch.Operation{
+		{Op: "add", Path: "/listing/info/title", Value: "Patched API"},
+		{Op: "add", Path: "/apis/users/apis/0/operations/0/x-internal", Value: true},
+		{Op: "move", From: "/apis/users/models/" + userId, Path: "/apis/users/models/User"},
+		{Op: "replace", Path: "/apis/users/models/User/id", Value: "User"},
+		{Op: "replace", Path: "/apis/users/apis/0/operations/0/type", Value: "User"},
+		{Op: "remove", Path: "/apis/groups"},
+		{Op: "remove", Path: "/listing/apis/0"},
+	})
+	if !assert.Nil(suite.T(), err, "Can not apply the patch") {
+		return
+	}
+
+	assert.Equal(suite.T(), "Patched API", patchParser.Listing.Infos.Title, "Info is not patched")
+	assert.Nil(suite.T(), patchParser.TopLevelApis["groups"], "Resource is not removed")
+	assert.Len(suite.T(), patchParser.Listing.Apis, 1, "Resource is not removed from the listing")
+	users := patchParser.TopLevelApis["users"]
+	if assert.NotNil(suite.T(), users, "Resource is lost") {
+		get := users.Apis[0].Operations[0]
+		assert.Equal(suite.T(), "User", get.Type, "Operation is not patched")
+		assert.Equal(suite.T(), parser.Extensions{"x-internal": json.RawMessage("true")}, get.Extensions, "Extension is not added")
+		assert.Len(suite.T(), get.Models, 1, "Fields which are not serialised are lost")
+	}
+	assert.NotNil(suite.T(), patchParser.Models["User"], "Model is not renamed")
+	assert.Nil(suite.T(), patchParser.Models[userId], "Model is not renamed")
+
+	err = patchParser.ApplyPatch([]jsonpatch.Operation{{Op: "test", Path: "/listing/info/title", Value: "Generated API"}})
+	assert.Error(suite.T(), err, "Failed test is not an error")
+	assert.Equal(suite.T(), "Patched API", patchParser.Listing.Infos.Title, "Failed patch is applied")
+}
+
+func (suite *ParserSuite) TestAddStandardHeaders() {
+	headersParser := parser.NewParser()
+	headersParser.IsController = IsController
+	if !assert.Nil(suite.T(), headersParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/coverage"), "Can not parse the coverage package") {
+		return
+	}
+	err := headersParser.AddStandardHeaders([]parser.StandardHeader{
+		{Name: "X-RateLimit-Limit", Type: "integer", Description: "Requests allowed per hour"},
+		{Name: "Link", Description: "Pagination links", Tags: []string{"groups"}},
+		{Name: "Retry-After", Type: "integer", Codes: []int{429}},
+	})
+	if !assert.Nil(suite.T(), err, "Can not add standard headers") {
+		return
+	}
+
+	users := headersParser.TopLevelApis["users"].Apis[0].Operations[0].ResponseMessages[0]
+	assert.Equal(suite.T(), map[string]parser.ResponseHeader{
+		"X-RateLimit-Limit": {Type: "integer", Description: "Requests allowed per hour"},
+	}, users.Headers, "Headers of all operations are not added")
+	groups := headersParser.TopLevelApis["groups"].Apis[0].Operations[0].ResponseMessages[0]
+	assert.Equal(suite.T(), map[string]parser.ResponseHeader{
+		"X-RateLimit-Limit": {Type: "integer", Description: "Requests allowed per hour"},
+		"Link":              {Type: "string", Description: "Pagination links"},
+	}, groups.Headers, "Headers of tagged operations are not added")
+
+	err = headersParser.AddStandardHeaders([]parser.StandardHeader{{Name: "X-Total-Count", Type: "int"}})
+	assert.Error(suite.T(), err, "Invalid header type is not an error")
+}
+
+func (suite *ParserSuite) TestParseApiIncremental() {
+	cacheDir, err := ioutil.TempDir("", "swagger_incremental")
+	if err != nil {
+		suite.T().Fatalf("Can not create cache directory: %v\n", err)
+	}
+	defer os.RemoveAll(cacheDir)
+
+	fullParser := parser.NewParser()
+	fullParser.IsController = IsController
+	if !assert.Nil(suite.T(), fullParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/coverage"), "Can not parse the coverage package") {
+		return
+	}
+	expected, _ := json.Marshal(fullParser.TopLevelApis)
+
+	// the first run parses and caches the packages, the second one reads them from the cache
+	for run := 1; run <= 2; run++ {
+		incrementalParser := parser.NewParser()
+		incrementalParser.IsController = IsController
+		if !assert.Nil(suite.T(), incrementalParser.ParseApiIncremental("github.com/yvasiyarov/swagger/parser/testdata/coverage", cacheDir, "key"), "Can not parse incrementally") {
+			return
+		}
+		actual, _ := json.Marshal(incrementalParser.TopLevelApis)
+		assert.JSONEq(suite.T(), string(expected), string(actual), "Incremental parsing of run %d differs", run)
+		assert.Equal(suite.T(), fullParser.Listing.Apis, incrementalParser.Listing.Apis, "Resources of run %d differ", run)
+		if run == 2 {
+			assert.Empty(suite.T(), incrementalParser.PackageTimings, "Unchanged package is parsed again")
+		}
+	}
+	_, err = os.Stat(filepath.Join(cacheDir, "manifest.json"))
+	assert.Nil(suite.T(), err, "Manifest is not written")
+}
+
+// incrementalGopath writes an API package and the package of its models to the src directory of a
+// temporary GOPATH, which is added to $GOPATH. The returned function restores $GOPATH.
+func (suite *ParserSuite) incrementalGopath(root string) func() {
+	files := map[string]string{
+		"incremental/api/api.go": `package api
+
+type Context struct{}
+
+// @Title getUser
+// @Success 200 {object} models.User
+// @Router /users/{id} [get]
+func (c *Context) GetUser() {}
+`,
+		"incremental/api/imports.go": `package api
+
+import _ "incremental/models"
+`,
+	}
+	for name, content := range files {
+		suite.writeIncrementalFile(filepath.Join(root, "src", name), content)
+	}
+	suite.writeIncrementalModels(root, "Name string `json:\"name\"`")
+
+	gopath := os.Getenv("GOPATH")
+	os.Setenv("GOPATH", root+string(filepath.ListSeparator)+gopath)
+	return func() { os.Setenv("GOPATH", gopath) }
+}
+
+func (suite *ParserSuite) writeIncrementalModels(root string, fields string) {
+	suite.writeIncrementalFile(filepath.Join(root, "src", "incremental", "models", "models.go"), "package models\n\ntype User struct {\n\t"+fields+"\n}\n")
+}
+
+func (suite *ParserSuite) writeIncrementalFile(name string, content string) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		suite.T().Fatalf("Can not create package directory: %v\n", err)
+	}
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		suite.T().Fatalf("Can not write package file: %v\n", err)
+	}
+}
+
+// parseIncremental parses the incremental/api package with the cache of cacheDir, and returns the
+// parsed packages which were not read from the cache
+func (suite *ParserSuite) parseIncremental(cacheDir string, key string) (*parser.Parser, []string) {
+	incrementalParser := parser.NewParser()
+	incrementalParser.IsController = IsController
+	if err := incrementalParser.ParseApiIncremental("incremental/api", cacheDir, key); err != nil {
+		suite.T().Fatalf("Can not parse incrementally: %v\n", err)
+	}
+	var parsed []string
+	for _, timing := range incrementalParser.PackageTimings {
+		if len(parsed) == 0 || parsed[len(parsed)-1] != timing.Package {
+			parsed = append(parsed, timing.Package)
+		}
+	}
+	return incrementalParser, parsed
+}
+
+// incrementalUser is the User model of the incremental/api package
+func incrementalUser(incrementalParser *parser.Parser) *parser.Model {
+	for _, api := range incrementalParser.TopLevelApis {
+		for id, model := range api.Models {
+			if strings.HasSuffix(id, "User") {
+				return model
+			}
+		}
+	}
+	return nil
+}
+
+func (suite *ParserSuite) TestParseApiIncrementalInvalidation() {
+	root, err := ioutil.TempDir("", "swagger_incremental")
+	if err != nil {
+		suite.T().Fatalf("Can not create GOPATH directory: %v\n", err)
+	}
+	defer os.RemoveAll(root)
+	defer suite.incrementalGopath(root)()
+	cacheDir := filepath.Join(root, "cache")
+
+	_, parsed := suite.parseIncremental(cacheDir, "key")
+	assert.Equal(suite.T(), []string{"incremental/api"}, parsed, "Package is not parsed on the first run")
+
+	incrementalParser, parsed := suite.parseIncremental(cacheDir, "key")
+	assert.Empty(suite.T(), parsed, "Unchanged package is not read from the cache")
+	if model := incrementalUser(incrementalParser); assert.NotNil(suite.T(), model, "Model is not read from the cache") {
+		assert.Len(suite.T(), model.Properties, 1, "Cached model differs")
+	}
+
+	// the API package is parsed again when the package of its models changes
+	suite.writeIncrementalModels(root, "Name string `json:\"name\"`\n\tEmail string `json:\"email\"`")
+	incrementalParser, parsed = suite.parseIncremental(cacheDir, "key")
+	assert.Equal(suite.T(), []string{"incremental/api"}, parsed, "Change of the package of a model does not invalidate the cache")
+	if model := incrementalUser(incrementalParser); assert.NotNil(suite.T(), model, "Model is not parsed again") {
+		assert.Contains(suite.T(), model.Properties, "email", "Changed model is not parsed again")
+	}
+
+	_, parsed = suite.parseIncremental(cacheDir, "other key")
+	assert.Equal(suite.T(), []string{"incremental/api"}, parsed, "Change of the key does not drop the cache")
+	_, parsed = suite.parseIncremental(cacheDir, "other key")
+	assert.Empty(suite.T(), parsed, "Cache of the new key is not written")
+}
+
+func (suite *ParserSuite) TestParseApiIncrementalDryRun() {
+	root, err := ioutil.TempDir("", "swagger_incremental")
+	if err != nil {
+		suite.T().Fatalf("Can not create GOPATH directory: %v\n", err)
+	}
+	defer os.RemoveAll(root)
+	defer suite.incrementalGopath(root)()
+	cacheDir := filepath.Join(root, "cache")
+
+	memory := output.NewMemory()
+	output.Current = memory
+	defer func() { output.Current = output.Disk{} }()
+	suite.parseIncremental(cacheDir, "key")
+
+	_, err = os.Stat(cacheDir)
+	assert.True(suite.T(), os.IsNotExist(err), "Cache is written to the disk with -dry-run")
+	_, ok := memory.Content(filepath.Join(cacheDir, "manifest.json"))
+	assert.True(suite.T(), ok, "Manifest is not written to the output")
+}
+
+func (suite *ParserSuite) TestSwaggoDialect() {
+	swaggoParser := parser.NewParser()
+	swaggoParser.IsController = IsController
+	swaggoParser.Dialect = parser.DialectSwaggo
+	swaggoParser.Strict = true
+	if !assert.Nil(suite.T(), swaggoParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/swaggo"), "Can not parse swaggo annotations") {
+		return
+	}
+
+	accounts := swaggoParser.TopLevelApis["accounts"]
+	if !assert.NotNil(suite.T(), accounts, "@Tags is not the resource") || !assert.Len(suite.T(), accounts.Apis, 2, "Operations are missing") {
+		return
+	}
+	list, show := accounts.Apis[0].Operations[0], accounts.Apis[1].Operations[0]
+	assert.Equal(suite.T(), "listUsers", list.Nickname, "@ID is not the nickname")
+	assert.Equal(suite.T(), "array[github.com.yvasiyarov.swagger.parser.testdata.swaggo.User]", list.Type, "[]User is not an array")
+
+	assert.Equal(suite.T(), "ShowUser", show.Nickname, "Nickname is not the handler name")
+	assert.Equal(suite.T(), "Show a user", show.Summary, "@Summary is not the summary")
+	assert.Equal(suite.T(), "get a user by ID", show.Notes, "@Description is not the notes")
+	if assert.Len(suite.T(), show.Parameters, 2, "Params are missing") {
+		assert.Equal(suite.T(), "int", show.Parameters[0].DataType, "integer is not an int")
+		assert.Equal(suite.T(), 1.0, show.Parameters[0].Minimum, "minimum() is lost")
+		assert.True(suite.T(), show.Parameters[1].AllowMultiple, "[]string is not allowMultiple")
+		assert.Equal(suite.T(), `"Other IDs"`, show.Parameters[1].Description, "Enums() is not removed")
+	}
+	assert.Len(suite.T(), show.ResponseMessages, 2, "Responses are missing")
+}
+
+func (suite *ParserSuite) TestGoSwaggerDialect() {
+	goSwaggerParser := parser.NewParser()
+	goSwaggerParser.IsController = IsController
+	goSwaggerParser.Dialect = parser.DialectGoSwagger
+	goSwaggerParser.Strict = true
+	if !assert.Nil(suite.T(), goSwaggerParser.ParseApi("github.com/yvasiyarov/swagger/parser/testdata/goswagger"), "Can not parse go-swagger annotations") {
+		return
+	}
+
+	pets := goSwaggerParser.TopLevelApis["pets"]
+	if !assert.NotNil(suite.T(), pets, "Routes are missing") || !assert.Len(suite.T(), pets.Apis, 2, "Operations are missing") {
+		return
+	}
+	find, get := pets.Apis[0].Operations[0], pets.Apis[1].Operations[0]
+	assert.Equal(suite.T(), "array[github.com.yvasiyarov.swagger.parser.testdata.goswagger.Pet]", find.Type, "body:[]Pet is not an array")
+	if assert.Len(suite.T(), find.Parameters, 1, "swagger:parameters are missing") {
+		assert.Equal(suite.T(), "query", find.Parameters[0].ParamType, "Params are not in query by default")
+		assert.True(suite.T(), find.Parameters[0].AllowMultiple, "[]string is not allowMultiple")
+	}
+
+	assert.Equal(suite.T(), "getPet", get.Nickname, "Operation id is not the nickname")
+	assert.Equal(suite.T(), "Get a pet.", get.Summary, "First paragraph is not the summary")
+	assert.Equal(suite.T(), "Returns the pet with the ID, if there is one.", get.Notes, "Next paragraphs are not the notes")
+	assert.Equal(suite.T(), []string{parser.ContentTypeJson}, get.Produces, "Produces section is lost")
+	if assert.Len(suite.T(), get.Parameters, 1, "swagger:parameters are missing") {
+		assert.Equal(suite.T(), parser.Parameter{ParamType: "path", Name: "id", Description: `"The ID of the pet"`, DataType: "int64", Type: "int64", Required: true}, get.Parameters[0], "Path param is wrong")
+	}
+	if assert.Len(suite.T(), get.ResponseMessages, 2, "Responses are missing") {
+		assert.Equal(suite.T(), "A pet", get.ResponseMessages[0].Message, "swagger:response description is lost")
+		assert.Equal(suite.T(), 404, get.ResponseMessages[1].Code, "body: response is lost")
+	}
+	if pet := pets.Models["github.com.yvasiyarov.swagger.parser.testdata.goswagger.Pet"]; assert.NotNil(suite.T(), pet, "Model is missing") {
+		assert.Equal(suite.T(), "Pet is a pet of the store", pet.Description, "swagger:model is in the description")
+	}
+}
+
+func (suite *ParserSuite) TestComposedType() {
+	defer func(currentPackage string) {
+		suite.parser.CurrentPackage = currentPackage
+	}(suite.parser.CurrentPackage)
+	suite.parser.CurrentPackage = "github.com/yvasiyarov/swagger/example"
+
+	op := parser.NewOperation(suite.parser, "github.com/yvasiyarov/swagger/example")
+	err := op.ParseComment("// @Success 200 {object} APIError{ErrorMessage=SimpleStructure}")
+	assert.Nil(suite.T(), err, "Can not parse composed type")
+	assert.Equal(suite.T(), "github.com.yvasiyarov.swagger.example.APIError_SimpleStructure", op.Type, "Composed type id is wrong")
+
+	var composed *parser.Model
+	for _, model := range op.Models {
+		if model.Id == op.Type {
+			composed = model
+		}
+	}
+	if assert.NotNil(suite.T(), composed, "Composed model is not registered") {
+		assert.Equal(suite.T(), "github.com.yvasiyarov.swagger.example.SimpleStructure", composed.Properties["ErrorMessage"].Type, "Field type is not replaced")
+		assert.Equal(suite.T(), "int", composed.Properties["ErrorCode"].Type, "Other fields are changed")
+	}
+
+	op = parser.NewOperation(suite.parser, "github.com/yvasiyarov/swagger/example")
+	assert.Nil(suite.T(), op.ParseComment("// @Wrapper APIError{ErrorMessage}"), "Can not parse @Wrapper")
+	assert.Nil(suite.T(), op.ParseComment("// @Success 200 {array} SimpleStructure"), "Can not parse wrapped response")
+	assert.Nil(suite.T(), op.ParseComment("// @Failure 404 {object} APIError"), "Can not parse failure response")
+	assert.Equal(suite.T(), "github.com.yvasiyarov.swagger.example.APIError_ArrayOfSimpleStructure", op.Type, "Success response is not wrapped")
+	assert.Equal(suite.T(), "object", op.ResponseMessages[0].ResponseType, "Wrapped response is not an object")
+	assert.Equal(suite.T(), "github.com.yvasiyarov.swagger.example.APIError", op.ResponseMessages[1].ResponseModel, "Failure response is wrapped")
+
+	assert.NotNil(suite.T(), op.ParseComment("// @Wrapper APIError"), "Wrapper without field accepted")
+	assert.NotNil(suite.T(), op.ParseComment("// @Success 200 {object} APIError{Unknown=SimpleStructure}"), "Unknown field accepted")
+}
+
+func (suite *ParserSuite) TestSharedModels() {
+	sharedParser := parser.NewParser()
+	for _, path := range []string{"/users", "/orders"} {
+		model := parser.NewModel(sharedParser)
+		model.Id = "example.User"
+		model.Properties = map[string]*parser.ModelProperty{"id": {Type: "int"}}
+
+		op := parser.NewOperation(sharedParser, "example")
+		op.Path = path
+		op.HttpMethod = "GET"
+		op.Models = append(op.Models, model)
+		sharedParser.AddOperation(op)
+	}
+
+	assert.Len(suite.T(), sharedParser.Models, 1, "Model is not shared")
+	assert.True(suite.T(), sharedParser.TopLevelApis["users"].Models["example.User"] == sharedParser.TopLevelApis["orders"].Models["example.User"], "Top level APIs have their own model definitions")
+}
+
+func (suite *ParserSuite) TestSortApis() {
+	sortedParser := parser.NewParser()
+	for _, route := range [][2]string{{"/users/{id}", "DELETE"}, {"/orders", "GET"}, {"/users", "POST"}, {"/users/{id}", "GET"}, {"/users", "GET"}} {
+		op := parser.NewOperation(sortedParser, "example")
+		op.Path = route[0]
+		op.HttpMethod = route[1]
+		op.Parameters = []parser.Parameter{{Name: "body", ParamType: "body"}, {Name: "b", ParamType: "query"}, {Name: "id", ParamType: "path"}, {Name: "a", ParamType: "query"}}
+		sortedParser.AddOperation(op)
+	}
+	sortedParser.SortApis()
+
+	assert.Equal(suite.T(), "/orders", sortedParser.Listing.Apis[0].Path, "Resources are not sorted")
+	users := sortedParser.TopLevelApis["users"]
+	assert.Equal(suite.T(), "/users", users.Apis[0].Path, "Paths are not sorted")
+	assert.Equal(suite.T(), "GET", users.Apis[0].Operations[0].HttpMethod, "Operations are not sorted")
+	assert.Equal(suite.T(), "GET", users.Apis[1].Operations[0].HttpMethod, "Operations are not sorted")
+	var names []string
+	for _, param := range users.Apis[0].Operations[0].Parameters {
+		names = append(names, param.Name)
+	}
+	assert.Equal(suite.T(), []string{"id", "b", "a", "body"}, names, "Parameters are not sorted by type")
+}
+
+func (suite *ParserSuite) TestSortApisOrders() {
+	newParser := func(sortOrder string) *parser.Parser {
+		sortedParser := parser.NewParser()
+		sortedParser.Sort = sortOrder
+		for _, route := range [][3]string{{"/users/{id}", "DELETE", "delete user"}, {"/users", "POST", "create user"}, {"/users/{id}", "GET", "get user"}, {"/users", "GET", "list users"}, {"/users/export", "POST", "export users"}} {
+			op := parser.NewOperation(sortedParser, "example")
+			op.Path, op.HttpMethod, op.Summary = route[0], route[1], route[2]
+			sortedParser.AddOperation(op)
+		}
+		sortedParser.SortApis()
+		return sortedParser
+	}
+	operations := func(sortedParser *parser.Parser) []string {
+		var summaries []string
+		for _, subApi := range sortedParser.TopLevelApis["users"].Apis {
+			for _, op := range subApi.Operations {
+				summaries = append(summaries, op.Summary)
+			}
+		}
+		return summaries
+	}
+
+	assert.Equal(suite.T(), []string{"list users", "create user", "export users", "get user", "delete user"}, operations(newParser("")), "Operations are not sorted by path")
+	assert.Equal(suite.T(), []string{"list users", "create user", "export users", "get user", "delete user"}, operations(newParser(parser.SortByPath)), "Operations are not sorted by path")
+	assert.Equal(suite.T(), []string{"list users", "create user", "get user", "delete user", "export users"}, operations(newParser(parser.SortByMethod)), "Operations are not sorted by method")
+	assert.Equal(suite.T(), []string{"create user", "list users", "delete user", "get user", "export users"}, operations(newParser(parser.SortBySummary)), "Operations are not sorted by summary")
+	assert.Equal(suite.T(), []string{"delete user", "get user", "create user", "list users", "export users"}, operations(newParser(parser.SortBySource)), "Operations are not sorted by source")
+}
+
+func (suite *ParserSuite) TestFilter() {
+	fullParser := parser.NewParser()
+	for _, route := range [][3]string{{"/users", "GET", "users"}, {"/users/{id}", "GET", "users"}, {"/admin/users", "GET", "admin"}, {"/admin/stats", "GET", "admin"}, {"/adminer", "GET", "admin"}} {
+		op := parser.NewOperation(fullParser, "example")
+		op.Path, op.HttpMethod, op.ForceResource = route[0], route[1], route[2]
+		fullParser.AddOperation(op)
+	}
+	paths := func(filtered *parser.Parser) []string {
+		var apiPaths []string
+		for _, apiKey := range []string{"admin", "users"} {
+			if api, ok := filtered.TopLevelApis[apiKey]; ok {
+				for _, subApi := range api.Apis {
+					apiPaths = append(apiPaths, subApi.Path)
+				}
+			}
+		}
+		return apiPaths
+	}
+
+	assert.Equal(suite.T(), []string{"/admin/users", "/admin/stats", "/adminer"}, paths(fullParser.Filter([]string{"Admin"}, nil)), "Resources are not filtered by tag")
+	assert.Equal(suite.T(), []string{"/adminer", "/users", "/users/{id}"}, paths(fullParser.Filter(nil, []string{"/admin/"})), "Operations are not filtered by path prefix")
+	assert.Equal(suite.T(), []string{"/users"}, paths(fullParser.Filter([]string{"users"}, []string{"/users/{id}"})), "Operations are not filtered by tag and path")
+	assert.Len(suite.T(), fullParser.Filter(nil, []string{"/admin", "/adminer"}).Listing.Apis, 1, "Resources without operations are not dropped")
+	assert.Len(suite.T(), paths(fullParser), 5, "Filter changed the parser")
+}
+
+func (suite *ParserSuite) TestFilterAudience() {
+	fullParser := parser.NewParser()
+	model := parser.NewModel(fullParser)
+	model.Id = "example.User"
+	model.Required = []string{"id", "margin"}
+	model.Properties = map[string]*parser.ModelProperty{"id": {Type: "int"}, "margin": {Type: "float", Audiences: []string{"partner", "internal"}}}
+	for _, route := range []string{"/users", "/partners", "/admin"} {
+		op := parser.NewOperation(fullParser, "example")
+		op.Path, op.HttpMethod = route, "GET"
+		op.Models = append(op.Models, model)
+		fullParser.AddOperation(op)
+	}
+	assert.Nil(suite.T(), fullParser.TopLevelApis["partners"].Apis[0].Operations[0].ParseComment("// @Audience partner, internal"), "Can not parse @Audience")
+	assert.Nil(suite.T(), fullParser.TopLevelApis["admin"].Apis[0].Operations[0].ParseComment("// @Audience Internal"), "Can not parse @Audience")
+
+	public := fullParser.FilterAudience("public")
+	assert.Len(suite.T(), public.TopLevelApis, 1, "Operations of other audiences are not filtered")
+	assert.Contains(suite.T(), public.TopLevelApis, "users", "Operations without audience are filtered")
+	assert.NotContains(suite.T(), public.TopLevelApis["users"].Models["example.User"].Properties, "margin", "Properties of other audiences are not filtered")
+	assert.Equal(suite.T(), []string{"id"}, public.TopLevelApis["users"].Models["example.User"].Required, "Required properties of other audiences are not filtered")
+
+	partner := fullParser.FilterAudience("partner")
+	assert.Len(suite.T(), partner.TopLevelApis, 2, "Operations of the audience are filtered")
+	assert.Contains(suite.T(), partner.TopLevelApis["partners"].Models["example.User"].Properties, "margin", "Properties of the audience are filtered")
+	assert.Len(suite.T(), fullParser.FilterAudience("internal").TopLevelApis, 3, "Audiences are not case insensitive")
+	assert.Contains(suite.T(), fullParser.Models["example.User"].Properties, "margin", "FilterAudience changed the models of the parser")
+}
+
+func (suite *ParserSuite) TestInternalOperation() {
+	op := parser.NewOperation(suite.parser, "github.com/yvasiyarov/swagger/example")
+	assert.False(suite.T(), op.Internal, "Operation is internal by default")
+	assert.Nil(suite.T(), op.ParseComment("// @Internal"), "Can not parse @Internal")
+	assert.True(suite.T(), op.Internal, "@Internal is not parsed")
+}
+
+func (suite *ParserSuite) TestLint() {
+	lintParser := parser.NewParser()
+	lintParser.IsController = IsController
+
+	issues := lintParser.Lint("github.com/yvasiyarov/swagger/example")
+	assert.Empty(suite.T(), issues, "Example annotations reported as invalid")
+}
+
 func TestParserSuite(t *testing.T) {
 	suite.Run(t, &ParserSuite{})
 }
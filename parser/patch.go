@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/jsonpatch"
+)
+
+// ApplyPatch applies a RFC 6902 JSON Patch to the parsed API, for the corner cases annotations and
+// overlays can not express, like renaming a model. The patched document is the spec as it is written:
+//
+//	{"listing": <resource listing>, "apis": {"users": <API declaration of /users>, ...}}
+//
+// e.g. {"op": "add", "path": "/apis/users/apis/0/operations/0/x-internal", "value": true}. Resources
+// and models added by the patch are documented, the removed ones are not anymore. The listing has an
+// info object even if the API has no info, which is left out of the spec, so its fields can be added.
+func (parser *Parser) ApplyPatch(patch []jsonpatch.Operation) error {
+	document := map[string]interface{}{
+		"listing": parser.Listing,
+		"apis":    parser.TopLevelApis,
+	}
+	var decoded map[string]interface{}
+	if err := decodeInto(&decoded, document); err != nil {
+		return fmt.Errorf("Can not serialise the spec to patch: %v\n", err)
+	}
+	if listing, ok := decoded["listing"].(map[string]interface{}); ok && listing["info"] == nil {
+		listing["info"] = map[string]interface{}{}
+	}
+	patched, err := jsonpatch.Apply(decoded, patch)
+	if err != nil {
+		return err
+	}
+	patchedDocument, ok := patched.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("Invalid patched spec: must be an object with listing and apis\n")
+	}
+
+	listing := &ResourceListing{}
+	if err := decodeInto(listing, patchedDocument["listing"]); err != nil {
+		return fmt.Errorf("Can not read the patched listing: %v\n", err)
+	}
+	listing.Infos.ContactName, listing.Infos.ContactUrl = parser.Listing.Infos.ContactName, parser.Listing.Infos.ContactUrl
+	apis, _ := patchedDocument["apis"].(map[string]interface{})
+	topLevelApis := make(map[string]*ApiDeclaration, len(apis))
+	for resource, api := range apis {
+		declaration := NewApiDeclaration()
+		if err := decodeInto(declaration, api); err != nil {
+			return fmt.Errorf("Can not read the patched API %s: %v\n", resource, err)
+		}
+		if generated, ok := parser.TopLevelApis[resource]; ok {
+			declaration.Consumes = generated.Consumes
+		}
+		topLevelApis[resource] = declaration
+	}
+	for _, ref := range listing.Apis {
+		if _, ok := topLevelApis[strings.Trim(ref.Path, "/")]; !ok {
+			return fmt.Errorf("Invalid patched spec: the listing refers to %s, which is not in apis\n", ref.Path)
+		}
+	}
+
+	// what is not serialised is kept from the generated operations and models with the same identity
+	generatedOperations := make(map[string]*Operation)
+	for _, api := range parser.TopLevelApis {
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				generatedOperations[subApi.Path+" "+op.HttpMethod] = op
+			}
+		}
+	}
+	models := make(map[string]*Model)
+	for _, api := range topLevelApis {
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				op.parser, op.Path = parser, subApi.Path
+				if generated, ok := generatedOperations[subApi.Path+" "+op.HttpMethod]; ok {
+					op.restore(generated)
+				}
+			}
+		}
+		for id, model := range api.Models {
+			model.parser = parser
+			if generated, ok := parser.Models[id]; ok {
+				model.restore(generated)
+			}
+			models[id] = model
+		}
+	}
+	parser.Listing, parser.TopLevelApis, parser.Models = listing, topLevelApis, models
+	return nil
+}
+
+// restore sets the fields of the operation which are not serialised from the generated one
+func (operation *Operation) restore(generated *Operation) {
+	operation.Consumes = generated.Consumes
+	operation.ForceResource = generated.ForceResource
+	operation.Versions = generated.Versions
+	operation.Audiences = generated.Audiences
+	operation.Wrapper = generated.Wrapper
+	operation.Ignored = generated.Ignored
+	operation.Internal = generated.Internal
+	operation.order = generated.order
+	operation.Models = generated.Models
+	operation.packageName = generated.packageName
+}
+
+// restore sets the fields of the model and of its properties which are not serialised from the generated one
+func (m *Model) restore(generated *Model) {
+	m.subTypeNames = generated.subTypeNames
+	m.modelPackage = generated.modelPackage
+	m.underlying = generated.underlying
+	for name, property := range m.Properties {
+		if generatedProperty, ok := generated.Properties[name]; ok {
+			property.Nullable = generatedProperty.Nullable
+			property.Audiences = generatedProperty.Audiences
+		}
+	}
+}
+
+// decodeInto decodes the JSON document of value into v
+func decodeInto(v interface{}, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
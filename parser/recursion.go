@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"go/ast"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/logger"
+)
+
+// referKnownModel makes property refer to the Id of typeName, a model which is already known: it is
+// parsed elsewhere, or being parsed, as Node is when its Children []Node field is parsed. Models
+// referring to each other are valid, but hard to use for clients, so they are reported.
+func (m *Model) referKnownModel(property *ModelProperty, typeName string, modelPackage string) {
+	astTypeSpec, typePackage, err := m.parser.resolveModelDefinition(typeName, modelPackage)
+	if err != nil {
+		return
+	}
+	if _, ok := astTypeSpec.Type.(*ast.StructType); !ok {
+		return
+	}
+	nameParts := strings.Split(typeName, ".")
+	modelId := m.parser.uniqueModelId(typePackage, nameParts[len(nameParts)-1], astTypeSpec.Pos())
+	if cycle := modelId + " " + m.Id; modelId != m.Id && m.parser.parsingModels[modelId] && !m.parser.circularModels[cycle] {
+		m.parser.circularModels[cycle] = true
+		logger.Warnf("Models %s and %s refer to each other\n", modelId, m.Id)
+	}
+
+	if property.Type == "array" {
+		if property.Items.Ref == typeName {
+			property.Items.Ref = modelId
+		}
+	} else if property.Type == typeName {
+		property.Type = modelId
+	}
+}
@@ -0,0 +1,49 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// beego params: :id, ?:id (optional), :id:int, :id([0-9]+)
+	colonParamRegexp = regexp.MustCompile(`^\??:([A-Za-z_][A-Za-z0-9_]*)(?::[a-z]+|\(.*\))?$`)
+	pathParamRegexp  = regexp.MustCompile(`\{([^{}]+)\}`)
+)
+
+// PathTemplate converts the path of a route to a path template, as Swagger expects it:
+//
+//	/users/:id, /users/?:id, /users/:id:int, /users/:id([0-9]+) => /users/{id}
+//	/files/{path:.*} => /files/{path}
+//	/static/* => /static/{splat}
+//	/download/*.* => /download/{path}.{ext}
+//
+// The names of the wildcards are the ones beego gives them. Paths already templated are unchanged.
+func PathTemplate(route string) string {
+	if strings.Contains(route, ":") && strings.Contains(route, "{") {
+		route = NormalizeMuxPath(route)
+	}
+	parts := strings.Split(route, "/")
+	for i, part := range parts {
+		switch {
+		case part == "*":
+			parts[i] = "{splat}"
+		case part == "*.*":
+			parts[i] = "{path}.{ext}"
+		default:
+			if matches := colonParamRegexp.FindStringSubmatch(part); matches != nil {
+				parts[i] = "{" + matches[1] + "}"
+			}
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// pathParamNames returns the names of the params of a path template, in order
+func pathParamNames(path string) []string {
+	var names []string
+	for _, matches := range pathParamRegexp.FindAllStringSubmatch(path, -1) {
+		names = append(names, matches[1])
+	}
+	return names
+}
@@ -0,0 +1,389 @@
+package parser
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// swaggerSchemas are the JSON schemas of the Swagger 1.2 spec, as published in
+// https://github.com/swagger-api/swagger-spec/tree/master/schemas/v1.2
+//
+//go:embed schemas/v1.2/*.json
+var swaggerSchemas embed.FS
+
+var (
+	loadSchemasOnce sync.Once
+	loadedSchemas   map[string]map[string]interface{} // file name -> schema
+	loadSchemasErr  error
+)
+
+// schemas decodes the embedded schemas once
+func schemas() (map[string]map[string]interface{}, error) {
+	loadSchemasOnce.Do(func() {
+		entries, err := swaggerSchemas.ReadDir("schemas/v1.2")
+		if err != nil {
+			loadSchemasErr = err
+			return
+		}
+		loadedSchemas = make(map[string]map[string]interface{})
+		for _, entry := range entries {
+			data, err := swaggerSchemas.ReadFile("schemas/v1.2/" + entry.Name())
+			if err != nil {
+				loadSchemasErr = err
+				return
+			}
+			var schema map[string]interface{}
+			if err := json.Unmarshal(data, &schema); err != nil {
+				loadSchemasErr = fmt.Errorf("Can not decode schema %s: %v", entry.Name(), err)
+				return
+			}
+			loadedSchemas[entry.Name()] = schema
+		}
+	})
+	return loadedSchemas, loadSchemasErr
+}
+
+// validateSchema validates document against the embedded schema file, a JSON Schema draft 4
+// validation. The format keyword is an annotation only, as draft 4 allows: uri, email, mime-type
+// and uri-template values are not checked.
+func validateSchema(document interface{}, file string) []error {
+	loaded, err := schemas()
+	if err != nil {
+		return []error{ValidationError{"", err.Error()}}
+	}
+	v := &schemaValidator{schemas: loaded}
+	v.validate(document, loaded[file], file, "")
+	return v.errors
+}
+
+type schemaValidator struct {
+	schemas map[string]map[string]interface{}
+	errors  []error
+}
+
+func (v *schemaValidator) fail(pointer, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{pointer, fmt.Sprintf(format, args...)})
+}
+
+// matches reports if value is valid against schema, and returns the errors if it is not
+func (v *schemaValidator) matches(value interface{}, schema map[string]interface{}, file, pointer string) []error {
+	sub := &schemaValidator{schemas: v.schemas}
+	sub.validate(value, schema, file, pointer)
+	return sub.errors
+}
+
+// resolve returns the schema ref points to, and the file it is in. Refs are file.json#/json/pointer,
+// relative to file.
+func (v *schemaValidator) resolve(ref, file string) (map[string]interface{}, string) {
+	refFile, fragment := ref, ""
+	if index := strings.Index(ref, "#"); index != -1 {
+		refFile, fragment = ref[:index], ref[index+1:]
+	}
+	if refFile != "" {
+		file = refFile
+	}
+	var schema interface{} = v.schemas[file]
+	for _, token := range strings.Split(strings.TrimPrefix(fragment, "/"), "/") {
+		if token == "" {
+			continue
+		}
+		object, _ := schema.(map[string]interface{})
+		schema = object[strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)]
+	}
+	resolved, _ := schema.(map[string]interface{})
+	return resolved, file
+}
+
+func (v *schemaValidator) validate(value interface{}, schema map[string]interface{}, file, pointer string) {
+	if schema == nil {
+		return
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, refFile := v.resolve(ref, file)
+		if resolved == nil {
+			v.fail(pointer, "unknown schema %s in %s", ref, file)
+			return
+		}
+		v.validate(value, resolved, refFile, pointer)
+		return
+	}
+
+	if types, ok := schema["type"]; ok && !hasSchemaType(value, types) {
+		v.fail(pointer, "must be %s", typeDescription(types))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(value, enum) {
+		if len(enum) == 1 {
+			v.fail(pointer, "must be %v, got %v", enum[0], value)
+		} else {
+			v.fail(pointer, "must be one of %s, got %v", joinValues(enum), value)
+		}
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(typed, schema, file, pointer)
+	case []interface{}:
+		v.validateArray(typed, schema, file, pointer)
+	case string:
+		if maxLength, ok := schema["maxLength"].(float64); ok && float64(len([]rune(typed))) > maxLength {
+			v.fail(pointer, "must be at most %v characters long", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(typed) {
+				v.fail(pointer, "must match %s, got %q", pattern, typed)
+			}
+		}
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok {
+			if exclusive, _ := schema["exclusiveMinimum"].(bool); typed < minimum || (exclusive && typed == minimum) {
+				v.fail(pointer, "must be %s %v, got %v", comparison(">", exclusive), minimum, typed)
+			}
+		}
+		if maximum, ok := schema["maximum"].(float64); ok {
+			if exclusive, _ := schema["exclusiveMaximum"].(bool); typed > maximum || (exclusive && typed == maximum) {
+				v.fail(pointer, "must be %s %v, got %v", comparison("<", exclusive), maximum, typed)
+			}
+		}
+	}
+
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		for _, subSchema := range allOf {
+			v.validate(value, asSchema(subSchema), file, pointer)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		var closest []error
+		for i, subSchema := range anyOf {
+			errors := v.matches(value, asSchema(subSchema), file, pointer)
+			if len(errors) == 0 {
+				closest = nil
+				break
+			}
+			if i == 0 || len(errors) < len(closest) {
+				closest = errors
+			}
+		}
+		v.errors = append(v.errors, closest...)
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		var closest []error
+		matched := 0
+		for i, subSchema := range oneOf {
+			errors := v.matches(value, asSchema(subSchema), file, pointer)
+			if len(errors) == 0 {
+				matched++
+			} else if i == 0 || closest == nil || len(errors) < len(closest) {
+				closest = errors
+			}
+		}
+		if matched == 0 {
+			// the errors of the closest alternative are the most useful ones
+			v.errors = append(v.errors, closest...)
+		} else if matched > 1 {
+			v.fail(pointer, "must match exactly one of %d schemas, matches %d", len(oneOf), matched)
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok && len(v.matches(value, not, file, pointer)) == 0 {
+		v.fail(pointer, "must not match %s", schemaDescription(not))
+	}
+}
+
+func (v *schemaValidator) validateObject(object map[string]interface{}, schema map[string]interface{}, file, pointer string) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, key := range required {
+			if _, exists := object[key.(string)]; !exists {
+				v.fail(pointer+"/"+pointerToken(key.(string)), "is required")
+			}
+		}
+	}
+	if minProperties, ok := schema["minProperties"].(float64); ok && float64(len(object)) < minProperties {
+		v.fail(pointer, "must have at least %v properties", minProperties)
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	for _, key := range sortedKeys(object) {
+		keyPointer := pointer + "/" + pointerToken(key)
+		matched := false
+		if property, ok := properties[key]; ok {
+			matched = true
+			v.validate(object[key], asSchema(property), file, keyPointer)
+		}
+		for pattern, property := range patternProperties {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+				matched = true
+				v.validate(object[key], asSchema(property), file, keyPointer)
+			}
+		}
+		if matched {
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(keyPointer, "is not allowed")
+			}
+		case map[string]interface{}:
+			v.validate(object[key], additional, file, keyPointer)
+		}
+	}
+
+	dependencies, _ := schema["dependencies"].(map[string]interface{})
+	for _, key := range sortedKeys(dependencies) {
+		if _, exists := object[key]; !exists {
+			continue
+		}
+		switch dependency := dependencies[key].(type) {
+		case []interface{}:
+			for _, required := range dependency {
+				if _, exists := object[required.(string)]; !exists {
+					v.fail(pointer+"/"+pointerToken(required.(string)), "is required with %s", key)
+				}
+			}
+		case map[string]interface{}:
+			v.validate(object, dependency, file, pointer)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(array []interface{}, schema map[string]interface{}, file, pointer string) {
+	if minItems, ok := schema["minItems"].(float64); ok && float64(len(array)) < minItems {
+		v.fail(pointer, "must have at least %v items", minItems)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := range array {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(array[i], array[j]) {
+					v.fail(fmt.Sprintf("%s/%d", pointer, i), "duplicates item %d", j)
+					break
+				}
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range array {
+			v.validate(item, items, file, fmt.Sprintf("%s/%d", pointer, i))
+		}
+	}
+}
+
+func asSchema(value interface{}) map[string]interface{} {
+	schema, _ := value.(map[string]interface{})
+	return schema
+}
+
+// pointerToken escapes key to be a token of a JSON pointer (RFC 6901)
+func pointerToken(key string) string {
+	return strings.Replace(strings.Replace(key, "~", "~0", -1), "/", "~1", -1)
+}
+
+func schemaType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	}
+	return ""
+}
+
+func hasSchemaType(value interface{}, types interface{}) bool {
+	valueType := schemaType(value)
+	switch typed := types.(type) {
+	case string:
+		return typed == valueType || typed == "number" && valueType == "integer"
+	case []interface{}:
+		for _, name := range typed {
+			if hasSchemaType(value, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeDescription(types interface{}) string {
+	var names []string
+	switch typed := types.(type) {
+	case string:
+		names = []string{typed}
+	case []interface{}:
+		for _, name := range typed {
+			names = append(names, fmt.Sprint(name))
+		}
+	}
+	for i, name := range names {
+		if strings.IndexAny(name[:1], "aeiou") == 0 {
+			names[i] = "an " + name
+		} else {
+			names[i] = "a " + name
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinValues(values []interface{}) string {
+	var names []string
+	for _, value := range values {
+		names = append(names, fmt.Sprint(value))
+	}
+	return strings.Join(names, ", ")
+}
+
+func comparison(operator string, exclusive bool) string {
+	if exclusive {
+		return operator
+	}
+	return operator + "="
+}
+
+// schemaDescription describes the schema of a not keyword in its error
+func schemaDescription(schema map[string]interface{}) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return "the schema " + ref
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		return joinValues(enum)
+	}
+	if types, ok := schema["type"]; ok {
+		return typeDescription(types)
+	}
+	data, _ := json.Marshal(schema)
+	return string(data)
+}
+
+// sortedPointers sorts errors by JSON pointer, keeping the order of the errors of a value
+func sortedPointers(errors []error) []error {
+	sort.SliceStable(errors, func(i, j int) bool {
+		return errors[i].(ValidationError).Pointer < errors[j].(ValidationError).Pointer
+	})
+	return errors
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// serverVariableRegexp matches the {variable} templates of a server URL
+var serverVariableRegexp = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// Server is a server of the API, declared with @Server. Its URL may be templated with variables,
+// declared with @ServerVariable after it. The servers are written to the resource listing as its
+// x-servers extension, and become the servers of OpenAPI 3.0.
+type Server struct {
+	Url         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+type ServerVariable struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// addServer adds the server of a @Server annotation, its URL and an optional description:
+//
+//	@Server https://{region}.api.example.com/{version} Production
+func (listing *ResourceListing) addServer(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return fmt.Errorf("Can not parse server comment \"%s\", expected: @Server url [description]", value)
+	}
+	listing.Servers = append(listing.Servers, Server{
+		Url:         fields[0],
+		Description: strings.TrimSpace(value[len(fields[0]):]),
+	})
+	return nil
+}
+
+// addServerVariable adds a variable to the last @Server. Its default value comes first, followed by
+// the other allowed values if any:
+//
+//	@ServerVariable region eu|us|ap "Region of the datacenter"
+func (listing *ResourceListing) addServerVariable(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) < 2 {
+		return fmt.Errorf("Can not parse server variable comment \"%s\", expected: @ServerVariable name default[|value...] [\"description\"]", value)
+	}
+	if len(listing.Servers) == 0 {
+		return fmt.Errorf("@ServerVariable %s must follow a @Server", fields[0])
+	}
+	server := &listing.Servers[len(listing.Servers)-1]
+	name := fields[0]
+	if !strings.Contains(server.Url, "{"+name+"}") {
+		return fmt.Errorf("Server variable %s is not a part of server URL %s", name, server.Url)
+	}
+	values := strings.Split(fields[1], "|")
+	variable := ServerVariable{
+		Default:     values[0],
+		Description: strings.Trim(strings.TrimSpace(strings.TrimSpace(value[len(name):])[len(fields[1]):]), "\""),
+	}
+	if len(values) > 1 {
+		variable.Enum = values
+	}
+	if server.Variables == nil {
+		server.Variables = make(map[string]ServerVariable)
+	}
+	server.Variables[name] = variable
+	return nil
+}
+
+// checkServers returns an error if a variable of a server URL has no @ServerVariable
+func (listing *ResourceListing) checkServers() error {
+	for _, server := range listing.Servers {
+		for _, matches := range serverVariableRegexp.FindAllStringSubmatch(server.Url, -1) {
+			if _, ok := server.Variables[matches[1]]; !ok {
+				return fmt.Errorf("Variable %s of server URL %s has no @ServerVariable", matches[1], server.Url)
+			}
+		}
+	}
+	return nil
+}
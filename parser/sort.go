@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+// sortedPackages returns the parsed packages ordered by name. Packages and files are maps, iterating
+// them in name order makes models and operations parsed in the same order on every run.
+func sortedPackages(astPackages map[string]*ast.Package) []*ast.Package {
+	names := make([]string, 0, len(astPackages))
+	for name := range astPackages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	packages := make([]*ast.Package, 0, len(names))
+	for _, name := range names {
+		packages = append(packages, astPackages[name])
+	}
+	return packages
+}
+
+// sortedFiles returns the files of astPackage ordered by file name
+func sortedFiles(astPackage *ast.Package) []*ast.File {
+	names := make([]string, 0, len(astPackage.Files))
+	for name := range astPackage.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]*ast.File, 0, len(names))
+	for _, name := range names {
+		files = append(files, astPackage.Files[name])
+	}
+	return files
+}
+
+// sortedSet returns the keys of set in order
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// paramTypeOrder is the order of parameters of an operation, parameters of the same type keep
+// the order of their @Param annotations
+var paramTypeOrder = map[string]int{"path": 0, "query": 1, "header": 2, "form": 3, "body": 4}
+
+// Orders of the paths and operations of a resource, the values of Parser.Sort
+const (
+	SortByPath    = "path"    // paths by path, operations of a path by HTTP method
+	SortByMethod  = "method"  // paths by the first HTTP method of their operations, then by path
+	SortBySummary = "summary" // operations by summary, paths by the summary of their first operation
+	SortBySource  = "source"  // paths and operations in the order of their declaration in the code
+)
+
+// AVAILABLE_SORTS are the values of Parser.Sort
+const AVAILABLE_SORTS = "path|method|summary|source"
+
+// SortApis orders resources by path, their paths and operations by Parser.Sort (SortByPath if empty)
+// and parameters by type, so regenerating unchanged code gives the same files
+func (parser *Parser) SortApis() {
+	sort.SliceStable(parser.Listing.Apis, func(i, j int) bool {
+		return parser.Listing.Apis[i].Path < parser.Listing.Apis[j].Path
+	})
+
+	methodOrder := make(map[string]int, len(validMethods))
+	for i, method := range validMethods {
+		methodOrder[method] = i
+	}
+	for _, api := range parser.TopLevelApis {
+		for _, subApi := range api.Apis {
+			operations := subApi.Operations
+			sort.SliceStable(operations, func(i, j int) bool {
+				switch parser.Sort {
+				case SortBySummary:
+					if summaryI, summaryJ := strings.ToLower(operations[i].Summary), strings.ToLower(operations[j].Summary); summaryI != summaryJ {
+						return summaryI < summaryJ
+					}
+				case SortBySource:
+					return operations[i].order < operations[j].order
+				}
+				return methodOrder[operations[i].HttpMethod] < methodOrder[operations[j].HttpMethod]
+			})
+			for _, op := range operations {
+				sort.SliceStable(op.Parameters, func(i, j int) bool {
+					return paramTypeOrder[op.Parameters[i].ParamType] < paramTypeOrder[op.Parameters[j].ParamType]
+				})
+			}
+		}
+
+		// the operations are sorted, the first one of a path is its first in the order
+		apis := api.Apis
+		sort.SliceStable(apis, func(i, j int) bool {
+			if len(apis[i].Operations) > 0 && len(apis[j].Operations) > 0 {
+				first, other := apis[i].Operations[0], apis[j].Operations[0]
+				switch parser.Sort {
+				case SortByMethod:
+					if methodOrder[first.HttpMethod] != methodOrder[other.HttpMethod] {
+						return methodOrder[first.HttpMethod] < methodOrder[other.HttpMethod]
+					}
+				case SortBySummary:
+					if summary, otherSummary := strings.ToLower(first.Summary), strings.ToLower(other.Summary); summary != otherSummary {
+						return summary < otherSummary
+					}
+				case SortBySource:
+					return first.order < other.order
+				}
+			}
+			return apis[i].Path < apis[j].Path
+		})
+	}
+}
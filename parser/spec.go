@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ReadSpec reads a spec written by -format=swagger: dir/index.json and dir/<api>/index.json
+func ReadSpec(dir string) (*ResourceListing, map[string]*ApiDeclaration, error) {
+	listing := &ResourceListing{}
+	if err := readJsonFile(filepath.Join(dir, "index.json"), listing); err != nil {
+		return nil, nil, err
+	}
+
+	apis := make(map[string]*ApiDeclaration, len(listing.Apis))
+	for _, ref := range listing.Apis {
+		apiKey := strings.Trim(ref.Path, "/")
+		api := NewApiDeclaration()
+		if err := readJsonFile(filepath.Join(dir, filepath.FromSlash(apiKey), "index.json"), api); err != nil {
+			return nil, nil, err
+		}
+		apis[apiKey] = api
+	}
+	return listing, apis, nil
+}
+
+func readJsonFile(filename string, v interface{}) error {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("Can not read spec file: %v\n", err)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("Can not parse spec file %s: %v\n", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const ContentTypeEventStream = "text/event-stream"
+
+// eventCommentRegexp matches @Event message {object} chat.Message "A message of the chat"
+var eventCommentRegexp = regexp.MustCompile(`^([\w\-\.:]+)[\s]+(\{object\}|\{array\})[\s]+([\w\-\.\/\{\}\[\]=,]+)[\s]*(.*)?$`)
+
+// ServerSentEvent is an event of a Server-Sent Events stream: its name, the event: field of the stream,
+// and the model of its data. The model is a type name, array[T] for arrays, as the model of a response message.
+// The events of an operation are written to the spec as its x-events extension.
+type ServerSentEvent struct {
+	Name        string `json:"name"`
+	Model       string `json:"model"`
+	Description string `json:"description,omitempty"`
+}
+
+// ParseEventComment parses an event of the Server-Sent Events stream of the operation, which produces
+// text/event-stream:
+//
+//	@Event message {object} chat.Message "A message of the chat"
+func (operation *Operation) ParseEventComment(commentLine string) (err error) {
+	defer recoverError(&err)
+	matches := eventCommentRegexp.FindStringSubmatch(commentLine)
+	if matches == nil {
+		return fmt.Errorf("Can not parse event comment \"%s\", expected: @Event name {object} dataType \"description\"", commentLine)
+	}
+	typeName, err := operation.registerType(matches[3])
+	if err != nil {
+		return err
+	}
+	event := ServerSentEvent{Name: matches[1], Model: typeName, Description: strings.Trim(matches[4], "\"")}
+	if matches[2] == "{array}" {
+		event.Model = "array[" + typeName + "]"
+	}
+	operation.Events = append(operation.Events, event)
+	if !inList(ContentTypeEventStream, operation.Produces) {
+		operation.Produces = append(operation.Produces, ContentTypeEventStream)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// httpStatusCodes are the status code constants of net/http
+var httpStatusCodes = map[string]int{
+	"StatusContinue":           100,
+	"StatusSwitchingProtocols": 101,
+	"StatusProcessing":         102,
+	"StatusEarlyHints":         103,
+
+	"StatusOK":                   200,
+	"StatusCreated":              201,
+	"StatusAccepted":             202,
+	"StatusNonAuthoritativeInfo": 203,
+	"StatusNoContent":            204,
+	"StatusResetContent":         205,
+	"StatusPartialContent":       206,
+	"StatusMultiStatus":          207,
+	"StatusAlreadyReported":      208,
+	"StatusIMUsed":               226,
+
+	"StatusMultipleChoices":   300,
+	"StatusMovedPermanently":  301,
+	"StatusFound":             302,
+	"StatusSeeOther":          303,
+	"StatusNotModified":       304,
+	"StatusUseProxy":          305,
+	"StatusTemporaryRedirect": 307,
+	"StatusPermanentRedirect": 308,
+
+	"StatusBadRequest":                   400,
+	"StatusUnauthorized":                 401,
+	"StatusPaymentRequired":              402,
+	"StatusForbidden":                    403,
+	"StatusNotFound":                     404,
+	"StatusMethodNotAllowed":             405,
+	"StatusNotAcceptable":                406,
+	"StatusProxyAuthRequired":            407,
+	"StatusRequestTimeout":               408,
+	"StatusConflict":                     409,
+	"StatusGone":                         410,
+	"StatusLengthRequired":               411,
+	"StatusPreconditionFailed":           412,
+	"StatusRequestEntityTooLarge":        413,
+	"StatusRequestURITooLong":            414,
+	"StatusUnsupportedMediaType":         415,
+	"StatusRequestedRangeNotSatisfiable": 416,
+	"StatusExpectationFailed":            417,
+	"StatusTeapot":                       418,
+	"StatusMisdirectedRequest":           421,
+	"StatusUnprocessableEntity":          422,
+	"StatusLocked":                       423,
+	"StatusFailedDependency":             424,
+	"StatusTooEarly":                     425,
+	"StatusUpgradeRequired":              426,
+	"StatusPreconditionRequired":         428,
+	"StatusTooManyRequests":              429,
+	"StatusRequestHeaderFieldsTooLarge":  431,
+	"StatusUnavailableForLegalReasons":   451,
+
+	"StatusInternalServerError":           500,
+	"StatusNotImplemented":                501,
+	"StatusBadGateway":                    502,
+	"StatusServiceUnavailable":            503,
+	"StatusGatewayTimeout":                504,
+	"StatusHTTPVersionNotSupported":       505,
+	"StatusVariantAlsoNegotiates":         506,
+	"StatusInsufficientStorage":           507,
+	"StatusLoopDetected":                  508,
+	"StatusNotExtended":                   510,
+	"StatusNetworkAuthenticationRequired": 511,
+}
+
+// resolveStatusCode replaces the http.Status constant starting a response comment, like
+// "http.StatusOK {object} User", by its code
+func resolveStatusCode(commentLine string) (string, error) {
+	fields := strings.Fields(commentLine)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "http.") {
+		return commentLine, nil
+	}
+	code, ok := httpStatusCodes[strings.TrimPrefix(fields[0], "http.")]
+	if !ok {
+		return commentLine, fmt.Errorf("Unknown status code %s in response comment \"%s\"", fields[0], commentLine)
+	}
+	return strings.Replace(commentLine, fields[0], strconv.Itoa(code), 1), nil
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"strings"
 )
 
 const SwaggerVersion = "1.2"
@@ -19,8 +20,10 @@ type ResourceListing struct {
 	ApiVersion     string `json:"apiVersion"`
 	SwaggerVersion string `json:"swaggerVersion"`
 	//	BasePath       string     `json:"basePath"`
-	Apis  []*ApiRef  `json:"apis"`
-	Infos Infomation `json:"info"`
+	Apis       []*ApiRef  `json:"apis"`
+	Infos      Infomation `json:"info"`
+	Extensions Extensions `json:"-"` // x- vendor extensions of the @Extension general annotations
+	Servers    []Server   `json:"-"` // written as the x-servers extension
 }
 
 type ApiRef struct {
@@ -35,6 +38,60 @@ type Infomation struct {
 	TermsOfServiceUrl string `json:"termsOfServiceUrl,omitempty"`
 	License           string `json:"license,omitempty"`
 	LicenseUrl        string `json:"licenseUrl,omitempty"`
+	// Swagger 1.2 has no place for the name and URL of the contact, only the other formats show them
+	ContactName string `json:"-"`
+	ContactUrl  string `json:"-"`
+}
+
+// ContactLine returns the contact as "name <email> url", without the parts which are not set
+func (info Infomation) ContactLine() string {
+	var parts []string
+	if info.ContactName != "" {
+		parts = append(parts, info.ContactName)
+	}
+	if info.Contact != "" {
+		parts = append(parts, "<"+info.Contact+">")
+	}
+	if info.ContactUrl != "" {
+		parts = append(parts, info.ContactUrl)
+	}
+	return strings.Join(parts, " ")
+}
+
+// LicenseLine returns the license as "name (url)", without the parts which are not set
+func (info Infomation) LicenseLine() string {
+	if info.LicenseUrl == "" {
+		return info.License
+	}
+	if info.License == "" {
+		return info.LicenseUrl
+	}
+	return info.License + " (" + info.LicenseUrl + ")"
+}
+
+// Lines returns the contact, terms of service and license of the API as "Label: value" lines
+func (info Infomation) Lines() []string {
+	var lines []string
+	if contact := info.ContactLine(); contact != "" {
+		lines = append(lines, "Contact: "+contact)
+	}
+	if info.TermsOfServiceUrl != "" {
+		lines = append(lines, "Terms of service: "+info.TermsOfServiceUrl)
+	}
+	if license := info.LicenseLine(); license != "" {
+		lines = append(lines, "License: "+license)
+	}
+	return lines
+}
+
+// FullDescription returns the description followed by Lines, separated by blank lines,
+// for formats without dedicated contact and license fields
+func (info Infomation) FullDescription() string {
+	paragraphs := info.Lines()
+	if info.Description != "" {
+		paragraphs = append([]string{info.Description}, paragraphs...)
+	}
+	return strings.Join(paragraphs, "\n\n")
 }
 
 type Api struct {
@@ -53,23 +110,28 @@ type Protocol struct {
 }
 
 type ResponseMessage struct {
-	Code          int    `json:"code"`
-	Message       string `json:"message"`
-	ResponseType  string `json:"responseType"`
-	ResponseModel string `json:"responseModel"`
+	Code          int                       `json:"code"`
+	Message       string                    `json:"message"`
+	ResponseType  string                    `json:"responseType"`
+	ResponseModel string                    `json:"responseModel"`
+	Headers       map[string]ResponseHeader `json:"x-headers,omitempty"`
 }
 
 type Parameter struct {
-	ParamType     string `json:"paramType"` // path,query,body,header,form
-	Name          string `json:"name"`
-	Description   string `json:"description"`
-	DataType      string `json:"dataType"` // 1.2 needed?
-	Type          string `json:"type"`     // integer
-	Format        string `json:"format"`   // int64
-	AllowMultiple bool   `json:"allowMultiple"`
-	Required      bool   `json:"required"`
-	Minimum       int    `json:"minimum"`
-	Maximum       int    `json:"maximum"`
+	ParamType     string  `json:"paramType"` // path,query,body,header,form
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	DataType      string  `json:"dataType"` // 1.2 needed?
+	Type          string  `json:"type"`     // integer
+	Format        string  `json:"format"`   // int64
+	AllowMultiple bool    `json:"allowMultiple"`
+	Required      bool    `json:"required"`
+	Minimum       float64 `json:"minimum"`
+	Maximum       float64 `json:"maximum"`
+	MinLength     int     `json:"minLength,omitempty"`
+	MaxLength     int     `json:"maxLength,omitempty"`
+	Pattern       string  `json:"pattern,omitempty"`
+	ContentType   string  `json:"x-contentType,omitempty"` // content types of a multipart/form-data part, comma separated
 }
 
 type ErrorResponse struct {
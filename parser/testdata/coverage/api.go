@@ -0,0 +1,34 @@
+package coverage
+
+import "net/http"
+
+type Context struct{}
+
+// User is a user of the API
+type User struct {
+	Name string `json:"name"`
+}
+
+type Group struct {
+	Users []User `json:"users"`
+}
+
+// @Title getUser
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func (c *Context) GetUser() {}
+
+// @Title getGroup
+// @Success 200 {object} Group
+// @Router /groups/{id} [get]
+func (c *Context) GetGroup() {}
+
+// DeleteUser is not documented yet
+func (c *Context) DeleteUser() {}
+
+// @Ignore
+func (c *Context) Debug() {}
+
+func Health(w http.ResponseWriter, r *http.Request) {}
+
+func helper(w http.ResponseWriter) {}
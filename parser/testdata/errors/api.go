@@ -0,0 +1,24 @@
+package errors
+
+type Context struct{}
+
+type User struct {
+	Name string `json:"name"`
+}
+
+// @Title getUser
+// @Param id path int
+// @Success 200 {object} User
+// @Router /users/{id} [get]
+func (c *Context) GetUser() {}
+
+// @Title listUsers
+// @Success 200 {array} Missing
+// @Router /users [get]
+func (c *Context) ListUsers() {}
+
+// @Title createUser
+// @Param user body User true "User"
+// @Success 201 {object} User
+// @Router /users [post]
+func (c *Context) CreateUser() {}
@@ -0,0 +1,56 @@
+package goswagger
+
+import "net/http"
+
+// Pet is a pet of the store
+//
+// swagger:model
+type Pet struct {
+	Name string `json:"name"`
+}
+
+// swagger:parameters getPet deletePet
+type petIDParams struct {
+	// The ID of the pet
+	// in: path
+	// required: true
+	ID int64 `json:"id"`
+}
+
+// swagger:parameters findPets
+type findPetsParams struct {
+	// Tags to filter by
+	Tags []string `json:"tags"`
+}
+
+// A pet
+// swagger:response petResponse
+type petResponse struct {
+	// in: body
+	Body Pet
+}
+
+// swagger:route GET /pets/{id} pets getPet
+//
+// Get a pet.
+//
+// Returns the pet with the ID,
+// if there is one.
+//
+// Produces:
+// - application/json
+//
+// Responses:
+//
+//	200: petResponse
+//	404: body:Pet
+func GetPet(w http.ResponseWriter, r *http.Request) {}
+
+// swagger:route GET /pets pets findPets
+//
+// Find pets.
+//
+// Responses:
+//
+//	200: body:[]Pet
+func FindPets(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,11 @@
+package controllers
+
+import (
+	. "github.com/yvasiyarov/swagger/parser/testdata/imports/models"
+	m "github.com/yvasiyarov/swagger/parser/testdata/imports/models"
+)
+
+type Profile struct {
+	User   m.User  `json:"user"`
+	Orders []Order `json:"orders"`
+}
@@ -0,0 +1,9 @@
+package models
+
+type User struct {
+	Id int `json:"id"`
+}
+
+type Order struct {
+	Id int `json:"id"`
+}
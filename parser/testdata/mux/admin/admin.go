@@ -0,0 +1,6 @@
+package admin
+
+import "net/http"
+
+// @Title listAdmins
+func List(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/yvasiyarov/swagger/parser/testdata/mux/admin"
+)
+
+// Router and Route stand for the ones of gorilla/mux
+type Router struct{}
+
+type Route struct{}
+
+func (r *Router) HandleFunc(path string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return &Route{}
+}
+
+func (r *Route) Methods(methods ...string) *Route {
+	return r
+}
+
+type UserController struct{}
+
+type OrderController struct{}
+
+// @Title getUser
+func (c *UserController) Get(w http.ResponseWriter, r *http.Request) {}
+
+// @Title getOrder
+func (c *OrderController) Get(w http.ResponseWriter, r *http.Request) {}
+
+// @Title listUsers
+func List(w http.ResponseWriter, r *http.Request) {}
+
+func NewRouter(orders *OrderController) *Router {
+	router := &Router{}
+	// only go/types knows the type of users
+	users := newUserController()
+	router.HandleFunc("/users/{id}", users.Get).Methods("GET")
+	router.HandleFunc("/orders/{id}", orders.Get).Methods("GET")
+	router.HandleFunc("/users", List).Methods("GET")
+	router.HandleFunc("/admin/users", admin.List).Methods("GET")
+	return router
+}
+
+func newUserController() *UserController {
+	return &UserController{}
+}
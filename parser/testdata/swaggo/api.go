@@ -0,0 +1,27 @@
+package swaggo
+
+type Context struct{}
+
+type User struct {
+	Name string `json:"name"`
+}
+
+// ShowUser godoc
+// @Summary      Show a user
+// @Description  get a user by ID
+// @Tags         accounts
+// @Accept       json
+// @Produce      json
+// @Param        id    path   integer   true  "User ID"  minimum(1)
+// @Param        ids   query  []string  false "Other IDs"  Enums(a, b)
+// @Success      200   {object}  User
+// @Failure      404   {object}  User  "Not found"
+// @Security     ApiKeyAuth
+// @Router       /accounts/{id} [get]
+func (c *Context) ShowUser() {}
+
+// @Summary  List users
+// @ID       listUsers
+// @Success  200  {object}  []User
+// @Router   /accounts [get]
+func (c *Context) ListUsers() {}
@@ -0,0 +1,3 @@
+package tags
+
+type Fake struct{}
@@ -0,0 +1,5 @@
+//go:build premium
+
+package tags
+
+type Premium struct{}
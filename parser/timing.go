@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"time"
+
+	"github.com/yvasiyarov/swagger/logger"
+)
+
+// PackageTiming is the time spent parsing a package, over all the passes of ParseApi over it
+type PackageTiming struct {
+	Package  string
+	Duration time.Duration
+}
+
+// timePackage runs a pass of ParseApi over a package, adding its duration to the timing of the package
+func (parser *Parser) timePackage(packageName string, parse func(packageName string)) {
+	start := time.Now()
+	parse(packageName)
+	if packageName == "" {
+		return
+	}
+	for i := range parser.PackageTimings {
+		if parser.PackageTimings[i].Package == packageName {
+			parser.PackageTimings[i].Duration += time.Since(start)
+			return
+		}
+	}
+	parser.PackageTimings = append(parser.PackageTimings, PackageTiming{packageName, time.Since(start)})
+}
+
+// progress logs the progress of the parsing of the annotations of the packages, in verbose mode
+type progress struct {
+	done, total int
+}
+
+func newProgress(packages []string) *progress {
+	progress := &progress{}
+	for _, packageName := range packages {
+		if packageName != "" {
+			progress.total++
+		}
+	}
+	return progress
+}
+
+// next logs that the annotations of packageName are being parsed
+func (progress *progress) next(packageName string) {
+	if packageName == "" {
+		return
+	}
+	progress.done++
+	logger.Debugf("Parsing package %d/%d (%d%%) %s", progress.done, progress.total, 100*progress.done/progress.total, packageName)
+}
@@ -0,0 +1,255 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError is a violation of a rule of the Swagger 1.2 spec, Pointer is the JSON pointer of the
+// offending value. The specs are validated against the JSON schemas of the spec, and the rules of the
+// spec the schemas can not express, like the path params which must be a part of the path.
+type ValidationError struct {
+	Pointer string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+var (
+	// Methods, param types and nicknames of the operationObject.json and parameterObject.json schemas,
+	// which Lint checks the annotations against
+	validMethods    = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	validParamTypes = []string{"path", "query", "body", "header", "form"}
+	validNickname   = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	// Data types of the Swagger spec, Go basic types emitted by the parser are accepted too
+	swaggerTypes = map[string]bool{"integer": true, "number": true, "string": true, "boolean": true, "array": true, "void": true, "File": true, "file": true}
+)
+
+// ValidateResourceListing validates the serialised resource listing against the resourceListing.json
+// schema of https://github.com/swagger-api/swagger-spec/tree/master/schemas/v1.2, and checks the paths
+// of the apis start with /. The errors are sorted by pointer.
+func ValidateResourceListing(listingJson []byte) []error {
+	var listing interface{}
+	if err := json.Unmarshal(listingJson, &listing); err != nil {
+		return []error{ValidationError{"", "invalid JSON: " + err.Error()}}
+	}
+
+	v := &validator{errors: validateSchema(listing, "resourceListing.json")}
+	listingObject, _ := listing.(map[string]interface{})
+	apis, _ := listingObject["apis"].([]interface{})
+	for i, api := range apis {
+		if apiObject, ok := api.(map[string]interface{}); ok {
+			v.checkPath(apiObject, fmt.Sprintf("/apis/%d", i))
+		}
+	}
+	return sortedPointers(v.errors)
+}
+
+// ValidateApiDeclaration validates the serialised API declaration against the apiDeclaration.json
+// schema of https://github.com/swagger-api/swagger-spec/tree/master/schemas/v1.2, and checks the rules
+// the schema can not express: the unique paths, methods and response codes, the path params (a part of
+// the path and required), the single body param not mixed with form params, the data types (basic
+// types or models), and the model ids, required properties, subtypes and discriminators. The errors are
+// sorted by pointer.
+func ValidateApiDeclaration(apiJson []byte) []error {
+	var api interface{}
+	if err := json.Unmarshal(apiJson, &api); err != nil {
+		return []error{ValidationError{"", "invalid JSON: " + err.Error()}}
+	}
+
+	v := &validator{errors: validateSchema(api, "apiDeclaration.json"), models: map[string]bool{}}
+	apiObject, _ := api.(map[string]interface{})
+	models, _ := apiObject["models"].(map[string]interface{})
+	for id := range models {
+		v.models[id] = true
+	}
+	for _, id := range sortedKeys(models) {
+		if model, ok := models[id].(map[string]interface{}); ok {
+			v.checkModel(model, id)
+		}
+	}
+
+	apis, _ := apiObject["apis"].([]interface{})
+	paths := map[string]bool{}
+	for i, subApi := range apis {
+		pointer := fmt.Sprintf("/apis/%d", i)
+		subApiObject, ok := subApi.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path, _ := subApiObject["path"].(string)
+		if paths[path] {
+			v.fail(pointer+"/path", fmt.Sprintf("duplicate path %s", path))
+		}
+		paths[path] = true
+
+		operations, _ := subApiObject["operations"].([]interface{})
+		methods := map[string]bool{}
+		for j, operation := range operations {
+			operationPointer := fmt.Sprintf("%s/operations/%d", pointer, j)
+			if operationObject, ok := operation.(map[string]interface{}); ok {
+				method := v.checkOperation(operationObject, operationPointer, path)
+				if method != "" && methods[method] {
+					v.fail(operationPointer, fmt.Sprintf("duplicate operation %s %s", method, path))
+				}
+				methods[method] = true
+			}
+		}
+	}
+	return sortedPointers(v.errors)
+}
+
+// validator checks the rules of the spec the schemas can not express, the types of the values are
+// the ones of the schemas
+type validator struct {
+	errors []error
+	models map[string]bool
+}
+
+func (v *validator) fail(pointer, message string) {
+	v.errors = append(v.errors, ValidationError{pointer, message})
+}
+
+func (v *validator) checkPath(object map[string]interface{}, pointer string) string {
+	path, ok := object["path"].(string)
+	if ok && !strings.HasPrefix(path, "/") {
+		v.fail(pointer+"/path", "must start with /")
+	}
+	return path
+}
+
+func (v *validator) checkOperation(operation map[string]interface{}, pointer string, path string) string {
+	method, _ := operation["method"].(string)
+	v.checkType(operation, pointer)
+
+	pathParams := map[string]bool{}
+	for _, name := range pathParamNames(path) {
+		pathParams[name] = true
+	}
+
+	params, _ := operation["parameters"].([]interface{})
+	bodyParams, formParams := 0, 0
+	for i, param := range params {
+		paramPointer := fmt.Sprintf("%s/parameters/%d", pointer, i)
+		paramObject, ok := param.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := paramObject["name"].(string)
+		paramType, _ := paramObject["paramType"].(string)
+		switch paramType {
+		case "path":
+			if !pathParams[name] {
+				v.fail(paramPointer, fmt.Sprintf("path param %s is not a part of path %s", name, path))
+			}
+			if required, _ := paramObject["required"].(bool); !required {
+				v.fail(paramPointer+"/required", "path params must be required")
+			}
+		case "body":
+			bodyParams++
+		case "form":
+			formParams++
+		}
+		v.checkType(paramObject, paramPointer)
+	}
+	if bodyParams > 1 {
+		v.fail(pointer+"/parameters", "only one body param is allowed")
+	}
+	if bodyParams > 0 && formParams > 0 {
+		v.fail(pointer+"/parameters", "body and form params can not be used together")
+	}
+
+	responses, _ := operation["responseMessages"].([]interface{})
+	codes := map[float64]bool{}
+	for i, response := range responses {
+		responsePointer := fmt.Sprintf("%s/responseMessages/%d", pointer, i)
+		responseObject, ok := response.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if code, ok := responseObject["code"].(float64); ok && codes[code] {
+			v.fail(responsePointer+"/code", fmt.Sprintf("duplicate response code %v", code))
+		} else if ok {
+			codes[code] = true
+		}
+		if model, ok := responseObject["responseModel"].(string); ok && model != "" {
+			v.checkTypeName(model, responsePointer+"/responseModel")
+		}
+	}
+	return method
+}
+
+func (v *validator) checkModel(model map[string]interface{}, id string) {
+	pointer := "/models/" + pointerToken(id)
+	if modelId, _ := model["id"].(string); modelId != id {
+		v.fail(pointer+"/id", fmt.Sprintf("must be equal to the model key %s, got %s", id, modelId))
+	}
+	properties, _ := model["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(properties) {
+		if property, ok := properties[name].(map[string]interface{}); ok {
+			v.checkType(property, pointer+"/properties/"+pointerToken(name))
+		}
+	}
+	if required, ok := model["required"].([]interface{}); ok {
+		for i, name := range required {
+			if nameString, _ := name.(string); properties[nameString] == nil {
+				v.fail(fmt.Sprintf("%s/required/%d", pointer, i), fmt.Sprintf("required property %v is not defined", name))
+			}
+		}
+	}
+	if subTypes, ok := model["subTypes"].([]interface{}); ok {
+		for i, subType := range subTypes {
+			if subTypeString, _ := subType.(string); !v.models[subTypeString] {
+				v.fail(fmt.Sprintf("%s/subTypes/%d", pointer, i), fmt.Sprintf("subtype %v is not a model", subType))
+			}
+		}
+	}
+	if discriminator, ok := model["discriminator"].(string); ok && properties[discriminator] == nil {
+		v.fail(pointer+"/discriminator", fmt.Sprintf("discriminator %s is not a property", discriminator))
+	}
+}
+
+// checkType checks type, dataType, $ref and items of a data type object
+func (v *validator) checkType(object map[string]interface{}, pointer string) {
+	for _, key := range []string{"type", "dataType", "$ref"} {
+		if typeName, ok := object[key].(string); ok && typeName != "" {
+			v.checkTypeName(typeName, pointer+"/"+pointerToken(key))
+		}
+	}
+	if items, ok := object["items"].(map[string]interface{}); ok {
+		v.checkType(items, pointer+"/items")
+	}
+}
+
+func (v *validator) checkTypeName(typeName, pointer string) {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		typeName = typeName[len("array[") : len(typeName)-1]
+	}
+	if swaggerTypes[typeName] || IsBasicType(typeName) || v.models[typeName] {
+		return
+	}
+	v.fail(pointer, fmt.Sprintf("unknown type %s, it is neither a basic type nor a model", typeName))
+}
+
+func inList(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
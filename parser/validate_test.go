@@ -0,0 +1,135 @@
+package parser_test
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"github.com/yvasiyarov/swagger/parser"
+	"strings"
+	"testing"
+)
+
+type ValidateSuite struct {
+	suite.Suite
+}
+
+func (suite *ValidateSuite) newApi() *parser.ApiDeclaration {
+	api := parser.NewApiDeclaration()
+	api.SwaggerVersion = parser.SwaggerVersion
+	api.BasePath = "http://localhost"
+	api.ResourcePath = "/users"
+
+	op := parser.NewOperation(nil, "test")
+	op.HttpMethod = "GET"
+	op.Nickname = "GetUser"
+	op.Path = "/users/{id}"
+	op.Type = "fixture.User"
+	op.Parameters = append(op.Parameters, parser.Parameter{Name: "id", ParamType: "path", Type: "int", Required: true})
+	op.ResponseMessages = append(op.ResponseMessages, parser.ResponseMessage{Code: 200, Message: "", ResponseModel: "fixture.User"})
+	api.AddOperation(op)
+
+	api.Models["fixture.User"] = &parser.Model{
+		Id:         "fixture.User",
+		Required:   []string{"id"},
+		Properties: map[string]*parser.ModelProperty{"id": &parser.ModelProperty{Type: "int"}},
+	}
+	return api
+}
+
+func (suite *ValidateSuite) validate(api *parser.ApiDeclaration) []string {
+	apiJson, err := json.Marshal(api)
+	assert.Nil(suite.T(), err, "Can not serialise api declaration")
+
+	var messages []string
+	for _, err := range parser.ValidateApiDeclaration(apiJson) {
+		messages = append(messages, err.Error())
+	}
+	return messages
+}
+
+func (suite *ValidateSuite) TestValidApiDeclaration() {
+	assert.Empty(suite.T(), suite.validate(suite.newApi()), "Valid api declaration reported as invalid")
+}
+
+func (suite *ValidateSuite) TestInvalidOperation() {
+	api := suite.newApi()
+	op := api.Apis[0].Operations[0]
+	op.HttpMethod = "FETCH"
+	op.Nickname = "Get user"
+	op.Parameters[0].Required = false
+
+	assert.Equal(suite.T(), []string{
+		"/apis/0/operations/0/method: must be one of GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS, got FETCH",
+		"/apis/0/operations/0/nickname: must match ^[a-zA-Z0-9_]+$, got \"Get user\"",
+		"/apis/0/operations/0/parameters/0/required: path params must be required",
+	}, suite.validate(api))
+}
+
+func (suite *ValidateSuite) TestInvalidParameters() {
+	api := suite.newApi()
+	op := api.Apis[0].Operations[0]
+	op.Parameters = append(op.Parameters,
+		parser.Parameter{Name: "name", ParamType: "path", Type: "string", Required: true},
+		parser.Parameter{Name: "user", ParamType: "body", Type: "fixture.User"},
+		parser.Parameter{Name: "file", ParamType: "form", Type: "File"},
+	)
+
+	assert.Equal(suite.T(), []string{
+		"/apis/0/operations/0/parameters: body and form params can not be used together",
+		"/apis/0/operations/0/parameters/1: path param name is not a part of path /users/{id}",
+	}, suite.validate(api))
+}
+
+func (suite *ValidateSuite) TestUnknownModel() {
+	api := suite.newApi()
+	api.Apis[0].Operations[0].Type = "fixture.Unknown"
+	api.Models["fixture.User"].Required = append(api.Models["fixture.User"].Required, "name")
+
+	assert.Equal(suite.T(), []string{
+		"/apis/0/operations/0/type: unknown type fixture.Unknown, it is neither a basic type nor a model",
+		"/models/fixture.User/required/1: required property name is not defined",
+	}, suite.validate(api))
+}
+
+func (suite *ValidateSuite) TestResourceListing() {
+	listing := []byte(`{"swaggerVersion": "1.1", "apis": [{"path": "users"}], "info": {"title": "API"}}`)
+
+	var messages []string
+	for _, err := range parser.ValidateResourceListing(listing) {
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(suite.T(), []string{
+		"/apis/0/path: must start with /",
+		"/info/description: is required",
+		"/swaggerVersion: must be 1.2, got 1.1",
+	}, messages)
+}
+
+// TestSchemaRules breaks rules only the Swagger 1.2 schemas check
+func (suite *ValidateSuite) TestSchemaRules() {
+	api := suite.newApi()
+	api.BasePath = "/api"
+	op := api.Apis[0].Operations[0]
+	op.Summary = strings.Repeat("a", 121)
+	op.ResponseMessages[0].Code = 99
+	op.Parameters[0].Format = "uuid"
+	api.Models["fixture.User"].SubTypes = []string{"fixture.User"}
+
+	assert.Equal(suite.T(), []string{
+		"/apis/0/operations/0/parameters/0/format: must be one of int32, int64, got uuid",
+		"/apis/0/operations/0/responseMessages/0/code: must be >= 100, got 99",
+		"/apis/0/operations/0/summary: must be at most 120 characters long",
+		"/basePath: must match ^https?://, got \"/api\"",
+		"/models/fixture.User/discriminator: is required with subTypes",
+	}, suite.validate(api))
+}
+
+func (suite *ValidateSuite) TestParametersAreAlwaysSerialised() {
+	api := suite.newApi()
+	api.Apis[0].Operations[0].Parameters = nil
+	assert.Empty(suite.T(), suite.validate(api), "Operation without params reported as invalid")
+}
+
+func TestValidateSuite(t *testing.T) {
+	suite.Run(t, &ValidateSuite{})
+}
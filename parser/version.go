@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"strings"
+)
+
+// HasVersion reports if the operation belongs to version: it is listed in @Version,
+// or the operation has no @Version and its path starts with /<version>/
+func (operation *Operation) HasVersion(version string) bool {
+	if len(operation.Versions) > 0 {
+		for _, operationVersion := range operation.Versions {
+			if operationVersion == version {
+				return true
+			}
+		}
+		return false
+	}
+	return operation.Path == "/"+version || strings.HasPrefix(operation.Path, "/"+version+"/")
+}
+
+// FilterVersion returns a copy of the parser with the operations of version only. Resources
+// without such operations are dropped, and the API version of the copy is version.
+func (parser *Parser) FilterVersion(version string) *Parser {
+	return parser.filterOperations(version, func(apiKey string, op *Operation) bool {
+		return op.HasVersion(version)
+	})
+}
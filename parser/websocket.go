@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// websocketCommentRegexp matches @Websocket client {object} chat.Message "Message sent by the client"
+var websocketCommentRegexp = regexp.MustCompile(`^(client|server)[\s]+(\{object\}|\{array\})[\s]+([\w\-\.\/\{\}\[\]=,]+)[\s]*(.*)?$`)
+
+// Websocket documents the messages exchanged on the WebSocket an operation upgrades its connection to.
+// It is written to the spec as the x-websocket extension of the operation.
+type Websocket struct {
+	Client []WebsocketMessage `json:"client,omitempty"` // messages sent by the client to the server
+	Server []WebsocketMessage `json:"server,omitempty"` // messages sent by the server to the client
+}
+
+// WebsocketMessage is a message of a WebSocket. Its model is a type name, array[T] for arrays, as the
+// model of a response message.
+type WebsocketMessage struct {
+	Model       string `json:"model"`
+	Description string `json:"description,omitempty"`
+}
+
+// ParseWebsocketComment parses a message of the WebSocket of the operation:
+//
+//	@Websocket client {object} chat.Message "Message sent by the client"
+//	@Websocket server {array} chat.Event "Events pushed by the server"
+func (operation *Operation) ParseWebsocketComment(commentLine string) (err error) {
+	defer recoverError(&err)
+	matches := websocketCommentRegexp.FindStringSubmatch(commentLine)
+	if matches == nil {
+		return fmt.Errorf("Can not parse websocket comment \"%s\", expected: @Websocket client|server {object} dataType \"description\"", commentLine)
+	}
+	typeName, err := operation.registerType(matches[3])
+	if err != nil {
+		return err
+	}
+	message := WebsocketMessage{Model: typeName, Description: strings.Trim(matches[4], "\"")}
+	if matches[2] == "{array}" {
+		message.Model = "array[" + typeName + "]"
+	}
+
+	if operation.Websocket == nil {
+		operation.Websocket = &Websocket{}
+	}
+	if matches[1] == "client" {
+		operation.Websocket.Client = append(operation.Websocket.Client, message)
+	} else {
+		operation.Websocket.Server = append(operation.Websocket.Server, message)
+	}
+	return nil
+}
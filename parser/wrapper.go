@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// composedTypeRegexp matches Envelope{data=User,meta=[]Meta}: the Envelope model, with the type
+	// of its data and meta fields replaced by User and array of Meta
+	composedTypeRegexp = regexp.MustCompile(`^([\w\-\.\/]+)\{([^{}]+)\}$`)
+	// wrapperRegexp matches Envelope{data}, the value of @Wrapper: every success response is put in
+	// the data field of Envelope
+	wrapperRegexp = regexp.MustCompile(`^([\w\-\.\/]+)\{(\w+)\}$`)
+)
+
+// NoWrapper disables the general @Wrapper for an operation
+const NoWrapper = "none"
+
+type composedField struct {
+	Name string
+	Type string
+}
+
+// parseComposedType splits Envelope{data=User} into the base model and its replaced fields
+func parseComposedType(typeName string) (string, []composedField, error) {
+	matches := composedTypeRegexp.FindStringSubmatch(typeName)
+	if matches == nil {
+		return "", nil, fmt.Errorf("Can not parse type %s, expected: Model{field=Type}", typeName)
+	}
+	var fields []composedField
+	for _, field := range strings.Split(matches[2], ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return "", nil, fmt.Errorf("Can not parse field \"%s\" of type %s, expected: field=Type", field, typeName)
+		}
+		fields = append(fields, composedField{strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])})
+	}
+	return matches[1], fields, nil
+}
+
+// checkWrapper returns an error if wrapper is not a valid @Wrapper value
+func checkWrapper(wrapper string) error {
+	if wrapper != NoWrapper && !wrapperRegexp.MatchString(wrapper) {
+		return fmt.Errorf("Can not parse wrapper %s, expected: Model{field} or %s", wrapper, NoWrapper)
+	}
+	return nil
+}
+
+// wrapType returns the composed type which puts dataType in the field of wrapper
+func wrapType(wrapper string, dataType string, isArray bool) string {
+	matches := wrapperRegexp.FindStringSubmatch(wrapper)
+	if isArray {
+		dataType = "[]" + dataType
+	}
+	return matches[1] + "{" + matches[2] + "=" + dataType + "}"
+}
+
+// wrapper returns the @Wrapper of the operation, or the general one
+func (operation *Operation) wrapper() string {
+	wrapper := operation.Wrapper
+	if wrapper == "" && operation.parser != nil {
+		wrapper = operation.parser.Wrapper
+	}
+	if wrapper == NoWrapper {
+		return ""
+	}
+	return wrapper
+}
+
+// registerComposedType registers a copy of the base model of typeName, with the types of its fields
+// replaced. Its id is the base model id followed by the replacing types: Envelope_User for
+// Envelope{data=User}, Envelope_ArrayOfUser for Envelope{data=[]User}.
+func (operation *Operation) registerComposedType(typeName string) (string, error) {
+	base, fields, err := parseComposedType(typeName)
+	if err != nil {
+		return "", err
+	}
+	baseId, err := operation.registerType(base)
+	if err != nil {
+		return "", err
+	}
+	var baseModel *Model
+	for _, model := range operation.Models {
+		if model.Id == baseId {
+			baseModel = model
+		}
+	}
+	if baseModel == nil {
+		return "", fmt.Errorf("%s is not a struct, its fields can not be replaced in %s", base, typeName)
+	}
+
+	model := NewModel(operation.parser)
+	model.Required = baseModel.Required
+	model.Properties = make(map[string]*ModelProperty, len(baseModel.Properties))
+	for name, property := range baseModel.Properties {
+		model.Properties[name] = property
+	}
+
+	idParts := []string{baseId}
+	for _, field := range fields {
+		property, ok := model.Properties[field.Name]
+		if !ok {
+			return "", fmt.Errorf("%s has no field %s, used in %s", base, field.Name, typeName)
+		}
+		fieldType := strings.TrimPrefix(field.Type, "[]")
+		fieldTypeId, err := operation.registerType(fieldType)
+		if err != nil {
+			return "", err
+		}
+
+		composedProperty := *property
+		idPart := fieldTypeId[strings.LastIndex(fieldTypeId, ".")+1:]
+		if fieldType != field.Type {
+			composedProperty.Type = "array"
+			composedProperty.SetItemType(fieldTypeId)
+			idPart = "ArrayOf" + idPart
+		} else {
+			composedProperty.Type = fieldTypeId
+			composedProperty.Items = ModelPropertyItems{}
+		}
+		model.Properties[field.Name] = &composedProperty
+		idParts = append(idParts, idPart)
+	}
+	model.Id = strings.Join(idParts, "_")
+
+	operation.Models = append(operation.Models, model)
+	return model.Id, nil
+}
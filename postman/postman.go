@@ -0,0 +1,235 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+const CollectionSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Collection is a Postman Collection v2.1, see https://schema.getpostman.com/
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []*Item    `json:"item"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// Item is a folder (Item is set) or a request (Request is set)
+type Item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Item        []*Item  `json:"item,omitempty"`
+	Request     *Request `json:"request,omitempty"`
+}
+
+type Request struct {
+	Method      string   `json:"method"`
+	Description string   `json:"description,omitempty"`
+	Header      []Header `json:"header"`
+	Body        *Body    `json:"body,omitempty"`
+	Url         Url      `json:"url"`
+}
+
+type Header struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type Url struct {
+	Raw      string       `json:"raw"`
+	Host     []string     `json:"host"`
+	Path     []string     `json:"path"`
+	Query    []QueryParam `json:"query,omitempty"`
+	Variable []Variable   `json:"variable,omitempty"`
+}
+
+type QueryParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+type Variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+type Body struct {
+	Mode     string       `json:"mode"` // raw, formdata
+	Raw      string       `json:"raw,omitempty"`
+	FormData []FormParam  `json:"formdata,omitempty"`
+	Options  *BodyOptions `json:"options,omitempty"`
+}
+
+type FormParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Type        string `json:"type"` // text, file
+	ContentType string `json:"contentType,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type BodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// NewCollection converts the parsed operations into a collection with one folder per top level API.
+// Requests are sent to the {{baseUrl}} collection variable, which defaults to baseUrl.
+func NewCollection(parser *parser.Parser, baseUrl string) *Collection {
+	collection := &Collection{
+		Info: Info{
+			Name:        parser.Listing.Infos.Title,
+			Description: parser.Listing.Infos.FullDescription(),
+			Schema:      CollectionSchema,
+		},
+		Item: make([]*Item, 0, len(parser.TopLevelApis)),
+		Variable: []Variable{
+			{Key: "baseUrl", Value: baseUrl},
+		},
+	}
+	if collection.Info.Name == "" {
+		collection.Info.Name = "API"
+	}
+
+	apiKeys := make([]string, 0, len(parser.TopLevelApis))
+	for apiKey := range parser.TopLevelApis {
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Strings(apiKeys)
+
+	for _, apiKey := range apiKeys {
+		api := parser.TopLevelApis[apiKey]
+		folder := &Item{
+			Name: apiKey,
+			Item: make([]*Item, 0),
+		}
+		for _, ref := range parser.Listing.Apis {
+			if ref.Path == api.ResourcePath {
+				folder.Description = ref.Description
+			}
+		}
+		for _, subApi := range api.Apis {
+			for _, op := range subApi.Operations {
+				folder.Item = append(folder.Item, newRequestItem(api, subApi.Path, op))
+			}
+		}
+		collection.Item = append(collection.Item, folder)
+	}
+	return collection
+}
+
+func newRequestItem(api *parser.ApiDeclaration, apiPath string, op *parser.Operation) *Item {
+	item := &Item{
+		Name: op.Nickname,
+		Request: &Request{
+			Method:      op.HttpMethod,
+			Description: strings.TrimSpace(op.Summary + "\n\n" + op.Notes),
+			Header:      make([]Header, 0),
+		},
+	}
+	if item.Name == "" {
+		item.Name = op.HttpMethod + " " + apiPath
+	}
+	request := item.Request
+
+	pathParts := []string{}
+	for _, pathPart := range strings.Split(apiPath, "/") {
+		if pathPart == "" {
+			continue
+		}
+		if strings.HasPrefix(pathPart, "{") && strings.HasSuffix(pathPart, "}") {
+			pathPart = ":" + pathPart[1:len(pathPart)-1]
+		}
+		pathParts = append(pathParts, pathPart)
+	}
+	request.Url = Url{
+		Host: []string{"{{baseUrl}}"},
+		Path: pathParts,
+	}
+
+	if len(op.Produces) > 0 {
+		request.Header = append(request.Header, Header{Key: "Accept", Value: op.Produces[0]})
+	}
+
+	for _, param := range op.Parameters {
+		example := api.ExampleString(param.DataType)
+		switch param.ParamType {
+		case "path":
+			request.Url.Variable = append(request.Url.Variable, Variable{Key: param.Name, Value: example, Description: param.Description})
+		case "query":
+			request.Url.Query = append(request.Url.Query, QueryParam{Key: param.Name, Value: example, Description: param.Description, Disabled: !param.Required})
+		case "header":
+			request.Header = append(request.Header, Header{Key: param.Name, Value: example, Description: param.Description})
+		case "form":
+			if request.Body == nil {
+				request.Body = &Body{Mode: "formdata"}
+			}
+			formParam := FormParam{Key: param.Name, Type: "text", Value: example, ContentType: param.ContentType, Description: param.Description}
+			if strings.ToLower(param.DataType) == "file" {
+				formParam.Type = "file"
+				formParam.Value = ""
+			}
+			request.Body.FormData = append(request.Body.FormData, formParam)
+		case "body":
+			raw, _ := json.MarshalIndent(api.ExampleValue(param.DataType), "", "    ")
+			request.Body = &Body{Mode: "raw", Raw: string(raw), Options: &BodyOptions{}}
+			request.Body.Options.Raw.Language = "json"
+			request.Header = append(request.Header, Header{Key: "Content-Type", Value: parser.ContentTypeJson})
+		}
+	}
+
+	request.Url.Raw = "{{baseUrl}}/" + strings.Join(pathParts, "/")
+	if len(request.Url.Query) > 0 {
+		query := make([]string, 0, len(request.Url.Query))
+		for _, queryParam := range request.Url.Query {
+			if !queryParam.Disabled {
+				query = append(query, queryParam.Key+"="+queryParam.Value)
+			}
+		}
+		if len(query) > 0 {
+			request.Url.Raw += "?" + strings.Join(query, "&")
+		}
+	}
+	return item
+}
+
+// GenerateCollection writes the Postman collection to outputSpec, ./API.postman_collection.json by default
+func GenerateCollection(parser *parser.Parser, baseUrl string, outputSpec *string) error {
+	var filename string
+	if *outputSpec == "" {
+		filename = path.Join("./", "API.postman_collection.json")
+	} else {
+		filename = path.Join(*outputSpec)
+	}
+
+	json, err := json.MarshalIndent(NewCollection(parser, baseUrl), "", "    ")
+	if err != nil {
+		return fmt.Errorf("Can not serialise Postman collection to JSON: %v\n", err)
+	}
+
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create Postman collection file: %v\n", err)
+	}
+	defer fd.Close()
+
+	fd.Write(json)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/yvasiyarov/swagger/logger"
+	"github.com/yvasiyarov/swagger/output"
+)
+
+// startProfiling starts the CPU profile of -cpuprofile. The returned function stops it, and writes the
+// memory profile of -memprofile. The profiles are read with go tool pprof.
+func startProfiling(cpuProfile, memProfile string) (func(), error) {
+	var cpuFile *os.File
+	if cpuProfile != "" {
+		var err error
+		if cpuFile, err = os.OpenFile(cpuProfile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, output.FileMode); err != nil {
+			return nil, fmt.Errorf("Can not create -cpuprofile file: %v\n", err)
+		}
+		if err := pprof.StartCPUProfile(cpuFile); err != nil {
+			cpuFile.Close()
+			return nil, fmt.Errorf("Can not start the CPU profile: %v\n", err)
+		}
+	}
+
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				logger.Errorf("Can not write -cpuprofile file: %v", err)
+			} else {
+				logger.Infof("CPU profile written to %s", cpuProfile)
+			}
+		}
+		if memProfile != "" {
+			if err := writeMemProfile(memProfile); err != nil {
+				logger.Errorf("%v", err)
+			} else {
+				logger.Infof("Memory profile written to %s", memProfile)
+			}
+		}
+	}, nil
+}
+
+// writeMemProfile writes the allocations of the run, with the memory still in use after a garbage
+// collection, to fileName
+func writeMemProfile(fileName string) error {
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, output.FileMode)
+	if err != nil {
+		return fmt.Errorf("Can not create -memprofile file: %v\n", err)
+	}
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(file, 0); err != nil {
+		file.Close()
+		return fmt.Errorf("Can not write -memprofile file: %v\n", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("Can not write -memprofile file: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package raml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// mediaType is the default media type of the API
+const mediaType = parser.ContentTypeJson
+
+// GenerateRaml writes the API as RAML 1.0 (https://raml.org/) to outputSpec, ./API.raml by default
+func GenerateRaml(parser *parser.Parser, baseUri string, outputSpec *string) error {
+	var filename string
+	if *outputSpec == "" {
+		filename = path.Join("./", "API.raml")
+	} else {
+		filename = path.Join(*outputSpec)
+	}
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create RAML file: %v\n", err)
+	}
+	defer fd.Close()
+
+	fd.Write(Render(parser, baseUri))
+	return nil
+}
+
+// resource is a node of the RAML resource tree, one per path segment
+type resource struct {
+	segment    string
+	children   map[string]*resource
+	operations []*parser.Operation
+	api        *parser.ApiDeclaration
+}
+
+func newResource(segment string) *resource {
+	return &resource{
+		segment:  segment,
+		children: make(map[string]*resource),
+	}
+}
+
+func (r *resource) child(segment string) *resource {
+	child, ok := r.children[segment]
+	if !ok {
+		child = newResource(segment)
+		r.children[segment] = child
+	}
+	return child
+}
+
+// uriParameter returns the @Param of the {var} segment of node
+func (r *resource) uriParameter() (parser.Parameter, bool) {
+	if strings.HasPrefix(r.segment, "{") && strings.HasSuffix(r.segment, "}") {
+		name := r.segment[1 : len(r.segment)-1]
+		for _, op := range r.operations {
+			for _, param := range op.Parameters {
+				if param.ParamType == "path" && param.Name == name {
+					return param, true
+				}
+			}
+		}
+	}
+	return parser.Parameter{}, false
+}
+
+// Render maps top level APIs to resources, operations to methods and models to types
+func Render(p *parser.Parser, baseUri string) []byte {
+	var buf bytes.Buffer
+
+	title := p.Listing.Infos.Title
+	if title == "" {
+		title = "API"
+	}
+	buf.WriteString("#%RAML 1.0\n")
+	buf.WriteString("title: " + quote(title) + "\n")
+	if description := p.Listing.Infos.FullDescription(); description != "" {
+		buf.WriteString("description: " + quote(description) + "\n")
+	}
+	if p.Listing.ApiVersion != "" {
+		buf.WriteString("version: " + quote(p.Listing.ApiVersion) + "\n")
+	}
+	if baseUri != "" {
+		buf.WriteString("baseUri: " + quote(baseUri) + "\n")
+	}
+	buf.WriteString("mediaType: " + mediaType + "\n")
+
+	root := newResource("")
+	for _, api := range p.TopLevelApis {
+		for _, subApi := range api.Apis {
+			node := root
+			for _, segment := range strings.Split(subApi.Path, "/") {
+				if segment != "" {
+					node = node.child(segment)
+				}
+			}
+			node.api = api
+			node.operations = append(node.operations, subApi.Operations...)
+		}
+	}
+
+	// types are named after the last part of the model Ids, prefixed with the package when two models share it
+	models := p.GetModels()
+	ids := sortedModelIds(models)
+	names := parser.ShortModelNames(ids)
+	if len(models) > 0 {
+		buf.WriteString("types:\n")
+		for _, id := range ids {
+			writeType(&buf, models[id], names)
+		}
+	}
+
+	for _, segment := range sortedSegments(root) {
+		writeResource(&buf, root.children[segment], 0, names)
+	}
+	return buf.Bytes()
+}
+
+func writeType(buf *bytes.Buffer, model *parser.Model, names map[string]string) {
+	buf.WriteString(fmt.Sprintf("  %s:\n", modelTypeName(model.Id, names)))
+	buf.WriteString("    type: object\n")
+	if len(model.Properties) == 0 {
+		return
+	}
+	buf.WriteString("    properties:\n")
+
+	propertyNames := make([]string, 0, len(model.Properties))
+	for name := range model.Properties {
+		propertyNames = append(propertyNames, name)
+	}
+	sort.Strings(propertyNames)
+
+	for _, name := range propertyNames {
+		property := model.Properties[name]
+		typeName := property.Type
+		if typeName == "array" {
+			itemType := property.Items.Type
+			if itemType == "" {
+				itemType = property.Items.Ref
+			}
+			typeName = "array[" + itemType + "]"
+		}
+
+		required := false
+		for _, requiredName := range model.Required {
+			if requiredName == name {
+				required = true
+			}
+		}
+
+		buf.WriteString(fmt.Sprintf("      %s:\n", name))
+		buf.WriteString(fmt.Sprintf("        type: %s\n", ramlType(typeName, names)))
+		buf.WriteString(fmt.Sprintf("        required: %v\n", required))
+		if property.Description != "" {
+			buf.WriteString(fmt.Sprintf("        description: %s\n", quote(property.Description)))
+		}
+	}
+}
+
+func writeResource(buf *bytes.Buffer, node *resource, level int, names map[string]string) {
+	indent := strings.Repeat("  ", level)
+	buf.WriteString(fmt.Sprintf("%s/%s:\n", indent, node.segment))
+
+	if param, ok := node.uriParameter(); ok {
+		buf.WriteString(indent + "  uriParameters:\n")
+		writeParameter(buf, param, indent+"    ", names)
+	}
+	for _, op := range node.operations {
+		writeMethod(buf, node.api, op, indent+"  ", names)
+	}
+	for _, segment := range sortedSegments(node) {
+		writeResource(buf, node.children[segment], level+1, names)
+	}
+}
+
+func writeMethod(buf *bytes.Buffer, api *parser.ApiDeclaration, op *parser.Operation, indent string, names map[string]string) {
+	buf.WriteString(fmt.Sprintf("%s%s:\n", indent, strings.ToLower(op.HttpMethod)))
+	if op.Nickname != "" {
+		buf.WriteString(fmt.Sprintf("%s  displayName: %s\n", indent, quote(op.Nickname)))
+	}
+	if description := strings.TrimSpace(op.Summary + "\n\n" + op.Notes); description != "" {
+		buf.WriteString(fmt.Sprintf("%s  description: %s\n", indent, quote(description)))
+	}
+
+	for _, section := range []struct{ paramType, name string }{{"query", "queryParameters"}, {"header", "headers"}} {
+		isFirst := true
+		for _, param := range op.Parameters {
+			if param.ParamType != section.paramType {
+				continue
+			}
+			if isFirst {
+				buf.WriteString(fmt.Sprintf("%s  %s:\n", indent, section.name))
+				isFirst = false
+			}
+			writeParameter(buf, param, indent+"    ", names)
+		}
+	}
+
+	isFirstForm := true
+	for _, param := range op.Parameters {
+		switch param.ParamType {
+		case "body":
+			buf.WriteString(fmt.Sprintf("%s  body:\n", indent))
+			buf.WriteString(fmt.Sprintf("%s    %s:\n", indent, parser.ContentTypeJson))
+			writeBody(buf, api, param.DataType, indent+"      ", names)
+		case "form":
+			if isFirstForm {
+				buf.WriteString(fmt.Sprintf("%s  body:\n", indent))
+				buf.WriteString(fmt.Sprintf("%s    %s:\n", indent, parser.ContentTypeMultiPartFormData))
+				buf.WriteString(fmt.Sprintf("%s      properties:\n", indent))
+				isFirstForm = false
+			}
+			writeParameter(buf, param, indent+"        ", names)
+		}
+	}
+
+	if len(op.ResponseMessages) > 0 {
+		buf.WriteString(fmt.Sprintf("%s  responses:\n", indent))
+		for _, response := range op.ResponseMessages {
+			buf.WriteString(fmt.Sprintf("%s    %d:\n", indent, response.Code))
+			if response.Message != "" {
+				buf.WriteString(fmt.Sprintf("%s      description: %s\n", indent, quote(response.Message)))
+			}
+			if response.ResponseModel != "" {
+				buf.WriteString(fmt.Sprintf("%s      body:\n", indent))
+				buf.WriteString(fmt.Sprintf("%s        %s:\n", indent, parser.ContentTypeJson))
+				writeBody(buf, api, response.ResponseModel, indent+"          ", names)
+			}
+		}
+	}
+}
+
+func writeParameter(buf *bytes.Buffer, param parser.Parameter, indent string, names map[string]string) {
+	buf.WriteString(fmt.Sprintf("%s%s:\n", indent, param.Name))
+	if strings.ToLower(param.DataType) == "file" {
+		buf.WriteString(fmt.Sprintf("%s  type: file\n", indent))
+		if param.ContentType != "" {
+			buf.WriteString(fmt.Sprintf("%s  fileTypes: [%s]\n", indent, param.ContentType))
+		}
+	} else {
+		buf.WriteString(fmt.Sprintf("%s  type: %s\n", indent, ramlType(param.DataType, names)))
+	}
+	buf.WriteString(fmt.Sprintf("%s  required: %v\n", indent, param.Required))
+	if description := strings.Trim(param.Description, "\""); description != "" {
+		buf.WriteString(fmt.Sprintf("%s  description: %s\n", indent, quote(description)))
+	}
+}
+
+func writeBody(buf *bytes.Buffer, api *parser.ApiDeclaration, typeName string, indent string, names map[string]string) {
+	buf.WriteString(fmt.Sprintf("%stype: %s\n", indent, ramlType(typeName, names)))
+	if example, err := json.Marshal(api.ExampleValue(typeName)); err == nil {
+		buf.WriteString(fmt.Sprintf("%sexample: %s\n", indent, string(example)))
+	}
+}
+
+// ramlType maps swagger type names to RAML built-in types, models are referenced by their name in names
+func ramlType(typeName string, names map[string]string) string {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		return ramlType(typeName[len("array["):len(typeName)-1], names) + "[]"
+	}
+	switch typeName {
+	case "bool":
+		return "boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune", "uintptr":
+		return "integer"
+	case "float32", "float64", "complex64", "complex128":
+		return "number"
+	case "string", "error", "":
+		return "string"
+	case "Time":
+		return "datetime"
+	}
+	if strings.Contains(typeName, "interface") {
+		return "any"
+	}
+	return modelTypeName(typeName, names)
+}
+
+// modelTypeName returns the RAML type name of a model, its name in names, the last part of its Id otherwise
+func modelTypeName(modelId string, names map[string]string) string {
+	if name, ok := names[modelId]; ok {
+		return name
+	}
+	return parser.ShortModelName(modelId)
+}
+
+// quote returns s as a double quoted scalar. JSON strings are valid YAML
+func quote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func sortedModelIds(models map[string]*parser.Model) []string {
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sortedSegments(node *resource) []string {
+	segments := make([]string, 0, len(node.children))
+	for segment := range node.children {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+	return segments
+}
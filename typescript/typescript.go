@@ -0,0 +1,167 @@
+package typescript
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yvasiyarov/swagger/output"
+	"github.com/yvasiyarov/swagger/parser"
+)
+
+// GenerateDefinitions writes TypeScript interfaces of all parsed models to outputSpec, ./API.d.ts by default
+func GenerateDefinitions(parser *parser.Parser, outputSpec *string) error {
+	var filename string
+	if *outputSpec == "" {
+		filename = path.Join("./", "API.d.ts")
+	} else {
+		filename = path.Join(*outputSpec)
+	}
+	fd, err := output.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Can not create TypeScript definitions file: %v\n", err)
+	}
+	defer fd.Close()
+
+	fd.Write(Render(parser.TopLevelApis))
+	return nil
+}
+
+// Render emits one exported interface per model. Property names follow the json tags,
+// properties which are not required are optional.
+func Render(apis map[string]*parser.ApiDeclaration) []byte {
+	models := make(map[string]*parser.Model)
+	for _, api := range apis {
+		for id, model := range api.Models {
+			models[id] = model
+		}
+	}
+	ids := make([]string, 0, len(models))
+	for id := range models {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	names := parser.ShortModelNames(ids)
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by swagger. DO NOT EDIT.\n")
+	for _, id := range ids {
+		buf.WriteString("\n")
+		writeInterface(&buf, models[id], names)
+	}
+	return buf.Bytes()
+}
+
+// writeInterface writes the interface of a model, named after names, the interface names of the models
+func writeInterface(buf *bytes.Buffer, model *parser.Model, names map[string]string) {
+	buf.WriteString(fmt.Sprintf("/** %s */\n", model.Id))
+	buf.WriteString(fmt.Sprintf("export interface %s {\n", interfaceName(model.Id, names)))
+
+	propertyNames := make([]string, 0, len(model.Properties))
+	for name := range model.Properties {
+		propertyNames = append(propertyNames, name)
+	}
+	sort.Strings(propertyNames)
+
+	for _, name := range propertyNames {
+		property := model.Properties[name]
+		if property.Description != "" {
+			buf.WriteString(fmt.Sprintf("    /** %s */\n", strings.Replace(property.Description, "*/", "*\\/", -1)))
+		}
+
+		optional := "?"
+		for _, required := range model.Required {
+			if required == name {
+				optional = ""
+			}
+		}
+		readonly := ""
+		if property.ReadOnly {
+			readonly = "readonly "
+		}
+		typeName := propertyType(property, names)
+		if property.Nullable {
+			typeName += " | null"
+		}
+		buf.WriteString(fmt.Sprintf("    %s%s%s: %s;\n", readonly, propertyName(name), optional, typeName))
+	}
+	buf.WriteString("}\n")
+}
+
+func propertyType(property *parser.ModelProperty, names map[string]string) string {
+	if len(property.Enum) > 0 {
+		values := make([]string, len(property.Enum))
+		for i, value := range property.Enum {
+			if tsType(property.Type, names) == "number" {
+				values[i] = value
+			} else {
+				values[i] = strconv.Quote(value)
+			}
+		}
+		return strings.Join(values, " | ")
+	}
+	if property.Type == "array" {
+		itemType := property.Items.Type
+		if itemType == "" {
+			itemType = property.Items.Ref
+		}
+		return tsType("array["+itemType+"]", names)
+	}
+	return tsType(property.Type, names)
+}
+
+// tsType maps swagger type names to TypeScript types, models are referenced by interface name
+func tsType(typeName string, names map[string]string) string {
+	if strings.HasPrefix(typeName, "array[") && strings.HasSuffix(typeName, "]") {
+		itemType := typeName[len("array[") : len(typeName)-1]
+		if itemType == "byte" || itemType == "uint8" {
+			// encoding/json writes []byte as a base64 string
+			return "string"
+		}
+		return arrayOf(tsType(itemType, names))
+	}
+	switch typeName {
+	case "bool":
+		return "boolean"
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune", "uintptr",
+		"float32", "float64", "complex64", "complex128":
+		return "number"
+	case "string", "error", "Time":
+		return "string"
+	case "":
+		return "any"
+	}
+	if strings.Contains(typeName, "interface") {
+		return "any"
+	}
+	return interfaceName(typeName, names)
+}
+
+func arrayOf(typeName string) string {
+	if strings.Contains(typeName, " ") {
+		return "Array<" + typeName + ">"
+	}
+	return typeName + "[]"
+}
+
+// interfaceName is the name of the interface of a model: the last part of its Id, unless several models
+// have the same one
+func interfaceName(modelId string, names map[string]string) string {
+	if name, ok := names[modelId]; ok {
+		return name
+	}
+	return parser.ShortModelName(modelId)
+}
+
+// propertyName quotes names which are not valid identifiers
+func propertyName(name string) string {
+	for i, c := range name {
+		if !(c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			return strconv.Quote(name)
+		}
+	}
+	return name
+}
@@ -0,0 +1,97 @@
+// Package upload sends generated specs to API registries, like SwaggerHub, or to any URL accepting a PUT
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// SWAGGERHUB_PREFIX starts the targets of SwaggerHub APIs: swaggerhub:owner/api
+	SWAGGERHUB_PREFIX = "swaggerhub:"
+	// SWAGGERHUB_API is the URL of the SwaggerHub registry API
+	SWAGGERHUB_API = "https://api.swaggerhub.com/apis/"
+	// SWAGGERHUB_KEY_ENV is the environment variable of the SwaggerHub API key
+	SWAGGERHUB_KEY_ENV = "SWAGGERHUB_API_KEY"
+	// UPLOAD_TIMEOUT is the time an upload request can take, so an unresponsive target does not hang the generation
+	UPLOAD_TIMEOUT = 60 * time.Second
+)
+
+// Target is where a spec is uploaded
+type Target struct {
+	Method string
+	Url    string
+	Header http.Header
+	Client *http.Client
+}
+
+// NewTarget returns the target of an -upload value: swaggerhub:owner/api, which creates or updates
+// the version of the API with the key of $SWAGGERHUB_API_KEY, or a URL the spec is PUT to.
+// header is an optional "Name: value" header, e.g. "Authorization: Bearer ${TOKEN}", environment
+// variables are expanded in it so secrets stay out of the command line.
+func NewTarget(target, header, version string) (*Target, error) {
+	t := &Target{Method: "PUT", Url: target, Header: make(http.Header), Client: &http.Client{Timeout: UPLOAD_TIMEOUT}}
+	if header != "" {
+		parts := strings.SplitN(os.ExpandEnv(header), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("Can not parse upload header %s, expected: Name: value", header)
+		}
+		t.Header.Set(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	if !strings.HasPrefix(target, SWAGGERHUB_PREFIX) {
+		if parsedUrl, err := url.Parse(target); err != nil || parsedUrl.Host == "" {
+			return nil, fmt.Errorf("Can not upload to %s, expected: a URL or %sowner/api", target, SWAGGERHUB_PREFIX)
+		}
+		return t, nil
+	}
+
+	api := strings.Trim(strings.TrimPrefix(target, SWAGGERHUB_PREFIX), "/")
+	if parts := strings.Split(api, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("Can not upload to %s, expected: %sowner/api", target, SWAGGERHUB_PREFIX)
+	}
+	key := os.Getenv(SWAGGERHUB_KEY_ENV)
+	if key == "" && t.Header.Get("Authorization") == "" {
+		return nil, errors.New("Uploading to SwaggerHub needs the $" + SWAGGERHUB_KEY_ENV + " environment variable\n")
+	}
+	if key != "" {
+		t.Header.Set("Authorization", key)
+	}
+	query := url.Values{"force": {"true"}}
+	if version != "" {
+		query.Set("version", version)
+	}
+	t.Method = "POST"
+	t.Url = SWAGGERHUB_API + api + "?" + query.Encode()
+	return t, nil
+}
+
+// Upload sends the JSON spec to the target
+func (t *Target) Upload(spec []byte) error {
+	request, err := http.NewRequest(t.Method, t.Url, bytes.NewReader(spec))
+	if err != nil {
+		return fmt.Errorf("Can not upload the spec to %s: %v\n", t.Url, err)
+	}
+	for name, values := range t.Header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := t.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("Can not upload the spec to %s: %v\n", t.Url, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("Can not upload the spec to %s: %s %s\n", t.Url, response.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}